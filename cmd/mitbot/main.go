@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // The image has no zoneinfo, embed it for user timezones.
 
 	"github.com/ksysoev/make-it-public-tgbot/pkg/cmd"
 )
@@ -0,0 +1,17 @@
+package bot
+
+import "slices"
+
+// adminCommands lists the operator commands. They are available only to the users in Config.AdminIDs
+// and behave as unknown commands for everyone else, so that they aren't discoverable.
+var adminCommands = []string{"audit", "stats"}
+
+// isAdminCommand reports whether command is reserved for operators.
+func isAdminCommand(command string) bool {
+	return slices.Contains(adminCommands, command)
+}
+
+// isAdmin reports whether the Telegram user is in the configured admin allowlist.
+func (s *Service) isAdmin(userID int64) bool {
+	return slices.Contains(s.adminIDs, userID)
+}
@@ -0,0 +1,135 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAdminCommand(t *testing.T) {
+	assert.True(t, isAdminCommand("audit"))
+	assert.False(t, isAdminCommand("new_token"))
+	assert.False(t, isAdminCommand(""))
+}
+
+func TestService_IsAdmin(t *testing.T) {
+	svc := &Service{adminIDs: []int64{1, 42}}
+
+	assert.True(t, svc.isAdmin(42))
+	assert.False(t, svc.isAdmin(7))
+
+	assert.False(t, (&Service{}).isAdmin(42), "no admins are configured by default")
+}
+
+func TestHandleCommand_AdminOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantText string
+		userID   int64
+		isAdmin  bool
+	}{
+		{
+			name:     "admin runs the command",
+			userID:   42,
+			isAdmin:  true,
+			wantText: "audit entries",
+		},
+		{
+			name:     "non-admin sees an unknown command",
+			userID:   7,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.UnknownCommand),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			svc := &Service{
+				tg:       newTypingTgClient(t),
+				tokenSvc: mockTokenSvc,
+				adminIDs: []int64{42},
+			}
+
+			if tt.isAdmin {
+				mockTokenSvc.EXPECT().AuditLog(mock.Anything, "123", core.DefaultAuditLogLimit).Return(&core.Response{Message: "audit entries"}, nil)
+			}
+
+			resp, err := svc.handleCommand(context.Background(), &tgbotapi.Message{
+				Text:     "/audit 123",
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/audit")}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: tt.userID},
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestHandleCommand_Stats(t *testing.T) {
+	tests := []struct {
+		setupMocks func(mockTokenSvc *MockTokenService)
+		name       string
+		wantText   string
+		userID     int64
+		wantErr    bool
+	}{
+		{
+			name:   "admin gets the summary",
+			userID: 42,
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().Stats(mock.Anything).Return(&core.TokenStats{Users: 2, Web: 3, TCP: 1}, nil)
+			},
+			wantText: "📊 Bot usage\n\nActive tokens: 4\nWeb: 3\nTCP: 1\nUsers with tokens: 2",
+		},
+		{
+			name:     "non-admin sees an unknown command",
+			userID:   7,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.UnknownCommand),
+		},
+		{
+			name:   "service error",
+			userID: 42,
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().Stats(mock.Anything).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			svc := &Service{
+				tokenSvc: mockTokenSvc,
+				adminIDs: []int64{42},
+			}
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockTokenSvc)
+			}
+
+			resp, err := svc.handleCommand(context.Background(), &tgbotapi.Message{
+				Text:     "/stats",
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/stats")}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: tt.userID},
+			})
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, assert.AnError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
@@ -5,64 +5,289 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
 	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/google/uuid"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/bot/middleware"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
-	requestTimeout = 3 * time.Second
+	// DefaultRequestTimeout bounds the handling of a single update when Config.RequestTimeout is unset.
+	// Provider calls happen within it, so the provider HTTP timeout must be shorter.
+	DefaultRequestTimeout = 10 * time.Second
+
+	// ModePolling receives updates by long polling the Telegram API.
+	ModePolling = "polling"
+	// ModeWebhook receives updates pushed by Telegram to a webhook HTTP server.
+	ModeWebhook = "webhook"
+
+	defaultWebhookListen  = ":8080"
+	defaultMaxConcurrency = 30
+	// defaultMaxInputLength leaves room for the longest free-text input core accepts, a 1000 character feedback.
+	defaultMaxInputLength = 1024
+
+	// getMeTimeout bounds the GetMe call made on startup, so that a slow Telegram API can't hang it.
+	getMeTimeout = 10 * time.Second
 )
 
+// webhookSecretPattern matches the secret tokens Telegram accepts for setWebhook.
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
 // tgClient interface represents the Telegram bot API capabilities we use
 type tgClient interface {
 	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
 	StopReceivingUpdates()
 	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error)
 }
 
 // Config holds the configuration for the Telegram bot
 type Config struct {
 	TelegramToken string `mapstructure:"token"`
+	Mode          string `mapstructure:"mode"`           // "polling" (default) or "webhook"
+	WebhookURL    string `mapstructure:"webhook_url"`    // public URL Telegram delivers updates to in webhook mode
+	WebhookListen string `mapstructure:"webhook_listen"` // local address of the webhook server, ":8080" by default
+	// WebhookSecret is registered with Telegram as the webhook secret token, updates that don't carry it are rejected.
+	// It may contain 1-256 characters A-Z, a-z, 0-9, _ and -.
+	WebhookSecret string `mapstructure:"webhook_secret"`
+	// MaxConcurrency limits how many updates are handled at the same time, 30 by default.
+	MaxConcurrency int `mapstructure:"max_concurrency"`
+	// RejectWhenBusy replies "busy, try again" to updates over the MaxConcurrency limit instead of queueing them.
+	RejectWhenBusy bool `mapstructure:"reject_when_busy"`
+	// BusyTimeout replies "busy, try again" to updates that wait longer than this for a free slot,
+	// 0 keeps them waiting until the request times out.
+	BusyTimeout time.Duration `mapstructure:"busy_timeout"`
+	// RequestTimeout bounds the handling of a single update, including provider calls, DefaultRequestTimeout by default.
+	// It must be longer than the provider HTTP timeout (mit.timeout), otherwise slow provider calls are cut short.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+	// ShutdownTimeout bounds how long shutdown waits for in-flight updates to finish,
+	// by default it matches the request timeout.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// AdminIDs lists the Telegram user IDs allowed to use operator commands such as /audit.
+	AdminIDs []int64 `mapstructure:"admin_ids"`
+	// FeedbackChatIDs lists the chats /feedback messages are forwarded to, none by default.
+	FeedbackChatIDs []int64 `mapstructure:"feedback_chat_ids"`
+	// RequireMention makes the bot ignore commands in group chats unless they are addressed to it,
+	// e.g. /new_token@MyBot. Commands addressed to other bots are always ignored.
+	RequireMention bool `mapstructure:"require_mention"`
+	// IgnoreNonCommands suppresses the "not a command" reply to messages that are neither commands nor answers
+	// to a question, which is noisy in busy chats.
+	IgnoreNonCommands bool `mapstructure:"ignore_non_commands"`
+	// ChatSendInterval is the pace of messages sent to a single chat once ChatSendBurst messages were sent
+	// in a row, one per second by default.
+	ChatSendInterval time.Duration `mapstructure:"chat_send_interval"`
+	// ChatSendBurst is the number of messages sent to a chat without pacing, 3 by default.
+	ChatSendBurst int `mapstructure:"chat_send_burst"`
+	// GlobalSendRate caps the messages sent to all chats together per second, 30 by default.
+	GlobalSendRate int `mapstructure:"global_send_rate"`
+	// MaxInputLength caps the length in characters of command arguments and answers, 1024 by default.
+	MaxInputLength int `mapstructure:"max_input_length"`
+}
+
+// Validate checks that the token is set and the mode settings are consistent.
+// It returns every problem found joined into a single error.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.TelegramToken == "" {
+		errs = append(errs, errors.New("token is required"))
+	}
+
+	switch c.Mode {
+	case "", ModePolling:
+	case ModeWebhook:
+		if c.WebhookURL == "" {
+			errs = append(errs, errors.New("webhook_url is required in webhook mode"))
+		} else if err := validateURL(c.WebhookURL); err != nil {
+			errs = append(errs, fmt.Errorf("webhook_url is invalid: %w", err))
+		}
+
+		if c.WebhookSecret != "" && !webhookSecretPattern.MatchString(c.WebhookSecret) {
+			errs = append(errs, errors.New("webhook_secret must be 1-256 characters of A-Z, a-z, 0-9, _ and -"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported mode: %q", c.Mode))
+	}
+
+	if c.RequestTimeout < 0 {
+		errs = append(errs, fmt.Errorf("request_timeout must not be negative, got %s", c.RequestTimeout))
+	}
+
+	if c.ChatSendInterval < 0 {
+		errs = append(errs, fmt.Errorf("chat_send_interval must not be negative, got %s", c.ChatSendInterval))
+	}
+
+	if c.ChatSendBurst < 0 {
+		errs = append(errs, fmt.Errorf("chat_send_burst must not be negative, got %d", c.ChatSendBurst))
+	}
+
+	if c.GlobalSendRate < 0 {
+		errs = append(errs, fmt.Errorf("global_send_rate must not be negative, got %d", c.GlobalSendRate))
+	}
+
+	if c.MaxInputLength < 0 {
+		errs = append(errs, fmt.Errorf("max_input_length must not be negative, got %d", c.MaxInputLength))
+	}
+
+	return errors.Join(errs...)
+}
+
+// EffectiveRequestTimeout returns the configured request timeout or DefaultRequestTimeout when it is unset.
+func (c *Config) EffectiveRequestTimeout() time.Duration {
+	if c.RequestTimeout > 0 {
+		return c.RequestTimeout
+	}
+
+	return DefaultRequestTimeout
+}
+
+// validateURL checks that raw is an absolute URL with a scheme and a host.
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute URL", raw)
+	}
+
+	return nil
 }
 
 type TokenService interface {
 	CreateToken(ctx context.Context, userID string) (*core.Response, error)
+	CreateTokenWithExpiration(ctx context.Context, userID string, days int) (*core.Response, error)
+	PreviewToken(ctx context.Context, userID string, days int) (*core.Response, error)
 	RevokeToken(ctx context.Context, userID string) (*core.Response, error)
+	ConfirmRevokeAll(ctx context.Context, userID string) (*core.Response, error)
+	RenewToken(ctx context.Context, userID string) (*core.Response, error)
 	ListTokens(ctx context.Context, userID string) (*core.Response, error)
+	ExportTokens(ctx context.Context, userID string) ([]core.TokenExport, error)
+	WhoAmI(ctx context.Context, userID string) (*core.Response, error)
+	SetTimezone(ctx context.Context, userID, timezone string) (*core.Response, error)
 	HandleMessage(ctx context.Context, userID string, message string) (*core.Response, error)
 	ResetConversation(ctx context.Context, userID string) error
+	GoBack(ctx context.Context, userID string) (*core.Response, error)
+	AuditLog(ctx context.Context, userID string, limit int) (*core.Response, error)
+	GetRecentActions(ctx context.Context, userID string, n int) ([]core.AuditEntry, error)
+	Stats(ctx context.Context) (*core.TokenStats, error)
+	SubmitFeedback(ctx context.Context, userID, text string) (*core.Response, error)
+	SetDefaultExpiration(ctx context.Context, userID string, days int) (*core.Response, error)
+	ClearDefaultExpiration(ctx context.Context, userID string) (*core.Response, error)
+	HasActiveConversation(ctx context.Context, userID string) (bool, error)
+	RestartExpiredConversation(ctx context.Context, userID string) (*core.Response, error)
+	SaveUserChat(ctx context.Context, userID string, chatID int64) error
+	DeactivateUserChat(ctx context.Context, userID string) error
+	WatchExpiredTokens(ctx context.Context) (<-chan core.ExpiryNotice, error)
 }
 
 type Service struct {
-	tg       tgClient
-	tokenSvc TokenService
-	handler  Handler
-	token    string
+	tg              tgClient
+	tokenSvc        TokenService
+	handler         Handler
+	metrics         *middleware.Metrics
+	limiter         *sendLimiter
+	token           string
+	version         string
+	username        string
+	botID           int64
+	mode            string
+	webhookURL      string
+	webhookListen   string
+	webhookSecret   string
+	maxConcurrency  int
+	maxInputLength  int
+	rejectWhenBusy  bool
+	requireMention  bool
+	ignoreNonCmds   bool
+	busyTimeout     time.Duration
+	requestTimeout  time.Duration
+	shutdownTimeout time.Duration
+	adminIDs        []int64
+	feedbackChatIDs []int64
 }
 
 // New initializes a new Service with the given configuration and returns an error if the configuration is invalid.
-func New(cfg *Config, tokenSvc TokenService) (*Service, error) {
+// Handler metrics are registered with reg; pass nil to keep them unregistered.
+// version is the build version reported to users by /ping.
+func New(cfg *Config, tokenSvc TokenService, reg prometheus.Registerer, version string) (*Service, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config cannot be nil")
 	}
 
-	if cfg.TelegramToken == "" {
-		return nil, fmt.Errorf("telegram token cannot be empty")
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
-	bot, err := tgbotapi.NewBotAPI(cfg.TelegramToken)
+	mode := cfg.Mode
+	if mode == "" {
+		mode = ModePolling
+	}
+
+	webhookListen := cfg.WebhookListen
+	if webhookListen == "" {
+		webhookListen = defaultWebhookListen
+	}
+
+	maxConcurrency := cfg.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultMaxConcurrency
+	}
+
+	maxInputLength := cfg.MaxInputLength
+	if maxInputLength <= 0 {
+		maxInputLength = defaultMaxInputLength
+	}
+
+	requestTimeout := cfg.EffectiveRequestTimeout()
+
+	shutdownTimeout := cfg.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = requestTimeout
+	}
+
+	bot, err := connect(cfg.TelegramToken, tgbotapi.APIEndpoint, getMeTimeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Telegram bot: %w", err)
 	}
 
+	slog.Info(fmt.Sprintf("Started as @%s (id=%d)", bot.Self.UserName, bot.Self.ID),
+		slog.String("username", bot.Self.UserName),
+		slog.Int64("bot_id", bot.Self.ID),
+	)
+
 	s := &Service{
-		token:    cfg.TelegramToken,
-		tg:       bot,
-		tokenSvc: tokenSvc,
+		token:           cfg.TelegramToken,
+		version:         version,
+		username:        bot.Self.UserName,
+		botID:           bot.Self.ID,
+		tg:              bot,
+		tokenSvc:        tokenSvc,
+		metrics:         middleware.NewMetrics(commands, middleware.WithRegisterer(reg)),
+		limiter:         newSendLimiter(cfg.ChatSendInterval, cfg.ChatSendBurst, cfg.GlobalSendRate),
+		mode:            mode,
+		webhookURL:      cfg.WebhookURL,
+		webhookListen:   webhookListen,
+		webhookSecret:   cfg.WebhookSecret,
+		maxConcurrency:  maxConcurrency,
+		maxInputLength:  maxInputLength,
+		rejectWhenBusy:  cfg.RejectWhenBusy,
+		requireMention:  cfg.RequireMention,
+		ignoreNonCmds:   cfg.IgnoreNonCommands,
+		busyTimeout:     cfg.BusyTimeout,
+		requestTimeout:  requestTimeout,
+		shutdownTimeout: shutdownTimeout,
+		adminIDs:        cfg.AdminIDs,
+		feedbackChatIDs: cfg.FeedbackChatIDs,
 	}
 
 	s.handler = s.setupHandler()
@@ -70,6 +295,29 @@ func New(cfg *Config, tokenSvc TokenService) (*Service, error) {
 	return s, nil
 }
 
+// connect creates the Telegram bot API client, which identifies the bot with GetMe.
+// The GetMe call gives up after timeout, afterwards the client has no timeout because long polling keeps requests open.
+func connect(token, endpoint string, timeout time.Duration) (*tgbotapi.BotAPI, error) {
+	bot, err := tgbotapi.NewBotAPIWithClient(token, endpoint, &http.Client{Timeout: timeout})
+	if err != nil {
+		return nil, err
+	}
+
+	bot.Client = &http.Client{}
+
+	return bot, nil
+}
+
+// Username returns the username of the bot without the leading @.
+func (s *Service) Username() string {
+	return s.username
+}
+
+// ID returns the Telegram user ID of the bot.
+func (s *Service) ID() int64 {
+	return s.botID
+}
+
 func (s *Service) processUpdate(ctx context.Context, update *tgbotapi.Update) {
 	if update.Message == nil {
 		return
@@ -83,13 +331,13 @@ func (s *Service) processUpdate(ctx context.Context, update *tgbotapi.Update) {
 	var wg sync.WaitGroup
 	defer wg.Wait()
 
-	ctx, cancel := context.WithCancel(ctx)
+	handlerCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	wg.Add(1)
 	defer wg.Done() // Ensure wg.Done() is called when the function returns
 
-	msgConfig, err := s.handler.Handle(ctx, msg)
+	replies, err := s.handler.Handle(handlerCtx, msg)
 
 	if errors.Is(err, context.Canceled) {
 		slog.InfoContext(ctx, "Request cancelled",
@@ -104,28 +352,48 @@ func (s *Service) processUpdate(ctx context.Context, update *tgbotapi.Update) {
 		return
 	}
 
-	// Skip sending if message is empty
-	if msgConfig.Text == "" {
+	if len(replies) == 0 {
 		return
 	}
 	cancel()
 
-	// Send response
-	if _, err := s.tg.Send(msgConfig); err != nil {
-		slog.ErrorContext(ctx, "Failed to send message",
-			slog.Any("error", err),
-		)
+	// Send the replies in order, a later reply makes no sense once an earlier one is lost.
+	for _, reply := range replies {
+		// Skip sending if message is empty
+		if reply.Text == "" {
+			continue
+		}
+
+		if err := s.sendMessage(ctx, reply); err != nil {
+			slog.ErrorContext(ctx, "Failed to send message",
+				slog.Any("error", err),
+			)
+
+			return
+		}
 	}
 }
 
+// Run receives updates in the configured mode and processes them until ctx is cancelled.
 func (s *Service) Run(ctx context.Context) error {
-	slog.InfoContext(ctx, "Starting Telegram bot")
+	slog.InfoContext(ctx, "Starting Telegram bot", slog.String("mode", s.mode))
+
+	s.registerCommands(ctx)
+
+	if s.mode == ModeWebhook {
+		return s.runWebhook(ctx)
+	}
 
 	updateConfig := tgbotapi.NewUpdate(0)
 	updateConfig.Timeout = 30
 
-	updates := s.tg.GetUpdatesChan(updateConfig)
+	return s.serve(ctx, s.tg.GetUpdatesChan(updateConfig), s.tg.StopReceivingUpdates)
+}
 
+// serve processes updates from the channel until it is closed or ctx is cancelled, each within requestTimeout.
+// On cancellation it calls stop to stop receiving updates and waits up to shutdownTimeout for in-flight requests to finish.
+// Request contexts are not cancelled by shutdown, so in-flight provider calls get the chance to complete.
+func (s *Service) serve(ctx context.Context, updates <-chan tgbotapi.Update, stop func()) error {
 	var wg sync.WaitGroup
 
 	for {
@@ -140,7 +408,7 @@ func (s *Service) Run(ctx context.Context) error {
 			go func() {
 				defer wg.Done()
 
-				reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+				reqCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.requestTimeout)
 
 				// nolint:staticcheck // don't want to have dependecy on cmd package here for now
 				reqCtx = context.WithValue(reqCtx, "req_id", uuid.New().String())
@@ -152,7 +420,7 @@ func (s *Service) Run(ctx context.Context) error {
 
 		case <-ctx.Done():
 			slog.Info("Starting graceful shutdown")
-			s.tg.StopReceivingUpdates()
+			stop()
 
 			// Wait for ongoing message processors with a timeout
 			done := make(chan struct{})
@@ -164,8 +432,8 @@ func (s *Service) Run(ctx context.Context) error {
 			select {
 			case <-done:
 				slog.InfoContext(ctx, "Graceful shutdown completed")
-			case <-time.After(requestTimeout):
-				slog.Warn("Graceful shutdown timed out after 30 seconds")
+			case <-time.After(s.shutdownTimeout):
+				slog.Warn("Graceful shutdown timed out", slog.Duration("timeout", s.shutdownTimeout))
 			}
 
 			return nil
@@ -1,14 +1,22 @@
 package bot
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/bot/middleware"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/i18n"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
@@ -27,11 +35,21 @@ func TestNew(t *testing.T) {
 			cfg:     &Config{},
 			wantErr: true,
 		},
+		{
+			name:    "unsupported mode",
+			cfg:     &Config{TelegramToken: "test-token", Mode: "carrier-pigeon"},
+			wantErr: true,
+		},
+		{
+			name:    "webhook mode without url",
+			cfg:     &Config{TelegramToken: "test-token", Mode: ModeWebhook},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := New(tt.cfg, &MockTokenService{})
+			_, err := New(tt.cfg, &MockTokenService{}, nil, "test")
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -41,11 +59,143 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestConnect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/bottest-token/getMe", r.URL.Path)
+
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"id":42,"is_bot":true,"first_name":"Test","username":"TestBot"}}`))
+	}))
+	defer server.Close()
+
+	bot, err := connect("test-token", server.URL+"/bot%s/%s", time.Second)
+	require.NoError(t, err)
+
+	assert.Equal(t, "TestBot", bot.Self.UserName)
+	assert.Equal(t, int64(42), bot.Self.ID)
+	assert.Equal(t, &http.Client{}, bot.Client, "client must not keep the GetMe timeout for long polling")
+}
+
+func TestConnect_Timeout(t *testing.T) {
+	done := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	start := time.Now()
+
+	_, err := connect("test-token", server.URL+"/bot%s/%s", 50*time.Millisecond)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestService_Identity(t *testing.T) {
+	svc := &Service{username: "TestBot", botID: 42}
+
+	assert.Equal(t, "TestBot", svc.Username())
+	assert.Equal(t, int64(42), svc.ID())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr []string
+	}{
+		{
+			name: "polling by default",
+			cfg:  Config{TelegramToken: "test-token"},
+		},
+		{
+			name: "webhook mode",
+			cfg:  Config{TelegramToken: "test-token", Mode: ModeWebhook, WebhookURL: "https://bot.example.com/hook"},
+		},
+		{
+			name:    "missing token and unsupported mode",
+			cfg:     Config{Mode: "carrier-pigeon"},
+			wantErr: []string{"token is required", `unsupported mode: "carrier-pigeon"`},
+		},
+		{
+			name:    "webhook mode without url",
+			cfg:     Config{TelegramToken: "test-token", Mode: ModeWebhook},
+			wantErr: []string{"webhook_url is required in webhook mode"},
+		},
+		{
+			name:    "webhook mode with relative url",
+			cfg:     Config{TelegramToken: "test-token", Mode: ModeWebhook, WebhookURL: "/hook"},
+			wantErr: []string{"webhook_url is invalid"},
+		},
+		{
+			name: "webhook mode with secret",
+			cfg: Config{
+				TelegramToken: "test-token",
+				Mode:          ModeWebhook,
+				WebhookURL:    "https://bot.example.com/hook",
+				WebhookSecret: "s3cret_Token-1",
+			},
+		},
+		{
+			name: "webhook mode with invalid secret",
+			cfg: Config{
+				TelegramToken: "test-token",
+				Mode:          ModeWebhook,
+				WebhookURL:    "https://bot.example.com/hook",
+				WebhookSecret: "not a valid secret!",
+			},
+			wantErr: []string{"webhook_secret must be 1-256 characters"},
+		},
+		{
+			name:    "negative request timeout",
+			cfg:     Config{TelegramToken: "test-token", RequestTimeout: -time.Second},
+			wantErr: []string{"request_timeout must not be negative"},
+		},
+		{
+			name: "negative send limits",
+			cfg: Config{
+				TelegramToken:    "test-token",
+				ChatSendInterval: -time.Second,
+				ChatSendBurst:    -1,
+				GlobalSendRate:   -1,
+			},
+			wantErr: []string{
+				"chat_send_interval must not be negative",
+				"chat_send_burst must not be negative",
+				"global_send_rate must not be negative",
+			},
+		},
+		{
+			name:    "negative max input length",
+			cfg:     Config{TelegramToken: "test-token", MaxInputLength: -1},
+			wantErr: []string{"max_input_length must not be negative"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			for _, want := range tt.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
 func TestHandle(t *testing.T) {
 	mockTokenSvc := NewMockTokenService(t)
 	svc := &Service{
 		token:    "test-token",
-		tg:       NewMocktgClient(t),
+		tg:       newTypingTgClient(t),
 		tokenSvc: mockTokenSvc,
 	}
 
@@ -77,7 +227,7 @@ func TestHandle(t *testing.T) {
 			setupMocks: func() {
 				mockTokenSvc.EXPECT().ResetConversation(mock.Anything, "456").Return(nil)
 			},
-			wantText: welcomeMessage,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.Welcome),
 			wantErr:  false,
 		},
 		{
@@ -99,7 +249,7 @@ func TestHandle(t *testing.T) {
 				},
 			},
 			setupMocks: func() {},
-			wantText:   helpMessage,
+			wantText:   helpMessage(i18n.DefaultLang, ""),
 			wantErr:    false,
 		},
 		{
@@ -198,7 +348,7 @@ func TestHandle(t *testing.T) {
 				},
 			},
 			setupMocks: func() {},
-			wantText:   unknownCommandMessage,
+			wantText:   i18n.Message(i18n.DefaultLang, i18n.UnknownCommand),
 			wantErr:    false,
 		},
 		{
@@ -213,11 +363,9 @@ func TestHandle(t *testing.T) {
 				},
 			},
 			setupMocks: func() {
-				mockTokenSvc.EXPECT().HandleMessage(mock.Anything, "456", "hello").Return(&core.Response{
-					Message: notCommandMessage,
-				}, nil)
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(false, nil)
 			},
-			wantText: notCommandMessage,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.NotCommand),
 			wantErr:  false,
 		},
 		{
@@ -236,6 +384,7 @@ func TestHandle(t *testing.T) {
 					Message: "Response to text message",
 					Answers: []string{"Option1", "Option2"},
 				}
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(true, nil)
 				mockTokenSvc.EXPECT().HandleMessage(mock.Anything, "456", "some text message").Return(response, nil)
 			},
 			wantText: "Response to text message",
@@ -253,6 +402,7 @@ func TestHandle(t *testing.T) {
 				},
 			},
 			setupMocks: func() {
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(true, nil)
 				mockTokenSvc.EXPECT().HandleMessage(mock.Anything, "456", "some text message").Return(nil, errors.New("handling error"))
 			},
 			wantErr: true,
@@ -262,7 +412,9 @@ func TestHandle(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockTokenSvc.ExpectedCalls = nil
+			mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 			tt.setupMocks()
+			expectNoConversation(mockTokenSvc)
 
 			msg, err := svc.Handle(context.Background(), tt.message)
 			if tt.wantErr {
@@ -292,7 +444,7 @@ func TestProcessUpdate(t *testing.T) {
 		tokenSvc: mockTokenSvc,
 	}
 
-	svc.handler = svc
+	svc.handler = middleware.SingleHandlerFunc(svc.Handle)
 
 	tests := []struct {
 		update     *tgbotapi.Update
@@ -337,9 +489,232 @@ func TestProcessUpdate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockTg.ExpectedCalls = nil
 			mockTokenSvc.ExpectedCalls = nil
+			mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 			tt.setupMocks()
+			expectNoConversation(mockTokenSvc)
 
 			svc.processUpdate(context.Background(), tt.update)
 		})
 	}
 }
+
+func TestProcessUpdate_IgnoreNonCommands(t *testing.T) {
+	tests := []struct {
+		msg  *tgbotapi.Message
+		name string
+	}{
+		{
+			name: "plain text",
+			msg:  &tgbotapi.Message{Text: "hello", Chat: &tgbotapi.Chat{ID: 123}, From: &tgbotapi.User{ID: 456}},
+		},
+		{
+			name: "message without text",
+			msg:  &tgbotapi.Message{Sticker: &tgbotapi.Sticker{}, Chat: &tgbotapi.Chat{ID: 123}, From: &tgbotapi.User{ID: 456}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The Telegram client mock fails the test on any Send call.
+			mockTokenSvc := NewMockTokenService(t)
+			mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, "456", int64(123)).Return(nil)
+			expectNoConversation(mockTokenSvc)
+
+			svc := &Service{
+				tg:            NewMocktgClient(t),
+				tokenSvc:      mockTokenSvc,
+				ignoreNonCmds: true,
+			}
+			svc.handler = middleware.SingleHandlerFunc(svc.Handle)
+
+			svc.processUpdate(context.Background(), &tgbotapi.Update{Message: tt.msg})
+		})
+	}
+}
+
+func TestHandle_IgnoreNonCommandsAnswersCommands(t *testing.T) {
+	mockTokenSvc := NewMockTokenService(t)
+	mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, "456", int64(123)).Return(nil)
+	mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(true, nil)
+	mockTokenSvc.EXPECT().HandleMessage(mock.Anything, "456", "TCP").Return(&core.Response{Message: "What is the expiration period?"}, nil)
+
+	svc := &Service{tg: NewMocktgClient(t), tokenSvc: mockTokenSvc, ignoreNonCmds: true}
+
+	resp, err := svc.Handle(context.Background(), &tgbotapi.Message{Text: "TCP", Chat: &tgbotapi.Chat{ID: 123}, From: &tgbotapi.User{ID: 456}})
+	require.NoError(t, err)
+	assert.Equal(t, "What is the expiration period?", resp.Text)
+}
+
+func TestProcessUpdate_MultipleReplies(t *testing.T) {
+	tests := []struct {
+		sendErr  error
+		name     string
+		wantSent []string
+	}{
+		{name: "sent in order", wantSent: []string{"header", "body"}},
+		{name: "stops on first send error", sendErr: assert.AnError, wantSent: []string{"header"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTg := NewMocktgClient(t)
+
+			var sent []string
+
+			mockTg.EXPECT().Send(mock.Anything).Run(func(c tgbotapi.Chattable) {
+				sent = append(sent, c.(tgbotapi.MessageConfig).Text)
+			}).Return(tgbotapi.Message{}, tt.sendErr)
+
+			svc := &Service{
+				tg: mockTg,
+				handler: middleware.HandlerFunc(func(_ context.Context, msg *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
+					return []tgbotapi.MessageConfig{
+						tgbotapi.NewMessage(msg.Chat.ID, "header"),
+						{},
+						tgbotapi.NewMessage(msg.Chat.ID, "body"),
+					}, nil
+				}),
+			}
+
+			svc.processUpdate(context.Background(), &tgbotapi.Update{Message: &tgbotapi.Message{Text: "ping", Chat: &tgbotapi.Chat{ID: 123}}})
+
+			assert.Equal(t, tt.wantSent, sent)
+		})
+	}
+}
+
+func TestServe_Shutdown(t *testing.T) {
+	tests := []struct {
+		name            string
+		shutdownTimeout time.Duration
+		wantSent        bool
+		wantLog         string
+	}{
+		{
+			name:            "waits for in-flight update",
+			shutdownTimeout: time.Second,
+			wantSent:        true,
+			wantLog:         "Graceful shutdown completed",
+		},
+		{
+			name:            "gives up after configured timeout",
+			shutdownTimeout: 50 * time.Millisecond,
+			wantLog:         "timeout=50ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			prev := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+			defer slog.SetDefault(prev)
+
+			mockTg := NewMocktgClient(t)
+			started := make(chan struct{})
+			sent := make(chan struct{})
+			release := make(chan struct{})
+			handled := make(chan struct{})
+
+			defer func() {
+				close(release)
+				<-handled
+			}()
+
+			svc := &Service{
+				tg:              mockTg,
+				requestTimeout:  time.Second,
+				shutdownTimeout: tt.shutdownTimeout,
+				handler: middleware.SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+					defer close(handled)
+
+					close(started)
+
+					if !tt.wantSent {
+						// Outlive the shutdown timeout and produce nothing to send.
+						<-release
+						return tgbotapi.MessageConfig{}, nil
+					}
+
+					select {
+					case <-time.After(100 * time.Millisecond):
+						return tgbotapi.NewMessage(msg.Chat.ID, "pong"), nil
+					case <-ctx.Done():
+						return tgbotapi.MessageConfig{}, ctx.Err()
+					}
+				}),
+			}
+
+			if tt.wantSent {
+				mockTg.EXPECT().Send(mock.Anything).Run(func(tgbotapi.Chattable) { close(sent) }).Return(tgbotapi.Message{}, nil).Once()
+			}
+
+			updates := make(chan tgbotapi.Update, 1)
+			updates <- tgbotapi.Update{Message: &tgbotapi.Message{Text: "ping", Chat: &tgbotapi.Chat{ID: 123}}}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+
+			go func() { done <- svc.serve(ctx, updates, func() {}) }()
+
+			<-started
+			cancel()
+
+			select {
+			case err := <-done:
+				require.NoError(t, err)
+			case <-time.After(2 * time.Second):
+				t.Fatal("serve did not return")
+			}
+
+			if tt.wantSent {
+				select {
+				case <-sent:
+				default:
+					t.Fatal("in-flight update was not answered before shutdown completed")
+				}
+			}
+
+			assert.Contains(t, buf.String(), tt.wantLog)
+		})
+	}
+}
+
+func TestConfig_EffectiveRequestTimeout(t *testing.T) {
+	assert.Equal(t, DefaultRequestTimeout, (&Config{}).EffectiveRequestTimeout())
+	assert.Equal(t, 4*time.Second, (&Config{RequestTimeout: 4 * time.Second}).EffectiveRequestTimeout())
+}
+
+func TestServe_RequestTimeout(t *testing.T) {
+	deadlines := make(chan time.Duration, 1)
+
+	svc := &Service{
+		requestTimeout:  7 * time.Second,
+		shutdownTimeout: time.Second,
+		handler: middleware.SingleHandlerFunc(func(ctx context.Context, _ *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			deadline, ok := ctx.Deadline()
+			require.True(t, ok, "request context must have a deadline")
+
+			deadlines <- time.Until(deadline)
+
+			return tgbotapi.MessageConfig{}, nil
+		}),
+	}
+
+	updates := make(chan tgbotapi.Update, 1)
+	updates <- tgbotapi.Update{Message: &tgbotapi.Message{Text: "ping", Chat: &tgbotapi.Chat{ID: 123}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = svc.serve(ctx, updates, func() {}) }()
+
+	select {
+	case remaining := <-deadlines:
+		assert.InDelta(t, 7*time.Second, remaining, float64(time.Second))
+	case <-time.After(2 * time.Second):
+		t.Fatal("update was not handled")
+	}
+}
@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxMessageLength is the longest text Telegram accepts in a single message, in characters.
+const maxMessageLength = 4096
+
+// sendMessage delivers msg to Telegram, split into several messages when its text exceeds maxMessageLength.
+// The parts are sent in order and sending stops at the first failure.
+func (s *Service) sendMessage(ctx context.Context, msg tgbotapi.MessageConfig) error {
+	for _, part := range splitMessage(msg, maxMessageLength) {
+		if _, err := s.send(ctx, part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitMessage splits msg into messages with at most limit characters of text each, breaking at line boundaries
+// where possible. Every part keeps the chat and the parse mode of msg, the reply markup is attached to the last
+// part only, so that a keyboard shows up below the whole reply. A message within the limit is returned as is.
+// Markdown entities spanning the break, such as a multi-line code block, are not balanced between the parts.
+func splitMessage(msg tgbotapi.MessageConfig, limit int) []tgbotapi.MessageConfig {
+	chunks := splitText(msg.Text, limit)
+	if len(chunks) == 1 {
+		return []tgbotapi.MessageConfig{msg}
+	}
+
+	parts := make([]tgbotapi.MessageConfig, len(chunks))
+
+	for i, chunk := range chunks {
+		part := msg
+		part.Text = chunk
+
+		if i < len(chunks)-1 {
+			part.ReplyMarkup = nil
+		}
+
+		parts[i] = part
+	}
+
+	return parts
+}
+
+// splitText breaks text into chunks of at most limit characters. Chunks end at a line break when possible,
+// the break itself is dropped. A single line longer than limit is cut at the limit.
+func splitText(text string, limit int) []string {
+	var chunks []string
+
+	for utf8.RuneCountInString(text) > limit {
+		cut := runeOffset(text, limit)
+
+		// Prefer the last line break that keeps the chunk within the limit, including one right after it.
+		if i := strings.LastIndexByte(text[:cut+1], '\n'); i > 0 {
+			chunks = append(chunks, text[:i])
+			text = text[i+1:]
+
+			continue
+		}
+
+		chunks = append(chunks, text[:cut])
+		text = text[cut:]
+	}
+
+	return append(chunks, text)
+}
+
+// runeOffset returns the byte offset of the n-th character of s, s must have more than n characters.
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+
+		n--
+	}
+
+	return len(s)
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/bot/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		want  []string
+		limit int
+	}{
+		{name: "within limit", text: "abc\ndef", limit: 10, want: []string{"abc\ndef"}},
+		{name: "exactly at limit", text: "abcde", limit: 5, want: []string{"abcde"}},
+		{name: "breaks at last line break", text: "ab\ncd\nef\ngh", limit: 6, want: []string{"ab\ncd", "ef\ngh"}},
+		{name: "line break right after limit", text: "abcd\nef", limit: 4, want: []string{"abcd", "ef"}},
+		{name: "long line is cut", text: "abcdefgh\nij", limit: 3, want: []string{"abc", "def", "gh", "ij"}},
+		{name: "multibyte characters", text: "ааааа\nбббб", limit: 5, want: []string{"ааааа", "бббб"}},
+		{name: "empty", text: "", limit: 5, want: []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitText(tt.text, tt.limit)
+			assert.Equal(t, tt.want, got)
+
+			for _, chunk := range got {
+				assert.LessOrEqual(t, utf8.RuneCountInString(chunk), tt.limit)
+			}
+		})
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	keyboard := tgbotapi.ReplyKeyboardMarkup{Keyboard: [][]tgbotapi.KeyboardButton{{{Text: "Yes"}}}}
+
+	msg := tgbotapi.NewMessage(123, "line 1\nline 2\nline 3")
+	msg.ParseMode = tgbotapi.ModeMarkdownV2
+	msg.ReplyMarkup = keyboard
+
+	parts := splitMessage(msg, 13)
+	require.Len(t, parts, 2)
+
+	assert.Equal(t, "line 1\nline 2", parts[0].Text)
+	assert.Nil(t, parts[0].ReplyMarkup)
+	assert.Equal(t, "line 3", parts[1].Text)
+	assert.Equal(t, keyboard, parts[1].ReplyMarkup)
+
+	for _, p := range parts {
+		assert.Equal(t, int64(123), p.ChatID)
+		assert.Equal(t, tgbotapi.ModeMarkdownV2, p.ParseMode)
+	}
+
+	assert.Equal(t, []tgbotapi.MessageConfig{msg}, splitMessage(msg, maxMessageLength))
+}
+
+func TestProcessUpdate_LongMessage(t *testing.T) {
+	line := strings.Repeat("x", 99) + "\n"
+	text := strings.Repeat(line, 50) // 5000 characters
+
+	mockTg := NewMocktgClient(t)
+
+	var sent []string
+
+	mockTg.EXPECT().Send(mock.Anything).Run(func(c tgbotapi.Chattable) {
+		sent = append(sent, c.(tgbotapi.MessageConfig).Text)
+	}).Return(tgbotapi.Message{}, nil).Twice()
+
+	svc := &Service{
+		tg: mockTg,
+		handler: middleware.SingleHandlerFunc(func(_ context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			return tgbotapi.NewMessage(msg.Chat.ID, text), nil
+		}),
+	}
+
+	svc.processUpdate(context.Background(), &tgbotapi.Update{Message: &tgbotapi.Message{Text: "/audit 1", Chat: &tgbotapi.Chat{ID: 123}}})
+
+	require.Len(t, sent, 2)
+	assert.Equal(t, strings.Repeat(line, 39)+strings.Repeat("x", 99), sent[0])
+	assert.Equal(t, strings.Repeat(line, 10), sent[1])
+}
+
+func TestSendMessage_StopsOnError(t *testing.T) {
+	mockTg := NewMocktgClient(t)
+	mockTg.EXPECT().Send(mock.Anything).Return(tgbotapi.Message{}, assert.AnError).Once()
+
+	svc := &Service{tg: mockTg}
+
+	err := svc.sendMessage(context.Background(), tgbotapi.NewMessage(123, strings.Repeat("x", maxMessageLength+1)))
+	assert.ErrorIs(t, err, assert.AnError)
+}
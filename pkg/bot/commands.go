@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/i18n"
+)
+
+// botCommand describes a user command for the Telegram command menu and /help.
+// Summary is the one-line description, Details the usage with arguments and examples.
+// Group marks informational commands that also work in group chats, all others act on behalf
+// of the sender and are answered only in a private chat.
+type botCommand struct {
+	Name    string
+	Summary i18n.MessageID
+	Details i18n.MessageID
+	Aliases []string
+	Group   bool
+}
+
+// commandRegistry lists the user commands in the order they are shown in the menu and in /help.
+// Operator commands are kept out of it, see adminCommands.
+var commandRegistry = []botCommand{
+	{Name: "start", Summary: i18n.StartSummary, Details: i18n.StartDetails, Group: true},
+	{Name: "help", Summary: i18n.HelpSummary, Details: i18n.HelpDetails, Group: true},
+	{Name: "new_token", Summary: i18n.NewTokenSummary, Details: i18n.NewTokenUsage},
+	{Name: "preview", Summary: i18n.PreviewSummary, Details: i18n.PreviewUsage},
+	{Name: "list_tokens", Summary: i18n.ListTokensSummary, Details: i18n.ListTokensDetails, Aliases: []string{"my_tokens"}},
+	{Name: "export", Summary: i18n.ExportSummary, Details: i18n.ExportDetails},
+	{Name: "history", Summary: i18n.HistorySummary, Details: i18n.HistoryDetails},
+	{Name: "renew_token", Summary: i18n.RenewTokenSummary, Details: i18n.RenewTokenDetails},
+	{Name: "revoke_token", Summary: i18n.RevokeTokenSummary, Details: i18n.RevokeTokenDetails},
+	{Name: "revoke_all", Summary: i18n.RevokeAllSummary, Details: i18n.RevokeAllDetails},
+	{Name: "whoami", Summary: i18n.WhoAmISummary, Details: i18n.WhoAmIDetails},
+	{Name: "timezone", Summary: i18n.TimezoneSummary, Details: i18n.TimezoneDetails},
+	{Name: "set_default_ttl", Summary: i18n.SetDefaultTTLSummary, Details: i18n.SetDefaultTTLUsage},
+	{Name: "clear_default_ttl", Summary: i18n.ClearDefaultTTLSummary, Details: i18n.ClearDefaultTTLDetails},
+	{Name: "feedback", Summary: i18n.FeedbackSummary, Details: i18n.FeedbackDetails},
+	{Name: "back", Summary: i18n.BackSummary, Details: i18n.BackDetails},
+	{Name: "cancel", Summary: i18n.CancelSummary, Details: i18n.CancelDetails},
+	{Name: "ping", Summary: i18n.PingSummary, Details: i18n.PingDetails, Group: true},
+}
+
+// commands lists the supported bot commands; it bounds the command label of handler metrics.
+var commands = commandNames()
+
+// commandNames returns the names and aliases of the registered commands followed by the operator commands.
+func commandNames() []string {
+	var names []string
+
+	for _, c := range commandRegistry {
+		names = append(names, c.Name)
+		names = append(names, c.Aliases...)
+	}
+
+	return append(names, adminCommands...)
+}
+
+// lookupCommand finds the registered command by its name or alias, a leading slash is ignored.
+func lookupCommand(name string) (botCommand, bool) {
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+
+	for _, c := range commandRegistry {
+		if c.Name == name || slices.Contains(c.Aliases, name) {
+			return c, true
+		}
+	}
+
+	return botCommand{}, false
+}
+
+// worksInGroups reports whether command may be used in a group chat. Unknown commands may,
+// so that they get the usual reply, operator commands may not, so that their output isn't shared.
+func worksInGroups(command string) bool {
+	if isAdminCommand(command) {
+		return false
+	}
+
+	c, ok := lookupCommand(command)
+
+	return !ok || c.Group
+}
+
+// steersConversation reports whether command acts on the current conversation itself, so it must not be reset
+// before the command is handled: /back moves to the previous question, /start and /cancel reset it on their own.
+func steersConversation(command string) bool {
+	switch command {
+	case "back", "start", "cancel":
+		return true
+	default:
+		return false
+	}
+}
+
+// isGroupChat reports whether the message was sent to a group or supergroup chat.
+func isGroupChat(msg *tgbotapi.Message) bool {
+	return msg.Chat != nil && (msg.Chat.IsGroup() || msg.Chat.IsSuperGroup())
+}
+
+// helpMessage lists the registered commands with their summaries in the language lang,
+// followed by the bot version when it is known.
+func helpMessage(lang, version string) string {
+	var sb strings.Builder
+
+	for _, c := range commandRegistry {
+		sb.WriteString("/" + c.Name + " - " + i18n.Message(lang, c.Summary) + "\n")
+	}
+
+	text := i18n.Message(lang, i18n.Help, sb.String())
+	if version == "" {
+		return text
+	}
+
+	return text + "\n\n" + i18n.Message(lang, i18n.HelpFooter, version)
+}
+
+// commandHelp returns the detailed help of the command named by topic, e.g. "new_token" or "/new_token".
+// An unknown or empty topic gets the command list.
+func commandHelp(lang, topic, version string) string {
+	c, ok := lookupCommand(strings.TrimSpace(topic))
+	if !ok {
+		return helpMessage(lang, version)
+	}
+
+	return i18n.Message(lang, c.Details)
+}
+
+// registerCommands publishes the registered commands as the bot's command menu in the default language.
+// It is best effort: without the menu the commands still work, so a failure is only logged.
+func (s *Service) registerCommands(ctx context.Context) {
+	menu := make([]tgbotapi.BotCommand, 0, len(commandRegistry))
+
+	for _, c := range commandRegistry {
+		menu = append(menu, tgbotapi.BotCommand{
+			Command:     c.Name,
+			Description: i18n.Message(i18n.DefaultLang, c.Summary),
+		})
+	}
+
+	if _, err := s.tg.Request(tgbotapi.NewSetMyCommands(menu...)); err != nil {
+		slog.WarnContext(ctx, "Failed to register bot commands", slog.Any("error", err))
+	}
+}
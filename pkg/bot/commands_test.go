@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandRegistry_Complete(t *testing.T) {
+	for _, c := range commandRegistry {
+		assert.NotEmptyf(t, i18n.Message(i18n.DefaultLang, c.Summary), "%s has no summary", c.Name)
+		assert.NotEmptyf(t, i18n.Message(i18n.DefaultLang, c.Details), "%s has no details", c.Name)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"start", "help", "new_token", "preview", "list_tokens", "my_tokens", "export", "history", "renew_token",
+		"revoke_token", "revoke_all", "whoami", "timezone", "set_default_ttl", "clear_default_ttl", "feedback", "back",
+		"cancel", "ping", "audit", "stats",
+	}, commands)
+}
+
+func TestHandleCommand_HelpTopics(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		lang     string
+		wantText string
+	}{
+		{name: "command list", text: "/help", wantText: helpMessage(i18n.DefaultLang, "")},
+		{name: "command topic", text: "/help new_token", wantText: i18n.Message(i18n.DefaultLang, i18n.NewTokenUsage)},
+		{name: "topic with slash", text: "/help /revoke_all", wantText: i18n.Message(i18n.DefaultLang, i18n.RevokeAllDetails)},
+		{name: "alias topic", text: "/help my_tokens", wantText: i18n.Message(i18n.DefaultLang, i18n.ListTokensDetails)},
+		{name: "translated topic", text: "/help timezone", lang: "ru", wantText: i18n.Message("ru", i18n.TimezoneDetails)},
+		{name: "unknown topic", text: "/help bogus", wantText: helpMessage(i18n.DefaultLang, "")},
+		{name: "admin commands are not documented", text: "/help audit", wantText: helpMessage(i18n.DefaultLang, "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &Service{tokenSvc: NewMockTokenService(t)}
+
+			msg := &tgbotapi.Message{
+				Text:     tt.text,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456, LanguageCode: tt.lang},
+			}
+
+			resp, err := svc.handleCommand(context.Background(), msg)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestHelpMessage(t *testing.T) {
+	text := helpMessage(i18n.DefaultLang, "")
+
+	assert.Contains(t, text, "/new_token - "+i18n.Message(i18n.DefaultLang, i18n.NewTokenSummary)+"\n")
+	assert.Contains(t, text, "/help <command>")
+	assert.NotContains(t, text, "/my_tokens", "aliases are not listed")
+	assert.NotContains(t, text, "/audit", "operator commands are not listed")
+}
+
+func TestHandleCommand_HelpVersion(t *testing.T) {
+	svc := &Service{tokenSvc: NewMockTokenService(t), version: "v1.2.3"}
+
+	help := func(text string) string {
+		msg := &tgbotapi.Message{
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+			Chat:     &tgbotapi.Chat{ID: 123},
+			From:     &tgbotapi.User{ID: 456},
+		}
+
+		resp, err := svc.handleCommand(context.Background(), msg)
+		require.NoError(t, err)
+
+		return resp.Text
+	}
+
+	assert.True(t, strings.HasSuffix(help("/help"), "\n\nmitbot v1.2.3"), "the command list ends with the version")
+	assert.NotContains(t, help("/help new_token"), "v1.2.3", "command topics have no footer")
+	assert.NotContains(t, helpMessage(i18n.DefaultLang, ""), "mitbot", "no footer without a version")
+}
+
+func TestRegisterCommands(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+	}{
+		{name: "registered"},
+		{name: "failure is ignored", err: errors.New("telegram error")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTg := NewMocktgClient(t)
+			mockTg.EXPECT().Request(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+				cfg, ok := c.(tgbotapi.SetMyCommandsConfig)
+				if !ok || len(cfg.Commands) != len(commandRegistry) {
+					return false
+				}
+
+				return cfg.Commands[2] == tgbotapi.BotCommand{Command: "new_token", Description: i18n.Message(i18n.DefaultLang, i18n.NewTokenSummary)}
+			})).Return(&tgbotapi.APIResponse{Ok: true}, tt.err).Once()
+
+			(&Service{tg: mockTg}).registerCommands(context.Background())
+		})
+	}
+}
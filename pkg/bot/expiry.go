@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// NotifyExpiredTokens tells users that one of their tokens has just expired, until ctx is done.
+// A failure to watch the expired tokens is logged, the bot keeps working without the notices.
+func (s *Service) NotifyExpiredTokens(ctx context.Context) {
+	notices, err := s.tokenSvc.WatchExpiredTokens(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to watch expired tokens", slog.Any("error", err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Watching expired tokens")
+
+	for notice := range notices {
+		// The reply keyboard is left alone, the user may be in the middle of answering a question.
+		msg := tgbotapi.NewMessage(notice.ChatID, notice.Response.Message)
+
+		err := s.sendMessage(ctx, msg)
+
+		switch {
+		case isBotBlocked(err):
+			s.deactivateChat(ctx, notice.UserID)
+		case err != nil:
+			slog.ErrorContext(ctx, "Failed to send expiry notice",
+				slog.String("user_id", notice.UserID),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// deactivateChat stops proactive messages to a user who blocked the bot, until the user writes to it again.
+// Later notices skip the user, so this is logged once per block.
+func (s *Service) deactivateChat(ctx context.Context, userID string) {
+	if err := s.tokenSvc.DeactivateUserChat(ctx, userID); err != nil {
+		slog.ErrorContext(ctx, "Failed to deactivate chat of user who blocked the bot",
+			slog.String("user_id", userID),
+			slog.Any("error", err),
+		)
+
+		return
+	}
+
+	slog.InfoContext(ctx, "User blocked the bot, proactive messages paused until their next message",
+		slog.String("user_id", userID),
+	)
+}
@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNotifyExpiredTokens(t *testing.T) {
+	mockTokenSvc := NewMockTokenService(t)
+	mockTg := NewMocktgClient(t)
+
+	src := make(chan core.ExpiryNotice, 2)
+	src <- core.ExpiryNotice{UserID: "456", ChatID: 100, Response: &core.Response{Message: "Your token key1 has just expired."}}
+	src <- core.ExpiryNotice{UserID: "789", ChatID: 200, Response: &core.Response{Message: "Your token key2 has just expired."}}
+	close(src)
+
+	var notices <-chan core.ExpiryNotice = src
+
+	mockTokenSvc.EXPECT().WatchExpiredTokens(mock.Anything).Return(notices, nil)
+
+	mockTg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == 100 && msg.Text == "Your token key1 has just expired." && msg.ReplyMarkup == nil
+	})).Return(tgbotapi.Message{}, assert.AnError).Once()
+	mockTg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == 200
+	})).Return(tgbotapi.Message{}, nil).Once()
+
+	svc := &Service{tg: mockTg, tokenSvc: mockTokenSvc}
+
+	// A failed notice doesn't stop the following ones.
+	svc.NotifyExpiredTokens(context.Background())
+}
+
+func TestNotifyExpiredTokens_BotBlocked(t *testing.T) {
+	mockTokenSvc := NewMockTokenService(t)
+	mockTg := NewMocktgClient(t)
+
+	src := make(chan core.ExpiryNotice, 3)
+	src <- core.ExpiryNotice{UserID: "456", ChatID: 100, Response: &core.Response{Message: "Your token key1 has just expired."}}
+	src <- core.ExpiryNotice{UserID: "789", ChatID: 200, Response: &core.Response{Message: "Your token key2 has just expired."}}
+	src <- core.ExpiryNotice{UserID: "999", ChatID: 300, Response: &core.Response{Message: "Your token key3 has just expired."}}
+	close(src)
+
+	var notices <-chan core.ExpiryNotice = src
+
+	mockTokenSvc.EXPECT().WatchExpiredTokens(mock.Anything).Return(notices, nil)
+
+	blocked := &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was blocked by the user"}
+
+	mockTg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		return chatIDOf(c) == 100
+	})).Return(tgbotapi.Message{}, blocked).Once()
+	mockTg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		return chatIDOf(c) == 200
+	})).Return(tgbotapi.Message{}, &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: user is deactivated"}).Once()
+	mockTg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		return chatIDOf(c) == 300
+	})).Return(tgbotapi.Message{}, blocked).Once()
+
+	// Only users who blocked the bot are marked inactive, a failure to mark one doesn't stop the notices.
+	mockTokenSvc.EXPECT().DeactivateUserChat(mock.Anything, "456").Return(nil).Once()
+	mockTokenSvc.EXPECT().DeactivateUserChat(mock.Anything, "999").Return(assert.AnError).Once()
+
+	svc := &Service{tg: mockTg, tokenSvc: mockTokenSvc}
+
+	svc.NotifyExpiredTokens(context.Background())
+}
+
+func TestIsBotBlocked(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		want bool
+	}{
+		{name: "blocked", err: &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was blocked by the user"}, want: true},
+		{name: "wrapped", err: fmt.Errorf("failed to send: %w", &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was blocked by the user"}), want: true},
+		{name: "other forbidden", err: &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was kicked from the group chat"}},
+		{name: "rate limited", err: &tgbotapi.Error{Code: http.StatusTooManyRequests, Message: "Too Many Requests: retry after 5"}},
+		{name: "other error", err: assert.AnError},
+		{name: "no error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isBotBlocked(tt.err))
+		})
+	}
+}
+
+func TestNotifyExpiredTokens_WatchError(t *testing.T) {
+	mockTokenSvc := NewMockTokenService(t)
+	mockTokenSvc.EXPECT().WatchExpiredTokens(mock.Anything).Return(nil, core.ErrExpiryNotificationsDisabled)
+
+	svc := &Service{tg: NewMocktgClient(t), tokenSvc: mockTokenSvc}
+
+	svc.NotifyExpiredTokens(context.Background())
+}
@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/i18n"
+)
+
+const exportFileName = "tokens.json"
+
+// handleExport sends the user's active tokens as a JSON document. The document is sent here directly,
+// so an empty reply is returned on success. A user without tokens gets a message instead of an empty file.
+func (s *Service) handleExport(ctx context.Context, msg *tgbotapi.Message, userID, lang string) (tgbotapi.MessageConfig, error) {
+	tokens, err := s.tokenSvc.ExportTokens(ctx, userID)
+
+	switch {
+	case errors.Is(err, core.ErrTokenNotFound):
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.NoTokens)), nil
+	case err != nil:
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to export tokens: %w", err)
+	}
+
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to marshal tokens: %w", err)
+	}
+
+	doc := tgbotapi.NewDocument(msg.Chat.ID, tgbotapi.FileBytes{Name: exportFileName, Bytes: data})
+
+	if _, err := s.send(ctx, doc); err != nil {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to send export: %w", err)
+	}
+
+	return tgbotapi.MessageConfig{}, nil
+}
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCommand_Export(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tokens := []core.TokenExport{
+		{KeyID: "web-key", Type: core.TokenTypeWeb, Label: "home", ExpiresAt: expiresAt},
+		{KeyID: "tcp-key", Type: core.TokenTypeTCP, ExpiresAt: expiresAt},
+	}
+
+	tests := []struct {
+		setupMocks func(mockTokenSvc *MockTokenService, mockTg *MocktgClient)
+		name       string
+		wantText   string
+		wantErr    string
+	}{
+		{
+			name: "sends tokens as json document",
+			setupMocks: func(mockTokenSvc *MockTokenService, mockTg *MocktgClient) {
+				mockTokenSvc.EXPECT().ExportTokens(mock.Anything, "456").Return(tokens, nil)
+				mockTg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+					doc, ok := c.(tgbotapi.DocumentConfig)
+					if !ok || doc.ChatID != 123 {
+						return false
+					}
+
+					file, ok := doc.File.(tgbotapi.FileBytes)
+					if !ok || file.Name != exportFileName {
+						return false
+					}
+
+					var got []core.TokenExport
+
+					return json.Unmarshal(file.Bytes, &got) == nil && assert.ObjectsAreEqual(tokens, got)
+				})).Return(tgbotapi.Message{}, nil).Once()
+			},
+		},
+		{
+			name: "no tokens",
+			setupMocks: func(mockTokenSvc *MockTokenService, _ *MocktgClient) {
+				mockTokenSvc.EXPECT().ExportTokens(mock.Anything, "456").Return(nil, core.ErrTokenNotFound)
+			},
+			wantText: i18n.Message(i18n.DefaultLang, i18n.NoTokens),
+		},
+		{
+			name: "export error",
+			setupMocks: func(mockTokenSvc *MockTokenService, _ *MocktgClient) {
+				mockTokenSvc.EXPECT().ExportTokens(mock.Anything, "456").Return(nil, assert.AnError)
+			},
+			wantErr: "failed to export tokens",
+		},
+		{
+			name: "send error",
+			setupMocks: func(mockTokenSvc *MockTokenService, mockTg *MocktgClient) {
+				mockTokenSvc.EXPECT().ExportTokens(mock.Anything, "456").Return(tokens, nil)
+				mockTg.EXPECT().Send(mock.Anything).Return(tgbotapi.Message{}, assert.AnError).Once()
+			},
+			wantErr: "failed to send export",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			mockTg := NewMocktgClient(t)
+
+			tt.setupMocks(mockTokenSvc, mockTg)
+
+			svc := &Service{
+				tg:       mockTg,
+				tokenSvc: mockTokenSvc,
+			}
+
+			resp, err := svc.handleCommand(context.Background(), &tgbotapi.Message{
+				Text:     "/export",
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/export")}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456},
+			})
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
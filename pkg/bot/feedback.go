@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+)
+
+const feedbackForwardMessage = "📝 Feedback from %s (ID %d):\n\n%s"
+
+// forwardFeedback sends the feedback to every configured feedback chat. The feedback is already stored,
+// so a failed delivery is logged and never affects the reply to the user. A nil feedback is ignored.
+func (s *Service) forwardFeedback(ctx context.Context, from *tgbotapi.User, fb *core.Feedback) {
+	if fb == nil {
+		return
+	}
+
+	if len(s.feedbackChatIDs) == 0 {
+		slog.InfoContext(ctx, "No feedback chats configured, feedback is only stored", slog.String("user_id", fb.UserID))
+		return
+	}
+
+	text := fmt.Sprintf(feedbackForwardMessage, senderName(from), from.ID, fb.Text)
+
+	for _, chatID := range s.feedbackChatIDs {
+		if err := s.sendMessage(ctx, newTextMessage(chatID, text)); err != nil {
+			slog.ErrorContext(ctx, "Failed to forward feedback",
+				slog.Int64("feedback_chat_id", chatID),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// senderName returns the @username of the user, or the first name when the user has no username.
+func senderName(from *tgbotapi.User) string {
+	if from.UserName != "" {
+		return "@" + from.UserName
+	}
+
+	return from.FirstName
+}
@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCommand_Feedback(t *testing.T) {
+	fb := &core.Feedback{UserID: "456", Text: "Renew doesn't work"}
+
+	tests := []struct {
+		setupMocks      func(mockTokenSvc *MockTokenService)
+		name            string
+		text            string
+		wantText        string
+		wantForward     string
+		feedbackChatIDs []int64
+	}{
+		{
+			name:            "forwards feedback to every feedback chat",
+			text:            "/feedback Renew doesn't work",
+			feedbackChatIDs: []int64{100, 200},
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().SubmitFeedback(mock.Anything, "456", "Renew doesn't work").
+					Return(&core.Response{Message: "Thanks, we got your feedback.", Feedback: fb}, nil)
+			},
+			wantText:    "Thanks, we got your feedback.",
+			wantForward: "📝 Feedback from @alice (ID 456):\n\nRenew doesn't work",
+		},
+		{
+			name: "no feedback chats configured",
+			text: "/feedback Renew doesn't work",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().SubmitFeedback(mock.Anything, "456", "Renew doesn't work").
+					Return(&core.Response{Message: "Thanks, we got your feedback.", Feedback: fb}, nil)
+			},
+			wantText: "Thanks, we got your feedback.",
+		},
+		{
+			name:            "asks for the text when it is missing",
+			text:            "/feedback",
+			feedbackChatIDs: []int64{100},
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().SubmitFeedback(mock.Anything, "456", "").
+					Return(&core.Response{Message: "What would you like to tell us?"}, nil)
+			},
+			wantText: "What would you like to tell us?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			mockTg := NewMocktgClient(t)
+
+			tt.setupMocks(mockTokenSvc)
+
+			if tt.wantForward != "" {
+				for _, chatID := range tt.feedbackChatIDs {
+					mockTg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+						msg, ok := c.(tgbotapi.MessageConfig)
+						return ok && msg.ChatID == chatID && msg.Text == tt.wantForward
+					})).Return(tgbotapi.Message{}, nil).Once()
+				}
+			}
+
+			svc := &Service{
+				tg:              mockTg,
+				tokenSvc:        mockTokenSvc,
+				feedbackChatIDs: tt.feedbackChatIDs,
+			}
+
+			resp, err := svc.handleCommand(context.Background(), &tgbotapi.Message{
+				Text:     tt.text,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/feedback")}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456, UserName: "alice"},
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestHandle_FeedbackAnswerIsForwarded(t *testing.T) {
+	mockTokenSvc := NewMockTokenService(t)
+	mockTg := NewMocktgClient(t)
+
+	mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, "456", int64(123)).Return(nil)
+	mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(true, nil)
+	mockTokenSvc.EXPECT().HandleMessage(mock.Anything, "456", "It's slow").Return(&core.Response{
+		Message:  "Thanks, we got your feedback.",
+		Feedback: &core.Feedback{UserID: "456", Text: "It's slow"},
+	}, nil)
+
+	mockTg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == 100 && msg.Text == "📝 Feedback from Alice (ID 456):\n\nIt's slow"
+	})).Return(tgbotapi.Message{}, assert.AnError).Once()
+
+	svc := &Service{
+		tg:              mockTg,
+		tokenSvc:        mockTokenSvc,
+		feedbackChatIDs: []int64{100},
+	}
+
+	// A failed forward doesn't affect the reply to the user.
+	resp, err := svc.Handle(context.Background(), &tgbotapi.Message{
+		Text: "It's slow",
+		Chat: &tgbotapi.Chat{ID: 123},
+		From: &tgbotapi.User{ID: 456, FirstName: "Alice"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Thanks, we got your feedback.", resp.Text)
+}
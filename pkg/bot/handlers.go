@@ -5,69 +5,96 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/bot/middleware"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/i18n"
 )
 
-const (
-	welcomeMessage = `👋 Welcome to Make It Public Bot!
+// startPayloadNewToken is the /start deep link payload that starts creating a token right after the greeting.
+const startPayloadNewToken = "newtoken"
 
-I help you manage API tokens for https://make-it-public.dev - a service that allows you to securely publish services hidden behind NAT.
-
-Use /help to see available commands.`
-	helpMessage = `Available Commands:
-
-/start - Show welcome message
-/help - Display this help message
-/new_token - Generate a new API token (up to 3 web + 1 TCP)
-/my_tokens - List your active API tokens
-/revoke_token - Revoke an API token
-/cancel - Cancel the current question
-
-Token Types:
-Web  - HTTP/HTTPS tunnel token (max 3 per user), supports a custom subdomain (e.g. myapp.make-it-public.dev)
-TCP  - Raw TCP tunnel token (max 1 per user)
-
-About Make It Public:
-Make It Public allows you to securely expose services that are behind NAT or firewalls to the internet.`
-	unknownCommandMessage  = "❓ Unknown command.\n\nUse /help to see the list of available commands."
-	notCommandMessage      = "I can only respond to commands. Try /help to see what I can do."
-	tokenRevokedMessage    = "🔒 Your API token has been successfully revoked.\n\nYou can create a new one using /new_token command."
-	noTokenToRevokeMessage = "❌ You don't have an active API token to revoke.\n\nUse /new_token to create one."
-	noTokensMessage        = "❌ You don't have any active API tokens.\n\nUse /new_token to create one."
-)
+// daysArgumentPattern matches the optional expiration argument of /new_token, e.g. "30" or "30d".
+var daysArgumentPattern = regexp.MustCompile(`(?i)^(\d{1,6})d?$`)
 
 // Handler defines the interface for processing and responding to incoming messages in a Telegram bot context.
-// It handles a message by performing necessary processing and returns the configuration for the outgoing message or an error.
+// It handles a message by performing necessary processing and returns the configurations of the outgoing messages or an error.
 // ctx is the context for managing request lifecycle and cancellation.
 // message is the incoming Telegram message to be processed.
-// Returns a configured message object for sending a response and an error if processing fails.
+// Returns the message objects to be sent in order, none when no response is needed, and an error if processing fails.
 type Handler interface {
-	Handle(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error)
+	Handle(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error)
 }
 
 // setupHandler initializes and configures the request handler with specified middleware components.
-// It applies middleware for request reduction, concurrency throttling, metric collection, and error handling,
-// ensuring proper management of requests and enhanced error messages.
+// It applies middleware for request reduction, concurrency throttling, metric collection, input length limiting,
+// command normalization, error handling, duplicate update filtering and panic recovery, ensuring proper management
+// of requests and enhanced error messages.
 // Returns a Handler that processes messages with the applied middleware stack.
 func (s *Service) setupHandler() Handler {
+	var throttlerOpts []middleware.ThrottlerOption
+	if s.rejectWhenBusy {
+		throttlerOpts = append(throttlerOpts, middleware.WithRejectWhenBusy())
+	}
+
+	if s.busyTimeout > 0 {
+		throttlerOpts = append(throttlerOpts, middleware.WithBusyTimeout(s.busyTimeout))
+	}
+
 	h := middleware.Use(
-		s,
-		middleware.WithThrottler(30),
+		middleware.SingleHandlerFunc(s.Handle),
+		middleware.WithThrottler(s.maxConcurrency, throttlerOpts...),
 		middleware.WithRequestSequencer(),
-		middleware.WithMetrics(),
+		withSender(),
+		middleware.WithMetrics(s.metrics),
+		middleware.WithInputLimit(s.maxInputLength),
+		middleware.WithCommandNormalization(),
 		middleware.WithErrorHandling(),
+		middleware.WithDeduplication(),
+		middleware.WithRecovery(),
 	)
 
 	return h
 }
 
+// withSender creates middleware that replies to messages without a sender, e.g. channel posts and some
+// service messages, instead of passing them on. Tokens can only be managed on behalf of a user, and the request
+// sequencer needs the sender to order requests, so it must wrap the sequencer.
+func withSender() middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return middleware.HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
+			if msg != nil && msg.From == nil && msg.Chat != nil {
+				return []tgbotapi.MessageConfig{newTextMessage(msg.Chat.ID, i18n.Message(i18n.DefaultLang, i18n.PrivateChatOnly))}, nil
+			}
+
+			return next.Handle(ctx, msg)
+		})
+	}
+}
+
 // Handle processes incoming telegram messages, handles commands, text messages, and generates appropriate responses.
 func (s *Service) Handle(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 	slog.DebugContext(ctx, "Handling message", slog.Any("message", msg))
+
+	lang := languageOf(msg)
+
+	// An empty reply is never sent, so commands meant for someone else are dropped silently.
+	if msg.Command() != "" && !s.isAddressedToBot(msg) {
+		return tgbotapi.MessageConfig{}, nil
+	}
+
+	// Keep the chat reachable for proactive messages, a failure here must not block the reply.
+	if err := s.tokenSvc.SaveUserChat(ctx, fmt.Sprintf("%d", msg.From.ID), msg.Chat.ID); err != nil {
+		slog.ErrorContext(ctx, "Failed to save user chat", slog.Any("error", err))
+	}
+
 	if msg.Command() != "" {
+		s.abandonConversation(ctx, fmt.Sprintf("%d", msg.From.ID), msg.Command())
+
 		resp, err := s.handleCommand(ctx, msg)
 		if err != nil {
 			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to handle command: %w", err)
@@ -76,21 +103,137 @@ func (s *Service) Handle(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.M
 		return resp, nil
 	}
 
+	// Questions are asked in private chats. Text in a group is never taken for an answer, which would show the result,
+	// e.g. a new token, to everyone in the group, and it isn't replied to either, as most of it is meant for others.
+	if isGroupChat(msg) {
+		return tgbotapi.MessageConfig{}, nil
+	}
+
 	if msg.Text == "" {
-		return tgbotapi.NewMessage(msg.Chat.ID, notCommandMessage), nil
+		return s.notCommandReply(msg.Chat.ID, lang, false), nil
+	}
+
+	if msg.Text == core.BackAnswer {
+		return s.handleBack(ctx, msg)
+	}
+
+	userID := fmt.Sprintf("%d", msg.From.ID)
+
+	// Free text is only meaningful as an answer to a question the bot asked.
+	active, err := s.tokenSvc.HasActiveConversation(ctx, userID)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to check conversation: %w", err)
+	}
+
+	if !active {
+		return s.handleInactiveText(ctx, msg, userID, lang)
+	}
+
+	resp, err := s.tokenSvc.HandleMessage(ctx, userID, msg.Text)
+	if errors.Is(err, core.ErrNoActiveConversation) {
+		// The conversation expired or was reset after the check above.
+		return s.handleInactiveText(ctx, msg, userID, lang)
+	}
+
+	if rlErr := (*core.RateLimitedError)(nil); errors.As(err, &rlErr) {
+		return newRateLimitedMessage(msg.Chat.ID, lang, rlErr), nil
 	}
 
-	resp, err := s.tokenSvc.HandleMessage(ctx, fmt.Sprintf("%d", msg.From.ID), msg.Text)
 	if err != nil {
 		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to handle text message: %w", err)
 	}
 
+	s.forwardFeedback(ctx, msg.From, resp.Feedback)
+
+	return newMessage(msg.Chat.ID, resp), nil
+}
+
+// handleInactiveText answers free text sent while no question is waiting for an answer. If the user's conversation
+// expired in the meantime, the text is a late answer and its flow is started again from the first question,
+// otherwise the user is told that the text isn't a command.
+func (s *Service) handleInactiveText(ctx context.Context, msg *tgbotapi.Message, userID, lang string) (tgbotapi.MessageConfig, error) {
+	resp, err := s.tokenSvc.RestartExpiredConversation(ctx, userID)
+	if errors.Is(err, core.ErrNoActiveConversation) {
+		return s.notCommandReply(msg.Chat.ID, lang, true), nil
+	}
+
+	if rlErr := (*core.RateLimitedError)(nil); errors.As(err, &rlErr) {
+		return newRateLimitedMessage(msg.Chat.ID, lang, rlErr), nil
+	}
+
+	if err != nil {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to restart expired conversation: %w", err)
+	}
+
 	return newMessage(msg.Chat.ID, resp), nil
 }
 
+// notCommandReply tells the user that a message is neither a command nor an answer, removing the reply keyboard
+// when removeKeyboard is set. With ignoreNonCmds it returns an empty message, which is never sent.
+func (s *Service) notCommandReply(chatID int64, lang string, removeKeyboard bool) tgbotapi.MessageConfig {
+	if s.ignoreNonCmds {
+		return tgbotapi.MessageConfig{}
+	}
+
+	if removeKeyboard {
+		return newTextMessage(chatID, i18n.Message(lang, i18n.NotCommand))
+	}
+
+	return tgbotapi.NewMessage(chatID, i18n.Message(lang, i18n.NotCommand))
+}
+
+// abandonConversation resets the user's conversation when a command other than those that steer the conversation
+// arrives while a question is waiting for an answer, so that a later answer isn't taken for the abandoned question.
+// It is best effort: a failure is logged and the command is handled anyway.
+func (s *Service) abandonConversation(ctx context.Context, userID, command string) {
+	if steersConversation(command) {
+		return
+	}
+
+	active, err := s.tokenSvc.HasActiveConversation(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to check conversation before command", slog.Any("error", err))
+		return
+	}
+
+	if !active {
+		return
+	}
+
+	if err := s.tokenSvc.ResetConversation(ctx, userID); err != nil {
+		slog.ErrorContext(ctx, "Failed to reset abandoned conversation", slog.Any("error", err))
+	}
+}
+
+// isAddressedToBot reports whether a command should be handled by this bot. Commands in private chats always are.
+// In group chats a command addressed to another bot, e.g. /new_token@OtherBot, is ignored, and with requireMention
+// so is a command without the bot's username.
+func (s *Service) isAddressedToBot(msg *tgbotapi.Message) bool {
+	if msg.Chat == nil || msg.Chat.IsPrivate() {
+		return true
+	}
+
+	_, username, mentioned := strings.Cut(msg.CommandWithAt(), "@")
+	if !mentioned {
+		return !s.requireMention
+	}
+
+	return strings.EqualFold(username, s.Username())
+}
+
 // handleCommand handles Telegram command messages and generates an appropriate response based on the command received.
 func (s *Service) handleCommand(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 	userID := fmt.Sprintf("%d", msg.From.ID)
+	lang := languageOf(msg)
+
+	if isAdminCommand(msg.Command()) && !s.isAdmin(msg.From.ID) {
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.UnknownCommand)), nil
+	}
+
+	// Tokens belong to the sender, managing them in a group would show the results to everyone.
+	if isGroupChat(msg) && !worksInGroups(msg.Command()) {
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.PrivateCommand)), nil
+	}
 
 	switch msg.Command() {
 	case "start":
@@ -98,33 +241,51 @@ func (s *Service) handleCommand(ctx context.Context, msg *tgbotapi.Message) (tgb
 			slog.ErrorContext(ctx, "Failed to reset conversation on start", slog.Any("error", err))
 		}
 
-		return newTextMessage(msg.Chat.ID, welcomeMessage), nil
+		return s.handleStart(ctx, msg, userID, lang)
 	case "help":
-		return newTextMessage(msg.Chat.ID, helpMessage), nil
+		return newTextMessage(msg.Chat.ID, commandHelp(lang, msg.CommandArguments(), s.version)), nil
+	case "ping":
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.Pong, s.version)), nil
 	case "new_token":
-		resp, err := s.tokenSvc.CreateToken(ctx, userID)
-		if err != nil {
-			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to create token: %w", err)
-		}
-
-		return newMessage(msg.Chat.ID, resp), nil
-	case "my_tokens":
+		return s.handleNewToken(ctx, msg, userID, lang)
+	case "preview":
+		return s.handlePreview(ctx, msg, userID, lang)
+	case "list_tokens", "my_tokens":
 		resp, err := s.tokenSvc.ListTokens(ctx, userID)
 
 		switch {
 		case errors.Is(err, core.ErrTokenNotFound):
-			return newTextMessage(msg.Chat.ID, noTokensMessage), nil
+			return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.NoTokens)), nil
 		case err != nil:
 			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to list tokens: %w", err)
 		default:
 			return newMessage(msg.Chat.ID, resp), nil
 		}
+	case "export":
+		return s.handleExport(ctx, msg, userID, lang)
+	case "history":
+		return s.handleHistory(ctx, msg, userID, lang)
+	case "renew_token":
+		s.sendTyping(ctx, msg.Chat.ID)
+
+		resp, err := s.tokenSvc.RenewToken(ctx, userID)
+
+		switch {
+		case errors.Is(err, core.ErrTokenNotFound):
+			return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.NoTokenToRenew)), nil
+		case err != nil:
+			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to renew token: %w", err)
+		default:
+			return newMessage(msg.Chat.ID, resp), nil
+		}
 	case "revoke_token":
+		s.sendTyping(ctx, msg.Chat.ID)
+
 		resp, err := s.tokenSvc.RevokeToken(ctx, userID)
 
 		switch {
 		case errors.Is(err, core.ErrTokenNotFound):
-			return newTextMessage(msg.Chat.ID, noTokenToRevokeMessage), nil
+			return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.NoTokenToRevoke)), nil
 		case err != nil:
 			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to revoke token: %w", err)
 		case resp != nil:
@@ -132,15 +293,253 @@ func (s *Service) handleCommand(ctx context.Context, msg *tgbotapi.Message) (tgb
 			return newMessage(msg.Chat.ID, resp), nil
 		default:
 			// Single-token case: revoked directly.
-			return newTextMessage(msg.Chat.ID, tokenRevokedMessage), nil
+			return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.TokenRevoked)), nil
+		}
+	case "revoke_all":
+		resp, err := s.tokenSvc.ConfirmRevokeAll(ctx, userID)
+
+		switch {
+		case errors.Is(err, core.ErrTokenNotFound):
+			return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.NoTokenToRevoke)), nil
+		case err != nil:
+			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to confirm revoking all tokens: %w", err)
+		default:
+			return newMessage(msg.Chat.ID, resp), nil
+		}
+	case "whoami":
+		resp, err := s.tokenSvc.WhoAmI(ctx, userID)
+		if err != nil {
+			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to get user summary: %w", err)
+		}
+
+		return newMessage(msg.Chat.ID, resp), nil
+	case "timezone":
+		resp, err := s.tokenSvc.SetTimezone(ctx, userID, msg.CommandArguments())
+
+		switch {
+		case errors.Is(err, core.ErrInvalidTimezone):
+			return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.TimezoneUsage)), nil
+		case err != nil:
+			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to set timezone: %w", err)
+		default:
+			return newMessage(msg.Chat.ID, resp), nil
+		}
+	case "set_default_ttl":
+		return s.handleSetDefaultTTL(ctx, msg, userID, lang)
+	case "clear_default_ttl":
+		resp, err := s.tokenSvc.ClearDefaultExpiration(ctx, userID)
+		if err != nil {
+			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to clear default expiration: %w", err)
+		}
+
+		return newMessage(msg.Chat.ID, resp), nil
+	case "feedback":
+		resp, err := s.tokenSvc.SubmitFeedback(ctx, userID, msg.CommandArguments())
+		if err != nil {
+			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to submit feedback: %w", err)
+		}
+
+		s.forwardFeedback(ctx, msg.From, resp.Feedback)
+
+		return newMessage(msg.Chat.ID, resp), nil
+	case "back":
+		return s.handleBack(ctx, msg)
+	case "audit":
+		return s.handleAudit(ctx, msg, lang)
+	case "stats":
+		stats, err := s.tokenSvc.Stats(ctx)
+		if err != nil {
+			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to get stats: %w", err)
 		}
+
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.Stats, stats.Total(), stats.Web, stats.TCP, stats.Users)), nil
 	case "cancel":
 		if err := s.tokenSvc.ResetConversation(ctx, userID); err != nil {
 			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to reset conversation: %w", err)
 		}
 
-		return newTextMessage(msg.Chat.ID, "Conversation has been reset. You can start over with /new_token."), nil
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.ConversationReset)), nil
+	default:
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.UnknownCommand)), nil
+	}
+}
+
+// handleBack returns the user's conversation to the previous question, triggered by /back or the BackAnswer button.
+func (s *Service) handleBack(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	resp, err := s.tokenSvc.GoBack(ctx, fmt.Sprintf("%d", msg.From.ID))
+	if err != nil {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to go back: %w", err)
+	}
+
+	return newMessage(msg.Chat.ID, resp), nil
+}
+
+// handleAudit shows the audit log of the user given as the first command argument, e.g. "/audit 123456 20".
+// The optional second argument sets the number of entries, core.DefaultAuditLogLimit by default.
+func (s *Service) handleAudit(ctx context.Context, msg *tgbotapi.Message, lang string) (tgbotapi.MessageConfig, error) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 || len(args) > 2 {
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.AuditUsage)), nil
+	}
+
+	limit := core.DefaultAuditLogLimit
+
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.AuditUsage)), nil
+		}
+
+		limit = n
+	}
+
+	resp, err := s.tokenSvc.AuditLog(ctx, args[0], limit)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to get audit log: %w", err)
+	}
+
+	return newMessage(msg.Chat.ID, resp), nil
+}
+
+// handlePreview shows the expiration and token usage that "/new_token <days>" would result in, e.g. "/preview 30".
+// A missing or invalid number of days is answered with usage help.
+func (s *Service) handlePreview(ctx context.Context, msg *tgbotapi.Message, userID, lang string) (tgbotapi.MessageConfig, error) {
+	days, ok := parseDaysArgument(strings.TrimSpace(msg.CommandArguments()))
+	if !ok {
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.PreviewUsage)), nil
+	}
+
+	resp, err := s.tokenSvc.PreviewToken(ctx, userID, days)
+
+	switch {
+	case errors.Is(err, core.ErrInvalidExpirationPeriod):
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.PreviewUsage)), nil
+	case err != nil:
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to preview token: %w", err)
 	default:
-		return newTextMessage(msg.Chat.ID, unknownCommandMessage), nil
+		return newMessage(msg.Chat.ID, resp), nil
 	}
 }
+
+// handleNewToken creates a web token in one step when the command carries an expiration argument
+// such as "/new_token 30" or "/new_token 30d", and starts the interactive flow otherwise.
+// An argument that isn't a valid number of days is answered with usage help.
+func (s *Service) handleNewToken(ctx context.Context, msg *tgbotapi.Message, userID, lang string) (tgbotapi.MessageConfig, error) {
+	var (
+		resp *core.Response
+		err  error
+	)
+
+	if arg := strings.TrimSpace(msg.CommandArguments()); arg != "" {
+		days, ok := parseDaysArgument(arg)
+		if !ok {
+			return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.NewTokenUsage)), nil
+		}
+
+		s.sendTyping(ctx, msg.Chat.ID)
+
+		resp, err = s.tokenSvc.CreateTokenWithExpiration(ctx, userID, days)
+	} else {
+		s.sendTyping(ctx, msg.Chat.ID)
+
+		resp, err = s.tokenSvc.CreateToken(ctx, userID)
+	}
+
+	return newTokenReply(msg.Chat.ID, lang, resp, err)
+}
+
+// handleSetDefaultTTL stores the number of days given as "/set_default_ttl 30" or "/set_default_ttl 30d" as the
+// default expiration of new tokens. A missing or invalid argument is answered with usage help.
+func (s *Service) handleSetDefaultTTL(ctx context.Context, msg *tgbotapi.Message, userID, lang string) (tgbotapi.MessageConfig, error) {
+	days, ok := parseDaysArgument(strings.TrimSpace(msg.CommandArguments()))
+	if !ok {
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.SetDefaultTTLUsage)), nil
+	}
+
+	resp, err := s.tokenSvc.SetDefaultExpiration(ctx, userID, days)
+
+	switch {
+	case errors.Is(err, core.ErrInvalidExpirationPeriod):
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.SetDefaultTTLUsage)), nil
+	case err != nil:
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to set default expiration: %w", err)
+	default:
+		return newMessage(msg.Chat.ID, resp), nil
+	}
+}
+
+// newTokenReply builds the reply to a token creation request from the result of the token service.
+func newTokenReply(chatID int64, lang string, resp *core.Response, err error) (tgbotapi.MessageConfig, error) {
+	if rlErr := (*core.RateLimitedError)(nil); errors.As(err, &rlErr) {
+		return newRateLimitedMessage(chatID, lang, rlErr), nil
+	}
+
+	switch {
+	case errors.Is(err, core.ErrInvalidExpirationPeriod):
+		return newTextMessage(chatID, i18n.Message(lang, i18n.NewTokenUsage)), nil
+	case err != nil:
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to create token: %w", err)
+	default:
+		return newMessage(chatID, resp), nil
+	}
+}
+
+// handleStart greets the user. A deep link such as https://t.me/<bot>?start=newtoken passes its payload as the
+// /start argument: a known payload continues with the flow it names right after the greeting, others are ignored.
+// Group chats only get the greeting, tokens are managed in private.
+func (s *Service) handleStart(ctx context.Context, msg *tgbotapi.Message, userID, lang string) (tgbotapi.MessageConfig, error) {
+	welcome := i18n.Message(lang, i18n.Welcome)
+
+	if isGroupChat(msg) || strings.TrimSpace(msg.CommandArguments()) != startPayloadNewToken {
+		return newTextMessage(msg.Chat.ID, welcome), nil
+	}
+
+	s.sendTyping(ctx, msg.Chat.ID)
+
+	resp, err := s.tokenSvc.CreateToken(ctx, userID)
+
+	reply, err := newTokenReply(msg.Chat.ID, lang, resp, err)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, err
+	}
+
+	return prependText(reply, welcome), nil
+}
+
+// parseDaysArgument parses a number of days given as "30" or "30d".
+func parseDaysArgument(arg string) (int, bool) {
+	m := daysArgumentPattern.FindStringSubmatch(arg)
+	if m == nil {
+		return 0, false
+	}
+
+	days, err := strconv.Atoi(m[1])
+	if err != nil || days <= 0 {
+		return 0, false
+	}
+
+	return days, true
+}
+
+// sendTyping shows the "typing…" indicator while a command waits on the provider.
+// It is best effort: a failure is logged and never blocks the actual response.
+func (s *Service) sendTyping(ctx context.Context, chatID int64) {
+	if _, err := s.tg.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping)); err != nil {
+		slog.WarnContext(ctx, "Failed to send typing action", slog.Any("error", err))
+	}
+}
+
+// newRateLimitedMessage tells the user they hit the daily token creation limit and when it resets,
+// in the user's timezone as formatted by the core service.
+func newRateLimitedMessage(chatID int64, lang string, err *core.RateLimitedError) tgbotapi.MessageConfig {
+	return newTextMessage(chatID, i18n.Message(lang, i18n.RateLimited, err.ResetAtText))
+}
+
+// languageOf returns the Telegram language code of the message sender, empty if unknown.
+func languageOf(msg *tgbotapi.Message) string {
+	if msg.From == nil {
+		return ""
+	}
+
+	return msg.From.LanguageCode
+}
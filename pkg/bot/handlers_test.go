@@ -4,22 +4,40 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/i18n"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
+// newTypingTgClient returns a tgClient mock that accepts the best-effort typing actions sent by slow commands.
+func newTypingTgClient(t *testing.T) *MocktgClient {
+	tg := NewMocktgClient(t)
+	tg.EXPECT().Request(mock.AnythingOfType("tgbotapi.ChatActionConfig")).Return(&tgbotapi.APIResponse{Ok: true}, nil).Maybe()
+
+	return tg
+}
+
+// expectNoConversation lets Handle find no conversation to abandon before it handles a command,
+// and no expired one to restart for free text.
+// Register it after any specific HasActiveConversation expectation, the first matching one wins.
+func expectNoConversation(m *MockTokenService) {
+	m.EXPECT().HasActiveConversation(mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	m.EXPECT().RestartExpiredConversation(mock.Anything, mock.Anything).Return(nil, core.ErrNoActiveConversation).Maybe()
+}
+
 func TestSetupHandler(t *testing.T) {
 	// Create a service with mocked dependencies
 	mockTokenSvc := NewMockTokenService(t)
 	svc := &Service{
 		token:    "test-token",
-		tg:       NewMocktgClient(t),
+		tg:       newTypingTgClient(t),
 		tokenSvc: mockTokenSvc,
 	}
 
@@ -30,6 +48,174 @@ func TestSetupHandler(t *testing.T) {
 	assert.NotNil(t, handler, "Handler should not be nil")
 }
 
+func TestSetupHandler_RecoversFromPanic(t *testing.T) {
+	mockTokenSvc := NewMockTokenService(t)
+	mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	expectNoConversation(mockTokenSvc)
+	mockTokenSvc.EXPECT().CreateToken(mock.Anything, "456").RunAndReturn(func(context.Context, string) (*core.Response, error) {
+		panic("unexpected state")
+	})
+
+	svc := &Service{
+		token:          "test-token",
+		tg:             newTypingTgClient(t),
+		tokenSvc:       mockTokenSvc,
+		maxConcurrency: defaultMaxConcurrency,
+	}
+
+	msg := &tgbotapi.Message{
+		Text:     "/new_token",
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 10}},
+		Chat:     &tgbotapi.Chat{ID: 123},
+		From:     &tgbotapi.User{ID: 456},
+	}
+
+	resp, err := svc.setupHandler().Handle(context.Background(), msg)
+	require.NoError(t, err)
+	require.Len(t, resp, 1)
+
+	assert.Equal(t, int64(123), resp[0].ChatID)
+	assert.Equal(t, "Something went wrong, please try again.", resp[0].Text)
+}
+
+func TestSetupHandler_NormalizesCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		offset int
+		length int
+	}{
+		{name: "mixed case", text: "/New_Token", offset: 0, length: 10},
+		{name: "upper case", text: "/NEW_TOKEN", offset: 0, length: 10},
+		{name: "whitespace padded", text: "  /new_token  ", offset: 2, length: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+			expectNoConversation(mockTokenSvc)
+			mockTokenSvc.EXPECT().CreateToken(mock.Anything, "456").Return(&core.Response{Message: "Token created"}, nil)
+
+			svc := &Service{
+				token:          "test-token",
+				tg:             newTypingTgClient(t),
+				tokenSvc:       mockTokenSvc,
+				maxConcurrency: defaultMaxConcurrency,
+			}
+
+			msg := &tgbotapi.Message{
+				Text:     tt.text,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: tt.offset, Length: tt.length}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456},
+			}
+
+			resp, err := svc.setupHandler().Handle(context.Background(), msg)
+			require.NoError(t, err)
+			require.Len(t, resp, 1)
+
+			assert.Equal(t, "Token created", resp[0].Text)
+		})
+	}
+}
+
+func TestSetupHandler_LimitsInputLength(t *testing.T) {
+	tests := []struct {
+		name        string
+		feedback    string
+		wantReply   string
+		wantHandled bool
+	}{
+		{
+			name:        "at the limit",
+			feedback:    strings.Repeat("a", 10),
+			wantReply:   "Thanks for your feedback!",
+			wantHandled: true,
+		},
+		{
+			name:      "over the limit never reaches the token service",
+			feedback:  strings.Repeat("a", 11),
+			wantReply: "✂️ That input is too long, please keep it within 10 characters.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+
+			if tt.wantHandled {
+				mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+				expectNoConversation(mockTokenSvc)
+				mockTokenSvc.EXPECT().SubmitFeedback(mock.Anything, "456", tt.feedback).
+					Return(&core.Response{Message: "Thanks for your feedback!"}, nil)
+			}
+
+			svc := &Service{
+				token:          "test-token",
+				tg:             newTypingTgClient(t),
+				tokenSvc:       mockTokenSvc,
+				maxConcurrency: defaultMaxConcurrency,
+				maxInputLength: 10,
+			}
+
+			msg := &tgbotapi.Message{
+				Text:     "/feedback " + tt.feedback,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 9}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456},
+			}
+
+			resp, err := svc.setupHandler().Handle(context.Background(), msg)
+			require.NoError(t, err)
+			require.Len(t, resp, 1)
+
+			assert.Equal(t, tt.wantReply, resp[0].Text)
+		})
+	}
+}
+
+func TestSetupHandler_MessageWithoutSender(t *testing.T) {
+	tests := []struct {
+		msg  *tgbotapi.Message
+		name string
+	}{
+		{
+			name: "command",
+			msg: &tgbotapi.Message{
+				Text:     "/new_token",
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 10}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+			},
+		},
+		{
+			name: "text message",
+			msg: &tgbotapi.Message{
+				Text: "hello",
+				Chat: &tgbotapi.Chat{ID: 123},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &Service{
+				token:          "test-token",
+				tg:             NewMocktgClient(t),
+				tokenSvc:       NewMockTokenService(t),
+				maxConcurrency: defaultMaxConcurrency,
+			}
+
+			resp, err := svc.setupHandler().Handle(context.Background(), tt.msg)
+			require.NoError(t, err)
+			require.Len(t, resp, 1)
+
+			assert.Equal(t, int64(123), resp[0].ChatID)
+			assert.Equal(t, i18n.Message(i18n.DefaultLang, i18n.PrivateChatOnly), resp[0].Text)
+		})
+	}
+}
+
 func TestHandleCommand(t *testing.T) {
 	tests := []struct {
 		setupMocks func(mockTokenSvc *MockTokenService)
@@ -48,7 +234,7 @@ func TestHandleCommand(t *testing.T) {
 			},
 			chatID:   123,
 			userID:   456,
-			wantText: welcomeMessage,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.Welcome),
 			wantErr:  false,
 		},
 		{
@@ -59,7 +245,7 @@ func TestHandleCommand(t *testing.T) {
 			},
 			chatID:   123,
 			userID:   456,
-			wantText: helpMessage,
+			wantText: helpMessage(i18n.DefaultLang, ""),
 			wantErr:  false,
 		},
 		{
@@ -108,7 +294,7 @@ func TestHandleCommand(t *testing.T) {
 			},
 			chatID:   123,
 			userID:   456,
-			wantText: tokenRevokedMessage,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.TokenRevoked),
 			wantErr:  false,
 		},
 		{
@@ -134,7 +320,7 @@ func TestHandleCommand(t *testing.T) {
 			},
 			chatID:   123,
 			userID:   456,
-			wantText: noTokenToRevokeMessage,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.NoTokenToRevoke),
 			wantErr:  false,
 		},
 		{
@@ -147,6 +333,40 @@ func TestHandleCommand(t *testing.T) {
 			userID:  456,
 			wantErr: true,
 		},
+		{
+			name:    "revoke_all command - asks for confirmation",
+			command: "revoke_all",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				resp := &core.Response{
+					Message: "Do you want to revoke all 2 of your tokens?",
+					Answers: []string{"Yes", "No"},
+				}
+				mockTokenSvc.EXPECT().ConfirmRevokeAll(mock.Anything, "456").Return(resp, nil)
+			},
+			chatID:   123,
+			userID:   456,
+			wantText: "Do you want to revoke all 2 of your tokens?",
+		},
+		{
+			name:    "revoke_all command - no token to revoke",
+			command: "revoke_all",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().ConfirmRevokeAll(mock.Anything, "456").Return(nil, core.ErrTokenNotFound)
+			},
+			chatID:   123,
+			userID:   456,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.NoTokenToRevoke),
+		},
+		{
+			name:    "revoke_all command - error",
+			command: "revoke_all",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().ConfirmRevokeAll(mock.Anything, "456").Return(nil, errors.New("redis error"))
+			},
+			chatID:  123,
+			userID:  456,
+			wantErr: true,
+		},
 		{
 			name:    "my_tokens command - success",
 			command: "my_tokens",
@@ -168,7 +388,7 @@ func TestHandleCommand(t *testing.T) {
 			},
 			chatID:   123,
 			userID:   456,
-			wantText: noTokensMessage,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.NoTokens),
 			wantErr:  false,
 		},
 		{
@@ -181,6 +401,91 @@ func TestHandleCommand(t *testing.T) {
 			userID:  456,
 			wantErr: true,
 		},
+		{
+			name:    "list_tokens command - success",
+			command: "list_tokens",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				resp := &core.Response{
+					Message: "🔑 Your Active API Tokens (Web: 1/3, TCP: 0/1)",
+				}
+				mockTokenSvc.EXPECT().ListTokens(mock.Anything, "456").Return(resp, nil)
+			},
+			chatID:   123,
+			userID:   456,
+			wantText: "🔑 Your Active API Tokens (Web: 1/3, TCP: 0/1)",
+			wantErr:  false,
+		},
+		{
+			name:    "list_tokens command - no tokens",
+			command: "list_tokens",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().ListTokens(mock.Anything, "456").Return(nil, core.ErrTokenNotFound)
+			},
+			chatID:   123,
+			userID:   456,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.NoTokens),
+			wantErr:  false,
+		},
+		{
+			name:    "whoami command - success",
+			command: "whoami",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				resp := &core.Response{
+					Message: "🆔 User ID: 456\n🔑 Web: 0/3, TCP: 0/1\n⏱ Nearest expiry: none",
+				}
+				mockTokenSvc.EXPECT().WhoAmI(mock.Anything, "456").Return(resp, nil)
+			},
+			chatID:   123,
+			userID:   456,
+			wantText: "🆔 User ID: 456\n🔑 Web: 0/3, TCP: 0/1\n⏱ Nearest expiry: none",
+			wantErr:  false,
+		},
+		{
+			name:    "whoami command - error",
+			command: "whoami",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().WhoAmI(mock.Anything, "456").Return(nil, errors.New("redis error"))
+			},
+			chatID:  123,
+			userID:  456,
+			wantErr: true,
+		},
+		{
+			name:    "renew_token command - asks for period",
+			command: "renew_token",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				resp := &core.Response{
+					Message: "How much longer should your token stay valid?",
+					Answers: []string{"1 day", "7 days"},
+				}
+				mockTokenSvc.EXPECT().RenewToken(mock.Anything, "456").Return(resp, nil)
+			},
+			chatID:   123,
+			userID:   456,
+			wantText: "How much longer should your token stay valid?",
+			wantErr:  false,
+		},
+		{
+			name:    "renew_token command - no tokens",
+			command: "renew_token",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().RenewToken(mock.Anything, "456").Return(nil, core.ErrTokenNotFound)
+			},
+			chatID:   123,
+			userID:   456,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.NoTokenToRenew),
+			wantErr:  false,
+		},
+		{
+			name:    "renew_token command - error",
+			command: "renew_token",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().RenewToken(mock.Anything, "456").Return(nil, errors.New("redis error"))
+			},
+			chatID:  123,
+			userID:  456,
+			wantErr: true,
+		},
 		{
 			name:    "unknown command",
 			command: "unknown",
@@ -189,9 +494,29 @@ func TestHandleCommand(t *testing.T) {
 			},
 			chatID:   123,
 			userID:   456,
-			wantText: unknownCommandMessage,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.UnknownCommand),
 			wantErr:  false,
 		},
+		{
+			name:    "back command",
+			command: "back",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().GoBack(mock.Anything, "456").Return(&core.Response{Message: "What type of token do you want to create?"}, nil)
+			},
+			chatID:   123,
+			userID:   456,
+			wantText: "What type of token do you want to create?",
+		},
+		{
+			name:    "back command - error",
+			command: "back",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().GoBack(mock.Anything, "456").Return(nil, errors.New("redis error"))
+			},
+			chatID:  123,
+			userID:  456,
+			wantErr: true,
+		},
 		{
 			name:    "cancel command",
 			command: "cancel",
@@ -211,12 +536,13 @@ func TestHandleCommand(t *testing.T) {
 			mockTokenSvc := NewMockTokenService(t)
 			svc := &Service{
 				token:    "test-token",
-				tg:       NewMocktgClient(t),
+				tg:       newTypingTgClient(t),
 				tokenSvc: mockTokenSvc,
 			}
 
 			// Setup mocks
 			tt.setupMocks(mockTokenSvc)
+			expectNoConversation(mockTokenSvc)
 
 			// Create a message with the command
 			msg := &tgbotapi.Message{
@@ -290,7 +616,7 @@ func TestHandleMessage(t *testing.T) {
 			setupMocks: func(mockTokenSvc *MockTokenService) {
 				mockTokenSvc.EXPECT().ResetConversation(mock.Anything, "456").Return(nil)
 			},
-			wantText: welcomeMessage,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.Welcome),
 			wantErr:  false,
 		},
 		{
@@ -305,13 +631,76 @@ func TestHandleMessage(t *testing.T) {
 				},
 			},
 			setupMocks: func(mockTokenSvc *MockTokenService) {
-				mockTokenSvc.EXPECT().HandleMessage(mock.Anything, "456", "hello").Return(&core.Response{
-					Message: notCommandMessage,
-				}, nil)
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(false, nil)
 			},
-			wantText: notCommandMessage,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.NotCommand),
 			wantErr:  false,
 		},
+		{
+			name: "answer to an active conversation",
+			message: &tgbotapi.Message{
+				Text: "TCP",
+				Chat: &tgbotapi.Chat{ID: 123},
+				From: &tgbotapi.User{ID: 456},
+			},
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(true, nil)
+				mockTokenSvc.EXPECT().HandleMessage(mock.Anything, "456", "TCP").Return(&core.Response{Message: "What is the expiration period?"}, nil)
+			},
+			wantText: "What is the expiration period?",
+		},
+		{
+			name: "conversation ended before the answer",
+			message: &tgbotapi.Message{
+				Text: "TCP",
+				Chat: &tgbotapi.Chat{ID: 123},
+				From: &tgbotapi.User{ID: 456},
+			},
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(true, nil)
+				mockTokenSvc.EXPECT().HandleMessage(mock.Anything, "456", "TCP").Return(nil, core.ErrNoActiveConversation)
+			},
+			wantText: i18n.Message(i18n.DefaultLang, i18n.NotCommand),
+		},
+		{
+			name: "late answer to an expired conversation",
+			message: &tgbotapi.Message{
+				Text: "TCP",
+				Chat: &tgbotapi.Chat{ID: 123},
+				From: &tgbotapi.User{ID: 456},
+			},
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(false, nil)
+				mockTokenSvc.EXPECT().RestartExpiredConversation(mock.Anything, "456").
+					Return(&core.Response{Message: "That session expired, here's where we were:\n\nWhich type of token?"}, nil)
+			},
+			wantText: "That session expired, here's where we were:\n\nWhich type of token?",
+		},
+		{
+			name: "expired conversation restart error",
+			message: &tgbotapi.Message{
+				Text: "TCP",
+				Chat: &tgbotapi.Chat{ID: 123},
+				From: &tgbotapi.User{ID: 456},
+			},
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(false, nil)
+				mockTokenSvc.EXPECT().RestartExpiredConversation(mock.Anything, "456").Return(nil, errors.New("redis error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "conversation check error",
+			message: &tgbotapi.Message{
+				Text: "TCP",
+				Chat: &tgbotapi.Chat{ID: 123},
+				From: &tgbotapi.User{ID: 456},
+			},
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(false, errors.New("redis error"))
+			},
+			wantErr: true,
+		},
 		{
 			name: "command with error",
 			message: &tgbotapi.Message{
@@ -341,14 +730,16 @@ func TestHandleMessage(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a service with mocked dependencies
 			mockTokenSvc := NewMockTokenService(t)
+			mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 			svc := &Service{
 				token:    "test-token",
-				tg:       NewMocktgClient(t),
+				tg:       newTypingTgClient(t),
 				tokenSvc: mockTokenSvc,
 			}
 
 			// Setup mocks
 			tt.setupMocks(mockTokenSvc)
+			expectNoConversation(mockTokenSvc)
 
 			// Call Handle
 			resp, err := svc.Handle(context.Background(), tt.message)
@@ -366,46 +757,688 @@ func TestHandleMessage(t *testing.T) {
 	}
 }
 
-// TestHandleCommandTimeFormat tests that the time format in the token message is correct
-func TestHandleCommandTimeFormat(t *testing.T) {
-	// Create a service with mocked dependencies
-	mockTokenSvc := NewMockTokenService(t)
-	svc := &Service{
-		token:    "test-token",
-		tg:       NewMocktgClient(t),
-		tokenSvc: mockTokenSvc,
-	}
-
-	// Setup mock for CreateToken
-	// Calculate expected expiration time (approximately 1 hour from now)
-	expectedTime := time.Now().Add(time.Hour)
-	formattedTime := expectedTime.Format(time.DateTime)
-
-	response := &core.Response{
-		Message: fmt.Sprintf("🔑 Your New API Token\n\ntoken123\n\n⏱ Valid until: %s\n\nKeep this token secure and don't share it with others.", formattedTime),
-	}
-	mockTokenSvc.EXPECT().CreateToken(mock.Anything, "456").Return(response, nil)
+func TestHandleCommand_NewTokenWithExpiration(t *testing.T) {
+	usage := i18n.Message(i18n.DefaultLang, i18n.NewTokenUsage)
 
-	// Create a message with the new_token command
-	msg := &tgbotapi.Message{
-		Text: "/new_token",
-		Entities: []tgbotapi.MessageEntity{
-			{
-				Type:   "bot_command",
-				Offset: 0,
-				Length: 10,
+	tests := []struct {
+		setupMocks func(mockTokenSvc *MockTokenService)
+		name       string
+		text       string
+		wantText   string
+		wantErr    bool
+	}{
+		{
+			name: "days",
+			text: "/new_token 30",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateTokenWithExpiration(mock.Anything, "456", 30).Return(&core.Response{Message: "created"}, nil)
 			},
+			wantText: "created",
 		},
-		Chat: &tgbotapi.Chat{
-			ID: 123,
+		{
+			name: "days with suffix",
+			text: "/new_token 30d",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateTokenWithExpiration(mock.Anything, "456", 30).Return(&core.Response{Message: "created"}, nil)
+			},
+			wantText: "created",
 		},
-		From: &tgbotapi.User{
-			ID: 456,
+		{
+			name:       "not a number",
+			text:       "/new_token month",
+			setupMocks: func(mockTokenSvc *MockTokenService) {},
+			wantText:   usage,
 		},
-	}
-
-	// Call handleCommand
-	resp, err := svc.handleCommand(context.Background(), msg)
+		{
+			name:       "zero days",
+			text:       "/new_token 0",
+			setupMocks: func(mockTokenSvc *MockTokenService) {},
+			wantText:   usage,
+		},
+		{
+			name: "out of the allowed range",
+			text: "/new_token 9999",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateTokenWithExpiration(mock.Anything, "456", 9999).Return(nil, core.ErrInvalidExpirationPeriod)
+			},
+			wantText: usage,
+		},
+		{
+			name: "rate limited",
+			text: "/new_token 7",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateTokenWithExpiration(mock.Anything, "456", 7).
+					Return(nil, &core.RateLimitedError{ResetAt: time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC), ResetAtText: "2030-01-02 16:04:05 CET"})
+			},
+			wantText: i18n.Message(i18n.DefaultLang, i18n.RateLimited, "2030-01-02 16:04:05 CET"),
+		},
+		{
+			name: "error",
+			text: "/new_token 7",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateTokenWithExpiration(mock.Anything, "456", 7).Return(nil, errors.New("api error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			tt.setupMocks(mockTokenSvc)
+			expectNoConversation(mockTokenSvc)
+
+			svc := &Service{
+				token:    "test-token",
+				tg:       newTypingTgClient(t),
+				tokenSvc: mockTokenSvc,
+			}
+
+			msg := &tgbotapi.Message{
+				Text:     tt.text,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/new_token")}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456},
+			}
+
+			resp, err := svc.handleCommand(context.Background(), msg)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "failed to create token")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestHandleCommand_Preview(t *testing.T) {
+	usage := i18n.Message(i18n.DefaultLang, i18n.PreviewUsage)
+
+	tests := []struct {
+		setupMocks func(mockTokenSvc *MockTokenService)
+		name       string
+		text       string
+		wantText   string
+		wantErr    bool
+	}{
+		{
+			name: "days",
+			text: "/preview 30",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().PreviewToken(mock.Anything, "456", 30).Return(&core.Response{Message: "preview"}, nil)
+			},
+			wantText: "preview",
+		},
+		{
+			name:       "missing days",
+			text:       "/preview",
+			setupMocks: func(mockTokenSvc *MockTokenService) {},
+			wantText:   usage,
+		},
+		{
+			name:       "not a number",
+			text:       "/preview month",
+			setupMocks: func(mockTokenSvc *MockTokenService) {},
+			wantText:   usage,
+		},
+		{
+			name: "out of the allowed range",
+			text: "/preview 9999",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().PreviewToken(mock.Anything, "456", 9999).Return(nil, core.ErrInvalidExpirationPeriod)
+			},
+			wantText: usage,
+		},
+		{
+			name: "error",
+			text: "/preview 7",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().PreviewToken(mock.Anything, "456", 7).Return(nil, errors.New("redis error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			tt.setupMocks(mockTokenSvc)
+			expectNoConversation(mockTokenSvc)
+
+			svc := &Service{tokenSvc: mockTokenSvc}
+
+			msg := &tgbotapi.Message{
+				Text:     tt.text,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/preview")}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456},
+			}
+
+			resp, err := svc.handleCommand(context.Background(), msg)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "failed to preview token")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestHandle_AbandonsConversation(t *testing.T) {
+	tests := []struct {
+		setupMocks func(mockTokenSvc *MockTokenService)
+		name       string
+		text       string
+	}{
+		{
+			name: "active conversation is reset",
+			text: "/help",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(true, nil)
+				mockTokenSvc.EXPECT().ResetConversation(mock.Anything, "456").Return(nil)
+			},
+		},
+		{
+			name: "idle conversation is left alone",
+			text: "/help",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(false, nil)
+			},
+		},
+		{
+			name: "check failure does not block the command",
+			text: "/help",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(false, errors.New("redis error"))
+			},
+		},
+		{
+			name: "reset failure does not block the command",
+			text: "/help",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(true, nil)
+				mockTokenSvc.EXPECT().ResetConversation(mock.Anything, "456").Return(errors.New("redis error"))
+			},
+		},
+		{
+			name: "back keeps the conversation",
+			text: "/back",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().GoBack(mock.Anything, "456").Return(&core.Response{Message: "previous question"}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, "456", int64(123)).Return(nil)
+			tt.setupMocks(mockTokenSvc)
+
+			svc := &Service{tg: newTypingTgClient(t), tokenSvc: mockTokenSvc}
+
+			resp, err := svc.Handle(context.Background(), &tgbotapi.Message{
+				Text:     tt.text,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(tt.text)}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456},
+			})
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, resp.Text)
+		})
+	}
+}
+
+func TestHandleCommand_StartDeepLink(t *testing.T) {
+	welcome := i18n.Message(i18n.DefaultLang, i18n.Welcome)
+
+	tests := []struct {
+		setupMocks  func(mockTokenSvc *MockTokenService)
+		name        string
+		text        string
+		chatType    string
+		wantText    string
+		wantButtons bool
+		wantErr     bool
+	}{
+		{
+			name:       "plain start shows only the welcome",
+			text:       "/start",
+			setupMocks: func(mockTokenSvc *MockTokenService) {},
+			wantText:   welcome,
+		},
+		{
+			name:       "unknown payload shows only the welcome",
+			text:       "/start promo2024",
+			setupMocks: func(mockTokenSvc *MockTokenService) {},
+			wantText:   welcome,
+		},
+		{
+			name:       "newtoken payload is ignored in groups",
+			text:       "/start newtoken",
+			chatType:   "group",
+			setupMocks: func(mockTokenSvc *MockTokenService) {},
+			wantText:   welcome,
+		},
+		{
+			name: "newtoken payload starts token creation",
+			text: "/start newtoken",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateToken(mock.Anything, "456").Return(&core.Response{
+					Message: "Which type of token do you need?",
+					Answers: []string{"Web", "TCP"},
+				}, nil)
+			},
+			wantText:    welcome + "\n\nWhich type of token do you need?",
+			wantButtons: true,
+		},
+		{
+			name: "newtoken payload when rate limited",
+			text: "/start newtoken",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateToken(mock.Anything, "456").Return(nil, &core.RateLimitedError{ResetAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), ResetAtText: "2026-01-01 00:00:00 UTC"})
+			},
+			wantText: welcome + "\n\n" + i18n.Message(i18n.DefaultLang, i18n.RateLimited, "2026-01-01 00:00:00 UTC"),
+		},
+		{
+			name: "newtoken payload error",
+			text: "/start newtoken",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateToken(mock.Anything, "456").Return(nil, errors.New("redis error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			mockTokenSvc.EXPECT().ResetConversation(mock.Anything, "456").Return(nil)
+			tt.setupMocks(mockTokenSvc)
+			expectNoConversation(mockTokenSvc)
+
+			svc := &Service{tg: newTypingTgClient(t), tokenSvc: mockTokenSvc}
+
+			msg := &tgbotapi.Message{
+				Text:     tt.text,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/start")}},
+				Chat:     &tgbotapi.Chat{ID: 123, Type: tt.chatType},
+				From:     &tgbotapi.User{ID: 456},
+			}
+
+			resp, err := svc.handleCommand(context.Background(), msg)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "failed to create token")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+
+			_, hasButtons := resp.ReplyMarkup.(tgbotapi.ReplyKeyboardMarkup)
+			assert.Equal(t, tt.wantButtons, hasButtons)
+		})
+	}
+}
+
+func TestHandleCommand_Ping(t *testing.T) {
+	svc := &Service{tokenSvc: NewMockTokenService(t), version: "v1.2.3"}
+
+	msg := &tgbotapi.Message{
+		Text:     "/ping",
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/ping")}},
+		Chat:     &tgbotapi.Chat{ID: 123, Type: "group"},
+		From:     &tgbotapi.User{ID: 456},
+	}
+
+	resp, err := svc.handleCommand(context.Background(), msg)
+
+	require.NoError(t, err)
+	assert.Contains(t, resp.Text, "pong")
+	assert.Contains(t, resp.Text, "v1.2.3")
+}
+
+func TestHandleCommand_Timezone(t *testing.T) {
+	tests := []struct {
+		setupMocks func(mockTokenSvc *MockTokenService)
+		name       string
+		text       string
+		wantText   string
+		wantErr    bool
+	}{
+		{
+			name: "sets timezone",
+			text: "/timezone Europe/Berlin",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().SetTimezone(mock.Anything, "456", "Europe/Berlin").Return(&core.Response{Message: "set"}, nil)
+			},
+			wantText: "set",
+		},
+		{
+			name: "shows current timezone",
+			text: "/timezone",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().SetTimezone(mock.Anything, "456", "").Return(&core.Response{Message: "current"}, nil)
+			},
+			wantText: "current",
+		},
+		{
+			name: "unknown timezone",
+			text: "/timezone Mars/Olympus",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().SetTimezone(mock.Anything, "456", "Mars/Olympus").Return(nil, core.ErrInvalidTimezone)
+			},
+			wantText: i18n.Message(i18n.DefaultLang, i18n.TimezoneUsage),
+		},
+		{
+			name: "error",
+			text: "/timezone UTC",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().SetTimezone(mock.Anything, "456", "UTC").Return(nil, errors.New("redis error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			tt.setupMocks(mockTokenSvc)
+			expectNoConversation(mockTokenSvc)
+
+			svc := &Service{tokenSvc: mockTokenSvc}
+
+			msg := &tgbotapi.Message{
+				Text:     tt.text,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/timezone")}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456},
+			}
+
+			resp, err := svc.handleCommand(context.Background(), msg)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "failed to set timezone")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestParseDaysArgument(t *testing.T) {
+	tests := []struct {
+		arg    string
+		days   int
+		wantOK bool
+	}{
+		{arg: "30", days: 30, wantOK: true},
+		{arg: "30d", days: 30, wantOK: true},
+		{arg: "7D", days: 7, wantOK: true},
+		{arg: "0", wantOK: false},
+		{arg: "-5", wantOK: false},
+		{arg: "30 days", wantOK: false},
+		{arg: "d", wantOK: false},
+		{arg: "1234567", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			days, ok := parseDaysArgument(tt.arg)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.days, days)
+		})
+	}
+}
+
+func TestHandleCommand_TypingAction(t *testing.T) {
+	tests := []struct {
+		setupMocks func(mockTokenSvc *MockTokenService)
+		requestErr error
+		name       string
+		command    string
+	}{
+		{
+			name:    "new_token",
+			command: "new_token",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateToken(mock.Anything, "456").Return(&core.Response{Message: "done"}, nil)
+			},
+		},
+		{
+			name:    "renew_token",
+			command: "renew_token",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().RenewToken(mock.Anything, "456").Return(&core.Response{Message: "done"}, nil)
+			},
+		},
+		{
+			name:    "revoke_token",
+			command: "revoke_token",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().RevokeToken(mock.Anything, "456").Return(&core.Response{Message: "done"}, nil)
+			},
+		},
+		{
+			name:       "failed action does not block the response",
+			command:    "new_token",
+			requestErr: errors.New("telegram is down"),
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateToken(mock.Anything, "456").Return(&core.Response{Message: "done"}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTg := NewMocktgClient(t)
+			mockTokenSvc := NewMockTokenService(t)
+
+			mockTg.EXPECT().Request(tgbotapi.NewChatAction(123, tgbotapi.ChatTyping)).Return(&tgbotapi.APIResponse{Ok: true}, tt.requestErr).Once()
+			tt.setupMocks(mockTokenSvc)
+			expectNoConversation(mockTokenSvc)
+
+			svc := &Service{
+				token:    "test-token",
+				tg:       mockTg,
+				tokenSvc: mockTokenSvc,
+			}
+
+			msg := &tgbotapi.Message{
+				Text:     "/" + tt.command,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(tt.command) + 1}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456},
+			}
+
+			resp, err := svc.handleCommand(context.Background(), msg)
+			require.NoError(t, err)
+
+			assert.Equal(t, "done", resp.Text)
+		})
+	}
+}
+
+func TestHandle_Localized(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		wantLang string
+	}{
+		{name: "russian", lang: "ru", wantLang: "ru"},
+		{name: "region subtag", lang: "ru-RU", wantLang: "ru"},
+		{name: "unsupported language falls back to english", lang: "xx", wantLang: "en"},
+		{name: "missing language falls back to english", lang: "", wantLang: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, "456", int64(123)).Return(nil)
+			expectNoConversation(mockTokenSvc)
+
+			svc := &Service{
+				token:    "test-token",
+				tg:       newTypingTgClient(t),
+				tokenSvc: mockTokenSvc,
+			}
+
+			msg := &tgbotapi.Message{
+				Text:     "/help",
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456, LanguageCode: tt.lang},
+			}
+
+			resp, err := svc.Handle(context.Background(), msg)
+			require.NoError(t, err)
+
+			assert.Equal(t, helpMessage(tt.wantLang, ""), resp.Text)
+		})
+	}
+}
+
+func TestHandle_SavesUserChat(t *testing.T) {
+	tests := []struct {
+		saveErr error
+		name    string
+	}{
+		{name: "chat saved"},
+		{name: "save failure does not block the reply", saveErr: errors.New("redis error")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, "456", int64(123)).Return(tt.saveErr).Once()
+			expectNoConversation(mockTokenSvc)
+
+			svc := &Service{
+				token:    "test-token",
+				tg:       newTypingTgClient(t),
+				tokenSvc: mockTokenSvc,
+			}
+
+			msg := &tgbotapi.Message{
+				Text:     "/help",
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456},
+			}
+
+			resp, err := svc.Handle(context.Background(), msg)
+			require.NoError(t, err)
+
+			assert.Equal(t, helpMessage(i18n.DefaultLang, ""), resp.Text)
+		})
+	}
+}
+
+func TestHandle_RateLimited(t *testing.T) {
+	resetAt := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+	rlErr := fmt.Errorf("failed to check limit: %w", &core.RateLimitedError{ResetAt: resetAt, ResetAtText: "2030-01-02 16:04:05 CET"})
+	wantText := i18n.Message(i18n.DefaultLang, i18n.RateLimited, "2030-01-02 16:04:05 CET")
+
+	tests := []struct {
+		message    *tgbotapi.Message
+		setupMocks func(mockTokenSvc *MockTokenService)
+		name       string
+	}{
+		{
+			name: "new_token command",
+			message: &tgbotapi.Message{
+				Text:     "/new_token",
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 10}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456},
+			},
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateToken(mock.Anything, "456").Return(nil, rlErr)
+			},
+		},
+		{
+			name: "conversation answer",
+			message: &tgbotapi.Message{
+				Text: "Yes",
+				Chat: &tgbotapi.Chat{ID: 123},
+				From: &tgbotapi.User{ID: 456},
+			},
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(true, nil)
+				mockTokenSvc.EXPECT().HandleMessage(mock.Anything, "456", "Yes").Return(nil, rlErr)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+			svc := &Service{
+				token:    "test-token",
+				tg:       newTypingTgClient(t),
+				tokenSvc: mockTokenSvc,
+			}
+
+			tt.setupMocks(mockTokenSvc)
+			expectNoConversation(mockTokenSvc)
+
+			resp, err := svc.Handle(context.Background(), tt.message)
+			require.NoError(t, err)
+
+			assert.Equal(t, int64(123), resp.ChatID)
+			assert.Equal(t, wantText, resp.Text)
+		})
+	}
+}
+
+// TestHandleCommandTimeFormat tests that the time format in the token message is correct
+func TestHandleCommandTimeFormat(t *testing.T) {
+	// Create a service with mocked dependencies
+	mockTokenSvc := NewMockTokenService(t)
+	svc := &Service{
+		token:    "test-token",
+		tg:       newTypingTgClient(t),
+		tokenSvc: mockTokenSvc,
+	}
+
+	// Setup mock for CreateToken
+	// Calculate expected expiration time (approximately 1 hour from now)
+	expectedTime := time.Now().Add(time.Hour)
+	formattedTime := expectedTime.Format(time.DateTime)
+
+	response := &core.Response{
+		Message: fmt.Sprintf("🔑 Your New API Token\n\ntoken123\n\n⏱ Valid until: %s\n\nKeep this token secure and don't share it with others.", formattedTime),
+	}
+	mockTokenSvc.EXPECT().CreateToken(mock.Anything, "456").Return(response, nil)
+
+	// Create a message with the new_token command
+	msg := &tgbotapi.Message{
+		Text: "/new_token",
+		Entities: []tgbotapi.MessageEntity{
+			{
+				Type:   "bot_command",
+				Offset: 0,
+				Length: 10,
+			},
+		},
+		Chat: &tgbotapi.Chat{
+			ID: 123,
+		},
+		From: &tgbotapi.User{
+			ID: 456,
+		},
+	}
+
+	// Call handleCommand
+	resp, err := svc.handleCommand(context.Background(), msg)
 	require.NoError(t, err)
 
 	// Check that the response contains the token and expiration time
@@ -416,3 +1449,297 @@ func TestHandleCommandTimeFormat(t *testing.T) {
 	assert.Contains(t, resp.Text, expectedTime.Format("01"))
 	assert.Contains(t, resp.Text, expectedTime.Format("02"))
 }
+
+func TestHandle_BackAnswer(t *testing.T) {
+	mockTokenSvc := NewMockTokenService(t)
+	svc := &Service{
+		token:    "test-token",
+		tg:       newTypingTgClient(t),
+		tokenSvc: mockTokenSvc,
+	}
+
+	mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, "456", int64(123)).Return(nil).Maybe()
+	mockTokenSvc.EXPECT().GoBack(mock.Anything, "456").Return(&core.Response{
+		Message: "What type of token do you want to create?",
+		Answers: []string{"Web", "TCP"},
+	}, nil)
+
+	resp, err := svc.Handle(context.Background(), &tgbotapi.Message{
+		Text: core.BackAnswer,
+		Chat: &tgbotapi.Chat{ID: 123},
+		From: &tgbotapi.User{ID: 456},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "What type of token do you want to create?", resp.Text)
+	assert.IsType(t, tgbotapi.ReplyKeyboardMarkup{}, resp.ReplyMarkup)
+}
+
+func TestHandleCommand_Audit(t *testing.T) {
+	tests := []struct {
+		setupMocks func(mockTokenSvc *MockTokenService)
+		name       string
+		args       string
+		wantText   string
+		userID     int64
+		wantErr    bool
+	}{
+		{
+			name:     "non-admin gets unknown command",
+			args:     "456",
+			userID:   789,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.UnknownCommand),
+		},
+		{
+			name:   "admin with default limit",
+			args:   "456",
+			userID: 42,
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().AuditLog(mock.Anything, "456", core.DefaultAuditLogLimit).Return(&core.Response{Message: "audit entries"}, nil)
+			},
+			wantText: "audit entries",
+		},
+		{
+			name:   "admin with explicit limit",
+			args:   "456 3",
+			userID: 42,
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().AuditLog(mock.Anything, "456", 3).Return(&core.Response{Message: "audit entries"}, nil)
+			},
+			wantText: "audit entries",
+		},
+		{
+			name:     "missing user ID",
+			userID:   42,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.AuditUsage),
+		},
+		{
+			name:     "invalid limit",
+			args:     "456 many",
+			userID:   42,
+			wantText: i18n.Message(i18n.DefaultLang, i18n.AuditUsage),
+		},
+		{
+			name:   "service error",
+			args:   "456",
+			userID: 42,
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().AuditLog(mock.Anything, "456", core.DefaultAuditLogLimit).Return(nil, errors.New("redis error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			svc := &Service{
+				tg:       newTypingTgClient(t),
+				tokenSvc: mockTokenSvc,
+				adminIDs: []int64{42},
+			}
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockTokenSvc)
+				expectNoConversation(mockTokenSvc)
+			}
+
+			text := "/audit"
+			if tt.args != "" {
+				text += " " + tt.args
+			}
+
+			resp, err := svc.handleCommand(context.Background(), &tgbotapi.Message{
+				Text:     text,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/audit")}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: tt.userID},
+			})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestHandle_GroupCommands(t *testing.T) {
+	tests := []struct {
+		name           string
+		text           string
+		chatType       string
+		requireMention bool
+		wantHandled    bool
+	}{
+		{name: "private chat", text: "/help", chatType: "private", requireMention: true, wantHandled: true},
+		{name: "group command addressed to the bot", text: "/help@MyBot", chatType: "group", requireMention: true, wantHandled: true},
+		{name: "username is case insensitive", text: "/help@mybot", chatType: "supergroup", requireMention: true, wantHandled: true},
+		{name: "group command without mention", text: "/help", chatType: "group", requireMention: true},
+		{name: "group command addressed to another bot", text: "/help@OtherBot", chatType: "group", requireMention: true},
+		{name: "mention not required", text: "/help", chatType: "group", wantHandled: true},
+		{name: "another bot without mention required", text: "/help@OtherBot", chatType: "group"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+
+			if tt.wantHandled {
+				mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, "456", int64(-100)).Return(nil)
+			}
+
+			expectNoConversation(mockTokenSvc)
+
+			svc := &Service{
+				tokenSvc:       mockTokenSvc,
+				username:       "MyBot",
+				requireMention: tt.requireMention,
+			}
+
+			cmd, _, _ := strings.Cut(tt.text, " ")
+
+			resp, err := svc.Handle(context.Background(), &tgbotapi.Message{
+				Text:     tt.text,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(cmd)}},
+				Chat:     &tgbotapi.Chat{ID: -100, Type: tt.chatType},
+				From:     &tgbotapi.User{ID: 456},
+			})
+			require.NoError(t, err)
+
+			if tt.wantHandled {
+				assert.Equal(t, helpMessage(i18n.DefaultLang, ""), resp.Text)
+			} else {
+				assert.Empty(t, resp.Text)
+			}
+		})
+	}
+}
+
+func TestHandle_GroupPrivateCommands(t *testing.T) {
+	tests := []struct {
+		setupMocks func(mockTokenSvc *MockTokenService)
+		name       string
+		text       string
+		chatType   string
+		wantText   string
+		adminIDs   []int64
+	}{
+		{
+			name:     "group new_token",
+			text:     "/new_token",
+			chatType: "group",
+			wantText: i18n.Message(i18n.DefaultLang, i18n.PrivateCommand),
+		},
+		{
+			name:     "supergroup list_tokens alias",
+			text:     "/my_tokens",
+			chatType: "supergroup",
+			wantText: i18n.Message(i18n.DefaultLang, i18n.PrivateCommand),
+		},
+		{
+			name:     "group help",
+			text:     "/help",
+			chatType: "group",
+			wantText: helpMessage(i18n.DefaultLang, ""),
+		},
+		{
+			name:     "group start",
+			text:     "/start",
+			chatType: "group",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().ResetConversation(mock.Anything, "456").Return(nil)
+			},
+			wantText: i18n.Message(i18n.DefaultLang, i18n.Welcome),
+		},
+		{
+			name:     "group admin command",
+			text:     "/stats",
+			chatType: "group",
+			adminIDs: []int64{456},
+			wantText: i18n.Message(i18n.DefaultLang, i18n.PrivateCommand),
+		},
+		{
+			name:     "group unknown command",
+			text:     "/bogus",
+			chatType: "group",
+			wantText: i18n.Message(i18n.DefaultLang, i18n.UnknownCommand),
+		},
+		{
+			name:     "private new_token",
+			text:     "/new_token",
+			chatType: "private",
+			setupMocks: func(mockTokenSvc *MockTokenService) {
+				mockTokenSvc.EXPECT().CreateToken(mock.Anything, "456").Return(&core.Response{Message: "Token created"}, nil)
+			},
+			wantText: "Token created",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, "456", int64(-100)).Return(nil)
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockTokenSvc)
+			}
+
+			expectNoConversation(mockTokenSvc)
+
+			svc := &Service{
+				tg:       newTypingTgClient(t),
+				tokenSvc: mockTokenSvc,
+				adminIDs: tt.adminIDs,
+			}
+
+			resp, err := svc.Handle(context.Background(), &tgbotapi.Message{
+				Text:     tt.text,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(tt.text)}},
+				Chat:     &tgbotapi.Chat{ID: -100, Type: tt.chatType},
+				From:     &tgbotapi.User{ID: 456},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestHandle_GroupFreeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		chatType string
+	}{
+		{name: "answer in a group", text: "Yes", chatType: "group"},
+		{name: "answer in a supergroup", text: "Web", chatType: "supergroup"},
+		{name: "back in a group", text: core.BackAnswer, chatType: "group"},
+		{name: "media in a group", chatType: "group"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			mockTokenSvc.EXPECT().SaveUserChat(mock.Anything, "456", int64(-100)).Return(nil)
+
+			// The sender has a conversation waiting for an answer in the private chat. The mock has no HandleMessage
+			// expectation, so the group text must not reach it.
+			mockTokenSvc.EXPECT().HasActiveConversation(mock.Anything, "456").Return(true, nil).Maybe()
+
+			svc := &Service{tokenSvc: mockTokenSvc}
+
+			resp, err := svc.Handle(context.Background(), &tgbotapi.Message{
+				Text: tt.text,
+				Chat: &tgbotapi.Chat{ID: -100, Type: tt.chatType},
+				From: &tgbotapi.User{ID: 456},
+			})
+			require.NoError(t, err)
+
+			assert.Empty(t, resp.Text, "group text must be ignored")
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/i18n"
+)
+
+// historyTimeLayout formats the time of an action in /history, e.g. "2025-01-02 15:04 CET".
+const historyTimeLayout = "2006-01-02 15:04 MST"
+
+// actionLabels maps the audited token actions to their texts in /history.
+var actionLabels = map[core.AuditAction]i18n.MessageID{
+	core.AuditActionCreate:     i18n.ActionCreated,
+	core.AuditActionRevoke:     i18n.ActionRevoked,
+	core.AuditActionRegenerate: i18n.ActionRegenerated,
+	core.AuditActionRenew:      i18n.ActionRenewed,
+}
+
+// handleHistory shows the user's latest token actions, one line per action, newest first.
+func (s *Service) handleHistory(ctx context.Context, msg *tgbotapi.Message, userID, lang string) (tgbotapi.MessageConfig, error) {
+	entries, err := s.tokenSvc.GetRecentActions(ctx, userID, core.RecentActionsLimit)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to get recent actions: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.NoHistory)), nil
+	}
+
+	return newTextMessage(msg.Chat.ID, i18n.Message(lang, i18n.History, formatHistory(lang, entries))), nil
+}
+
+// formatHistory renders the actions as lines like "2025-01-02 15:04 CET · created · a1b2c3d4".
+// An action without a translation is shown by its name.
+func formatHistory(lang string, entries []core.AuditEntry) string {
+	var sb strings.Builder
+
+	for _, e := range entries {
+		action := string(e.Action)
+		if id, ok := actionLabels[e.Action]; ok {
+			action = i18n.Message(lang, id)
+		}
+
+		fmt.Fprintf(&sb, "%s · %s · %s\n", e.Time.Format(historyTimeLayout), action, e.KeyID)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCommand_History(t *testing.T) {
+	at := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+	entries := []core.AuditEntry{
+		{Time: at.Add(time.Hour), UserID: "456", Action: core.AuditActionRevoke, KeyID: "key1"},
+		{Time: at, UserID: "456", Action: core.AuditActionCreate, KeyID: "key1"},
+	}
+
+	tests := []struct {
+		entries  []core.AuditEntry
+		getErr   error
+		name     string
+		lang     string
+		wantText string
+		wantErr  string
+	}{
+		{
+			name:    "lists actions newest first",
+			entries: entries,
+			wantText: "🕘 Your recent token actions:\n\n" +
+				"2026-03-15 11:00 UTC · revoked · key1\n" +
+				"2026-03-15 10:00 UTC · created · key1\n",
+		},
+		{
+			name:    "translated actions",
+			entries: entries[1:],
+			lang:    "ru",
+			wantText: "🕘 Ваши последние действия с токенами:\n\n" +
+				"2026-03-15 10:00 UTC · создан · key1\n",
+		},
+		{
+			name:     "no actions",
+			wantText: i18n.Message(i18n.DefaultLang, i18n.NoHistory),
+		},
+		{
+			name:    "service error",
+			getErr:  assert.AnError,
+			wantErr: "failed to get recent actions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenSvc := NewMockTokenService(t)
+			mockTokenSvc.EXPECT().GetRecentActions(mock.Anything, "456", core.RecentActionsLimit).Return(tt.entries, tt.getErr)
+
+			svc := &Service{tg: NewMocktgClient(t), tokenSvc: mockTokenSvc}
+
+			resp, err := svc.handleCommand(context.Background(), &tgbotapi.Message{
+				Text:     "/history",
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/history")}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456, LanguageCode: tt.lang},
+			})
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestFormatHistory_UnknownAction(t *testing.T) {
+	at := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	got := formatHistory(i18n.DefaultLang, []core.AuditEntry{{Time: at, Action: "transfer", KeyID: "key1"}})
+
+	assert.Equal(t, "2026-03-15 10:00 UTC · transfer · key1\n", got)
+}
@@ -0,0 +1,398 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/i18n"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/prov"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const integrationKeyPrefix = "it:"
+
+// fakeMIT is an in-memory MIT API server that records the calls it receives.
+type fakeMIT struct {
+	tokens    map[string]string
+	generated []map[string]any
+	revoked   []string
+	mu        sync.Mutex
+	nextID    int
+	// rejectKeyIDs makes the server answer 409 Conflict to every client-supplied key ID.
+	rejectKeyIDs bool
+}
+
+// newFakeMIT starts a fake MIT API server that is stopped when the test ends.
+func newFakeMIT(t *testing.T) (*fakeMIT, *httptest.Server) {
+	t.Helper()
+
+	f := &fakeMIT{tokens: make(map[string]string)}
+
+	server := httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(server.Close)
+
+	return f, server
+}
+
+func (f *fakeMIT) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keyID := strings.TrimPrefix(r.URL.Path, "/token/")
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/token":
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		f.generated = append(f.generated, req)
+
+		id, _ := req["key_id"].(string)
+		if id != "" && f.rejectKeyIDs {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		if id == "" {
+			f.nextID++
+			id = fmt.Sprintf("key%d", f.nextID)
+		}
+
+		f.tokens[id], _ = req["type"].(string)
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"token": "token-" + id, "key_id": id, "type": req["type"], "ttl": req["ttl"]})
+	case r.Method == http.MethodGet && keyID != r.URL.Path:
+		tokenType, ok := f.tokens[keyID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"key_id": keyID, "type": tokenType, "status": "active", "ttl": 3600})
+	case r.Method == http.MethodDelete && keyID != r.URL.Path:
+		delete(f.tokens, keyID)
+		f.revoked = append(f.revoked, keyID)
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// generateRequests returns the bodies of the generate calls received so far.
+func (f *fakeMIT) generateRequests() []map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]map[string]any(nil), f.generated...)
+}
+
+// integrationEnv wires the bot handler to a real core service, a repository backed by miniredis
+// and a fake MIT API server. Only the Telegram client is mocked.
+type integrationEnv struct {
+	svc   *Service
+	redis *miniredis.Miniredis
+	mit   *fakeMIT
+}
+
+// newIntegrationEnv builds an integrationEnv; all resources are released when the test ends.
+func newIntegrationEnv(t *testing.T) *integrationEnv {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	users := repo.New(repo.Config{RedisAddr: mr.Addr(), KeyPrefix: integrationKeyPrefix})
+	t.Cleanup(func() { _ = users.Close() })
+
+	mit, server := newFakeMIT(t)
+	provider := prov.New(prov.Config{Url: server.URL, DefaultTTL: 3600})
+
+	tg := NewMocktgClient(t)
+	tg.EXPECT().Request(mock.Anything).Return(&tgbotapi.APIResponse{Ok: true}, nil).Maybe()
+
+	tokenSvc, err := core.New(core.Config{}, users, provider)
+	require.NoError(t, err)
+
+	return &integrationEnv{
+		svc: &Service{
+			tg:       tg,
+			tokenSvc: tokenSvc,
+		},
+		redis: mr,
+		mit:   mit,
+	}
+}
+
+// send delivers text from the user to the bot and returns the reply.
+// Text starting with "/" is sent as a command.
+func (e *integrationEnv) send(t *testing.T, userID int64, text string) tgbotapi.MessageConfig {
+	t.Helper()
+
+	msg := &tgbotapi.Message{
+		Text: text,
+		Chat: &tgbotapi.Chat{ID: userID},
+		From: &tgbotapi.User{ID: userID},
+	}
+
+	if strings.HasPrefix(text, "/") {
+		cmd, _, _ := strings.Cut(text, " ")
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(cmd)}}
+	}
+
+	resp, err := e.svc.Handle(context.Background(), msg)
+	require.NoError(t, err)
+
+	return resp
+}
+
+// storedKeys returns the key IDs stored in Redis for the user.
+func (e *integrationEnv) storedKeys(t *testing.T, userID int64) []string {
+	t.Helper()
+
+	key := fmt.Sprintf("%sUSER_KEYS::%d", integrationKeyPrefix, userID)
+	if !e.redis.Exists(key) {
+		return nil
+	}
+
+	members, err := e.redis.ZMembers(key)
+	require.NoError(t, err)
+
+	return members
+}
+
+func TestIntegration_NewTCPToken(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	const userID = 1001
+
+	resp := env.send(t, userID, "/new_token")
+	assert.Contains(t, resp.Text, "What type of token")
+
+	resp = env.send(t, userID, "TCP")
+	assert.Contains(t, resp.Text, "expiration period")
+
+	resp = env.send(t, userID, "7")
+	assert.Contains(t, resp.Text, "label")
+
+	resp = env.send(t, userID, "Skip")
+	assert.Contains(t, resp.Text, "token-key1")
+
+	reqs := env.mit.generateRequests()
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "tcp", reqs[0]["type"])
+	assert.InDelta(t, 7*24*60*60, reqs[0]["ttl"], 0)
+
+	assert.Equal(t, []string{"t:key1"}, env.storedKeys(t, userID))
+
+	resp = env.send(t, userID, "/list_tokens")
+	assert.Contains(t, resp.Text, "key1")
+}
+
+func TestIntegration_NewTokenOneStep(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	const userID = 1002
+
+	resp := env.send(t, userID, "/new_token 30")
+	assert.Contains(t, resp.Text, "token-key1")
+
+	reqs := env.mit.generateRequests()
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "web", reqs[0]["type"])
+	assert.InDelta(t, 30*24*60*60, reqs[0]["ttl"], 0)
+
+	assert.Equal(t, []string{"w:key1"}, env.storedKeys(t, userID))
+}
+
+// createTCPToken drives the interactive /new_token flow for an unnamed TCP token valid for 7 days.
+func (e *integrationEnv) createTCPToken(t *testing.T, userID int64) {
+	t.Helper()
+
+	e.send(t, userID, "/new_token")
+	e.send(t, userID, "TCP")
+	e.send(t, userID, "7")
+
+	resp := e.send(t, userID, "Skip")
+	require.Contains(t, resp.Text, "Your New API Token")
+}
+
+// regenerateTCPToken drives the /new_token flow of a user at the TCP token limit through regeneration.
+func (e *integrationEnv) regenerateTCPToken(t *testing.T, userID int64) tgbotapi.MessageConfig {
+	t.Helper()
+
+	e.send(t, userID, "/new_token")
+
+	resp := e.send(t, userID, "TCP")
+	require.Contains(t, resp.Text, "regenerate")
+
+	resp = e.send(t, userID, "Yes")
+	require.Contains(t, resp.Text, "expiration period")
+
+	return e.send(t, userID, "7")
+}
+
+func TestIntegration_RegenerateKeepsKeyID(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	const userID = 1003
+
+	env.createTCPToken(t, userID)
+
+	resp := env.regenerateTCPToken(t, userID)
+	assert.Contains(t, resp.Text, "token-key1")
+
+	reqs := env.mit.generateRequests()
+	require.Len(t, reqs, 2)
+	assert.NotContains(t, reqs[0], "key_id")
+	assert.Equal(t, "key1", reqs[1]["key_id"])
+	assert.Equal(t, []string{"key1"}, env.mit.revoked)
+
+	assert.Equal(t, []string{"t:key1"}, env.storedKeys(t, userID))
+}
+
+func TestIntegration_RegenerateFallsBackToNewKeyID(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	const userID = 1004
+
+	env.createTCPToken(t, userID)
+
+	env.mit.rejectKeyIDs = true
+
+	resp := env.regenerateTCPToken(t, userID)
+	assert.Contains(t, resp.Text, "token-key2")
+
+	reqs := env.mit.generateRequests()
+	require.Len(t, reqs, 3)
+	assert.Equal(t, "key1", reqs[1]["key_id"])
+	assert.NotContains(t, reqs[2], "key_id")
+
+	assert.Equal(t, []string{"t:key2"}, env.storedKeys(t, userID))
+}
+
+func TestIntegration_CommandAbandonsConversation(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	const userID = 1005
+
+	resp := env.send(t, userID, "/new_token")
+	require.Contains(t, resp.Text, "What type of token")
+
+	resp = env.send(t, userID, "/help")
+	assert.Equal(t, helpMessage(i18n.DefaultLang, ""), resp.Text)
+
+	resp = env.send(t, userID, "7 days")
+	assert.Equal(t, i18n.Message(i18n.DefaultLang, i18n.NotCommand), resp.Text)
+
+	assert.Empty(t, env.mit.generateRequests())
+}
+
+func TestIntegration_BackKeepsConversation(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	const userID = 1006
+
+	env.send(t, userID, "/new_token")
+
+	resp := env.send(t, userID, "TCP")
+	require.Contains(t, resp.Text, "expiration period")
+
+	// The type question is asked again within the same conversation.
+	resp = env.send(t, userID, "/back")
+	require.Contains(t, resp.Text, "What type of token")
+
+	resp = env.send(t, userID, "TCP")
+	require.Contains(t, resp.Text, "expiration period")
+
+	resp = env.send(t, userID, "7")
+	assert.Contains(t, resp.Text, "label")
+}
+
+func TestIntegration_LateAnswerRestartsExpiredConversation(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	const userID = 1007
+
+	env.send(t, userID, "/new_token")
+
+	resp := env.send(t, userID, "TCP")
+	require.Contains(t, resp.Text, "expiration period")
+
+	env.redis.FastForward(time.Hour)
+
+	// The answer to the expiration question arrives after the conversation expired, the flow starts over.
+	resp = env.send(t, userID, "7")
+	assert.True(t, strings.HasPrefix(resp.Text, "That session expired, here's where we were:"), resp.Text)
+	assert.Contains(t, resp.Text, "What type of token")
+
+	resp = env.send(t, userID, "TCP")
+	require.Contains(t, resp.Text, "expiration period")
+
+	resp = env.send(t, userID, "7")
+	assert.Contains(t, resp.Text, "label")
+
+	resp = env.send(t, userID, "Skip")
+	assert.Contains(t, resp.Text, "token-key1")
+
+	// Once the flow is finished, stray text isn't taken for a late answer.
+	env.redis.FastForward(time.Hour)
+
+	resp = env.send(t, userID, "hello")
+	assert.Equal(t, i18n.Message(i18n.DefaultLang, i18n.NotCommand), resp.Text)
+}
+
+func TestIntegration_DefaultExpiration(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	const userID = 1010
+
+	resp := env.send(t, userID, "/set_default_ttl")
+	assert.Equal(t, i18n.Message(i18n.DefaultLang, i18n.SetDefaultTTLUsage), resp.Text)
+
+	resp = env.send(t, userID, "/set_default_ttl 1000")
+	assert.Equal(t, i18n.Message(i18n.DefaultLang, i18n.SetDefaultTTLUsage), resp.Text)
+
+	resp = env.send(t, userID, "/set_default_ttl 30d")
+	assert.Contains(t, resp.Text, "valid for 30 days")
+
+	// The expiration question is skipped.
+	env.send(t, userID, "/new_token")
+	resp = env.send(t, userID, "TCP")
+	assert.Contains(t, resp.Text, "label")
+
+	resp = env.send(t, userID, "Skip")
+	assert.Contains(t, resp.Text, "token-key1")
+
+	// An explicit argument overrides the default.
+	resp = env.send(t, userID, "/new_token 7")
+	assert.Contains(t, resp.Text, "token-key2")
+
+	env.send(t, userID, "/clear_default_ttl")
+
+	env.send(t, userID, "/new_token")
+	env.send(t, userID, "Web")
+	resp = env.send(t, userID, "Skip")
+	assert.Contains(t, resp.Text, "expiration period")
+
+	reqs := env.mit.generateRequests()
+	require.Len(t, reqs, 2)
+	assert.InDelta(t, 30*24*60*60, reqs[0]["ttl"], 0)
+	assert.InDelta(t, 7*24*60*60, reqs[1]["ttl"], 0)
+}
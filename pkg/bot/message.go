@@ -6,9 +6,14 @@ import (
 )
 
 // newMessage constructs a Telegram message configuration with optional inline keyboard buttons based on given responses.
+// Responses formatted as Markdown are sent with the MarkdownV2 parse mode.
 func newMessage(chatID int64, r *core.Response) tgbotapi.MessageConfig {
 	msg := tgbotapi.NewMessage(chatID, r.Message)
 
+	if r.Markdown {
+		msg.ParseMode = tgbotapi.ModeMarkdownV2
+	}
+
 	if len(r.Answers) > 0 {
 		keyboard := make([][]tgbotapi.KeyboardButton, len(r.Answers))
 		for i, answer := range r.Answers {
@@ -42,3 +47,15 @@ func newTextMessage(chatID int64, text string) tgbotapi.MessageConfig {
 
 	return msg
 }
+
+// prependText puts text in front of the text of msg, separated by an empty line.
+// text is escaped when msg is formatted as MarkdownV2.
+func prependText(msg tgbotapi.MessageConfig, text string) tgbotapi.MessageConfig {
+	if msg.ParseMode == tgbotapi.ModeMarkdownV2 {
+		text = tgbotapi.EscapeText(tgbotapi.ModeMarkdownV2, text)
+	}
+
+	msg.Text = text + "\n\n" + msg.Text
+
+	return msg
+}
@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMessage(t *testing.T) {
+	tests := []struct {
+		resp        *core.Response
+		name        string
+		wantButtons []string
+	}{
+		{
+			name: "answers rendered as keyboard buttons",
+			resp: &core.Response{
+				Message: "Do you want to regenerate it?",
+				Answers: []string{"Yes", "No"},
+			},
+			wantButtons: []string{"Yes", "No"},
+		},
+		{
+			name: "no answers removes keyboard",
+			resp: &core.Response{
+				Message: "Done",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := newMessage(123, tt.resp)
+
+			assert.Equal(t, int64(123), msg.ChatID)
+			assert.Equal(t, tt.resp.Message, msg.Text)
+
+			if len(tt.wantButtons) == 0 {
+				assert.Equal(t, tgbotapi.ReplyKeyboardRemove{RemoveKeyboard: true}, msg.ReplyMarkup)
+				return
+			}
+
+			markup, ok := msg.ReplyMarkup.(tgbotapi.ReplyKeyboardMarkup)
+			require.True(t, ok, "expected reply keyboard markup")
+			assert.True(t, markup.OneTimeKeyboard)
+			require.Len(t, markup.Keyboard, len(tt.wantButtons))
+
+			for i, row := range markup.Keyboard {
+				require.Len(t, row, 1)
+				assert.Equal(t, tt.wantButtons[i], row[0].Text)
+			}
+		})
+	}
+}
+
+func TestNewMessage_ParseMode(t *testing.T) {
+	plain := newMessage(123, &core.Response{Message: "Done."})
+	assert.Empty(t, plain.ParseMode)
+
+	formatted := newMessage(123, &core.Response{Message: "`token`", Markdown: true})
+	assert.Equal(t, tgbotapi.ModeMarkdownV2, formatted.ParseMode)
+	assert.Equal(t, "`token`", formatted.Text)
+}
+
+func TestNewTextMessage(t *testing.T) {
+	msg := newTextMessage(123, "hello")
+
+	assert.Equal(t, int64(123), msg.ChatID)
+	assert.Equal(t, "hello", msg.Text)
+	assert.Equal(t, tgbotapi.ReplyKeyboardRemove{RemoveKeyboard: true}, msg.ReplyMarkup)
+}
+
+func TestPrependText(t *testing.T) {
+	plain := prependText(newMessage(123, &core.Response{Message: "Question?", Answers: []string{"Yes"}}), "Hi!")
+	assert.Equal(t, "Hi!\n\nQuestion?", plain.Text)
+	assert.IsType(t, tgbotapi.ReplyKeyboardMarkup{}, plain.ReplyMarkup)
+
+	formatted := prependText(newMessage(123, &core.Response{Message: "`token`", Markdown: true}), "Hi!")
+	assert.Equal(t, "Hi\\!\n\n`token`", formatted.Text)
+	assert.Equal(t, tgbotapi.ModeMarkdownV2, formatted.ParseMode)
+}
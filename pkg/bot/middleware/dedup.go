@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// dedupTTL is how long a handled message is remembered; redeliveries come within seconds.
+	dedupTTL = 5 * time.Minute
+	// dedupMaxEntries bounds the memory used when the bot receives a burst of messages.
+	dedupMaxEntries = 10000
+)
+
+// messageKey identifies a message, message IDs are only unique within a chat.
+type messageKey struct {
+	chatID    int64
+	messageID int
+}
+
+type seenMessage struct {
+	at  time.Time
+	key messageKey
+}
+
+// deduplicator remembers recently seen messages. All entries share the same TTL, so the insertion order
+// is also the expiration order and the oldest entries are always at the front of the list.
+type deduplicator struct {
+	now     func() time.Time
+	entries map[messageKey]*list.Element
+	order   *list.List
+	ttl     time.Duration
+	maxSize int
+	mu      sync.Mutex
+}
+
+func newDeduplicator(ttl time.Duration, maxSize int, now func() time.Time) *deduplicator {
+	return &deduplicator{
+		now:     now,
+		entries: make(map[messageKey]*list.Element),
+		order:   list.New(),
+		ttl:     ttl,
+		maxSize: maxSize,
+	}
+}
+
+// seen records the message and reports whether it was already recorded within the TTL.
+func (d *deduplicator) seen(key messageKey) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+
+	for front := d.order.Front(); front != nil && now.Sub(front.Value.(seenMessage).at) >= d.ttl; front = d.order.Front() {
+		d.evict(front)
+	}
+
+	if _, ok := d.entries[key]; ok {
+		return true
+	}
+
+	for d.order.Len() >= d.maxSize {
+		d.evict(d.order.Front())
+	}
+
+	d.entries[key] = d.order.PushBack(seenMessage{key: key, at: now})
+
+	return false
+}
+
+// evict forgets the message held by the list element.
+func (d *deduplicator) evict(e *list.Element) {
+	d.order.Remove(e)
+	delete(d.entries, e.Value.(seenMessage).key)
+}
+
+// WithDeduplication drops messages that were already handled, which happens when Telegram redelivers
+// updates after a webhook or long polling hiccup. Messages are identified by chat and message ID and
+// remembered for a few minutes. A duplicate gets no replies.
+// Messages without a chat or message ID are always passed through.
+func WithDeduplication() Middleware {
+	return withDeduplication(newDeduplicator(dedupTTL, dedupMaxEntries, time.Now))
+}
+
+func withDeduplication(d *deduplicator) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
+			if message == nil || message.Chat == nil || message.MessageID == 0 {
+				return next.Handle(ctx, message)
+			}
+
+			if d.seen(messageKey{chatID: message.Chat.ID, messageID: message.MessageID}) {
+				return nil, nil
+			}
+
+			return next.Handle(ctx, message)
+		})
+	}
+}
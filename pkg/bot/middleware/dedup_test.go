@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestWithDeduplication(t *testing.T) {
+	calls := 0
+
+	handler := SingleHandlerFunc(func(_ context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		calls++
+		return tgbotapi.NewMessage(msg.Chat.ID, "reply"), nil
+	})
+
+	deduped := WithDeduplication()(handler)
+
+	handle := func(chatID int64, messageID int) string {
+		resp, err := deduped.Handle(context.Background(), &tgbotapi.Message{
+			MessageID: messageID,
+			Chat:      &tgbotapi.Chat{ID: chatID},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return replyText(resp)
+	}
+
+	if text := handle(1, 10); text != "reply" {
+		t.Errorf("expected first delivery to be handled, got %q", text)
+	}
+
+	if text := handle(1, 10); text != "" {
+		t.Errorf("expected duplicate delivery to be dropped, got %q", text)
+	}
+
+	if text := handle(1, 11); text != "reply" {
+		t.Errorf("expected a different message to be handled, got %q", text)
+	}
+
+	// Message IDs are unique per chat only.
+	if text := handle(2, 10); text != "reply" {
+		t.Errorf("expected the same message ID in another chat to be handled, got %q", text)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected handler to be called 3 times, got %d", calls)
+	}
+}
+
+func TestWithDeduplicationPassesThroughMessagesWithoutID(t *testing.T) {
+	calls := 0
+
+	handler := SingleHandlerFunc(func(_ context.Context, _ *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		calls++
+		return tgbotapi.MessageConfig{}, nil
+	})
+
+	deduped := WithDeduplication()(handler)
+
+	for range 2 {
+		_, _ = deduped.Handle(context.Background(), &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1}})
+		_, _ = deduped.Handle(context.Background(), &tgbotapi.Message{MessageID: 1})
+	}
+
+	if calls != 4 {
+		t.Errorf("expected handler to be called 4 times, got %d", calls)
+	}
+}
+
+func TestDeduplicatorExpiresEntries(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newDeduplicator(time.Minute, 10, func() time.Time { return now })
+
+	key := messageKey{chatID: 1, messageID: 1}
+
+	if d.seen(key) {
+		t.Fatal("expected first sighting to be new")
+	}
+
+	now = now.Add(30 * time.Second)
+	if !d.seen(key) {
+		t.Error("expected message to be remembered within the TTL")
+	}
+
+	now = now.Add(time.Minute)
+	if d.seen(key) {
+		t.Error("expected message to be forgotten after the TTL")
+	}
+}
+
+func TestDeduplicatorEvictsOldestOverCapacity(t *testing.T) {
+	d := newDeduplicator(time.Hour, 2, time.Now)
+
+	for id := 1; id <= 3; id++ {
+		d.seen(messageKey{chatID: 1, messageID: id})
+	}
+
+	if len(d.entries) > 2 {
+		t.Errorf("expected at most 2 entries, got %d", len(d.entries))
+	}
+
+	if d.seen(messageKey{chatID: 1, messageID: 1}) {
+		t.Error("expected the oldest message to be evicted")
+	}
+
+	if !d.seen(messageKey{chatID: 1, messageID: 3}) {
+		t.Error("expected the newest message to be remembered")
+	}
+}
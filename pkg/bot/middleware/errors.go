@@ -6,6 +6,13 @@ import (
 	"log/slog"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+)
+
+const (
+	errorMessage       = "Sorry, I encountered an error while processing your request. Please try again later."
+	unavailableMessage = "The token service is temporarily unavailable. Please try again in a few minutes."
+	userBusyMessage    = "⏳ Your previous request is still being processed. Please wait a moment and try again."
 )
 
 // WithErrorHandling adds error handling middleware to a Handler.
@@ -14,9 +21,9 @@ import (
 // Returns a Middleware wrapping the original Handler with error handling logic.
 func WithErrorHandling() Middleware {
 	return func(next Handler) Handler {
-		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
 			if message == nil {
-				return tgbotapi.MessageConfig{}, errors.New("message is nil")
+				return nil, errors.New("message is nil")
 			}
 
 			msgConfig, err := next.Handle(ctx, message)
@@ -26,9 +33,28 @@ func WithErrorHandling() Middleware {
 					chatID = message.Chat.ID
 				}
 
-				slog.ErrorContext(ctx, "Failed to handle message", slog.Any("error", err))
+				var provErr *core.ProviderError
+
+				switch {
+				case errors.Is(err, core.ErrUserBusy):
+					slog.InfoContext(ctx, "Request rejected while another request of the user is in progress", slog.Any("error", err))
+
+					return Reply(tgbotapi.NewMessage(chatID, userBusyMessage)), nil
+				case errors.Is(err, core.ErrProviderUnauthorized):
+					slog.ErrorContext(ctx, "MIT provider rejected the bot's credentials, check your MIT credentials (mit.api_key)", slog.Any("error", err))
+				case errors.Is(err, core.ErrProviderUnavailable):
+					slog.WarnContext(ctx, "MIT provider calls are suspended after repeated failures", slog.Any("error", err))
+
+					return Reply(tgbotapi.NewMessage(chatID, unavailableMessage)), nil
+				case errors.As(err, &provErr) && provErr.Temporary():
+					slog.ErrorContext(ctx, "MIT provider is unavailable", slog.Any("error", err))
+
+					return Reply(tgbotapi.NewMessage(chatID, unavailableMessage)), nil
+				default:
+					slog.ErrorContext(ctx, "Failed to handle message", slog.Any("error", err))
+				}
 
-				return tgbotapi.NewMessage(chatID, "Sorry, I encountered an error while processing your request. Please try again later."), nil
+				return Reply(tgbotapi.NewMessage(chatID, errorMessage)), nil
 			}
 			return msgConfig, nil
 		})
@@ -1,12 +1,18 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"testing"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWithErrorHandling(t *testing.T) {
@@ -20,7 +26,7 @@ func TestWithErrorHandling(t *testing.T) {
 	}{
 		{
 			name: "handles error from handler",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 				return tgbotapi.MessageConfig{}, errors.New("handler error")
 			}),
 			message: &tgbotapi.Message{
@@ -32,7 +38,7 @@ func TestWithErrorHandling(t *testing.T) {
 		},
 		{
 			name: "passes through successful response",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 				return tgbotapi.NewMessage(123, "success"), nil
 			}),
 			message: &tgbotapi.Message{
@@ -44,7 +50,7 @@ func TestWithErrorHandling(t *testing.T) {
 		},
 		{
 			name: "handles message without From field",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 				return tgbotapi.MessageConfig{}, errors.New("handler error")
 			}),
 			message: &tgbotapi.Message{
@@ -55,7 +61,7 @@ func TestWithErrorHandling(t *testing.T) {
 		},
 		{
 			name: "handles message with empty language code",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 				return tgbotapi.MessageConfig{}, errors.New("handler error")
 			}),
 			message: &tgbotapi.Message{
@@ -67,7 +73,7 @@ func TestWithErrorHandling(t *testing.T) {
 		},
 		{
 			name: "handles context cancellation",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 				return tgbotapi.MessageConfig{}, context.Canceled
 			}),
 			message: &tgbotapi.Message{
@@ -79,7 +85,7 @@ func TestWithErrorHandling(t *testing.T) {
 		},
 		{
 			name: "handles nil chat",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 				return tgbotapi.MessageConfig{}, errors.New("handler error")
 			}),
 			message:       &tgbotapi.Message{},
@@ -88,7 +94,7 @@ func TestWithErrorHandling(t *testing.T) {
 		},
 		{
 			name: "handles nil message",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 				return tgbotapi.MessageConfig{}, errors.New("handler error")
 			}),
 			message:       nil,
@@ -107,8 +113,76 @@ func TestWithErrorHandling(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedMsg, msgConfig.Text)
+				assert.Equal(t, tt.expectedMsg, replyText(msgConfig))
 			}
 		})
 	}
 }
+
+func TestWithErrorHandling_ProviderUnauthorized(t *testing.T) {
+	var buf bytes.Buffer
+
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	defer slog.SetDefault(prev)
+
+	handler := WithErrorHandling()(SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to generate token: %w", core.ErrProviderUnauthorized)
+	}))
+
+	msgConfig, err := handler.Handle(context.Background(), &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Sorry, I encountered an error while processing your request. Please try again later.", replyText(msgConfig))
+	assert.Contains(t, buf.String(), "check your MIT credentials")
+}
+
+func TestWithErrorHandling_ProviderError(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		status int
+	}{
+		{name: "server error is reported as temporary", status: http.StatusBadGateway, want: unavailableMessage},
+		{name: "client error gets generic message", status: http.StatusBadRequest, want: errorMessage},
+		{name: "unauthorized gets generic message", status: http.StatusUnauthorized, want: errorMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := WithErrorHandling()(SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+				return tgbotapi.MessageConfig{}, fmt.Errorf("failed to generate token: %w", &core.ProviderError{Op: "generate token", StatusCode: tt.status})
+			}))
+
+			msgConfig, err := handler.Handle(context.Background(), &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, replyText(msgConfig))
+		})
+	}
+}
+
+func TestWithErrorHandling_ProviderUnavailable(t *testing.T) {
+	handler := WithErrorHandling()(SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to generate token: %w", core.ErrProviderUnavailable)
+	}))
+
+	msgConfig, err := handler.Handle(context.Background(), &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, unavailableMessage, replyText(msgConfig))
+}
+
+func TestWithErrorHandling_UserBusy(t *testing.T) {
+	handler := WithErrorHandling()(SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to create token: %w", fmt.Errorf("failed to acquire user lock: %w", core.ErrUserBusy))
+	}))
+
+	msgConfig, err := handler.Handle(context.Background(), &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}})
+
+	assert.NoError(t, err)
+	require.Len(t, msgConfig, 1)
+	assert.Equal(t, int64(123), msgConfig[0].ChatID)
+	assert.Equal(t, userBusyMessage, replyText(msgConfig))
+}
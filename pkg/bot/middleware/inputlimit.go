@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const inputTooLongMessage = "✂️ That input is too long, please keep it within %d characters."
+
+// WithInputLimit rejects messages whose input is longer than maxLen characters before they reach the next Handler,
+// so that huge free-text inputs such as labels or feedback can't bloat Redis or the replies.
+// The input of a command is its arguments, the input of any other message is its whole text.
+// A non-positive maxLen disables the limit.
+func WithInputLimit(maxLen int) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
+			if message == nil {
+				return nil, errors.New("message is nil")
+			}
+
+			if maxLen <= 0 {
+				return next.Handle(ctx, message)
+			}
+
+			input := message.Text
+			if message.IsCommand() {
+				input = message.CommandArguments()
+			}
+
+			if n := utf8.RuneCountInString(input); n > maxLen {
+				slog.InfoContext(ctx, "Message input is too long", slog.Int("length", n), slog.Int("limit", maxLen))
+
+				var chatID int64
+				if message.Chat != nil {
+					chatID = message.Chat.ID
+				}
+
+				return Reply(tgbotapi.NewMessage(chatID, fmt.Sprintf(inputTooLongMessage, maxLen))), nil
+			}
+
+			return next.Handle(ctx, message)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithInputLimit(t *testing.T) {
+	tests := []struct {
+		message    *tgbotapi.Message
+		name       string
+		wantReply  string
+		maxLen     int
+		wantCalled bool
+	}{
+		{
+			name:       "answer at the limit",
+			maxLen:     5,
+			message:    &tgbotapi.Message{Text: "abcde"},
+			wantCalled: true,
+		},
+		{
+			name:      "answer over the limit",
+			maxLen:    5,
+			message:   &tgbotapi.Message{Text: "abcdef"},
+			wantReply: "✂️ That input is too long, please keep it within 5 characters.",
+		},
+		{
+			name:       "characters are counted, not bytes",
+			maxLen:     5,
+			message:    &tgbotapi.Message{Text: "приве"},
+			wantCalled: true,
+		},
+		{
+			name:       "command arguments at the limit",
+			maxLen:     5,
+			message:    commandMessage("/feedback abcde", 0, 9),
+			wantCalled: true,
+		},
+		{
+			name:      "command arguments over the limit",
+			maxLen:    5,
+			message:   commandMessage("/feedback abcdef", 0, 9),
+			wantReply: "✂️ That input is too long, please keep it within 5 characters.",
+		},
+		{
+			name:       "limit disabled",
+			message:    &tgbotapi.Message{Text: strings.Repeat("a", 10000)},
+			wantCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.message.Chat = &tgbotapi.Chat{ID: 42}
+			called := false
+
+			handler := WithInputLimit(tt.maxLen)(SingleHandlerFunc(func(_ context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+				called = true
+				return tgbotapi.NewMessage(msg.Chat.ID, "handled"), nil
+			}))
+
+			replies, err := handler.Handle(context.Background(), tt.message)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantCalled, called)
+
+			if tt.wantCalled {
+				assert.Equal(t, "handled", replyText(replies))
+				return
+			}
+
+			require.Len(t, replies, 1)
+			assert.Equal(t, int64(42), replies[0].ChatID)
+			assert.Equal(t, tt.wantReply, replies[0].Text)
+		})
+	}
+}
+
+func TestWithInputLimit_NilMessage(t *testing.T) {
+	handler := WithInputLimit(5)(SingleHandlerFunc(func(context.Context, *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		t.Fatal("handler must not be called")
+		return tgbotapi.MessageConfig{}, nil
+	}))
+
+	_, err := handler.Handle(context.Background(), nil)
+	assert.Error(t, err)
+}
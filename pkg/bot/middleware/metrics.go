@@ -3,21 +3,110 @@ package middleware
 import (
 	"context"
 	"log/slog"
+	"slices"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+const (
+	// messageCommandLabel labels plain text messages that are not commands.
+	messageCommandLabel = "message"
+	// unknownCommandLabel labels commands outside the known set, keeping label cardinality bounded.
+	unknownCommandLabel = "unknown"
+
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+)
+
+// latencyBuckets covers both local commands answered in milliseconds and commands
+// waiting on a provider round-trip, which can take seconds with retries.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics holds the Prometheus collectors updated by WithMetrics.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	commands []string
+}
+
+// MetricsOption configures optional parameters of NewMetrics.
+type MetricsOption func(*metricsOptions)
+
+type metricsOptions struct {
+	reg prometheus.Registerer
+}
+
+// WithRegisterer registers the handler collectors with reg, by default they are left unregistered.
+func WithRegisterer(reg prometheus.Registerer) MetricsOption {
+	return func(o *metricsOptions) {
+		o.reg = reg
+	}
+}
+
+// NewMetrics creates the handler collectors.
+// commands lists the command names that get their own label value, any other command is counted as "unknown".
+func NewMetrics(commands []string, opts ...MetricsOption) *Metrics {
+	var o metricsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	factory := promauto.With(o.reg)
+
+	return &Metrics{
+		requests: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "mitbot_requests_total",
+			Help: "Total number of handled Telegram messages by command and outcome.",
+		}, []string{"command", "outcome"}),
+		duration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mitbot_request_duration_seconds",
+			Help:    "Time spent handling Telegram messages by command and outcome.",
+			Buckets: latencyBuckets,
+		}, []string{"command", "outcome"}),
+		commands: commands,
+	}
+}
+
+// commandLabel returns the label value used for the given message.
+func (m *Metrics) commandLabel(message *tgbotapi.Message) string {
+	if message == nil || !message.IsCommand() {
+		return messageCommandLabel
+	}
+
+	if cmd := message.Command(); slices.Contains(m.commands, cmd) {
+		return cmd
+	}
+
+	return unknownCommandLabel
+}
+
 // WithMetrics wraps a Handler to record processing time and error occurrence metrics for each message processed.
-// It logs the duration of message processing and whether an error occurred during execution.
+// It logs the duration of message processing and whether an error occurred during execution and, when m is not nil,
+// updates the request counter and latency histogram labeled by command and outcome.
 // Returns a Middleware that measures and logs performance metrics for the wrapped Handler.
-func WithMetrics() Middleware {
+func WithMetrics(m *Metrics) Middleware {
 	return func(next Handler) Handler {
-		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
 			start := time.Now()
 			resp, err := next.Handle(ctx, message)
+			duration := time.Since(start)
+
+			slog.InfoContext(ctx, "Message processing time", slog.Duration("duration", duration), slog.Bool("error", err != nil))
+
+			if m != nil {
+				command := m.commandLabel(message)
+
+				outcome := outcomeSuccess
+				if err != nil {
+					outcome = outcomeError
+				}
 
-			slog.InfoContext(ctx, "Message processing time", slog.Duration("duration", time.Since(start)), slog.Bool("error", err != nil))
+				m.requests.WithLabelValues(command, outcome).Inc()
+				m.duration.WithLabelValues(command, outcome).Observe(duration.Seconds())
+			}
 
 			return resp, err
 		})
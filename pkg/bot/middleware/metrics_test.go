@@ -6,6 +6,8 @@ import (
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,7 +21,7 @@ func TestWithMetrics(t *testing.T) {
 	}{
 		{
 			name: "successful handler execution",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 				return tgbotapi.MessageConfig{BaseChat: tgbotapi.BaseChat{ChatID: msg.Chat.ID}}, nil
 			}),
 			message:       &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 12345}},
@@ -27,7 +29,7 @@ func TestWithMetrics(t *testing.T) {
 		},
 		{
 			name: "handler execution with error",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 				return tgbotapi.MessageConfig{}, assert.AnError
 			}),
 			message:       &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 12345}},
@@ -35,7 +37,7 @@ func TestWithMetrics(t *testing.T) {
 		},
 		{
 			name: "nil message",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 				if msg == nil {
 					return tgbotapi.MessageConfig{}, assert.AnError
 				}
@@ -48,7 +50,7 @@ func TestWithMetrics(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			middleware := WithMetrics()
+			middleware := WithMetrics(nil)
 			wrappedHandler := middleware(tt.handler)
 
 			start := time.Now()
@@ -65,3 +67,53 @@ func TestWithMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestWithMetrics_Collectors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics([]string{"start", "new_token"}, WithRegisterer(reg))
+
+	ok := WithMetrics(m)(SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		return tgbotapi.MessageConfig{}, nil
+	}))
+	failing := WithMetrics(m)(SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		return tgbotapi.MessageConfig{}, assert.AnError
+	}))
+
+	command := func(text string) *tgbotapi.Message {
+		return &tgbotapi.Message{
+			Text:     text,
+			Chat:     &tgbotapi.Chat{ID: 1},
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(text)}},
+		}
+	}
+
+	_, _ = ok.Handle(context.Background(), command("/start"))
+	_, _ = ok.Handle(context.Background(), command("/start"))
+	_, _ = failing.Handle(context.Background(), command("/new_token"))
+	_, _ = ok.Handle(context.Background(), command("/new_token"))
+	_, _ = ok.Handle(context.Background(), command("/whatever"))
+	_, _ = ok.Handle(context.Background(), &tgbotapi.Message{Text: "hello", Chat: &tgbotapi.Chat{ID: 1}})
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues("start", outcomeSuccess)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.requests.WithLabelValues("start", outcomeError)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("new_token", outcomeSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("new_token", outcomeError)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(unknownCommandLabel, outcomeSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(messageCommandLabel, outcomeSuccess)))
+
+	count, err := testutil.GatherAndCount(reg, "mitbot_requests_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 6, count)
+
+	count, err = testutil.GatherAndCount(reg, "mitbot_request_duration_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+}
+
+func TestNewMetrics_Unregistered(t *testing.T) {
+	// Without a registerer the collectors are usable but not exposed, so repeated construction must not panic.
+	assert.NotPanics(t, func() {
+		NewMetrics(nil)
+		NewMetrics(nil)
+	})
+}
@@ -12,23 +12,42 @@ type Middleware func(next Handler) Handler
 
 // Handler defines the interface for processing incoming messages in a bot framework.
 // It accepts a context for request-scoped values and cancellation signals, and the message to be handled.
-// Returns a configured message response and an error if the processing fails.
+// Returns the replies to be sent in order, none when the message needs no reply, and an error if the processing fails.
 type Handler interface {
-	Handle(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error)
+	Handle(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error)
 }
 
-// HandlerFunc processes an incoming Telegram message within a given context and generates a response configuration.
+// HandlerFunc processes an incoming Telegram message within a given context and generates the replies.
 // It takes a context for controlling execution and a pointer to the incoming Telegram message as input parameters.
-// Returns a MessageConfig containing the response to be sent and an error if message handling fails.
-type HandlerFunc func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error)
+// Returns the MessageConfigs of the replies to be sent and an error if message handling fails.
+type HandlerFunc func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error)
 
 // Handle executes the HandlerFunc with the provided context and Telegram message.
-// It processes the incoming message and generates a response configuration.
-// Returns MessageConfig containing the response settings and error if the handler execution fails.
-func (h HandlerFunc) Handle(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+// It processes the incoming message and generates the replies.
+// Returns the MessageConfigs of the replies and error if the handler execution fails.
+func (h HandlerFunc) Handle(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
 	return h(ctx, message)
 }
 
+// SingleHandlerFunc is a convenience Handler for handlers that answer with at most one reply.
+// A reply without text means that nothing is sent.
+type SingleHandlerFunc func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error)
+
+// Handle executes the SingleHandlerFunc and returns its reply as the only one, or no replies when it has no text.
+func (h SingleHandlerFunc) Handle(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
+	resp, err := h(ctx, message)
+	if err != nil || resp.Text == "" {
+		return nil, err
+	}
+
+	return Reply(resp), nil
+}
+
+// Reply returns msg as the only reply of a handler.
+func Reply(msg tgbotapi.MessageConfig) []tgbotapi.MessageConfig {
+	return []tgbotapi.MessageConfig{msg}
+}
+
 // Use composes a new Handler by wrapping the provided handler with the given middlewares in the specified order.
 // It iterates over the middlewares and applies each one sequentially, returning the final wrapped handler.
 // Accepts handler, the base Handler to wrap, and middlewares, a variadic list of Middleware functions to apply.
@@ -11,13 +11,22 @@ import (
 
 type testHandler struct {
 	err      error
-	response tgbotapi.MessageConfig
+	response []tgbotapi.MessageConfig
 }
 
-func (h *testHandler) Handle(_ context.Context, _ *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+func (h *testHandler) Handle(_ context.Context, _ *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
 	return h.response, h.err
 }
 
+// replyText returns the text of the only reply, or an empty string when there are no replies.
+func replyText(replies []tgbotapi.MessageConfig) string {
+	if len(replies) == 0 {
+		return ""
+	}
+
+	return replies[0].Text
+}
+
 func TestUse(t *testing.T) {
 	type testCase struct {
 		handler         Handler
@@ -25,86 +34,82 @@ func TestUse(t *testing.T) {
 		message         *tgbotapi.Message
 		name            string
 		middlewares     []Middleware
-		expectedMessage tgbotapi.MessageConfig
+		expectedMessage []tgbotapi.MessageConfig
 	}
 
 	tests := []testCase{
 		{
-			name:    "no_middlewares",
-			handler: &testHandler{response: tgbotapi.MessageConfig{}, err: nil},
-			message: &tgbotapi.Message{Text: "test"},
-			expectedMessage: tgbotapi.MessageConfig{
-				Text: "",
-			},
-			expectedErr: nil,
+			name:            "no_middlewares",
+			handler:         &testHandler{response: nil, err: nil},
+			message:         &tgbotapi.Message{Text: "test"},
+			expectedMessage: nil,
+			expectedErr:     nil,
 		},
 		{
 			name: "single_middleware_modifies_response",
 			handler: &testHandler{
-				response: tgbotapi.MessageConfig{Text: "hello"},
+				response: Reply(tgbotapi.MessageConfig{Text: "hello"}),
 				err:      nil,
 			},
 			middlewares: []Middleware{
 				func(next Handler) Handler {
-					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
 						res, err := next.Handle(ctx, message)
-						res.Text = res.Text + " world"
+						res[0].Text = res[0].Text + " world"
 						return res, err
 					})
 				},
 			},
 			message: &tgbotapi.Message{Text: "test"},
-			expectedMessage: tgbotapi.MessageConfig{
+			expectedMessage: Reply(tgbotapi.MessageConfig{
 				Text: "hello world",
-			},
+			}),
 			expectedErr: nil,
 		},
 		{
 			name: "multiple_middlewares_applied_in_order",
 			handler: &testHandler{
-				response: tgbotapi.MessageConfig{Text: "start"},
+				response: Reply(tgbotapi.MessageConfig{Text: "start"}),
 				err:      nil,
 			},
 			middlewares: []Middleware{
 				func(next Handler) Handler {
-					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
 						res, err := next.Handle(ctx, message)
-						res.Text = res.Text + " middle"
+						res[0].Text = res[0].Text + " middle"
 						return res, err
 					})
 				},
 				func(next Handler) Handler {
-					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
 						res, err := next.Handle(ctx, message)
-						res.Text = res.Text + " end"
+						res[0].Text = res[0].Text + " end"
 						return res, err
 					})
 				},
 			},
 			message: &tgbotapi.Message{Text: "test"},
-			expectedMessage: tgbotapi.MessageConfig{
+			expectedMessage: Reply(tgbotapi.MessageConfig{
 				Text: "start middle end",
-			},
+			}),
 			expectedErr: nil,
 		},
 		{
 			name: "middleware_returns_error",
 			handler: &testHandler{
-				response: tgbotapi.MessageConfig{Text: ""},
+				response: nil,
 				err:      nil,
 			},
 			middlewares: []Middleware{
 				func(next Handler) Handler {
-					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-						return tgbotapi.MessageConfig{}, errors.New("middleware error")
+					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
+						return nil, errors.New("middleware error")
 					})
 				},
 			},
-			message: &tgbotapi.Message{Text: "test"},
-			expectedMessage: tgbotapi.MessageConfig{
-				Text: "",
-			},
-			expectedErr: errors.New("middleware error"),
+			message:         &tgbotapi.Message{Text: "test"},
+			expectedMessage: nil,
+			expectedErr:     errors.New("middleware error"),
 		},
 	}
 
@@ -117,3 +122,39 @@ func TestUse(t *testing.T) {
 		})
 	}
 }
+
+func TestSingleHandlerFunc(t *testing.T) {
+	tests := []struct {
+		err         error
+		name        string
+		response    tgbotapi.MessageConfig
+		wantReplies []tgbotapi.MessageConfig
+	}{
+		{
+			name:        "reply",
+			response:    tgbotapi.NewMessage(123, "hello"),
+			wantReplies: []tgbotapi.MessageConfig{tgbotapi.NewMessage(123, "hello")},
+		},
+		{
+			name:     "empty reply is not sent",
+			response: tgbotapi.MessageConfig{},
+		},
+		{
+			name:     "error",
+			response: tgbotapi.NewMessage(123, "ignored"),
+			err:      errors.New("handler error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := SingleHandlerFunc(func(_ context.Context, _ *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+				return tt.response, tt.err
+			})
+
+			replies, err := h.Handle(context.Background(), &tgbotapi.Message{Text: "test"})
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.wantReplies, replies)
+		})
+	}
+}
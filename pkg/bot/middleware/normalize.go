@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// commandWhitespace is the whitespace trimmed around a command. It is ASCII only, so that the number of
+// trimmed bytes equals the number of UTF-16 code units entity offsets are measured in.
+const commandWhitespace = " \t\r\n"
+
+// WithCommandNormalization adds command normalization middleware to a Handler.
+// It lowercases the command and trims the whitespace around the message, so that "/New_Token" or " /new_token "
+// are handled as "/new_token". Command arguments keep their casing.
+// Returns a Middleware wrapping the original Handler with command normalization.
+func WithCommandNormalization() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
+			return next.Handle(ctx, normalizeCommand(message))
+		})
+	}
+}
+
+// normalizeCommand returns a copy of msg with a normalized leading command, or msg itself when it has none.
+func normalizeCommand(msg *tgbotapi.Message) *tgbotapi.Message {
+	if msg == nil || len(msg.Entities) == 0 {
+		return msg
+	}
+
+	text := strings.TrimRight(strings.TrimLeft(msg.Text, commandWhitespace), commandWhitespace)
+	trimmed := len(msg.Text) - len(strings.TrimLeft(msg.Text, commandWhitespace))
+
+	entity := msg.Entities[0]
+	if entity.Type != "bot_command" || entity.Offset != trimmed || entity.Length > len(text) {
+		return msg
+	}
+
+	// Commands are ASCII, anything else means the entity length in UTF-16 units can't be used as a byte length.
+	cmd := text[:entity.Length]
+	if !isASCII(cmd) {
+		return msg
+	}
+
+	normalized := *msg
+	normalized.Text = strings.ToLower(cmd) + text[entity.Length:]
+	normalized.Entities = make([]tgbotapi.MessageEntity, len(msg.Entities))
+
+	for i, e := range msg.Entities {
+		e.Offset -= trimmed
+		normalized.Entities[i] = e
+	}
+
+	return &normalized
+}
+
+// isASCII reports whether s consists of ASCII characters only.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCommandNormalization(t *testing.T) {
+	tests := []struct {
+		message     *tgbotapi.Message
+		name        string
+		wantCommand string
+		wantArgs    string
+		wantText    string
+	}{
+		{
+			name:        "mixed case command",
+			message:     commandMessage("/New_Token 30", 0, 10),
+			wantCommand: "new_token",
+			wantArgs:    "30",
+			wantText:    "/new_token 30",
+		},
+		{
+			name:        "arguments keep their case",
+			message:     commandMessage("/FEEDBACK Renew Is Broken", 0, 9),
+			wantCommand: "feedback",
+			wantArgs:    "Renew Is Broken",
+			wantText:    "/feedback Renew Is Broken",
+		},
+		{
+			name:        "leading whitespace",
+			message:     commandMessage("  /List_Tokens", 2, 12),
+			wantCommand: "list_tokens",
+			wantText:    "/list_tokens",
+		},
+		{
+			name:        "trailing whitespace",
+			message:     commandMessage("/new_token  ", 0, 10),
+			wantCommand: "new_token",
+			wantText:    "/new_token",
+		},
+		{
+			name:        "bot mention",
+			message:     commandMessage("/Stats@MyBot", 0, 12),
+			wantCommand: "stats",
+			wantText:    "/stats@mybot",
+		},
+		{
+			name:     "plain text is left as is",
+			message:  &tgbotapi.Message{Text: "  Hello There"},
+			wantText: "  Hello There",
+		},
+		{
+			name: "command not at the start is left as is",
+			message: &tgbotapi.Message{
+				Text:     "Try /Help",
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 4, Length: 5}},
+			},
+			wantText: "Try /Help",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.message.Text
+
+			var got *tgbotapi.Message
+
+			handler := WithCommandNormalization()(SingleHandlerFunc(func(_ context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+				got = msg
+				return tgbotapi.MessageConfig{}, nil
+			}))
+
+			_, err := handler.Handle(context.Background(), tt.message)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+
+			assert.Equal(t, tt.wantText, got.Text)
+			assert.Equal(t, tt.wantCommand, got.Command())
+			assert.Equal(t, tt.wantArgs, got.CommandArguments())
+			assert.Equal(t, original, tt.message.Text, "the original message must not be modified")
+		})
+	}
+}
+
+func TestWithCommandNormalization_NilMessage(t *testing.T) {
+	handler := WithCommandNormalization()(SingleHandlerFunc(func(_ context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		assert.Nil(t, msg)
+		return tgbotapi.MessageConfig{}, nil
+	}))
+
+	_, err := handler.Handle(context.Background(), nil)
+	assert.NoError(t, err)
+}
+
+func commandMessage(text string, offset, length int) *tgbotapi.Message {
+	return &tgbotapi.Message{
+		Text:     text,
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: offset, Length: length}},
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const recoveredMessage = "Something went wrong, please try again."
+
+// WithRecovery adds panic recovery middleware to a Handler.
+// A panic raised by the next Handler is logged together with its stack trace and turned into
+// a generic reply for the user, so a single bad update cannot stop the processing of others.
+// Returns a Middleware wrapping the original Handler with panic recovery.
+func WithRecovery() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (resp []tgbotapi.MessageConfig, err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+
+				slog.ErrorContext(ctx, "Recovered from panic while handling message",
+					slog.String("panic", fmt.Sprint(r)),
+					slog.String("stack", string(debug.Stack())),
+				)
+
+				var chatID int64
+				if message != nil && message.Chat != nil {
+					chatID = message.Chat.ID
+				}
+
+				resp, err = Reply(tgbotapi.NewMessage(chatID, recoveredMessage)), nil
+			}()
+
+			return next.Handle(ctx, message)
+		})
+	}
+}
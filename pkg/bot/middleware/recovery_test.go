@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRecovery(t *testing.T) {
+	tests := []struct {
+		handler     Handler
+		message     *tgbotapi.Message
+		name        string
+		expectedMsg string
+		chatID      int64
+	}{
+		{
+			name: "recovers from panic",
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+				panic("boom")
+			}),
+			message:     &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+			expectedMsg: recoveredMessage,
+			chatID:      123,
+		},
+		{
+			name: "recovers from nil pointer dereference",
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+				return tgbotapi.NewMessage(msg.Chat.ID, "unreachable"), nil
+			}),
+			message:     &tgbotapi.Message{},
+			expectedMsg: recoveredMessage,
+		},
+		{
+			name: "passes through successful response",
+			handler: SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+				return tgbotapi.NewMessage(msg.Chat.ID, "success"), nil
+			}),
+			message:     &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+			expectedMsg: "success",
+			chatID:      123,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := WithRecovery()(tt.handler)
+
+			var resp []tgbotapi.MessageConfig
+			var err error
+
+			require.NotPanics(t, func() {
+				resp, err = handler.Handle(context.Background(), tt.message)
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedMsg, replyText(resp))
+			require.Len(t, resp, 1)
+			assert.Equal(t, tt.chatID, resp[0].ChatID)
+		})
+	}
+}
+
+func TestWithRecovery_LogsStack(t *testing.T) {
+	var buf bytes.Buffer
+
+	oldLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	defer slog.SetDefault(oldLogger)
+
+	handler := WithRecovery()(SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		panic("boom")
+	}))
+
+	_, err := handler.Handle(context.Background(), &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}})
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), "Recovered from panic while handling message")
+	assert.Contains(t, buf.String(), "panic=boom")
+	assert.Contains(t, buf.String(), "TestWithRecovery_LogsStack")
+}
@@ -21,9 +21,9 @@ func WithRequestSequencer() Middleware {
 	)
 
 	return func(next Handler) Handler {
-		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
 			if message == nil || message.From == nil {
-				return tgbotapi.MessageConfig{}, errors.New("message or user is nil")
+				return nil, errors.New("message or user is nil")
 			}
 
 			userID := message.From.ID
@@ -65,7 +65,7 @@ func WithRequestSequencer() Middleware {
 				return resp, err
 			case <-ctx.Done():
 				// Context was cancelled while waiting for our turn
-				return tgbotapi.MessageConfig{}, fmt.Errorf("context cancelled while waiting for user's previous requests to complete: %w", ctx.Err())
+				return nil, fmt.Errorf("context cancelled while waiting for user's previous requests to complete: %w", ctx.Err())
 			}
 		})
 	}
@@ -19,7 +19,7 @@ func TestWithRequestSequencerLimitsPerUserProcessing(t *testing.T) {
 	)
 
 	// Create a handler that tracks concurrent executions per user
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 		userID := msg.From.ID
 
 		mu.Lock()
@@ -70,7 +70,7 @@ func TestWithRequestSequencerLimitsPerUserProcessing(t *testing.T) {
 func TestWithRequestSequencerHandlesContextCancellation(t *testing.T) {
 	// Create a handler that blocks until explicitly unblocked
 	blockCh := make(chan struct{})
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 		<-blockCh // Block until channel is closed
 		return tgbotapi.MessageConfig{}, nil
 	})
@@ -117,7 +117,7 @@ func TestWithRequestSequencerHandlesContextCancellation(t *testing.T) {
 
 func TestWithRequestSequencerHandlerError(t *testing.T) {
 	expectedErr := errors.New("handler error")
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 		return tgbotapi.MessageConfig{}, expectedErr
 	})
 
@@ -135,7 +135,7 @@ func TestWithRequestSequencerHandlerError(t *testing.T) {
 }
 
 func TestWithRequestSequencerNilMessage(t *testing.T) {
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 		return tgbotapi.MessageConfig{}, nil
 	})
 
@@ -148,7 +148,7 @@ func TestWithRequestSequencerNilMessage(t *testing.T) {
 }
 
 func TestWithRequestSequencerNilUser(t *testing.T) {
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 		return tgbotapi.MessageConfig{}, nil
 	})
 
@@ -159,3 +159,72 @@ func TestWithRequestSequencerNilUser(t *testing.T) {
 		t.Error("expected error for nil user, got nil")
 	}
 }
+
+func TestWithRequestSequencerBackToBackMessages(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		order   []int
+		wg      sync.WaitGroup
+		release = make(chan struct{})
+		started = make(chan struct{})
+	)
+
+	handler := SingleHandlerFunc(func(_ context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		if msg.MessageID == 1 {
+			close(started)
+			<-release
+		}
+
+		mu.Lock()
+		order = append(order, msg.MessageID)
+		mu.Unlock()
+
+		return tgbotapi.MessageConfig{}, nil
+	})
+
+	sequenced := WithRequestSequencer()(handler)
+
+	send := func(id int) {
+		defer wg.Done()
+
+		msg := &tgbotapi.Message{MessageID: id, From: &tgbotapi.User{ID: 1}, Chat: &tgbotapi.Chat{ID: 1}}
+		if _, err := sequenced.Handle(context.Background(), msg); err != nil {
+			t.Errorf("message %d: unexpected error: %v", id, err)
+		}
+	}
+
+	wg.Add(3)
+
+	go send(1)
+	<-started
+
+	// Give each message time to queue up behind the previous one.
+	go send(2)
+	time.Sleep(20 * time.Millisecond)
+
+	go send(3)
+	time.Sleep(20 * time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	if len(order) != 3 || order[0] != 1 || order[1] != 2 || order[2] != 3 {
+		t.Fatalf("expected messages to be handled in order [1 2 3], got %v", order)
+	}
+
+	// The chat must not be left blocked once the burst is over.
+	done := make(chan struct{})
+
+	wg.Add(1)
+
+	go func() {
+		send(4)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("message after the burst was not handled")
+	}
+}
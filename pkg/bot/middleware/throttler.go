@@ -4,22 +4,76 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+const busyMessage = "I'm busy right now, please try again in a moment."
+
+// ThrottlerOption configures optional behavior of WithThrottler.
+type ThrottlerOption func(*throttlerOptions)
+
+type throttlerOptions struct {
+	busyTimeout    time.Duration
+	rejectWhenBusy bool
+}
+
+// WithRejectWhenBusy makes the throttler reply with a "busy, try again" message as soon as all slots are taken,
+// instead of the default behavior of waiting for a free slot until the request context is cancelled.
+// Rejected requests never reach the next Handler.
+func WithRejectWhenBusy() ThrottlerOption {
+	return func(o *throttlerOptions) {
+		o.rejectWhenBusy = true
+	}
+}
+
+// WithBusyTimeout makes the throttler reply with a "busy, try again" message when no slot frees up within timeout,
+// instead of waiting until the request context is cancelled. A non-positive timeout keeps the default behavior.
+func WithBusyTimeout(timeout time.Duration) ThrottlerOption {
+	return func(o *throttlerOptions) {
+		o.busyTimeout = timeout
+	}
+}
+
 // WithThrottler limits the number of concurrent handler executions by ensuring no more than maxConcurrent routines run.
-// It uses a buffered channel as a semaphore to manage concurrency, blocking excess requests until a slot is available.
-// Accepts maxConcurrent, the maximum number of concurrent executions allowed.
+// It uses a buffered channel as a semaphore to manage concurrency, blocking excess requests until a slot is available
+// unless WithRejectWhenBusy or WithBusyTimeout is given.
+// Accepts maxConcurrent, the maximum number of concurrent executions allowed, and optional ThrottlerOption values.
 // Returns a Middleware that enforces the concurrency limit and an error if context is cancelled or message is nil.
-func WithThrottler(maxConcurrent int) Middleware {
+func WithThrottler(maxConcurrent int, opts ...ThrottlerOption) Middleware {
+	var o throttlerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	// Create a buffered channel with capacity of maxConcurrent to act as a semaphore
 	throttler := make(chan struct{}, maxConcurrent)
 
 	return func(next Handler) Handler {
-		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) ([]tgbotapi.MessageConfig, error) {
 			if message == nil {
-				return tgbotapi.MessageConfig{}, errors.New("message is nil")
+				return nil, errors.New("message is nil")
+			}
+
+			if o.rejectWhenBusy {
+				select {
+				case throttler <- struct{}{}:
+					defer func() { <-throttler }()
+					return next.Handle(ctx, message)
+				default:
+					return Reply(newBusyMessage(message)), nil
+				}
+			}
+
+			// A nil channel never fires, so without a busy timeout the request waits for a slot or cancellation.
+			var busy <-chan time.Time
+
+			if o.busyTimeout > 0 {
+				timer := time.NewTimer(o.busyTimeout)
+				defer timer.Stop()
+
+				busy = timer.C
 			}
 
 			// Try to acquire a slot or wait for context cancellation
@@ -29,10 +83,22 @@ func WithThrottler(maxConcurrent int) Middleware {
 				defer func() { <-throttler }()
 				// Process the message
 				return next.Handle(ctx, message)
+			case <-busy:
+				return Reply(newBusyMessage(message)), nil
 			case <-ctx.Done():
 				// Context was cancelled while waiting for a slot
-				return tgbotapi.MessageConfig{}, fmt.Errorf("context cancelled while waiting for throttler: %w", ctx.Err())
+				return nil, fmt.Errorf("context cancelled while waiting for throttler: %w", ctx.Err())
 			}
 		})
 	}
 }
+
+// newBusyMessage builds the reply sent to requests the throttler couldn't admit.
+func newBusyMessage(message *tgbotapi.Message) tgbotapi.MessageConfig {
+	var chatID int64
+	if message.Chat != nil {
+		chatID = message.Chat.ID
+	}
+
+	return tgbotapi.NewMessage(chatID, busyMessage)
+}
@@ -9,6 +9,7 @@ import (
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWithThrottlerLimitsConcurrentProcessing(t *testing.T) {
@@ -20,7 +21,7 @@ func TestWithThrottlerLimitsConcurrentProcessing(t *testing.T) {
 	)
 
 	// Create a handler that tracks concurrent executions
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 		mu.Lock()
 		currentCount++
 		if currentCount > maxCount {
@@ -58,7 +59,7 @@ func TestWithThrottlerLimitsConcurrentProcessing(t *testing.T) {
 func TestWithThrottlerHandlesContextCancellation(t *testing.T) {
 	// Create a handler that blocks until explicitly unblocked
 	blockCh := make(chan struct{})
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 		<-blockCh // Block until channel is closed
 		return tgbotapi.MessageConfig{}, nil
 	})
@@ -91,7 +92,7 @@ func TestWithThrottlerHandlesContextCancellation(t *testing.T) {
 }
 
 func TestWithThrottlerNilMessage(t *testing.T) {
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 		return tgbotapi.MessageConfig{}, nil
 	})
 
@@ -104,7 +105,7 @@ func TestWithThrottlerNilMessage(t *testing.T) {
 
 func TestWithThrottlerHandlerError(t *testing.T) {
 	expectedErr := errors.New("handler error")
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 		return tgbotapi.MessageConfig{}, expectedErr
 	})
 
@@ -116,7 +117,7 @@ func TestWithThrottlerHandlerError(t *testing.T) {
 }
 
 func TestWithThrottlerReleasesSlots(t *testing.T) {
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
 		return tgbotapi.MessageConfig{}, nil
 	})
 
@@ -130,3 +131,132 @@ func TestWithThrottlerReleasesSlots(t *testing.T) {
 	_, err2 := throttled.Handle(context.Background(), &tgbotapi.Message{})
 	assert.NoError(t, err2, "second call should succeed after slot is released")
 }
+
+func TestWithThrottlerRejectWhenBusy(t *testing.T) {
+	const limit = 3
+
+	var (
+		mu       sync.Mutex
+		current  int
+		maxCount int
+		handled  int
+		busy     int
+		wg       sync.WaitGroup
+	)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, limit)
+
+	handler := SingleHandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		mu.Lock()
+		current++
+		maxCount = max(maxCount, current)
+		mu.Unlock()
+
+		started <- struct{}{}
+		<-release
+
+		mu.Lock()
+		current--
+		handled++
+		mu.Unlock()
+
+		return tgbotapi.NewMessage(msg.Chat.ID, "done"), nil
+	})
+
+	throttled := WithThrottler(limit, WithRejectWhenBusy())(handler)
+
+	send := func() {
+		defer wg.Done()
+
+		resp, err := throttled.Handle(context.Background(), &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1}})
+		assert.NoError(t, err)
+
+		if replyText(resp) == busyMessage {
+			mu.Lock()
+			busy++
+			mu.Unlock()
+		}
+	}
+
+	// Occupy every slot first so that the following requests are guaranteed to be over the limit.
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+
+		go send()
+	}
+
+	for i := 0; i < limit; i++ {
+		<-started
+	}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go send()
+	}
+
+	// Wait for the rejected requests before freeing the slots.
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return busy == 10
+	}, time.Second, 5*time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, limit, maxCount)
+	assert.Equal(t, limit, handled)
+	assert.Equal(t, 10, busy)
+
+	// Slots are released after processing, so new requests are accepted again.
+	resp, err := throttled.Handle(context.Background(), &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "done", replyText(resp))
+}
+
+func TestWithThrottlerBusyTimeout(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	handler := SingleHandlerFunc(func(_ context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		if msg.MessageID == 1 {
+			close(started)
+			<-release
+		}
+
+		return tgbotapi.NewMessage(msg.Chat.ID, "done"), nil
+	})
+
+	throttled := WithThrottler(1, WithBusyTimeout(20*time.Millisecond))(handler)
+
+	done := make(chan struct{})
+
+	// Saturate the only slot.
+	go func() {
+		defer close(done)
+
+		resp, err := throttled.Handle(context.Background(), &tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: 1}})
+		assert.NoError(t, err)
+		assert.Equal(t, "done", replyText(resp))
+	}()
+
+	<-started
+
+	resp, err := throttled.Handle(context.Background(), &tgbotapi.Message{MessageID: 2, Chat: &tgbotapi.Chat{ID: 2}})
+	require.NoError(t, err)
+	require.Len(t, resp, 1)
+	assert.Equal(t, busyMessage, resp[0].Text)
+	assert.Equal(t, int64(2), resp[0].ChatID)
+
+	close(release)
+	<-done
+
+	// Once the slot is free, requests are admitted again.
+	resp, err = throttled.Handle(context.Background(), &tgbotapi.Message{MessageID: 3, Chat: &tgbotapi.Chat{ID: 3}})
+	require.NoError(t, err)
+	assert.Equal(t, "done", replyText(resp))
+}
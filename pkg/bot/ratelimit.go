@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// defaultChatSendInterval and defaultGlobalSendRate follow the Telegram guidance of about one message
+	// per second in a chat and 30 messages per second overall.
+	defaultChatSendInterval = time.Second
+	defaultChatSendBurst    = 3
+	defaultGlobalSendRate   = 30
+
+	// maxIdleChats is the number of tracked chats above which buckets back at full capacity are dropped.
+	maxIdleChats = 1024
+)
+
+// bucket is a token bucket kept as the time at which it will be full again (the theoretical arrival time
+// of the generic cell rate algorithm). A zero bucket is full.
+type bucket struct {
+	full time.Time
+}
+
+// earliest returns the earliest time at or after now at which a message may be sent.
+func (b *bucket) earliest(now time.Time, interval time.Duration, burst int) time.Time {
+	at := b.full.Add(-time.Duration(burst-1) * interval)
+	if at.Before(now) {
+		return now
+	}
+
+	return at
+}
+
+// take uses up one message sent at the given time.
+func (b *bucket) take(at time.Time, interval time.Duration) {
+	if b.full.Before(at) {
+		b.full = at
+	}
+
+	b.full = b.full.Add(interval)
+}
+
+// sendLimiter paces outgoing messages with a token bucket per chat and a global one. Callers wait for their turn
+// in the order they asked for it, so messages to a chat are never sent in a burst beyond its bucket.
+// A nil sendLimiter doesn't limit anything.
+type sendLimiter struct {
+	now            func() time.Time
+	chats          map[int64]*bucket
+	global         bucket
+	mu             sync.Mutex
+	chatInterval   time.Duration
+	globalInterval time.Duration
+	chatBurst      int
+	globalBurst    int
+}
+
+// newSendLimiter creates a limiter allowing a message per chatInterval in a chat, with bursts of up to chatBurst
+// messages, and up to globalRate messages per second overall. Zero settings are replaced with defaults.
+func newSendLimiter(chatInterval time.Duration, chatBurst, globalRate int) *sendLimiter {
+	if chatInterval <= 0 {
+		chatInterval = defaultChatSendInterval
+	}
+
+	if chatBurst <= 0 {
+		chatBurst = defaultChatSendBurst
+	}
+
+	if globalRate <= 0 {
+		globalRate = defaultGlobalSendRate
+	}
+
+	return &sendLimiter{
+		now:            time.Now,
+		chats:          make(map[int64]*bucket),
+		chatInterval:   chatInterval,
+		chatBurst:      chatBurst,
+		globalInterval: time.Second / time.Duration(globalRate),
+		globalBurst:    globalRate,
+	}
+}
+
+// wait blocks until a message may be sent to the chat, or until ctx is done.
+func (l *sendLimiter) wait(ctx context.Context, chatID int64) error {
+	if l == nil {
+		return nil
+	}
+
+	delay := l.reserve(chatID)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve books the earliest slot allowed by both the chat and the global bucket and returns how long
+// to wait for it.
+func (l *sendLimiter) reserve(chatID int64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	if len(l.chats) > maxIdleChats {
+		l.dropIdle(now)
+	}
+
+	chat, ok := l.chats[chatID]
+	if !ok {
+		chat = &bucket{}
+		l.chats[chatID] = chat
+	}
+
+	at := chat.earliest(now, l.chatInterval, l.chatBurst)
+	if global := l.global.earliest(now, l.globalInterval, l.globalBurst); global.After(at) {
+		at = global
+	}
+
+	// The global bucket is charged from now rather than from the booked slot, so that a message held back
+	// by its chat doesn't throttle messages to other chats in the meantime.
+	chat.take(at, l.chatInterval)
+	l.global.take(now, l.globalInterval)
+
+	return at.Sub(now)
+}
+
+// dropIdle forgets the chats whose buckets are full again, they behave like new ones. The caller must hold l.mu.
+func (l *sendLimiter) dropIdle(now time.Time) {
+	for id, b := range l.chats {
+		if !b.full.After(now) {
+			delete(l.chats, id)
+		}
+	}
+}
+
+// chatIDOf returns the chat a message is sent to, zero for kinds of messages the bot doesn't send.
+func chatIDOf(c tgbotapi.Chattable) int64 {
+	switch m := c.(type) {
+	case tgbotapi.MessageConfig:
+		return m.ChatID
+	case tgbotapi.DocumentConfig:
+		return m.ChatID
+	default:
+		return 0
+	}
+}
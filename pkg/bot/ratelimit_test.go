@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLimiter returns a send limiter whose clock only moves when the returned function is called.
+func newTestLimiter(chatInterval time.Duration, chatBurst, globalRate int) (*sendLimiter, func(time.Duration)) {
+	now := time.Now()
+	l := newSendLimiter(chatInterval, chatBurst, globalRate)
+	l.now = func() time.Time { return now }
+
+	return l, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestSendLimiter_Reserve(t *testing.T) {
+	t.Run("paces a chat after its burst", func(t *testing.T) {
+		l, advance := newTestLimiter(time.Second, 2, 100)
+
+		assert.Zero(t, l.reserve(1))
+		assert.Zero(t, l.reserve(1))
+		assert.Equal(t, time.Second, l.reserve(1))
+		assert.Equal(t, 2*time.Second, l.reserve(1))
+
+		advance(5 * time.Second)
+
+		assert.Zero(t, l.reserve(1), "bucket refills while the chat is idle")
+	})
+
+	t.Run("chats don't delay each other", func(t *testing.T) {
+		l, _ := newTestLimiter(time.Second, 1, 100)
+
+		assert.Zero(t, l.reserve(1))
+		assert.Equal(t, time.Second, l.reserve(1))
+		assert.Zero(t, l.reserve(2))
+	})
+
+	t.Run("global cap applies across chats", func(t *testing.T) {
+		l, _ := newTestLimiter(time.Second, 1, 2)
+
+		assert.Zero(t, l.reserve(1))
+		assert.Zero(t, l.reserve(2))
+		assert.Equal(t, 500*time.Millisecond, l.reserve(3))
+	})
+}
+
+func TestSendLimiter_DropsIdleChats(t *testing.T) {
+	l, advance := newTestLimiter(time.Second, 1, 1_000_000)
+
+	for id := range int64(maxIdleChats + 1) {
+		l.reserve(id)
+	}
+
+	advance(time.Second)
+	l.reserve(-1)
+
+	assert.Len(t, l.chats, 1)
+}
+
+func TestSendLimiter_Wait(t *testing.T) {
+	var nilLimiter *sendLimiter
+	require.NoError(t, nilLimiter.wait(context.Background(), 1))
+
+	l := newSendLimiter(time.Hour, 1, 0)
+	require.NoError(t, l.wait(context.Background(), 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, l.wait(ctx, 1), context.Canceled)
+}
+
+func TestSend_SpacesMessagesToSameChat(t *testing.T) {
+	const interval = 50 * time.Millisecond
+
+	mockTg := NewMocktgClient(t)
+	mockTg.On("Send", mock.Anything).Return(tgbotapi.Message{}, nil).Times(2)
+
+	svc := &Service{tg: mockTg, limiter: newSendLimiter(interval, 1, 0)}
+
+	start := time.Now()
+
+	_, err := svc.send(context.Background(), tgbotapi.NewMessage(123, "first"))
+	require.NoError(t, err)
+
+	_, err = svc.send(context.Background(), tgbotapi.NewMessage(123, "second"))
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, time.Since(start), interval)
+}
+
+func TestChatIDOf(t *testing.T) {
+	assert.Equal(t, int64(123), chatIDOf(tgbotapi.NewMessage(123, "hello")))
+	assert.Equal(t, int64(123), chatIDOf(tgbotapi.NewDocument(123, tgbotapi.FileBytes{Name: "a.csv"})))
+	assert.Zero(t, chatIDOf(tgbotapi.NewSetMyCommands()))
+}
@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxSendRetries is the number of extra attempts made when Telegram rate limits a message.
+	maxSendRetries = 2
+	// maxRetryAfter caps the wait suggested by Telegram; longer waits would hold the worker for too long.
+	maxRetryAfter = 30 * time.Second
+)
+
+// send delivers c to Telegram once the send limiter lets it through. When Telegram answers 429 Too Many Requests,
+// it waits the retry_after period from the response and tries again, up to maxSendRetries times. Any other error
+// is returned immediately, as is the last rate limit error once the retries are exhausted. Waiting stops early
+// once ctx is done.
+func (s *Service) send(ctx context.Context, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	if err := s.limiter.wait(ctx, chatIDOf(c)); err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		msg, err := s.tg.Send(c)
+
+		wait, ok := retryAfter(err)
+		if !ok || attempt >= maxSendRetries {
+			return msg, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return tgbotapi.Message{}, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryAfter reports whether err is a Telegram rate limit worth waiting for, and how long to wait.
+func retryAfter(err error) (time.Duration, bool) {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) || tgErr.Code != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	wait := time.Duration(tgErr.RetryAfter) * time.Second
+	if wait > maxRetryAfter {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+// isBotBlocked reports whether err is Telegram refusing a message because the user blocked the bot.
+func isBotBlocked(err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) || tgErr.Code != http.StatusForbidden {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(tgErr.Message), "bot was blocked by the user")
+}
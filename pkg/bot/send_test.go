@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// tooManyRequests builds the error returned by the Telegram client for a 429 response.
+func tooManyRequests(retryAfter int) error {
+	return &tgbotapi.Error{
+		Code:               http.StatusTooManyRequests,
+		Message:            "Too Many Requests: retry after " + fmt.Sprint(retryAfter),
+		ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: retryAfter},
+	}
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		wantErr   error
+		name      string
+		errs      []error
+		wantCalls int
+	}{
+		{
+			name:      "success",
+			errs:      []error{nil},
+			wantCalls: 1,
+		},
+		{
+			name:      "retries after rate limit",
+			errs:      []error{tooManyRequests(0), nil},
+			wantCalls: 2,
+		},
+		{
+			name:      "gives up after max retries",
+			errs:      []error{tooManyRequests(0), tooManyRequests(0), tooManyRequests(0)},
+			wantCalls: maxSendRetries + 1,
+			wantErr:   &tgbotapi.Error{},
+		},
+		{
+			name:      "retry_after too long",
+			errs:      []error{tooManyRequests(3600)},
+			wantCalls: 1,
+			wantErr:   &tgbotapi.Error{},
+		},
+		{
+			name:      "other error is not retried",
+			errs:      []error{assert.AnError},
+			wantCalls: 1,
+			wantErr:   assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTg := NewMocktgClient(t)
+
+			for _, err := range tt.errs {
+				mockTg.EXPECT().Send(mock.Anything).Return(tgbotapi.Message{MessageID: 1}, err).Once()
+			}
+
+			svc := &Service{tg: mockTg}
+
+			msg, err := svc.send(context.Background(), tgbotapi.NewMessage(123, "hello"))
+
+			mockTg.AssertNumberOfCalls(t, "Send", tt.wantCalls)
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				assert.Equal(t, 1, msg.MessageID)
+
+				return
+			}
+
+			var tgErr *tgbotapi.Error
+			if errors.As(tt.wantErr, &tgErr) {
+				assert.ErrorAs(t, err, &tgErr)
+				assert.Equal(t, http.StatusTooManyRequests, tgErr.Code)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSend_ContextCancelledWhileWaiting(t *testing.T) {
+	mockTg := NewMocktgClient(t)
+	mockTg.EXPECT().Send(mock.Anything).Return(tgbotapi.Message{}, tooManyRequests(1)).Once()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	svc := &Service{tg: mockTg}
+
+	_, err := svc.send(ctx, tgbotapi.NewMessage(123, "hello"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -24,12 +24,72 @@ func (_m *MockTokenService) EXPECT() *MockTokenService_Expecter {
 	return &MockTokenService_Expecter{mock: &_m.Mock}
 }
 
-// CreateToken provides a mock function with given fields: ctx, userID
-func (_m *MockTokenService) CreateToken(ctx context.Context, userID string) (*core.Response, error) {
+// AuditLog provides a mock function with given fields: ctx, userID, limit
+func (_m *MockTokenService) AuditLog(ctx context.Context, userID string, limit int) (*core.Response, error) {
+	ret := _m.Called(ctx, userID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuditLog")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*core.Response, error)); ok {
+		return rf(ctx, userID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *core.Response); ok {
+		r0 = rf(ctx, userID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, userID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_AuditLog_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuditLog'
+type MockTokenService_AuditLog_Call struct {
+	*mock.Call
+}
+
+// AuditLog is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - limit int
+func (_e *MockTokenService_Expecter) AuditLog(ctx interface{}, userID interface{}, limit interface{}) *MockTokenService_AuditLog_Call {
+	return &MockTokenService_AuditLog_Call{Call: _e.mock.On("AuditLog", ctx, userID, limit)}
+}
+
+func (_c *MockTokenService_AuditLog_Call) Run(run func(ctx context.Context, userID string, limit int)) *MockTokenService_AuditLog_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_AuditLog_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_AuditLog_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_AuditLog_Call) RunAndReturn(run func(context.Context, string, int) (*core.Response, error)) *MockTokenService_AuditLog_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ClearDefaultExpiration provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) ClearDefaultExpiration(ctx context.Context, userID string) (*core.Response, error) {
 	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateToken")
+		panic("no return value specified for ClearDefaultExpiration")
 	}
 
 	var r0 *core.Response
@@ -54,58 +114,58 @@ func (_m *MockTokenService) CreateToken(ctx context.Context, userID string) (*co
 	return r0, r1
 }
 
-// MockTokenService_CreateToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateToken'
-type MockTokenService_CreateToken_Call struct {
+// MockTokenService_ClearDefaultExpiration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClearDefaultExpiration'
+type MockTokenService_ClearDefaultExpiration_Call struct {
 	*mock.Call
 }
 
-// CreateToken is a helper method to define mock.On call
+// ClearDefaultExpiration is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID string
-func (_e *MockTokenService_Expecter) CreateToken(ctx interface{}, userID interface{}) *MockTokenService_CreateToken_Call {
-	return &MockTokenService_CreateToken_Call{Call: _e.mock.On("CreateToken", ctx, userID)}
+func (_e *MockTokenService_Expecter) ClearDefaultExpiration(ctx interface{}, userID interface{}) *MockTokenService_ClearDefaultExpiration_Call {
+	return &MockTokenService_ClearDefaultExpiration_Call{Call: _e.mock.On("ClearDefaultExpiration", ctx, userID)}
 }
 
-func (_c *MockTokenService_CreateToken_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_CreateToken_Call {
+func (_c *MockTokenService_ClearDefaultExpiration_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_ClearDefaultExpiration_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockTokenService_CreateToken_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_CreateToken_Call {
+func (_c *MockTokenService_ClearDefaultExpiration_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_ClearDefaultExpiration_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockTokenService_CreateToken_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_CreateToken_Call {
+func (_c *MockTokenService_ClearDefaultExpiration_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_ClearDefaultExpiration_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// HandleMessage provides a mock function with given fields: ctx, userID, message
-func (_m *MockTokenService) HandleMessage(ctx context.Context, userID string, message string) (*core.Response, error) {
-	ret := _m.Called(ctx, userID, message)
+// ConfirmRevokeAll provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) ConfirmRevokeAll(ctx context.Context, userID string) (*core.Response, error) {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for HandleMessage")
+		panic("no return value specified for ConfirmRevokeAll")
 	}
 
 	var r0 *core.Response
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*core.Response, error)); ok {
-		return rf(ctx, userID, message)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.Response, error)); ok {
+		return rf(ctx, userID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.Response); ok {
-		r0 = rf(ctx, userID, message)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.Response); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*core.Response)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, userID, message)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -113,42 +173,41 @@ func (_m *MockTokenService) HandleMessage(ctx context.Context, userID string, me
 	return r0, r1
 }
 
-// MockTokenService_HandleMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HandleMessage'
-type MockTokenService_HandleMessage_Call struct {
+// MockTokenService_ConfirmRevokeAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConfirmRevokeAll'
+type MockTokenService_ConfirmRevokeAll_Call struct {
 	*mock.Call
 }
 
-// HandleMessage is a helper method to define mock.On call
+// ConfirmRevokeAll is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID string
-//   - message string
-func (_e *MockTokenService_Expecter) HandleMessage(ctx interface{}, userID interface{}, message interface{}) *MockTokenService_HandleMessage_Call {
-	return &MockTokenService_HandleMessage_Call{Call: _e.mock.On("HandleMessage", ctx, userID, message)}
+func (_e *MockTokenService_Expecter) ConfirmRevokeAll(ctx interface{}, userID interface{}) *MockTokenService_ConfirmRevokeAll_Call {
+	return &MockTokenService_ConfirmRevokeAll_Call{Call: _e.mock.On("ConfirmRevokeAll", ctx, userID)}
 }
 
-func (_c *MockTokenService_HandleMessage_Call) Run(run func(ctx context.Context, userID string, message string)) *MockTokenService_HandleMessage_Call {
+func (_c *MockTokenService_ConfirmRevokeAll_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_ConfirmRevokeAll_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockTokenService_HandleMessage_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_HandleMessage_Call {
+func (_c *MockTokenService_ConfirmRevokeAll_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_ConfirmRevokeAll_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockTokenService_HandleMessage_Call) RunAndReturn(run func(context.Context, string, string) (*core.Response, error)) *MockTokenService_HandleMessage_Call {
+func (_c *MockTokenService_ConfirmRevokeAll_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_ConfirmRevokeAll_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListTokens provides a mock function with given fields: ctx, userID
-func (_m *MockTokenService) ListTokens(ctx context.Context, userID string) (*core.Response, error) {
+// CreateToken provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) CreateToken(ctx context.Context, userID string) (*core.Response, error) {
 	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListTokens")
+		panic("no return value specified for CreateToken")
 	}
 
 	var r0 *core.Response
@@ -173,41 +232,101 @@ func (_m *MockTokenService) ListTokens(ctx context.Context, userID string) (*cor
 	return r0, r1
 }
 
-// MockTokenService_ListTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTokens'
-type MockTokenService_ListTokens_Call struct {
+// MockTokenService_CreateToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateToken'
+type MockTokenService_CreateToken_Call struct {
 	*mock.Call
 }
 
-// ListTokens is a helper method to define mock.On call
+// CreateToken is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID string
-func (_e *MockTokenService_Expecter) ListTokens(ctx interface{}, userID interface{}) *MockTokenService_ListTokens_Call {
-	return &MockTokenService_ListTokens_Call{Call: _e.mock.On("ListTokens", ctx, userID)}
+func (_e *MockTokenService_Expecter) CreateToken(ctx interface{}, userID interface{}) *MockTokenService_CreateToken_Call {
+	return &MockTokenService_CreateToken_Call{Call: _e.mock.On("CreateToken", ctx, userID)}
 }
 
-func (_c *MockTokenService_ListTokens_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_ListTokens_Call {
+func (_c *MockTokenService_CreateToken_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_CreateToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockTokenService_ListTokens_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_ListTokens_Call {
+func (_c *MockTokenService_CreateToken_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_CreateToken_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockTokenService_ListTokens_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_ListTokens_Call {
+func (_c *MockTokenService_CreateToken_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_CreateToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ResetConversation provides a mock function with given fields: ctx, userID
-func (_m *MockTokenService) ResetConversation(ctx context.Context, userID string) error {
+// CreateTokenWithExpiration provides a mock function with given fields: ctx, userID, days
+func (_m *MockTokenService) CreateTokenWithExpiration(ctx context.Context, userID string, days int) (*core.Response, error) {
+	ret := _m.Called(ctx, userID, days)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTokenWithExpiration")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*core.Response, error)); ok {
+		return rf(ctx, userID, days)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *core.Response); ok {
+		r0 = rf(ctx, userID, days)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, userID, days)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_CreateTokenWithExpiration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateTokenWithExpiration'
+type MockTokenService_CreateTokenWithExpiration_Call struct {
+	*mock.Call
+}
+
+// CreateTokenWithExpiration is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - days int
+func (_e *MockTokenService_Expecter) CreateTokenWithExpiration(ctx interface{}, userID interface{}, days interface{}) *MockTokenService_CreateTokenWithExpiration_Call {
+	return &MockTokenService_CreateTokenWithExpiration_Call{Call: _e.mock.On("CreateTokenWithExpiration", ctx, userID, days)}
+}
+
+func (_c *MockTokenService_CreateTokenWithExpiration_Call) Run(run func(ctx context.Context, userID string, days int)) *MockTokenService_CreateTokenWithExpiration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_CreateTokenWithExpiration_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_CreateTokenWithExpiration_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_CreateTokenWithExpiration_Call) RunAndReturn(run func(context.Context, string, int) (*core.Response, error)) *MockTokenService_CreateTokenWithExpiration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeactivateUserChat provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) DeactivateUserChat(ctx context.Context, userID string) error {
 	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ResetConversation")
+		panic("no return value specified for DeactivateUserChat")
 	}
 
 	var r0 error
@@ -220,41 +339,160 @@ func (_m *MockTokenService) ResetConversation(ctx context.Context, userID string
 	return r0
 }
 
-// MockTokenService_ResetConversation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetConversation'
-type MockTokenService_ResetConversation_Call struct {
+// MockTokenService_DeactivateUserChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeactivateUserChat'
+type MockTokenService_DeactivateUserChat_Call struct {
 	*mock.Call
 }
 
-// ResetConversation is a helper method to define mock.On call
+// DeactivateUserChat is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID string
-func (_e *MockTokenService_Expecter) ResetConversation(ctx interface{}, userID interface{}) *MockTokenService_ResetConversation_Call {
-	return &MockTokenService_ResetConversation_Call{Call: _e.mock.On("ResetConversation", ctx, userID)}
+func (_e *MockTokenService_Expecter) DeactivateUserChat(ctx interface{}, userID interface{}) *MockTokenService_DeactivateUserChat_Call {
+	return &MockTokenService_DeactivateUserChat_Call{Call: _e.mock.On("DeactivateUserChat", ctx, userID)}
 }
 
-func (_c *MockTokenService_ResetConversation_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_ResetConversation_Call {
+func (_c *MockTokenService_DeactivateUserChat_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_DeactivateUserChat_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockTokenService_ResetConversation_Call) Return(_a0 error) *MockTokenService_ResetConversation_Call {
+func (_c *MockTokenService_DeactivateUserChat_Call) Return(_a0 error) *MockTokenService_DeactivateUserChat_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockTokenService_ResetConversation_Call) RunAndReturn(run func(context.Context, string) error) *MockTokenService_ResetConversation_Call {
+func (_c *MockTokenService_DeactivateUserChat_Call) RunAndReturn(run func(context.Context, string) error) *MockTokenService_DeactivateUserChat_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// RevokeToken provides a mock function with given fields: ctx, userID
-func (_m *MockTokenService) RevokeToken(ctx context.Context, userID string) (*core.Response, error) {
+// ExportTokens provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) ExportTokens(ctx context.Context, userID string) ([]core.TokenExport, error) {
 	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for RevokeToken")
+		panic("no return value specified for ExportTokens")
+	}
+
+	var r0 []core.TokenExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]core.TokenExport, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []core.TokenExport); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.TokenExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_ExportTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportTokens'
+type MockTokenService_ExportTokens_Call struct {
+	*mock.Call
+}
+
+// ExportTokens is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockTokenService_Expecter) ExportTokens(ctx interface{}, userID interface{}) *MockTokenService_ExportTokens_Call {
+	return &MockTokenService_ExportTokens_Call{Call: _e.mock.On("ExportTokens", ctx, userID)}
+}
+
+func (_c *MockTokenService_ExportTokens_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_ExportTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_ExportTokens_Call) Return(_a0 []core.TokenExport, _a1 error) *MockTokenService_ExportTokens_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_ExportTokens_Call) RunAndReturn(run func(context.Context, string) ([]core.TokenExport, error)) *MockTokenService_ExportTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRecentActions provides a mock function with given fields: ctx, userID, n
+func (_m *MockTokenService) GetRecentActions(ctx context.Context, userID string, n int) ([]core.AuditEntry, error) {
+	ret := _m.Called(ctx, userID, n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRecentActions")
+	}
+
+	var r0 []core.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]core.AuditEntry, error)); ok {
+		return rf(ctx, userID, n)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []core.AuditEntry); ok {
+		r0 = rf(ctx, userID, n)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, userID, n)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_GetRecentActions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRecentActions'
+type MockTokenService_GetRecentActions_Call struct {
+	*mock.Call
+}
+
+// GetRecentActions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - n int
+func (_e *MockTokenService_Expecter) GetRecentActions(ctx interface{}, userID interface{}, n interface{}) *MockTokenService_GetRecentActions_Call {
+	return &MockTokenService_GetRecentActions_Call{Call: _e.mock.On("GetRecentActions", ctx, userID, n)}
+}
+
+func (_c *MockTokenService_GetRecentActions_Call) Run(run func(ctx context.Context, userID string, n int)) *MockTokenService_GetRecentActions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_GetRecentActions_Call) Return(_a0 []core.AuditEntry, _a1 error) *MockTokenService_GetRecentActions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_GetRecentActions_Call) RunAndReturn(run func(context.Context, string, int) ([]core.AuditEntry, error)) *MockTokenService_GetRecentActions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GoBack provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) GoBack(ctx context.Context, userID string) (*core.Response, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GoBack")
 	}
 
 	var r0 *core.Response
@@ -279,31 +517,894 @@ func (_m *MockTokenService) RevokeToken(ctx context.Context, userID string) (*co
 	return r0, r1
 }
 
-// MockTokenService_RevokeToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeToken'
-type MockTokenService_RevokeToken_Call struct {
+// MockTokenService_GoBack_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GoBack'
+type MockTokenService_GoBack_Call struct {
 	*mock.Call
 }
 
-// RevokeToken is a helper method to define mock.On call
+// GoBack is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID string
-func (_e *MockTokenService_Expecter) RevokeToken(ctx interface{}, userID interface{}) *MockTokenService_RevokeToken_Call {
-	return &MockTokenService_RevokeToken_Call{Call: _e.mock.On("RevokeToken", ctx, userID)}
+func (_e *MockTokenService_Expecter) GoBack(ctx interface{}, userID interface{}) *MockTokenService_GoBack_Call {
+	return &MockTokenService_GoBack_Call{Call: _e.mock.On("GoBack", ctx, userID)}
 }
 
-func (_c *MockTokenService_RevokeToken_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_RevokeToken_Call {
+func (_c *MockTokenService_GoBack_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_GoBack_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockTokenService_RevokeToken_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_RevokeToken_Call {
+func (_c *MockTokenService_GoBack_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_GoBack_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockTokenService_RevokeToken_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_RevokeToken_Call {
+func (_c *MockTokenService_GoBack_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_GoBack_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HandleMessage provides a mock function with given fields: ctx, userID, message
+func (_m *MockTokenService) HandleMessage(ctx context.Context, userID string, message string) (*core.Response, error) {
+	ret := _m.Called(ctx, userID, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HandleMessage")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*core.Response, error)); ok {
+		return rf(ctx, userID, message)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.Response); ok {
+		r0 = rf(ctx, userID, message)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, message)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_HandleMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HandleMessage'
+type MockTokenService_HandleMessage_Call struct {
+	*mock.Call
+}
+
+// HandleMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - message string
+func (_e *MockTokenService_Expecter) HandleMessage(ctx interface{}, userID interface{}, message interface{}) *MockTokenService_HandleMessage_Call {
+	return &MockTokenService_HandleMessage_Call{Call: _e.mock.On("HandleMessage", ctx, userID, message)}
+}
+
+func (_c *MockTokenService_HandleMessage_Call) Run(run func(ctx context.Context, userID string, message string)) *MockTokenService_HandleMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_HandleMessage_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_HandleMessage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_HandleMessage_Call) RunAndReturn(run func(context.Context, string, string) (*core.Response, error)) *MockTokenService_HandleMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HasActiveConversation provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) HasActiveConversation(ctx context.Context, userID string) (bool, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HasActiveConversation")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_HasActiveConversation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HasActiveConversation'
+type MockTokenService_HasActiveConversation_Call struct {
+	*mock.Call
+}
+
+// HasActiveConversation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockTokenService_Expecter) HasActiveConversation(ctx interface{}, userID interface{}) *MockTokenService_HasActiveConversation_Call {
+	return &MockTokenService_HasActiveConversation_Call{Call: _e.mock.On("HasActiveConversation", ctx, userID)}
+}
+
+func (_c *MockTokenService_HasActiveConversation_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_HasActiveConversation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_HasActiveConversation_Call) Return(_a0 bool, _a1 error) *MockTokenService_HasActiveConversation_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_HasActiveConversation_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *MockTokenService_HasActiveConversation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListTokens provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) ListTokens(ctx context.Context, userID string) (*core.Response, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTokens")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.Response, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.Response); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_ListTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTokens'
+type MockTokenService_ListTokens_Call struct {
+	*mock.Call
+}
+
+// ListTokens is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockTokenService_Expecter) ListTokens(ctx interface{}, userID interface{}) *MockTokenService_ListTokens_Call {
+	return &MockTokenService_ListTokens_Call{Call: _e.mock.On("ListTokens", ctx, userID)}
+}
+
+func (_c *MockTokenService_ListTokens_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_ListTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_ListTokens_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_ListTokens_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_ListTokens_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_ListTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PreviewToken provides a mock function with given fields: ctx, userID, days
+func (_m *MockTokenService) PreviewToken(ctx context.Context, userID string, days int) (*core.Response, error) {
+	ret := _m.Called(ctx, userID, days)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PreviewToken")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*core.Response, error)); ok {
+		return rf(ctx, userID, days)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *core.Response); ok {
+		r0 = rf(ctx, userID, days)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, userID, days)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_PreviewToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PreviewToken'
+type MockTokenService_PreviewToken_Call struct {
+	*mock.Call
+}
+
+// PreviewToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - days int
+func (_e *MockTokenService_Expecter) PreviewToken(ctx interface{}, userID interface{}, days interface{}) *MockTokenService_PreviewToken_Call {
+	return &MockTokenService_PreviewToken_Call{Call: _e.mock.On("PreviewToken", ctx, userID, days)}
+}
+
+func (_c *MockTokenService_PreviewToken_Call) Run(run func(ctx context.Context, userID string, days int)) *MockTokenService_PreviewToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_PreviewToken_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_PreviewToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_PreviewToken_Call) RunAndReturn(run func(context.Context, string, int) (*core.Response, error)) *MockTokenService_PreviewToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenewToken provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) RenewToken(ctx context.Context, userID string) (*core.Response, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenewToken")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.Response, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.Response); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_RenewToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenewToken'
+type MockTokenService_RenewToken_Call struct {
+	*mock.Call
+}
+
+// RenewToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockTokenService_Expecter) RenewToken(ctx interface{}, userID interface{}) *MockTokenService_RenewToken_Call {
+	return &MockTokenService_RenewToken_Call{Call: _e.mock.On("RenewToken", ctx, userID)}
+}
+
+func (_c *MockTokenService_RenewToken_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_RenewToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_RenewToken_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_RenewToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_RenewToken_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_RenewToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetConversation provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) ResetConversation(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetConversation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockTokenService_ResetConversation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetConversation'
+type MockTokenService_ResetConversation_Call struct {
+	*mock.Call
+}
+
+// ResetConversation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockTokenService_Expecter) ResetConversation(ctx interface{}, userID interface{}) *MockTokenService_ResetConversation_Call {
+	return &MockTokenService_ResetConversation_Call{Call: _e.mock.On("ResetConversation", ctx, userID)}
+}
+
+func (_c *MockTokenService_ResetConversation_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_ResetConversation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_ResetConversation_Call) Return(_a0 error) *MockTokenService_ResetConversation_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTokenService_ResetConversation_Call) RunAndReturn(run func(context.Context, string) error) *MockTokenService_ResetConversation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestartExpiredConversation provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) RestartExpiredConversation(ctx context.Context, userID string) (*core.Response, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestartExpiredConversation")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.Response, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.Response); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_RestartExpiredConversation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestartExpiredConversation'
+type MockTokenService_RestartExpiredConversation_Call struct {
+	*mock.Call
+}
+
+// RestartExpiredConversation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockTokenService_Expecter) RestartExpiredConversation(ctx interface{}, userID interface{}) *MockTokenService_RestartExpiredConversation_Call {
+	return &MockTokenService_RestartExpiredConversation_Call{Call: _e.mock.On("RestartExpiredConversation", ctx, userID)}
+}
+
+func (_c *MockTokenService_RestartExpiredConversation_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_RestartExpiredConversation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_RestartExpiredConversation_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_RestartExpiredConversation_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_RestartExpiredConversation_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_RestartExpiredConversation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeToken provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) RevokeToken(ctx context.Context, userID string) (*core.Response, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeToken")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.Response, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.Response); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_RevokeToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeToken'
+type MockTokenService_RevokeToken_Call struct {
+	*mock.Call
+}
+
+// RevokeToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockTokenService_Expecter) RevokeToken(ctx interface{}, userID interface{}) *MockTokenService_RevokeToken_Call {
+	return &MockTokenService_RevokeToken_Call{Call: _e.mock.On("RevokeToken", ctx, userID)}
+}
+
+func (_c *MockTokenService_RevokeToken_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_RevokeToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_RevokeToken_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_RevokeToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_RevokeToken_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_RevokeToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveUserChat provides a mock function with given fields: ctx, userID, chatID
+func (_m *MockTokenService) SaveUserChat(ctx context.Context, userID string, chatID int64) error {
+	ret := _m.Called(ctx, userID, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveUserChat")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) error); ok {
+		r0 = rf(ctx, userID, chatID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockTokenService_SaveUserChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveUserChat'
+type MockTokenService_SaveUserChat_Call struct {
+	*mock.Call
+}
+
+// SaveUserChat is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - chatID int64
+func (_e *MockTokenService_Expecter) SaveUserChat(ctx interface{}, userID interface{}, chatID interface{}) *MockTokenService_SaveUserChat_Call {
+	return &MockTokenService_SaveUserChat_Call{Call: _e.mock.On("SaveUserChat", ctx, userID, chatID)}
+}
+
+func (_c *MockTokenService_SaveUserChat_Call) Run(run func(ctx context.Context, userID string, chatID int64)) *MockTokenService_SaveUserChat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_SaveUserChat_Call) Return(_a0 error) *MockTokenService_SaveUserChat_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTokenService_SaveUserChat_Call) RunAndReturn(run func(context.Context, string, int64) error) *MockTokenService_SaveUserChat_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetDefaultExpiration provides a mock function with given fields: ctx, userID, days
+func (_m *MockTokenService) SetDefaultExpiration(ctx context.Context, userID string, days int) (*core.Response, error) {
+	ret := _m.Called(ctx, userID, days)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDefaultExpiration")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*core.Response, error)); ok {
+		return rf(ctx, userID, days)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *core.Response); ok {
+		r0 = rf(ctx, userID, days)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, userID, days)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_SetDefaultExpiration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetDefaultExpiration'
+type MockTokenService_SetDefaultExpiration_Call struct {
+	*mock.Call
+}
+
+// SetDefaultExpiration is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - days int
+func (_e *MockTokenService_Expecter) SetDefaultExpiration(ctx interface{}, userID interface{}, days interface{}) *MockTokenService_SetDefaultExpiration_Call {
+	return &MockTokenService_SetDefaultExpiration_Call{Call: _e.mock.On("SetDefaultExpiration", ctx, userID, days)}
+}
+
+func (_c *MockTokenService_SetDefaultExpiration_Call) Run(run func(ctx context.Context, userID string, days int)) *MockTokenService_SetDefaultExpiration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_SetDefaultExpiration_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_SetDefaultExpiration_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_SetDefaultExpiration_Call) RunAndReturn(run func(context.Context, string, int) (*core.Response, error)) *MockTokenService_SetDefaultExpiration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetTimezone provides a mock function with given fields: ctx, userID, timezone
+func (_m *MockTokenService) SetTimezone(ctx context.Context, userID string, timezone string) (*core.Response, error) {
+	ret := _m.Called(ctx, userID, timezone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTimezone")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*core.Response, error)); ok {
+		return rf(ctx, userID, timezone)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.Response); ok {
+		r0 = rf(ctx, userID, timezone)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, timezone)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_SetTimezone_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetTimezone'
+type MockTokenService_SetTimezone_Call struct {
+	*mock.Call
+}
+
+// SetTimezone is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - timezone string
+func (_e *MockTokenService_Expecter) SetTimezone(ctx interface{}, userID interface{}, timezone interface{}) *MockTokenService_SetTimezone_Call {
+	return &MockTokenService_SetTimezone_Call{Call: _e.mock.On("SetTimezone", ctx, userID, timezone)}
+}
+
+func (_c *MockTokenService_SetTimezone_Call) Run(run func(ctx context.Context, userID string, timezone string)) *MockTokenService_SetTimezone_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_SetTimezone_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_SetTimezone_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_SetTimezone_Call) RunAndReturn(run func(context.Context, string, string) (*core.Response, error)) *MockTokenService_SetTimezone_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Stats provides a mock function with given fields: ctx
+func (_m *MockTokenService) Stats(ctx context.Context) (*core.TokenStats, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 *core.TokenStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*core.TokenStats, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *core.TokenStats); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.TokenStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_Stats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stats'
+type MockTokenService_Stats_Call struct {
+	*mock.Call
+}
+
+// Stats is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockTokenService_Expecter) Stats(ctx interface{}) *MockTokenService_Stats_Call {
+	return &MockTokenService_Stats_Call{Call: _e.mock.On("Stats", ctx)}
+}
+
+func (_c *MockTokenService_Stats_Call) Run(run func(ctx context.Context)) *MockTokenService_Stats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_Stats_Call) Return(_a0 *core.TokenStats, _a1 error) *MockTokenService_Stats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_Stats_Call) RunAndReturn(run func(context.Context) (*core.TokenStats, error)) *MockTokenService_Stats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SubmitFeedback provides a mock function with given fields: ctx, userID, text
+func (_m *MockTokenService) SubmitFeedback(ctx context.Context, userID string, text string) (*core.Response, error) {
+	ret := _m.Called(ctx, userID, text)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubmitFeedback")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*core.Response, error)); ok {
+		return rf(ctx, userID, text)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.Response); ok {
+		r0 = rf(ctx, userID, text)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, text)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_SubmitFeedback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SubmitFeedback'
+type MockTokenService_SubmitFeedback_Call struct {
+	*mock.Call
+}
+
+// SubmitFeedback is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - text string
+func (_e *MockTokenService_Expecter) SubmitFeedback(ctx interface{}, userID interface{}, text interface{}) *MockTokenService_SubmitFeedback_Call {
+	return &MockTokenService_SubmitFeedback_Call{Call: _e.mock.On("SubmitFeedback", ctx, userID, text)}
+}
+
+func (_c *MockTokenService_SubmitFeedback_Call) Run(run func(ctx context.Context, userID string, text string)) *MockTokenService_SubmitFeedback_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_SubmitFeedback_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_SubmitFeedback_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_SubmitFeedback_Call) RunAndReturn(run func(context.Context, string, string) (*core.Response, error)) *MockTokenService_SubmitFeedback_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WatchExpiredTokens provides a mock function with given fields: ctx
+func (_m *MockTokenService) WatchExpiredTokens(ctx context.Context) (<-chan core.ExpiryNotice, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WatchExpiredTokens")
+	}
+
+	var r0 <-chan core.ExpiryNotice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (<-chan core.ExpiryNotice, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) <-chan core.ExpiryNotice); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan core.ExpiryNotice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_WatchExpiredTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WatchExpiredTokens'
+type MockTokenService_WatchExpiredTokens_Call struct {
+	*mock.Call
+}
+
+// WatchExpiredTokens is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockTokenService_Expecter) WatchExpiredTokens(ctx interface{}) *MockTokenService_WatchExpiredTokens_Call {
+	return &MockTokenService_WatchExpiredTokens_Call{Call: _e.mock.On("WatchExpiredTokens", ctx)}
+}
+
+func (_c *MockTokenService_WatchExpiredTokens_Call) Run(run func(ctx context.Context)) *MockTokenService_WatchExpiredTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_WatchExpiredTokens_Call) Return(_a0 <-chan core.ExpiryNotice, _a1 error) *MockTokenService_WatchExpiredTokens_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_WatchExpiredTokens_Call) RunAndReturn(run func(context.Context) (<-chan core.ExpiryNotice, error)) *MockTokenService_WatchExpiredTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WhoAmI provides a mock function with given fields: ctx, userID
+func (_m *MockTokenService) WhoAmI(ctx context.Context, userID string) (*core.Response, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WhoAmI")
+	}
+
+	var r0 *core.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.Response, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.Response); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTokenService_WhoAmI_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WhoAmI'
+type MockTokenService_WhoAmI_Call struct {
+	*mock.Call
+}
+
+// WhoAmI is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockTokenService_Expecter) WhoAmI(ctx interface{}, userID interface{}) *MockTokenService_WhoAmI_Call {
+	return &MockTokenService_WhoAmI_Call{Call: _e.mock.On("WhoAmI", ctx, userID)}
+}
+
+func (_c *MockTokenService_WhoAmI_Call) Run(run func(ctx context.Context, userID string)) *MockTokenService_WhoAmI_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTokenService_WhoAmI_Call) Return(_a0 *core.Response, _a1 error) *MockTokenService_WhoAmI_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTokenService_WhoAmI_Call) RunAndReturn(run func(context.Context, string) (*core.Response, error)) *MockTokenService_WhoAmI_Call {
 	_c.Call.Return(run)
 	return _c
 }
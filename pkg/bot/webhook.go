@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	webhookShutdownTimeout = 5 * time.Second
+	webhookUpdatesBuffer   = 100
+	webhookMaxBodySize     = 1 << 20
+
+	// webhookSecretHeader carries the secret token registered with setWebhook in every update Telegram delivers.
+	webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+)
+
+// runWebhook registers the webhook with Telegram and serves incoming updates over HTTP until ctx is cancelled.
+// On shutdown the HTTP server is stopped and the webhook is deleted so the bot can switch back to polling.
+func (s *Service) runWebhook(ctx context.Context) error {
+	u, err := url.Parse(s.webhookURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse webhook url: %w", err)
+	}
+
+	// tgbotapi.WebhookConfig has no secret_token field, so the setWebhook parameters are sent as is.
+	params := tgbotapi.Params{"url": s.webhookURL}
+	params.AddNonEmpty("secret_token", s.webhookSecret)
+
+	if _, err := s.tg.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates := make(chan tgbotapi.Update, webhookUpdatesBuffer)
+
+	srv := &http.Server{
+		Addr:              s.webhookListen,
+		Handler:           webhookHandler(ctx, u.Path, s.webhookSecret, updates),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	srvErr := make(chan error, 1)
+
+	go func() {
+		defer close(srvErr)
+
+		slog.InfoContext(ctx, "Starting webhook server", slog.String("addr", s.webhookListen))
+
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			srvErr <- fmt.Errorf("failed to run webhook server: %w", err)
+
+			cancel()
+		}
+	}()
+
+	stop := func() {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+		defer cancelShutdown()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Failed to shutdown webhook server", slog.Any("error", err))
+		}
+
+		if _, err := s.tg.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			slog.Error("Failed to delete webhook", slog.Any("error", err))
+		}
+	}
+
+	err = s.serve(ctx, updates, stop)
+
+	return errors.Join(err, <-srvErr)
+}
+
+// webhookHandler returns an HTTP handler that decodes Telegram updates posted to path and forwards them to updates.
+// When secret is set, requests that don't carry it in the secret token header are rejected, so only Telegram
+// can inject updates.
+func webhookHandler(ctx context.Context, path, secret string, updates chan<- tgbotapi.Update) http.Handler {
+	if path == "" {
+		path = "/"
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(webhookSecretHeader)), []byte(secret)) != 1 {
+			slog.WarnContext(r.Context(), "Rejected webhook request with invalid secret token",
+				slog.String("remote_addr", r.RemoteAddr),
+			)
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, webhookMaxBodySize)
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			slog.WarnContext(r.Context(), "Failed to decode webhook update", slog.Any("error", err))
+
+			if maxErr := new(http.MaxBytesError); errors.As(err, &maxErr) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		select {
+		case updates <- update:
+			w.WriteHeader(http.StatusOK)
+		case <-ctx.Done():
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case <-r.Context().Done():
+		}
+	})
+}
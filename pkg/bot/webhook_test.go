@@ -0,0 +1,190 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/bot/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		secret     string
+		body       string
+		wantStatus int
+		wantUpdate bool
+	}{
+		{
+			name:       "valid update",
+			method:     http.MethodPost,
+			path:       "/tg/hook",
+			secret:     "s3cret",
+			body:       `{"update_id": 42, "message": {"message_id": 1, "text": "hi", "chat": {"id": 123}}}`,
+			wantStatus: http.StatusOK,
+			wantUpdate: true,
+		},
+		{
+			name:       "missing secret",
+			method:     http.MethodPost,
+			path:       "/tg/hook",
+			body:       `{"update_id": 42, "message": {"message_id": 1, "text": "hi", "chat": {"id": 123}}}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong secret",
+			method:     http.MethodPost,
+			path:       "/tg/hook",
+			secret:     "guess",
+			body:       `{"update_id": 42, "message": {"message_id": 1, "text": "hi", "chat": {"id": 123}}}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "body too large",
+			method:     http.MethodPost,
+			path:       "/tg/hook",
+			secret:     "s3cret",
+			body:       `{"update_id": 42, "message": {"message_id": 1, "text": "` + strings.Repeat("a", webhookMaxBodySize) + `"}}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "wrong path",
+			method:     http.MethodPost,
+			path:       "/other",
+			body:       `{"update_id": 42}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			path:       "/tg/hook",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "invalid payload",
+			method:     http.MethodPost,
+			path:       "/tg/hook",
+			secret:     "s3cret",
+			body:       `not json`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := make(chan tgbotapi.Update, 1)
+			h := webhookHandler(context.Background(), "/tg/hook", "s3cret", updates)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.secret != "" {
+				req.Header.Set(webhookSecretHeader, tt.secret)
+			}
+
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if !tt.wantUpdate {
+				assert.Empty(t, updates)
+				return
+			}
+
+			require.Len(t, updates, 1)
+
+			update := <-updates
+			assert.Equal(t, 42, update.UpdateID)
+			require.NotNil(t, update.Message)
+			assert.Equal(t, "hi", update.Message.Text)
+		})
+	}
+}
+
+func TestRunWebhook(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	mockTg := NewMocktgClient(t)
+
+	svc := &Service{
+		tg:            mockTg,
+		mode:          ModeWebhook,
+		webhookURL:    "https://bot.example.com/tg/hook",
+		webhookListen: addr,
+		webhookSecret: "s3cret",
+		handler: middleware.SingleHandlerFunc(func(_ context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+			return tgbotapi.NewMessage(msg.Chat.ID, "pong"), nil
+		}),
+	}
+
+	mockTg.EXPECT().Request(mock.AnythingOfType("tgbotapi.SetMyCommandsConfig")).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+	mockTg.EXPECT().MakeRequest("setWebhook", tgbotapi.Params{
+		"url":          "https://bot.example.com/tg/hook",
+		"secret_token": "s3cret",
+	}).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+
+	sent := make(chan struct{})
+	mockTg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == 123 && msg.Text == "pong"
+	})).Run(func(tgbotapi.Chattable) { close(sent) }).Return(tgbotapi.Message{}, nil).Once()
+
+	mockTg.EXPECT().Request(tgbotapi.DeleteWebhookConfig{}).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- svc.Run(ctx) }()
+
+	body := []byte(`{"update_id": 1, "message": {"message_id": 1, "text": "ping", "chat": {"id": 123}}}`)
+
+	require.Eventually(t, func() bool {
+		req, err := http.NewRequest(http.MethodPost, "http://"+addr+"/tg/hook", bytes.NewReader(body))
+		if err != nil {
+			return false
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(webhookSecretHeader, "s3cret")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false
+		}
+
+		_ = resp.Body.Close()
+
+		return resp.StatusCode == http.StatusOK
+	}, 2*time.Second, 10*time.Millisecond)
+
+	select {
+	case <-sent:
+	case <-time.After(2 * time.Second):
+		t.Fatal("update was not processed")
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook mode did not shut down")
+	}
+}
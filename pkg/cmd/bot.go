@@ -2,35 +2,101 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ksysoev/make-it-public-tgbot/pkg/bot"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/prov"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/repo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 // runBot is the entry point to initialize and run the bot application with the provided context and arguments.
 // It configures logging, loads the configuration, initializes dependencies, and starts the bot runtime loop.
 // Returns an error if any initialization or runtime operation fails.
 func runBot(ctx context.Context, arg *args) error {
+	cfg, err := initConfig(arg)
+	if err != nil {
+		return err
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	tokeSvc, _, err := newTokenService(ctx, cfg, prov.WithMetrics(prov.NewPrometheusMetrics(reg)))
+	if err != nil {
+		return err
+	}
+
+	b, err := bot.New(&cfg.Bot, tokeSvc, reg, arg.version)
+	if err != nil {
+		return fmt.Errorf("failed to create bot: %w", err)
+	}
+
+	if cfg.Repo.ExpiryNotifications {
+		go b.NotifyExpiredTokens(ctx)
+	}
+
+	if !cfg.Metrics.Enabled {
+		return b.Run(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	metricsErr := make(chan error, 1)
+
+	go func() {
+		// Stop the bot as well if the metrics server can't run.
+		defer cancel()
+
+		metricsErr <- runMetricsServer(ctx, cfg.Metrics.Listen, reg)
+	}()
+
+	err = b.Run(ctx)
+
+	cancel()
+
+	return errors.Join(err, <-metricsErr)
+}
+
+// initConfig configures logging and loads and validates the configuration.
+func initConfig(arg *args) (*appConfig, error) {
 	if err := initLogger(arg); err != nil {
-		return fmt.Errorf("failed to init logger: %w", err)
+		return nil, fmt.Errorf("failed to init logger: %w", err)
 	}
 
 	cfg, err := loadConfig(arg)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	userRepo := repo.New(cfg.Repo)
-	MITProv := prov.New(cfg.MIT)
-	tokeSvc := core.New(userRepo, MITProv)
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
 
-	b, err := bot.New(&cfg.Bot, tokeSvc)
+	return cfg, nil
+}
+
+// newTokenService connects to Redis and builds the token service on top of it and the MIT provider.
+// The repository is returned as well, so that short-lived commands can close it when done.
+func newTokenService(ctx context.Context, cfg *appConfig, provOpts ...prov.Option) (*core.Service, *repo.User, error) {
+	userRepo, err := repo.Connect(ctx, cfg.Repo)
 	if err != nil {
-		return fmt.Errorf("failed to create bot: %w", err)
+		return nil, nil, err
+	}
+
+	MITProv := prov.New(cfg.MIT, provOpts...)
+
+	svc, err := core.New(cfg.Tokens, userRepo, MITProv)
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("failed to create token service: %w", err), userRepo.Close())
 	}
 
-	return b.Run(ctx)
+	return svc, userRepo, nil
 }
@@ -1,20 +1,29 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 
 	"github.com/ksysoev/make-it-public-tgbot/pkg/bot"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/prov"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/repo"
 	"github.com/spf13/viper"
 )
 
+// defaultProfile is the provider profile applied when none is selected.
+const defaultProfile = "default"
+
 type appConfig struct {
 	Repo repo.Config `mapstructure:"repo"`
 	Bot  bot.Config  `mapstructure:"bot"`
-	MIT  prov.Config `mapstructure:"mit"`
+	// Profile selects one of the provider profiles, see applyProfile.
+	Profile string        `mapstructure:"profile"`
+	MIT     prov.Config   `mapstructure:"mit"`
+	Metrics metricsConfig `mapstructure:"metrics"`
+	Tokens  core.Config   `mapstructure:"tokens"`
 }
 
 // loadConfig loads the application configuration using the provided arguments and environment variables.
@@ -39,7 +48,93 @@ func loadConfig(arg *args) (*appConfig, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if arg.Profile != "" {
+		cfg.Profile = arg.Profile
+	}
+
+	if err := applyProfile(v, &cfg); err != nil {
+		return nil, err
+	}
+
 	slog.Debug("Config loaded", slog.Any("config", cfg))
 
 	return &cfg, nil
 }
+
+// applyProfile overlays the selected provider profile, profiles.<name> in the config file, onto the mit section,
+// so that settings shared by every environment stay in mit and a profile only holds what differs, e.g. its URL
+// and API key. A profile setting wins over the same mit setting from the file or the environment.
+// The default profile is optional, any other selected profile must exist.
+func applyProfile(v *viper.Viper, cfg *appConfig) error {
+	name := cfg.Profile
+	if name == "" {
+		name = defaultProfile
+	}
+
+	profile := v.Sub("profiles." + name)
+	if profile == nil {
+		if name != defaultProfile {
+			return fmt.Errorf("provider profile %q is not defined", name)
+		}
+
+		return nil
+	}
+
+	if err := profile.Unmarshal(&cfg.MIT); err != nil {
+		return fmt.Errorf("failed to unmarshal provider profile %q: %w", name, err)
+	}
+
+	cfg.Profile = name
+
+	slog.Info("Provider profile applied", slog.String("profile", name))
+
+	return nil
+}
+
+// Validate checks every config section and returns all problems found joined into a single error.
+// Each problem is prefixed with the section it belongs to, e.g. "mit: url is required".
+func (c *appConfig) Validate() error {
+	sections := []struct {
+		err  error
+		name string
+	}{
+		{name: "bot", err: c.Bot.Validate()},
+		{name: "mit", err: c.MIT.Validate()},
+		{name: "repo", err: c.Repo.Validate()},
+		{name: "tokens", err: c.Tokens.Validate()},
+		{name: "mit", err: c.validateTimeouts()},
+	}
+
+	var errs []error
+
+	for _, sec := range sections {
+		if sec.err == nil {
+			continue
+		}
+
+		// Sub-configs join their problems, unwrap them so that every one gets its own prefixed line.
+		if joined, ok := sec.err.(interface{ Unwrap() []error }); ok {
+			for _, err := range joined.Unwrap() {
+				errs = append(errs, fmt.Errorf("%s: %w", sec.name, err))
+			}
+
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", sec.name, sec.err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateTimeouts checks that a provider HTTP request fits into the bot request timeout,
+// so that a slow provider call fails with an error the user sees instead of being cut short with the whole update.
+func (c *appConfig) validateTimeouts() error {
+	httpTimeout, requestTimeout := c.MIT.EffectiveTimeout(), c.Bot.EffectiveRequestTimeout()
+
+	if httpTimeout >= requestTimeout {
+		return fmt.Errorf("timeout %s must be less than bot request_timeout %s", httpTimeout, requestTimeout)
+	}
+
+	return nil
+}
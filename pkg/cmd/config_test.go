@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/bot"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/prov"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_BotToken(t *testing.T) {
+	tests := []struct {
+		env       map[string]string
+		name      string
+		fileYAML  string
+		wantToken string
+	}{
+		{
+			name:      "from config file",
+			fileYAML:  "bot:\n  token: \"file-token\"\n",
+			wantToken: "file-token",
+		},
+		{
+			name:      "from environment",
+			env:       map[string]string{"BOT_TOKEN": "env-token"},
+			wantToken: "env-token",
+		},
+		{
+			name:      "environment overrides config file",
+			fileYAML:  "bot:\n  token: \"file-token\"\n",
+			env:       map[string]string{"BOT_TOKEN": "env-token"},
+			wantToken: "env-token",
+		},
+		{
+			name:     "telegram_token key is not bound",
+			fileYAML: "bot:\n  telegram_token: \"ignored\"\n",
+			env:      map[string]string{"BOT_TELEGRAM_TOKEN": "ignored"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Empty variables are treated as unset, so a token from the outer environment can't leak in.
+			t.Setenv("BOT_TOKEN", "")
+
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			arg := &args{}
+
+			if tt.fileYAML != "" {
+				arg.ConfigPath = filepath.Join(t.TempDir(), "config.yml")
+				require.NoError(t, os.WriteFile(arg.ConfigPath, []byte(tt.fileYAML), 0o600))
+			}
+
+			cfg, err := loadConfig(arg)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantToken, cfg.Bot.TelegramToken)
+		})
+	}
+}
+
+func TestLoadConfig_MissingFile(t *testing.T) {
+	_, err := loadConfig(&args{ConfigPath: filepath.Join(t.TempDir(), "missing.yml")})
+
+	assert.ErrorContains(t, err, "failed to read config")
+}
+
+func TestAppConfig_Validate(t *testing.T) {
+	valid := appConfig{
+		Bot:  bot.Config{TelegramToken: "test-token"},
+		MIT:  prov.Config{Url: "http://localhost:8082", DefaultTTL: 604800},
+		Repo: repo.Config{RedisAddr: "localhost:6379"},
+	}
+
+	assert.NoError(t, valid.Validate())
+
+	err := (&appConfig{MIT: prov.Config{Url: "localhost"}}).Validate()
+	require.Error(t, err)
+
+	assert.Equal(t, strings.Join([]string{
+		"bot: token is required",
+		`mit: url "localhost" must be an absolute http or https URL`,
+		"mit: default_ttl must be positive, got 0",
+		"repo: redis_addr is required",
+	}, "\n"), err.Error())
+}
+
+func TestAppConfig_ValidateTimeouts(t *testing.T) {
+	cfg := appConfig{
+		Bot:  bot.Config{TelegramToken: "test-token", RequestTimeout: 3 * time.Second},
+		MIT:  prov.Config{Url: "http://localhost:8082", DefaultTTL: 604800},
+		Repo: repo.Config{RedisAddr: "localhost:6379"},
+	}
+
+	// The default provider timeout doesn't fit into a 3s request.
+	assert.EqualError(t, cfg.Validate(), "mit: timeout 5s must be less than bot request_timeout 3s")
+
+	cfg.MIT.Timeout = 2 * time.Second
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestAppConfig_ValidateMessageTemplates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(`{{define "token_created"}}{{.Secret}}{{end}}`), 0o600))
+
+	cfg := appConfig{
+		Bot:    bot.Config{TelegramToken: "test-token"},
+		MIT:    prov.Config{Url: "http://localhost:8082", DefaultTTL: 604800},
+		Repo:   repo.Config{RedisAddr: "localhost:6379"},
+		Tokens: core.Config{MessageTemplates: path},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), `tokens: message_templates is invalid: failed to render message template "token_created"`), err.Error())
+
+	cfg.Tokens.MessageTemplates = ""
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoadConfig_AdminIDs(t *testing.T) {
+	t.Setenv("BOT_ADMIN_IDS", "42,4242")
+
+	cfg, err := loadConfig(&args{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{42, 4242}, cfg.Bot.AdminIDs)
+}
+
+func TestLoadConfig_ExpirationPresets(t *testing.T) {
+	t.Setenv("TOKENS_EXPIRATION_PRESETS", "1 hour,12 hours,365 days")
+
+	cfg, err := loadConfig(&args{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"1 hour", "12 hours", "365 days"}, cfg.Tokens.ExpirationPresets)
+}
+
+func TestLoadConfig_MaxRetries(t *testing.T) {
+	cfg, err := loadConfig(&args{})
+	require.NoError(t, err)
+
+	assert.Nil(t, cfg.MIT.MaxRetries, "unset max_retries must stay unset to get the default")
+
+	t.Setenv("MIT_MAX_RETRIES", "0")
+
+	cfg, err = loadConfig(&args{})
+	require.NoError(t, err)
+
+	require.NotNil(t, cfg.MIT.MaxRetries)
+	assert.Equal(t, 0, *cfg.MIT.MaxRetries)
+}
+
+func TestLoadConfig_Profile(t *testing.T) {
+	const fileYAML = `
+mit:
+  url: "http://localhost:8082"
+  default_ttl: 604800
+  api_key: "shared-key"
+profiles:
+  default:
+    url: "http://mit.example.com"
+  staging:
+    url: "http://staging.mit.example.com"
+    api_key: "staging-key"
+`
+
+	tests := []struct {
+		env        map[string]string
+		name       string
+		flag       string
+		fileYAML   string
+		wantURL    string
+		wantAPIKey string
+		wantErr    string
+	}{
+		{
+			name:       "default profile when none is selected",
+			fileYAML:   fileYAML,
+			wantURL:    "http://mit.example.com",
+			wantAPIKey: "shared-key",
+		},
+		{
+			name:       "selected by flag",
+			fileYAML:   fileYAML,
+			flag:       "staging",
+			wantURL:    "http://staging.mit.example.com",
+			wantAPIKey: "staging-key",
+		},
+		{
+			name:       "selected by environment",
+			fileYAML:   fileYAML,
+			env:        map[string]string{"PROFILE": "staging"},
+			wantURL:    "http://staging.mit.example.com",
+			wantAPIKey: "staging-key",
+		},
+		{
+			name:     "flag overrides environment",
+			fileYAML: fileYAML,
+			flag:     "default",
+			env:      map[string]string{"PROFILE": "staging"},
+			wantURL:  "http://mit.example.com",
+			// The shared key comes from the mit section, which the default profile doesn't override.
+			wantAPIKey: "shared-key",
+		},
+		{
+			name:     "without profiles",
+			fileYAML: "mit:\n  url: \"http://localhost:8082\"\n",
+			wantURL:  "http://localhost:8082",
+		},
+		{
+			name:     "unknown profile",
+			fileYAML: fileYAML,
+			flag:     "production",
+			wantErr:  `provider profile "production" is not defined`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PROFILE", "")
+
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			arg := &args{
+				ConfigPath: filepath.Join(t.TempDir(), "config.yml"),
+				Profile:    tt.flag,
+			}
+			require.NoError(t, os.WriteFile(arg.ConfigPath, []byte(tt.fileYAML), 0o600))
+
+			cfg, err := loadConfig(arg)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantURL, cfg.MIT.Url)
+			assert.Equal(t, tt.wantAPIKey, cfg.MIT.APIKey)
+		})
+	}
+}
@@ -8,6 +8,7 @@ type args struct {
 	version    string
 	LogLevel   string
 	ConfigPath string
+	Profile    string
 	TextFormat bool
 }
 
@@ -23,9 +24,10 @@ func InitCommands(version string) *cobra.Command {
 		Long:  "Make It Public Telegram tg is a bot for managing your accounts and tokens.",
 	}
 
-	cmd.AddCommand(initRunCommand(arg))
+	cmd.AddCommand(initRunCommand(arg), initRevokeCommand(arg), initListCommand(arg), initValidateConfigCommand(arg))
 
 	cmd.PersistentFlags().StringVar(&arg.ConfigPath, "config", "", "config file path")
+	cmd.PersistentFlags().StringVar(&arg.Profile, "profile", "", `provider profile to use, overrides PROFILE (default "default")`)
 	cmd.PersistentFlags().StringVar(&arg.LogLevel, "loglevel", "info", "log level (debug, info, warn, error)")
 	cmd.PersistentFlags().BoolVar(&arg.TextFormat, "logtext", false, "log in text format, otherwise JSON")
 
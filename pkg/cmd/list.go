@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+type listArgs struct {
+	UserID string
+	JSON   bool
+}
+
+func initListCommand(arg *args) *cobra.Command {
+	listArg := &listArgs{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a user's tokens",
+		Long:  "List the active tokens of a user as the bot sees them, after reconciling them with the provider.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := listArg.Validate(); err != nil {
+				return err
+			}
+
+			// Flags are valid at this point, failures are not caused by the usage.
+			cmd.SilenceUsage = true
+
+			return runList(cmd.Context(), arg, listArg, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&listArg.UserID, "user", "", "Telegram user ID to list the tokens of")
+	cmd.Flags().BoolVar(&listArg.JSON, "json", false, "print the tokens as JSON instead of a table")
+
+	_ = cmd.MarkFlagRequired("user")
+
+	return cmd
+}
+
+// Validate checks that the user is given.
+func (a *listArgs) Validate() error {
+	if strings.TrimSpace(a.UserID) == "" {
+		return errors.New("--user must not be empty")
+	}
+
+	return nil
+}
+
+// runList prints the active tokens of the user to out. A user without tokens is not an error,
+// it is reported with a message, or an empty list in JSON.
+func runList(ctx context.Context, arg *args, listArg *listArgs, out io.Writer) error {
+	cfg, err := initConfig(arg)
+	if err != nil {
+		return err
+	}
+
+	tokenSvc, userRepo, err := newTokenService(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = userRepo.Close() }()
+
+	tokens, err := tokenSvc.ExportTokens(ctx, listArg.UserID)
+
+	switch {
+	case errors.Is(err, core.ErrTokenNotFound):
+		tokens = []core.TokenExport{}
+	case err != nil:
+		return fmt.Errorf("failed to list tokens of user %s: %w", listArg.UserID, err)
+	}
+
+	if listArg.JSON {
+		return printTokensJSON(out, tokens)
+	}
+
+	if len(tokens) == 0 {
+		_, _ = fmt.Fprintf(out, "User %s has no active tokens\n", listArg.UserID)
+		return nil
+	}
+
+	return printTokensTable(out, tokens)
+}
+
+// printTokensJSON writes tokens to out as an indented JSON array.
+func printTokensJSON(out io.Writer, tokens []core.TokenExport) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(tokens); err != nil {
+		return fmt.Errorf("failed to encode tokens: %w", err)
+	}
+
+	return nil
+}
+
+// printTokensTable writes tokens to out as an aligned table with expiry times in UTC.
+// A missing label is shown as a dash to keep the columns readable.
+func printTokensTable(out io.Writer, tokens []core.TokenExport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(w, "KEY ID\tTYPE\tLABEL\tEXPIRES AT")
+
+	for _, t := range tokens {
+		label := t.Label
+		if label == "" {
+			label = "-"
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.KeyID, t.Type, label, t.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to print tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// executeList runs "mitbot list" with the given flags and returns its output.
+func executeList(t *testing.T, flags ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+
+	root := InitCommands("test")
+	root.SetArgs(append([]string{"list", "--logtext"}, flags...))
+	root.SetOut(&out)
+	root.SetErr(io.Discard)
+
+	err := root.ExecuteContext(context.Background())
+
+	return out.String(), err
+}
+
+func TestListCommand_Flags(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr string
+		flags   []string
+	}{
+		{name: "missing user", flags: []string{"--json"}, wantErr: `required flag(s) "user" not set`},
+		{name: "empty user", flags: []string{"--user", " "}, wantErr: "--user must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeList(t, tt.flags...)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestListCommand(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	mit := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyID := strings.TrimPrefix(r.URL.Path, "/token/")
+		_, _ = w.Write([]byte(`{"key_id":"` + keyID + `","type":"web","status":"active","ttl":3600}`))
+	}))
+	defer mit.Close()
+
+	t.Setenv("BOT_TOKEN", "test-token")
+	t.Setenv("MIT_URL", mit.URL)
+	t.Setenv("MIT_DEFAULT_TTL", "3600")
+	t.Setenv("REPO_REDIS_ADDR", mr.Addr())
+
+	userRepo := repo.New(repo.Config{RedisAddr: mr.Addr()})
+	defer func() { _ = userRepo.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, userRepo.AddAPIKey(ctx, "42", "key123", core.TokenTypeWeb, "home", time.Hour))
+
+	t.Run("table", func(t *testing.T) {
+		out, err := executeList(t, "--user", "42")
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, []string{"KEY", "ID", "TYPE", "LABEL", "EXPIRES", "AT"}, strings.Fields(lines[0]))
+
+		fields := strings.Fields(lines[1])
+		require.Len(t, fields, 4)
+		assert.Equal(t, []string{"key123", "web", "home"}, fields[:3])
+
+		expiresAt, err := time.Parse(time.RFC3339, fields[3])
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := executeList(t, "--user", "42", "--json")
+		require.NoError(t, err)
+
+		var tokens []core.TokenExport
+
+		require.NoError(t, json.Unmarshal([]byte(out), &tokens))
+		require.Len(t, tokens, 1)
+		assert.Equal(t, "key123", tokens[0].KeyID)
+		assert.Equal(t, core.TokenTypeWeb, tokens[0].Type)
+		assert.Equal(t, "home", tokens[0].Label)
+	})
+
+	t.Run("no tokens", func(t *testing.T) {
+		out, err := executeList(t, "--user", "7")
+		require.NoError(t, err)
+		assert.Equal(t, "User 7 has no active tokens\n", out)
+	})
+
+	t.Run("no tokens json", func(t *testing.T) {
+		out, err := executeList(t, "--user", "7", "--json")
+		require.NoError(t, err)
+		assert.JSONEq(t, "[]", out)
+	})
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	defaultMetricsListen   = ":9090"
+	metricsShutdownTimeout = 5 * time.Second
+)
+
+type metricsConfig struct {
+	Listen  string `mapstructure:"listen"`
+	Enabled bool   `mapstructure:"enabled"`
+}
+
+// runMetricsServer serves the given registry on /metrics at addr until ctx is cancelled, then shuts the server down.
+// Returns an error if the server fails to start or to shut down cleanly.
+func runMetricsServer(ctx context.Context, addr string, reg *prometheus.Registry) error {
+	if addr == "" {
+		addr = defaultMetricsListen
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}))
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		slog.InfoContext(ctx, "Starting metrics server", slog.String("addr", addr))
+
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("failed to run metrics server: %w", err)
+		}
+
+		return nil
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shutdown metrics server: %w", err)
+	}
+
+	return nil
+}
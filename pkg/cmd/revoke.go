@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+type revokeArgs struct {
+	UserID string
+	KeyID  string
+}
+
+func initRevokeCommand(arg *args) *cobra.Command {
+	revArg := &revokeArgs{}
+
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke a user's token",
+		Long:  "Revoke the token with the given key ID on behalf of a user, without going through Telegram.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := revArg.Validate(); err != nil {
+				return err
+			}
+
+			// Flags are valid at this point, failures are not caused by the usage.
+			cmd.SilenceUsage = true
+
+			return runRevoke(cmd.Context(), arg, revArg, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&revArg.UserID, "user", "", "Telegram user ID the token belongs to")
+	cmd.Flags().StringVar(&revArg.KeyID, "key", "", "key ID of the token to revoke")
+
+	_ = cmd.MarkFlagRequired("user")
+	_ = cmd.MarkFlagRequired("key")
+
+	return cmd
+}
+
+// Validate checks that both the user and the key ID are given.
+func (a *revokeArgs) Validate() error {
+	var errs []error
+
+	if strings.TrimSpace(a.UserID) == "" {
+		errs = append(errs, errors.New("--user must not be empty"))
+	}
+
+	if strings.TrimSpace(a.KeyID) == "" {
+		errs = append(errs, errors.New("--key must not be empty"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// runRevoke revokes the key of the user at the provider and in the repository and reports the result to out.
+// The key must belong to the user, so a mistyped user ID can't revoke someone else's token.
+func runRevoke(ctx context.Context, arg *args, revArg *revokeArgs, out io.Writer) error {
+	cfg, err := initConfig(arg)
+	if err != nil {
+		return err
+	}
+
+	tokenSvc, userRepo, err := newTokenService(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = userRepo.Close() }()
+
+	err = tokenSvc.RevokeTokenByID(ctx, revArg.UserID, revArg.KeyID)
+
+	switch {
+	case errors.Is(err, core.ErrKeyNotFound):
+		return fmt.Errorf("user %s has no token with key ID %s", revArg.UserID, revArg.KeyID)
+	case err != nil:
+		return fmt.Errorf("failed to revoke token %s of user %s: %w", revArg.KeyID, revArg.UserID, err)
+	}
+
+	_, _ = fmt.Fprintf(out, "Revoked token %s of user %s\n", revArg.KeyID, revArg.UserID)
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// executeRevoke runs "mitbot revoke" with the given flags and returns its output.
+func executeRevoke(t *testing.T, flags ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+
+	root := InitCommands("test")
+	root.SetArgs(append([]string{"revoke", "--logtext"}, flags...))
+	root.SetOut(&out)
+	root.SetErr(io.Discard)
+
+	err := root.ExecuteContext(context.Background())
+
+	return out.String(), err
+}
+
+func TestRevokeCommand_Flags(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr string
+		flags   []string
+	}{
+		{name: "missing key", flags: []string{"--user", "42"}, wantErr: `required flag(s) "key" not set`},
+		{name: "missing user", flags: []string{"--key", "key123"}, wantErr: `required flag(s) "user" not set`},
+		{name: "empty user", flags: []string{"--user", " ", "--key", "key123"}, wantErr: "--user must not be empty"},
+		{name: "empty key", flags: []string{"--user", "42", "--key", ""}, wantErr: "--key must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeRevoke(t, tt.flags...)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRevokeCommand(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	var revoked []string
+
+	mit := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		revoked = append(revoked, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer mit.Close()
+
+	t.Setenv("BOT_TOKEN", "test-token")
+	t.Setenv("MIT_URL", mit.URL)
+	t.Setenv("MIT_DEFAULT_TTL", "3600")
+	t.Setenv("REPO_REDIS_ADDR", mr.Addr())
+
+	userRepo := repo.New(repo.Config{RedisAddr: mr.Addr()})
+	defer func() { _ = userRepo.Close() }()
+
+	ctx := context.Background()
+	require.NoError(t, userRepo.AddAPIKey(ctx, "42", "key123", core.TokenTypeWeb, "", time.Hour))
+
+	t.Run("key of another user", func(t *testing.T) {
+		_, err := executeRevoke(t, "--user", "7", "--key", "key123")
+		assert.EqualError(t, err, "user 7 has no token with key ID key123")
+		assert.Empty(t, revoked)
+	})
+
+	t.Run("revokes the key", func(t *testing.T) {
+		out, err := executeRevoke(t, "--user", "42", "--key", "key123")
+		require.NoError(t, err)
+
+		assert.Equal(t, "Revoked token key123 of user 42\n", out)
+		assert.Equal(t, []string{"DELETE /token/key123"}, revoked)
+
+		keys, err := userRepo.GetAPIKeys(ctx, "42")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+}
+
+func TestRevokeCommand_InvalidConfig(t *testing.T) {
+	t.Setenv("BOT_TOKEN", "")
+	t.Setenv("MIT_URL", "")
+
+	_, err := executeRevoke(t, "--user", "42", "--key", "key123")
+	assert.ErrorContains(t, err, "invalid config")
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+func initValidateConfigCommand(arg *args) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate-config",
+		Short: "Validate the configuration",
+		Long:  "Load and validate the configuration without connecting to Telegram, Redis or the provider.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// Flags are valid at this point, failures are not caused by the usage.
+			cmd.SilenceUsage = true
+
+			return runValidateConfig(arg, cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
+
+// runValidateConfig loads the configuration the same way the bot does and checks it statically.
+// It prints "config OK" or every problem found on its own line, in the latter case it returns an error
+// so that the process exits with a non-zero code.
+func runValidateConfig(arg *args, out io.Writer) error {
+	if err := initLogger(arg); err != nil {
+		return fmt.Errorf("failed to init logger: %w", err)
+	}
+
+	cfg, err := loadConfig(arg)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	err = cfg.Validate()
+	if err == nil {
+		_, _ = fmt.Fprintln(out, "config OK")
+		return nil
+	}
+
+	problems := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		problems = joined.Unwrap()
+	}
+
+	_, _ = fmt.Fprintln(out, "config has problems:")
+
+	for _, p := range problems {
+		_, _ = fmt.Fprintf(out, "  - %s\n", p)
+	}
+
+	return fmt.Errorf("invalid config: %d problem(s) found", len(problems))
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfigCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileYAML string
+		wantOut  string
+		wantErr  string
+	}{
+		{
+			name: "valid config",
+			// Nothing listens on these addresses, the command must not try to connect.
+			fileYAML: "bot:\n  token: \"test-token\"\nmit:\n  url: \"http://127.0.0.1:1\"\n  default_ttl: 3600\nrepo:\n  redis_addr: \"127.0.0.1:1\"\n",
+			wantOut:  "config OK\n",
+		},
+		{
+			name:     "invalid config",
+			fileYAML: "mit:\n  url: \"localhost\"\n",
+			wantOut: "config has problems:\n" +
+				"  - bot: token is required\n" +
+				"  - mit: url \"localhost\" must be an absolute http or https URL\n" +
+				"  - mit: default_ttl must be positive, got 0\n" +
+				"  - repo: redis_addr is required\n",
+			wantErr: "invalid config: 4 problem(s) found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Empty variables are treated as unset, so values from the outer environment can't leak in.
+			for _, env := range []string{"BOT_TOKEN", "MIT_URL", "MIT_DEFAULT_TTL", "REPO_REDIS_ADDR"} {
+				t.Setenv(env, "")
+			}
+
+			path := filepath.Join(t.TempDir(), "config.yml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.fileYAML), 0o600))
+
+			var out bytes.Buffer
+
+			root := InitCommands("test")
+			root.SetArgs([]string{"validate-config", "--logtext", "--config", path})
+			root.SetOut(&out)
+			root.SetErr(io.Discard)
+
+			err := root.ExecuteContext(context.Background())
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantOut, out.String())
+		})
+	}
+}
+
+func TestValidateConfigCommand_MissingFile(t *testing.T) {
+	root := InitCommands("test")
+	root.SetArgs([]string{"validate-config", "--logtext", "--config", filepath.Join(t.TempDir(), "missing.yml")})
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+
+	err := root.ExecuteContext(context.Background())
+	assert.ErrorContains(t, err, "failed to load config")
+}
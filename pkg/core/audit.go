@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// AuditAction names a token lifecycle event recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditActionCreate     AuditAction = "create"
+	AuditActionRevoke     AuditAction = "revoke"
+	AuditActionRegenerate AuditAction = "regenerate"
+	AuditActionRenew      AuditAction = "renew"
+)
+
+const (
+	// DefaultAuditLogLimit is the number of audit entries shown when no limit is requested.
+	DefaultAuditLogLimit = 10
+	// RecentActionsLimit is the number of a user's own recent actions shown by default.
+	RecentActionsLimit = 10
+
+	auditLogHeader = "📜 Audit log of user %s (last %d):\n\n"
+	auditLogEntry  = "%s %s %s\n"
+	noAuditLog     = "No audit entries for user %s."
+)
+
+// AuditEntry is a single token lifecycle event of a user.
+type AuditEntry struct {
+	Time   time.Time   `json:"time"`
+	UserID string      `json:"user_id"`
+	Action AuditAction `json:"action"`
+	KeyID  string      `json:"key_id"`
+}
+
+// audit records a token lifecycle event. The audit trail must never block token management,
+// so a failure to write it is logged and otherwise ignored.
+func (s *Service) audit(ctx context.Context, userID string, action AuditAction, keyID string) {
+	entry := AuditEntry{
+		Time:   time.Now().UTC(),
+		UserID: userID,
+		Action: action,
+		KeyID:  keyID,
+	}
+
+	if err := s.repo.AppendAuditLog(ctx, entry); err != nil {
+		slog.ErrorContext(ctx, "Failed to write audit log",
+			slog.String("user_id", userID),
+			slog.String("action", string(action)),
+			slog.String("key_id", keyID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// AuditLog renders the latest limit audit entries of the given user, newest first.
+func (s *Service) AuditLog(ctx context.Context, userID string, limit int) (*Response, error) {
+	if limit <= 0 {
+		limit = DefaultAuditLogLimit
+	}
+
+	entries, err := s.repo.GetAuditLog(ctx, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return &Response{
+			Message: fmt.Sprintf(noAuditLog, userID),
+		}, nil
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, auditLogHeader, userID, len(entries))
+
+	for _, e := range entries {
+		fmt.Fprintf(&sb, auditLogEntry, e.Time.Format(time.DateTime), e.Action, e.KeyID)
+	}
+
+	return &Response{
+		Message: sb.String(),
+	}, nil
+}
+
+// GetRecentActions returns the latest n token lifecycle events of the user, newest first, RecentActionsLimit when n
+// isn't positive. The times are in the user's timezone, so that they can be shown as they are.
+func (s *Service) GetRecentActions(ctx context.Context, userID string, n int) ([]AuditEntry, error) {
+	if n <= 0 {
+		n = RecentActionsLimit
+	}
+
+	entries, err := s.repo.GetAuditLog(ctx, userID, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	loc := s.userLocation(ctx, userID)
+
+	for i := range entries {
+		entries[i].Time = entries[i].Time.In(loc)
+	}
+
+	return entries, nil
+}
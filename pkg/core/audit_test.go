@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// expectAudit expects an audit entry with the given action and key ID to be written for the user.
+func expectAudit(repo *MockUserRepo, userID string, action AuditAction, keyID string) {
+	repo.On("AppendAuditLog", mock.Anything, mock.MatchedBy(func(e AuditEntry) bool {
+		return e.UserID == userID && e.Action == action && e.KeyID == keyID && !e.Time.IsZero()
+	})).Return(nil)
+}
+
+func TestAudit_FailureDoesNotAbortOperation(t *testing.T) {
+	repo := NewMockUserRepo(t)
+	expectUserLock(repo, "user123")
+	prov := NewMockMITProv(t)
+
+	repo.On("GetAPIKeys", mock.Anything, "user123").Return([]string{"key1"}, nil)
+	prov.On("RevokeToken", mock.Anything, "key1").Return(nil)
+	repo.On("RevokeToken", mock.Anything, "user123", "key1").Return(nil)
+	repo.On("AppendAuditLog", mock.Anything, mock.Anything).Return(assert.AnError)
+
+	svc := newTestService(t, Config{}, repo, prov)
+
+	resp, err := svc.RevokeToken(context.Background(), "user123")
+
+	assert.NoError(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestAuditLog(t *testing.T) {
+	at := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		getErr      error
+		name        string
+		wantMessage string
+		entries     []AuditEntry
+		limit       int
+		wantLimit   int
+	}{
+		{
+			name:      "renders entries",
+			limit:     2,
+			wantLimit: 2,
+			entries: []AuditEntry{
+				{Time: at.Add(time.Hour), UserID: "user123", Action: AuditActionRevoke, KeyID: "key1"},
+				{Time: at, UserID: "user123", Action: AuditActionCreate, KeyID: "key1"},
+			},
+			wantMessage: "📜 Audit log of user user123 (last 2):\n\n" +
+				"2026-03-15 11:00:00 revoke key1\n" +
+				"2026-03-15 10:00:00 create key1\n",
+		},
+		{
+			name:        "default limit and no entries",
+			wantLimit:   DefaultAuditLogLimit,
+			wantMessage: "No audit entries for user user123.",
+		},
+		{
+			name:      "repository error",
+			limit:     5,
+			wantLimit: 5,
+			getErr:    assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			repo.On("GetAuditLog", mock.Anything, "user123", tt.wantLimit).Return(tt.entries, tt.getErr)
+
+			svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+			resp, err := svc.AuditLog(context.Background(), "user123", tt.limit)
+
+			if tt.getErr != nil {
+				assert.ErrorIs(t, err, tt.getErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMessage, resp.Message)
+		})
+	}
+}
+
+func TestGetRecentActions(t *testing.T) {
+	at := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("times in the user's timezone", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("GetAuditLog", mock.Anything, "user123", 3).Return([]AuditEntry{
+			{Time: at, UserID: "user123", Action: AuditActionCreate, KeyID: "key1"},
+		}, nil)
+		repo.On("GetUserSetting", mock.Anything, "user123", SettingTimezone).Return("Europe/Berlin", nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		entries, err := svc.GetRecentActions(context.Background(), "user123", 3)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "2026-03-15 11:00 CET", entries[0].Time.Format("2006-01-02 15:04 MST"))
+		assert.Equal(t, AuditActionCreate, entries[0].Action)
+	})
+
+	t.Run("default limit and no actions", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("GetAuditLog", mock.Anything, "user123", RecentActionsLimit).Return(nil, nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		entries, err := svc.GetRecentActions(context.Background(), "user123", 0)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("GetAuditLog", mock.Anything, "user123", RecentActionsLimit).Return(nil, assert.AnError)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.GetRecentActions(context.Background(), "user123", 0)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+)
+
+const (
+	// BackAnswer is the reserved answer that returns to the previous question, it is offered with every question
+	// that has one to return to.
+	BackAnswer = "⬅️ Back"
+
+	noPreviousQuestionMessage = "There is no previous question to go back to."
+)
+
+// GoBack returns the user's conversation to the previously answered question and asks it again.
+// If there is nothing to go back to, the user is told so and the current question, if any, is repeated.
+func (s *Service) GoBack(ctx context.Context, userID string) (*Response, error) {
+	cnv, err := s.repo.GetConversation(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	from := cnv.State
+
+	err = cnv.Back()
+
+	switch {
+	case errors.Is(err, conv.ErrNoPreviousQuestion):
+		q, err := cnv.Current()
+		if err != nil {
+			return &Response{Message: noPreviousQuestionMessage}, nil
+		}
+
+		resp := newQuestionResponse(cnv, q)
+		resp.Message = noPreviousQuestionMessage + "\n\n" + resp.Message
+
+		return resp, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to go back: %w", err)
+	}
+
+	logTransition(ctx, userID, from, cnv.State)
+
+	q, err := cnv.Current()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current question: %w", err)
+	}
+
+	if err := s.repo.SaveConversation(ctx, cnv); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+
+	return newQuestionResponse(cnv, q), nil
+}
+
+// newQuestionResponse renders a question of an ongoing conversation, adding BackAnswer to the suggested answers
+// when an earlier question can be revisited.
+func newQuestionResponse(cnv *conv.Conversation, q *conv.Question) *Response {
+	answers := q.Answers
+
+	if cnv.Questions.CanGoBack() {
+		answers = append(append([]string{}, q.Answers...), BackAnswer)
+	}
+
+	return &Response{
+		Message: q.Text,
+		Answers: answers,
+	}
+}
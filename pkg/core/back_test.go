@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoBack(t *testing.T) {
+	userID := "user123"
+
+	newConversation := func(t *testing.T, answers ...string) *conv.Conversation {
+		t.Helper()
+
+		c := conv.New(userID)
+		require.NoError(t, c.Start(StateNewToken, conv.NewQuestions([]conv.Question{
+			{Text: expirationQuestion, Answers: []string{"1 day", "7 days"}},
+			{Text: tokenNameQuestion},
+		})))
+
+		for _, a := range answers {
+			_, err := c.Submit(a)
+			require.NoError(t, err)
+		}
+
+		return c
+	}
+
+	t.Run("returns to the previous question", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		c := newConversation(t, "7 days")
+
+		repo.On("GetConversation", mock.Anything, userID).Return(c, nil)
+		repo.On("SaveConversation", mock.Anything, c).Return(nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		resp, err := svc.GoBack(context.Background(), userID)
+		require.NoError(t, err)
+
+		assert.Equal(t, expirationQuestion, resp.Message)
+		assert.Equal(t, []string{"1 day", "7 days"}, resp.Answers)
+		assert.Equal(t, 0, c.Questions.Position)
+	})
+
+	t.Run("first question is repeated", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+
+		repo.On("GetConversation", mock.Anything, userID).Return(newConversation(t), nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		resp, err := svc.GoBack(context.Background(), userID)
+		require.NoError(t, err)
+
+		assert.Equal(t, noPreviousQuestionMessage+"\n\n"+expirationQuestion, resp.Message)
+		assert.Equal(t, []string{"1 day", "7 days"}, resp.Answers)
+	})
+
+	t.Run("no conversation", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+
+		repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		resp, err := svc.GoBack(context.Background(), userID)
+		require.NoError(t, err)
+
+		assert.Equal(t, noPreviousQuestionMessage, resp.Message)
+		assert.Empty(t, resp.Answers)
+	})
+
+	t.Run("get conversation error", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+
+		repo.On("GetConversation", mock.Anything, userID).Return(nil, assert.AnError)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.GoBack(context.Background(), userID)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestNewQuestionResponse_BackAnswer(t *testing.T) {
+	c := conv.New("user123")
+	require.NoError(t, c.Start(StateNewToken, conv.NewQuestions([]conv.Question{
+		{Text: "First?", Answers: []string{"A"}},
+		{Text: "Second?"},
+	})))
+
+	q, err := c.Current()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A"}, newQuestionResponse(c, q).Answers)
+
+	_, err = c.Submit("A")
+	require.NoError(t, err)
+
+	q, err = c.Current()
+	require.NoError(t, err)
+	assert.Equal(t, []string{BackAnswer}, newQuestionResponse(c, q).Answers)
+}
@@ -7,6 +7,8 @@ import (
 
 var (
 	ErrIsNotComplete = errors.New("conversation is not complete")
+	// ErrNotActive is returned by Submit when the conversation isn't waiting for an answer.
+	ErrNotActive = errors.New("conversation is not in questions state")
 )
 
 type State string
@@ -16,22 +18,59 @@ const (
 	StateComplete State = "complete"
 )
 
+// Question is a single step of a conversation.
+// When Answers is empty the question accepts free text: any non-empty answer matching the optional
+// Pattern regular expression is accepted. When AllowCustom is set, Answers are only suggestions and
+// other answers are validated as free text. Validators add further checks to free-text answers, their
+// messages explain to the user why an answer was rejected.
+// Branches are checked in order once the question is answered, the first matching one decides which
+// question comes next. Without a matching branch the next question in the list follows.
+// A MultiSelect question collects several answers, one or more comma-separated options per message,
+// and moves on once DoneAnswer is sent. Its answers are joined with ", " for branching.
+// The ID names a question for branches and for looking its result up with Lookup or AnswersByID.
 type Question struct {
-	Text    string   `json:"text"`
-	Field   string   `json:"field,omitempty"`
-	Answers []string `json:"answers,omitempty"`
+	ID          string      `json:"id,omitempty"`
+	Text        string      `json:"text"`
+	Field       string      `json:"field,omitempty"`
+	Pattern     string      `json:"pattern,omitempty"`
+	Answers     []string    `json:"answers,omitempty"`
+	Validators  []Validator `json:"validators,omitempty"`
+	Branches    []Branch    `json:"branches,omitempty"`
+	AllowCustom bool        `json:"allow_custom,omitempty"`
+	MultiSelect bool        `json:"multi_select,omitempty"`
+}
+
+// Branch routes a questionnaire to a later question, or to its end, depending on a given answer.
+type Branch struct {
+	// QuestionID selects an earlier question whose answer is checked, by default the current one.
+	QuestionID string `json:"question_id,omitempty"`
+	// Answer selects the branch when it equals the checked answer, an empty Answer matches any answer.
+	Answer string `json:"answer,omitempty"`
+	// Next is the ID of a later question to continue with, or EndQuestions to complete the questionnaire.
+	Next string `json:"next"`
 }
 
 type QuestionAnswer struct {
-	Answer   string   `json:"answer"`
-	Field    string   `json:"field,omitempty"`
+	Answer string `json:"answer"`
+	Field  string `json:"field,omitempty"`
+	// Answers holds the options selected for a MultiSelect question, in the order they were selected.
+	Answers  []string `json:"answers,omitempty"`
 	Question Question `json:"question"`
+	// Skipped marks questions jumped over by a branch, they are left out of the results.
+	Skipped bool `json:"skipped,omitempty"`
 }
 
 type Conversation struct {
-	ID        string
-	State     State
+	ID    string
+	State State
+	// Flow is the questions state the conversation was started with, it is kept after completion so that Back can resume it.
+	Flow      State     `json:"Flow,omitempty"`
 	Questions Questions `json:"Questions"`
+	// Session identifies a single run of the conversation, it is set by whoever starts the conversation.
+	Session string `json:"Session,omitempty"`
+	// Step counts the answers submitted and the steps taken back within the run, so that together with Session
+	// it names the question the conversation is waiting for.
+	Step int `json:"Step,omitempty"`
 }
 
 // New creates a new Conversation instance with the given ID and sets its state to StateIdle.
@@ -53,7 +92,9 @@ func (c *Conversation) Start(newState State, questions Questions) error {
 	}
 
 	c.State = newState
+	c.Flow = newState
 	c.Questions = questions
+	c.Step = 0
 
 	return nil
 }
@@ -67,10 +108,15 @@ func (c *Conversation) Current() (*Question, error) {
 	return c.Questions.GetQuestion()
 }
 
+// IsActive reports whether the conversation is waiting for an answer to one of its questions.
+func (c *Conversation) IsActive() bool {
+	return c.State != StateIdle && c.State != StateComplete
+}
+
 // Submit processes the provided answer, advancing the conversation state and tracking completion or errors as appropriate.
 func (c *Conversation) Submit(answer string) (State, error) {
-	if c.State == StateIdle || c.State == StateComplete {
-		return "", fmt.Errorf("conversation is not in questions state, current state: %s", c.State)
+	if !c.IsActive() {
+		return "", fmt.Errorf("%w, current state: %s", ErrNotActive, c.State)
 	}
 
 	done, err := c.Questions.ProcessAnswer(answer)
@@ -78,6 +124,7 @@ func (c *Conversation) Submit(answer string) (State, error) {
 		return "", err
 	}
 
+	c.Step++
 	state := c.State
 
 	if done {
@@ -87,6 +134,32 @@ func (c *Conversation) Submit(answer string) (State, error) {
 	return state, nil
 }
 
+// Back returns the conversation to the previously answered question and clears that answer.
+// A completed conversation whose results were not collected yet is resumed in its questions state.
+// Returns ErrNoPreviousQuestion when there is no question to go back to.
+func (c *Conversation) Back() error {
+	switch c.State {
+	case StateIdle:
+		return ErrNoPreviousQuestion
+	case StateComplete:
+		if c.Flow == "" {
+			return ErrNoPreviousQuestion
+		}
+	}
+
+	if err := c.Questions.Back(); err != nil {
+		return err
+	}
+
+	c.Step++
+
+	if c.State == StateComplete {
+		c.State = c.Flow
+	}
+
+	return nil
+}
+
 // Results retrieves the completed question-answer pairs of a conversation if it is in the complete state, returning an error otherwise.
 func (c *Conversation) Results() ([]QuestionAnswer, error) {
 	if c.State != StateComplete {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
@@ -278,3 +279,127 @@ func TestConversation_Results(t *testing.T) {
 		})
 	}
 }
+
+func newTwoStepConversation(t *testing.T) *Conversation {
+	t.Helper()
+
+	c := New("test-id")
+	err := c.Start("testState", NewQuestions([]Question{
+		{Text: "Type?", Answers: []string{"Web", "TCP"}, Field: "type"},
+		{Text: "Days?", Answers: []string{"1", "7"}},
+	}))
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestConversation_Back(t *testing.T) {
+	t.Run("from the middle", func(t *testing.T) {
+		c := newTwoStepConversation(t)
+
+		_, err := c.Submit("TCP")
+		require.NoError(t, err)
+
+		require.NoError(t, c.Back())
+
+		q, err := c.Current()
+		require.NoError(t, err)
+		assert.Equal(t, "Type?", q.Text)
+		assert.Empty(t, c.Questions.QAPairs[0].Answer)
+		assert.Empty(t, c.Questions.QAPairs[0].Field)
+		assert.False(t, c.Questions.CanGoBack())
+
+		// The question can be answered again.
+		_, err = c.Submit("Web")
+		require.NoError(t, err)
+		assert.Equal(t, "Web", c.Questions.QAPairs[0].Answer)
+	})
+
+	t.Run("from the first question", func(t *testing.T) {
+		c := newTwoStepConversation(t)
+
+		assert.ErrorIs(t, c.Back(), ErrNoPreviousQuestion)
+		assert.Equal(t, State("testState"), c.State)
+		assert.Equal(t, 0, c.Questions.Position)
+	})
+
+	t.Run("from a completed conversation", func(t *testing.T) {
+		c := newTwoStepConversation(t)
+
+		_, err := c.Submit("TCP")
+		require.NoError(t, err)
+		_, err = c.Submit("7")
+		require.NoError(t, err)
+		require.Equal(t, StateComplete, c.State)
+
+		require.NoError(t, c.Back())
+
+		assert.Equal(t, State("testState"), c.State)
+
+		q, err := c.Current()
+		require.NoError(t, err)
+		assert.Equal(t, "Days?", q.Text)
+		assert.Equal(t, "TCP", c.Questions.QAPairs[0].Answer)
+	})
+
+	t.Run("idle conversation", func(t *testing.T) {
+		assert.ErrorIs(t, New("test-id").Back(), ErrNoPreviousQuestion)
+	})
+
+	t.Run("over a skipped question", func(t *testing.T) {
+		c := New("test-id")
+		err := c.Start("testState", NewQuestions([]Question{
+			{ID: "type", Text: "Type?", Answers: []string{"Web", "TCP"}, Branches: []Branch{{Answer: "TCP", Next: "days"}}},
+			{ID: "subdomain", Text: "Subdomain?"},
+			{ID: "days", Text: "Days?"},
+		}))
+		require.NoError(t, err)
+
+		_, err = c.Submit("TCP")
+		require.NoError(t, err)
+		require.True(t, c.Questions.QAPairs[1].Skipped)
+
+		require.NoError(t, c.Back())
+
+		assert.Equal(t, 0, c.Questions.Position)
+		assert.False(t, c.Questions.QAPairs[1].Skipped)
+	})
+}
+
+func TestConversation_IsActive(t *testing.T) {
+	c := New("user123")
+	assert.False(t, c.IsActive())
+
+	require.NoError(t, c.Start("test", NewQuestions([]Question{{Text: "Name?"}})))
+	assert.True(t, c.IsActive())
+
+	_, err := c.Submit("Alice")
+	require.NoError(t, err)
+	assert.False(t, c.IsActive(), "a completed conversation waits for no answer")
+}
+
+func TestConversation_Step(t *testing.T) {
+	c := newTwoStepConversation(t)
+	assert.Zero(t, c.Step)
+
+	// A rejected answer leaves the conversation at the same step.
+	_, err := c.Submit("Carrier pigeon")
+	require.Error(t, err)
+	assert.Zero(t, c.Step)
+
+	_, err = c.Submit("TCP")
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Step)
+
+	// Going back and answering the same question again are new steps, not a repetition of the first answer.
+	require.NoError(t, c.Back())
+	assert.Equal(t, 2, c.Step)
+
+	_, err = c.Submit("Web")
+	require.NoError(t, err)
+	assert.Equal(t, 3, c.Step)
+
+	c.State = StateIdle
+	require.NoError(t, c.Start("otherState", NewQuestions([]Question{{Text: "Name?"}})))
+	assert.Zero(t, c.Step, "a new run starts from the first step")
+}
@@ -2,11 +2,27 @@ package conv
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// EndQuestions is the Branch.Next value that completes the questionnaire.
+	EndQuestions = "end"
+	// DoneAnswer completes the selection of a MultiSelect question.
+	DoneAnswer = "Done"
+	// multiSelectSeparator separates several options sent in one answer to a MultiSelect question.
+	multiSelectSeparator = ","
 )
 
 var (
 	ErrNoMoreQuestions         = errors.New("no more questions")
 	ErrQuestionnaireIncomplete = errors.New("questionnaire is incomplete")
+	ErrInvalidAnswer           = errors.New("invalid answer")
+	ErrInvalidBranch           = errors.New("invalid question branch")
+	ErrNoPreviousQuestion      = errors.New("no previous question")
 )
 
 type Questions struct {
@@ -41,32 +57,256 @@ func (f *Questions) ProcessAnswer(answer string) (bool, error) {
 		return false, ErrNoMoreQuestions
 	}
 
-	answers := f.QAPairs[f.Position].Question.Answers
+	q := f.QAPairs[f.Position].Question
+
+	if q.MultiSelect {
+		return f.processSelection(answer)
+	}
 
-	// Free-text mode: when no answer whitelist is defined, accept any input.
-	if len(answers) == 0 {
-		f.QAPairs[f.Position].Answer = answer
-		f.QAPairs[f.Position].Field = f.QAPairs[f.Position].Question.Field
-		f.Position++
-		return f.Position >= len(f.QAPairs), nil
+	if err := validateAnswer(q, answer); err != nil {
+		return false, err
 	}
 
-	for _, a := range answers {
-		if a == answer {
-			f.QAPairs[f.Position].Answer = answer
-			f.QAPairs[f.Position].Field = f.QAPairs[f.Position].Question.Field
-			f.Position++
-			return f.Position >= len(f.QAPairs), nil
+	return f.accept(answer)
+}
+
+// processSelection adds the options of answer to the selection of the current MultiSelect question.
+// Options already selected are ignored, and nothing is selected if any of the options is invalid.
+// DoneAnswer accepts the selection, it requires at least one selected option.
+func (f *Questions) processSelection(answer string) (bool, error) {
+	qa := &f.QAPairs[f.Position]
+
+	if answer == DoneAnswer {
+		if len(qa.Answers) == 0 {
+			return false, ErrInvalidAnswer
 		}
+
+		return f.accept(strings.Join(qa.Answers, multiSelectSeparator+" "))
+	}
+
+	// An option that contains the separator itself is taken as a whole.
+	options := []string{answer}
+	if !slices.Contains(qa.Question.Answers, answer) {
+		options = strings.Split(answer, multiSelectSeparator)
+	}
+
+	for i, o := range options {
+		options[i] = strings.TrimSpace(o)
+
+		if err := validateAnswer(qa.Question, options[i]); err != nil {
+			return false, err
+		}
+	}
+
+	for _, o := range options {
+		if !slices.Contains(qa.Answers, o) {
+			qa.Answers = append(qa.Answers, o)
+		}
+	}
+
+	return false, nil
+}
+
+// validateAnswer checks answer against the options of q. Free-text questions, and answers other than the
+// options of a question that allows custom answers, are checked with validateFreeText.
+func validateAnswer(q Question, answer string) error {
+	// Free-text mode: when no answer whitelist is defined, accept any non-empty input
+	// that matches the optional pattern.
+	if len(q.Answers) == 0 {
+		return validateFreeText(q, answer)
+	}
+
+	if slices.Contains(q.Answers, answer) {
+		return nil
+	}
+
+	if q.AllowCustom {
+		return validateFreeText(q, answer)
 	}
 
-	return false, errors.New("invalid answer")
+	return ErrInvalidAnswer
 }
 
+// accept records the answer for the current question and advances to the next one, following the
+// question branches. Questions jumped over are marked as skipped.
+// It returns true when the questionnaire is complete.
+func (f *Questions) accept(answer string) (bool, error) {
+	next, err := f.nextPosition(answer)
+	if err != nil {
+		return false, err
+	}
+
+	f.QAPairs[f.Position].Answer = answer
+	f.QAPairs[f.Position].Field = f.QAPairs[f.Position].Question.Field
+
+	for i := f.Position + 1; i < next; i++ {
+		f.QAPairs[i].Skipped = true
+	}
+
+	f.Position = next
+
+	return f.Position >= len(f.QAPairs), nil
+}
+
+// nextPosition returns the position of the question that follows the current one once it is answered with answer.
+// Branches may only jump forward, so a questionnaire can't loop.
+func (f *Questions) nextPosition(answer string) (int, error) {
+	q := f.QAPairs[f.Position].Question
+
+	for _, b := range q.Branches {
+		if !f.branchMatches(b, answer) {
+			continue
+		}
+
+		if b.Next == EndQuestions {
+			return len(f.QAPairs), nil
+		}
+
+		for i := f.Position + 1; i < len(f.QAPairs); i++ {
+			if f.QAPairs[i].Question.ID == b.Next {
+				return i, nil
+			}
+		}
+
+		return 0, fmt.Errorf("%w: no question %q after %q", ErrInvalidBranch, b.Next, q.Text)
+	}
+
+	return f.Position + 1, nil
+}
+
+// branchMatches reports whether b applies given the answer to the current question and the earlier answers.
+// A branch checking a question that was skipped or doesn't exist never matches.
+func (f *Questions) branchMatches(b Branch, answer string) bool {
+	if b.QuestionID != "" && b.QuestionID != f.QAPairs[f.Position].Question.ID {
+		found := false
+
+		for _, qa := range f.QAPairs[:f.Position] {
+			if qa.Question.ID == b.QuestionID && !qa.Skipped {
+				answer = qa.Answer
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return b.Answer == "" || b.Answer == answer
+}
+
+// validateFreeText checks a free-text answer against the question constraints.
+// Blank answers are always rejected; if the question defines a Pattern, the answer must match it.
+// The question validators run last, the first failing one is returned as a *ValidationError.
+func validateFreeText(q Question, answer string) error {
+	if strings.TrimSpace(answer) == "" {
+		return ErrInvalidAnswer
+	}
+
+	if q.Pattern != "" {
+		matched, err := regexp.MatchString(q.Pattern, answer)
+		if err != nil {
+			return fmt.Errorf("invalid answer pattern %q: %w", q.Pattern, err)
+		}
+
+		if !matched {
+			return ErrInvalidAnswer
+		}
+	}
+
+	for _, v := range q.Validators {
+		if err := v.Check(answer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Back returns to the previously answered question and clears its answer, so that it can be answered again.
+// Questions skipped by the branch of that question become reachable again, and options already selected
+// for the current question are dropped.
+// Returns ErrNoPreviousQuestion if no question has been answered yet.
+func (f *Questions) Back() error {
+	prev, ok := f.previousPosition()
+	if !ok {
+		return ErrNoPreviousQuestion
+	}
+
+	for i := prev + 1; i < f.Position && i < len(f.QAPairs); i++ {
+		f.QAPairs[i].Skipped = false
+	}
+
+	if f.Position < len(f.QAPairs) {
+		f.QAPairs[f.Position].Answers = nil
+	}
+
+	f.QAPairs[prev].Answer = ""
+	f.QAPairs[prev].Answers = nil
+	f.QAPairs[prev].Field = ""
+	f.Position = prev
+
+	return nil
+}
+
+// CanGoBack reports whether there is an answered question to return to.
+func (f *Questions) CanGoBack() bool {
+	_, ok := f.previousPosition()
+	return ok
+}
+
+// previousPosition returns the position of the last answered question before the current one.
+func (f *Questions) previousPosition() (int, bool) {
+	for i := min(f.Position, len(f.QAPairs)) - 1; i >= 0; i-- {
+		if !f.QAPairs[i].Skipped {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// GetResults returns the answered question-answer pairs in order, leaving out questions skipped by a branch.
 func (f *Questions) GetResults() ([]QuestionAnswer, error) {
 	if f.Position < len(f.QAPairs) {
 		return nil, ErrQuestionnaireIncomplete
 	}
 
-	return f.QAPairs, nil
+	results := make([]QuestionAnswer, 0, len(f.QAPairs))
+
+	for _, qa := range f.QAPairs {
+		if !qa.Skipped {
+			results = append(results, qa)
+		}
+	}
+
+	return results, nil
+}
+
+// Lookup returns the result of the question with the given ID, so that handlers don't depend on
+// the position of a question in the questionnaire.
+func Lookup(results []QuestionAnswer, id string) (QuestionAnswer, bool) {
+	for _, qa := range results {
+		if qa.Question.ID == id {
+			return qa, true
+		}
+	}
+
+	return QuestionAnswer{}, false
+}
+
+// AnswersByID returns the answers of results keyed by question ID, questions without an ID are left out.
+// The selection of a MultiSelect question is given as its joined answer.
+func AnswersByID(results []QuestionAnswer) map[string]string {
+	answers := make(map[string]string, len(results))
+
+	for _, qa := range results {
+		if qa.Question.ID != "" {
+			answers[qa.Question.ID] = qa.Answer
+		}
+	}
+
+	return answers
 }
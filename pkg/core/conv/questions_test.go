@@ -1,9 +1,12 @@
 package conv
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewQuestions(t *testing.T) {
@@ -199,6 +202,80 @@ func TestQuestions_ProcessAnswer(t *testing.T) {
 			wantAnswer: "my-custom-key",
 			wantField:  "web",
 		},
+		{
+			name: "free-text question - blank answer rejected",
+			qs: Questions{
+				QAPairs: []QuestionAnswer{
+					{Question: Question{Text: "Enter a key ID:"}},
+				},
+				Position: 0,
+			},
+			answer:  "   ",
+			wantErr: true,
+			wantPos: 0,
+		},
+		{
+			name: "free-text question - pattern matched",
+			qs: Questions{
+				QAPairs: []QuestionAnswer{
+					{Question: Question{Text: "How many days?", Pattern: `^\d+$`}},
+				},
+				Position: 0,
+			},
+			answer:     "14",
+			wantDone:   true,
+			wantPos:    1,
+			wantAnswer: "14",
+		},
+		{
+			name: "free-text question - pattern not matched",
+			qs: Questions{
+				QAPairs: []QuestionAnswer{
+					{Question: Question{Text: "How many days?", Pattern: `^\d+$`}},
+				},
+				Position: 0,
+			},
+			answer:  "two weeks",
+			wantErr: true,
+			wantPos: 0,
+		},
+		{
+			name: "free-text question - invalid pattern",
+			qs: Questions{
+				QAPairs: []QuestionAnswer{
+					{Question: Question{Text: "How many days?", Pattern: `(`}},
+				},
+				Position: 0,
+			},
+			answer:  "14",
+			wantErr: true,
+			wantPos: 0,
+		},
+		{
+			name: "custom answer accepted",
+			qs: Questions{
+				QAPairs: []QuestionAnswer{
+					{Question: Question{Text: "How many days?", Answers: []string{"7 days", "30 days"}, Pattern: `^\d+$`, AllowCustom: true}},
+				},
+				Position: 0,
+			},
+			answer:     "14",
+			wantDone:   true,
+			wantPos:    1,
+			wantAnswer: "14",
+		},
+		{
+			name: "custom answer not matching pattern",
+			qs: Questions{
+				QAPairs: []QuestionAnswer{
+					{Question: Question{Text: "How many days?", Answers: []string{"7 days", "30 days"}, Pattern: `^\d+$`, AllowCustom: true}},
+				},
+				Position: 0,
+			},
+			answer:  "forever",
+			wantErr: true,
+			wantPos: 0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -283,3 +360,396 @@ func TestQuestions_GetResults(t *testing.T) {
 		})
 	}
 }
+
+func TestQuestions_FreeTextJSONRoundTrip(t *testing.T) {
+	qs := NewQuestions([]Question{
+		{Text: "How many days?", Field: "ttl", Pattern: `^\d+$`},
+	})
+
+	data, err := json.Marshal(qs)
+	require.NoError(t, err)
+
+	var decoded Questions
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, qs, decoded)
+
+	_, err = decoded.ProcessAnswer("abc")
+	assert.ErrorIs(t, err, ErrInvalidAnswer)
+
+	done, err := decoded.ProcessAnswer("30")
+	require.NoError(t, err)
+	assert.True(t, done)
+}
+
+func newBranchingQuestions() Questions {
+	return NewQuestions([]Question{
+		{
+			ID:      "type",
+			Text:    "What type of token?",
+			Answers: []string{"Web", "TCP"},
+			Branches: []Branch{
+				{Answer: "TCP", Next: "ttl"},
+			},
+		},
+		{ID: "subdomain", Text: "Which subdomain?"},
+		{
+			ID:      "ttl",
+			Text:    "How many days?",
+			Answers: []string{"1", "7", "delete"},
+			Branches: []Branch{
+				{Answer: "delete", Next: "confirm"},
+				{QuestionID: "type", Answer: "TCP", Next: EndQuestions},
+			},
+		},
+		{ID: "name", Text: "Token name?"},
+		{ID: "confirm", Text: "Are you sure?", Answers: []string{"Yes", "No"}},
+	})
+}
+
+func TestQuestions_Branching(t *testing.T) {
+	tests := []struct {
+		name        string
+		answers     []string
+		wantAsked   []string
+		wantAnswers []string
+	}{
+		{
+			name:        "no branch matches, questions follow in order",
+			answers:     []string{"Web", "myapp", "7", "home", "Yes"},
+			wantAsked:   []string{"type", "subdomain", "ttl", "name", "confirm"},
+			wantAnswers: []string{"Web", "myapp", "7", "home", "Yes"},
+		},
+		{
+			name:        "answer branch skips forward and predicate on earlier answer ends early",
+			answers:     []string{"TCP", "1"},
+			wantAsked:   []string{"type", "ttl"},
+			wantAnswers: []string{"TCP", "1"},
+		},
+		{
+			name:        "first matching branch wins",
+			answers:     []string{"TCP", "delete", "No"},
+			wantAsked:   []string{"type", "ttl", "confirm"},
+			wantAnswers: []string{"TCP", "delete", "No"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qs := newBranchingQuestions()
+
+			var asked []string
+
+			for i, answer := range tt.answers {
+				q, err := qs.GetQuestion()
+				require.NoError(t, err)
+
+				asked = append(asked, q.ID)
+
+				done, err := qs.ProcessAnswer(answer)
+				require.NoError(t, err)
+				assert.Equal(t, i == len(tt.answers)-1, done)
+			}
+
+			assert.Equal(t, tt.wantAsked, asked)
+
+			results, err := qs.GetResults()
+			require.NoError(t, err)
+
+			got := make([]string, len(results))
+			for i, r := range results {
+				got[i] = r.Answer
+			}
+
+			assert.Equal(t, tt.wantAnswers, got)
+		})
+	}
+}
+
+func TestQuestions_BranchToUnknownQuestion(t *testing.T) {
+	qs := NewQuestions([]Question{
+		{ID: "second", Text: "Second?"},
+		{ID: "first", Text: "First?", Branches: []Branch{{Next: "second"}}},
+	})
+
+	_, err := qs.ProcessAnswer("a")
+	require.NoError(t, err)
+
+	// Branches can only jump forward.
+	_, err = qs.ProcessAnswer("b")
+	assert.ErrorIs(t, err, ErrInvalidBranch)
+	assert.Equal(t, 1, qs.Position)
+	assert.Empty(t, qs.QAPairs[1].Answer)
+}
+
+func TestQuestions_BranchingJSONRoundTrip(t *testing.T) {
+	qs := newBranchingQuestions()
+
+	_, err := qs.ProcessAnswer("TCP")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(qs)
+	require.NoError(t, err)
+
+	var decoded Questions
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, qs, decoded)
+	assert.True(t, decoded.QAPairs[1].Skipped)
+
+	done, err := decoded.ProcessAnswer("7")
+	require.NoError(t, err)
+	assert.True(t, done)
+}
+
+func TestQuestions_ProcessAnswer_Validators(t *testing.T) {
+	tests := []struct {
+		wantErr     error
+		name        string
+		answer      string
+		wantMessage string
+		question    Question
+	}{
+		{
+			name:   "free text passes validators",
+			answer: "home",
+			question: Question{
+				Text:       "Name?",
+				Validators: []Validator{NotEmpty(""), MaxLength(20, "Name must be 3–20 characters")},
+			},
+		},
+		{
+			name:        "free text fails validator with custom message",
+			answer:      "a very long name that does not fit",
+			question:    Question{Text: "Name?", Validators: []Validator{MaxLength(20, "Name must be 3–20 characters")}},
+			wantErr:     ErrInvalidAnswer,
+			wantMessage: "Name must be 3–20 characters",
+		},
+		{
+			name:        "custom answer fails validator",
+			answer:      "500",
+			question:    Question{Text: "Days?", Answers: []string{"7"}, AllowCustom: true, Validators: []Validator{IntRange(1, 365, "")}},
+			wantErr:     ErrInvalidAnswer,
+			wantMessage: "Enter a whole number from 1 to 365.",
+		},
+		{
+			name:     "fixed answer skips validators",
+			answer:   "7",
+			question: Question{Text: "Days?", Answers: []string{"7"}, AllowCustom: true, Validators: []Validator{IntRange(10, 20, "")}},
+		},
+		{
+			name:     "pattern is checked before validators",
+			answer:   "abc",
+			question: Question{Text: "Days?", Pattern: `^\d+$`, Validators: []Validator{IntRange(1, 365, "")}},
+			wantErr:  ErrInvalidAnswer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qs := NewQuestions([]Question{tt.question})
+
+			done, err := qs.ProcessAnswer(tt.answer)
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				assert.True(t, done)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.False(t, done)
+			assert.Equal(t, 0, qs.Position)
+
+			var vErr *ValidationError
+			if tt.wantMessage == "" {
+				assert.False(t, errors.As(err, &vErr))
+				return
+			}
+
+			require.ErrorAs(t, err, &vErr)
+			assert.Equal(t, tt.wantMessage, vErr.Message)
+		})
+	}
+}
+
+func newScopeQuestions() Questions {
+	return NewQuestions([]Question{
+		{ID: "scopes", Text: "Which scopes?", Field: "scopes", Answers: []string{"read", "write", "admin"}, MultiSelect: true},
+		{ID: "name", Text: "Token name?"},
+	})
+}
+
+func TestQuestions_MultiSelect(t *testing.T) {
+	tests := []struct {
+		wantErr     error
+		name        string
+		answers     []string
+		wantAnswers []string
+		wantAnswer  string
+	}{
+		{
+			name:        "repeated taps",
+			answers:     []string{"read", "write", DoneAnswer},
+			wantAnswers: []string{"read", "write"},
+			wantAnswer:  "read, write",
+		},
+		{
+			name:        "comma-separated options",
+			answers:     []string{" write , admin", DoneAnswer},
+			wantAnswers: []string{"write", "admin"},
+			wantAnswer:  "write, admin",
+		},
+		{
+			name:        "option selected twice is kept once",
+			answers:     []string{"read", "read,admin", DoneAnswer},
+			wantAnswers: []string{"read", "admin"},
+			wantAnswer:  "read, admin",
+		},
+		{
+			name:    "unknown option",
+			answers: []string{"read,owner"},
+			wantErr: ErrInvalidAnswer,
+		},
+		{
+			name:    "done without selection",
+			answers: []string{DoneAnswer},
+			wantErr: ErrInvalidAnswer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qs := newScopeQuestions()
+
+			var err error
+
+			for i, a := range tt.answers {
+				var done bool
+
+				done, err = qs.ProcessAnswer(a)
+				assert.False(t, done)
+
+				if i < len(tt.answers)-1 {
+					require.NoError(t, err)
+					assert.Equal(t, 0, qs.Position, "selection must not advance before done")
+				}
+			}
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, 0, qs.Position)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, qs.Position)
+			assert.Equal(t, tt.wantAnswers, qs.QAPairs[0].Answers)
+			assert.Equal(t, tt.wantAnswer, qs.QAPairs[0].Answer)
+			assert.Equal(t, "scopes", qs.QAPairs[0].Field)
+		})
+	}
+}
+
+func TestQuestions_MultiSelect_InvalidAnswerKeepsSelection(t *testing.T) {
+	qs := newScopeQuestions()
+
+	_, err := qs.ProcessAnswer("read")
+	require.NoError(t, err)
+
+	_, err = qs.ProcessAnswer("write,owner")
+	assert.ErrorIs(t, err, ErrInvalidAnswer)
+	assert.Equal(t, []string{"read"}, qs.QAPairs[0].Answers)
+}
+
+func TestQuestions_MultiSelect_Back(t *testing.T) {
+	qs := newScopeQuestions()
+
+	_, err := qs.ProcessAnswer("read,write")
+	require.NoError(t, err)
+	_, err = qs.ProcessAnswer(DoneAnswer)
+	require.NoError(t, err)
+
+	require.NoError(t, qs.Back())
+	assert.Equal(t, 0, qs.Position)
+	assert.Empty(t, qs.QAPairs[0].Answers)
+	assert.Empty(t, qs.QAPairs[0].Answer)
+}
+
+func TestQuestions_MultiSelectJSONRoundTrip(t *testing.T) {
+	qs := newScopeQuestions()
+
+	_, err := qs.ProcessAnswer("admin")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(qs)
+	require.NoError(t, err)
+
+	var decoded Questions
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, qs, decoded)
+
+	_, err = decoded.ProcessAnswer("read")
+	require.NoError(t, err)
+	_, err = decoded.ProcessAnswer(DoneAnswer)
+	require.NoError(t, err)
+
+	done, err := decoded.ProcessAnswer("ci")
+	require.NoError(t, err)
+	assert.True(t, done)
+
+	results, err := decoded.GetResults()
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, []string{"admin", "read"}, results[0].Answers)
+	assert.Equal(t, "admin, read", results[0].Answer)
+
+	data, err = json.Marshal(results[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"answers":["admin","read"]`)
+}
+
+func TestLookup(t *testing.T) {
+	qs := NewQuestions([]Question{
+		{ID: "ttl", Text: "Expiration?", Field: "web:key123", Answers: []string{"1 day", "7 days"}},
+		{Text: "Comment?"},
+		{ID: "label", Text: "Label?"},
+	})
+
+	for _, a := range []string{"7 days", "nothing", "homelab"} {
+		_, err := qs.ProcessAnswer(a)
+		require.NoError(t, err)
+	}
+
+	results, err := qs.GetResults()
+	require.NoError(t, err)
+
+	qa, ok := Lookup(results, "ttl")
+	require.True(t, ok)
+	assert.Equal(t, "7 days", qa.Answer)
+	assert.Equal(t, "web:key123", qa.Field)
+
+	_, ok = Lookup(results, "type")
+	assert.False(t, ok)
+
+	assert.Equal(t, map[string]string{"ttl": "7 days", "label": "homelab"}, AnswersByID(results))
+}
+
+func TestAnswersByID_SkipsBranchedOverQuestions(t *testing.T) {
+	qs := NewQuestions([]Question{
+		{ID: "scopes", Text: "Which scopes?", Answers: []string{"read", "write"}, MultiSelect: true},
+		{ID: "reason", Text: "Why write access?"},
+		{ID: "name", Text: "Token name?"},
+	})
+	qs.QAPairs[0].Question.Branches = []Branch{{Answer: "read", Next: "name"}}
+
+	for _, a := range []string{"read", DoneAnswer, "ci"} {
+		_, err := qs.ProcessAnswer(a)
+		require.NoError(t, err)
+	}
+
+	results, err := qs.GetResults()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"scopes": "read", "name": "ci"}, AnswersByID(results))
+}
@@ -0,0 +1,96 @@
+package conv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidatorType names a reusable check applied to free-text answers.
+type ValidatorType string
+
+const (
+	// ValidatorNotEmpty rejects answers that are blank after trimming spaces.
+	ValidatorNotEmpty ValidatorType = "not_empty"
+	// ValidatorMaxLength rejects answers longer than Max characters.
+	ValidatorMaxLength ValidatorType = "max_length"
+	// ValidatorIntRange accepts only whole numbers from Min to Max inclusive.
+	ValidatorIntRange ValidatorType = "int_range"
+)
+
+// Validator is a declarative check of free-text answers. It is stored with the question as data rather than
+// as a function, so that it survives the conversation being saved and loaded.
+// Message is shown to the user when the check fails, a default message is used when it is empty.
+type Validator struct {
+	Type    ValidatorType `json:"type"`
+	Message string        `json:"message,omitempty"`
+	Min     int           `json:"min,omitempty"`
+	Max     int           `json:"max,omitempty"`
+}
+
+// ValidationError is returned by ProcessAnswer when a free-text answer fails one of the question validators.
+// Message is meant to be shown to the user. It matches ErrInvalidAnswer with errors.Is.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid answer: " + e.Message
+}
+
+// Is makes ValidationError match ErrInvalidAnswer, so callers that don't care about the message keep working.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidAnswer
+}
+
+// NotEmpty returns a validator that rejects blank answers.
+func NotEmpty(message string) Validator {
+	return Validator{Type: ValidatorNotEmpty, Message: message}
+}
+
+// MaxLength returns a validator that rejects answers longer than maxLen characters.
+func MaxLength(maxLen int, message string) Validator {
+	return Validator{Type: ValidatorMaxLength, Max: maxLen, Message: message}
+}
+
+// IntRange returns a validator that accepts only whole numbers from minVal to maxVal inclusive.
+func IntRange(minVal, maxVal int, message string) Validator {
+	return Validator{Type: ValidatorIntRange, Min: minVal, Max: maxVal, Message: message}
+}
+
+// Check validates answer, surrounding spaces are ignored.
+// It returns a *ValidationError if the answer is rejected and a plain error if the validator itself is invalid.
+func (v Validator) Check(answer string) error {
+	answer = strings.TrimSpace(answer)
+
+	switch v.Type {
+	case ValidatorNotEmpty:
+		if answer == "" {
+			return v.fail("Answer can't be empty.")
+		}
+	case ValidatorMaxLength:
+		if utf8.RuneCountInString(answer) > v.Max {
+			return v.fail(fmt.Sprintf("Answer must be at most %d characters.", v.Max))
+		}
+	case ValidatorIntRange:
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < v.Min || n > v.Max {
+			return v.fail(fmt.Sprintf("Enter a whole number from %d to %d.", v.Min, v.Max))
+		}
+	default:
+		return fmt.Errorf("unknown validator type %q", v.Type)
+	}
+
+	return nil
+}
+
+// fail builds the ValidationError for v, using defaultMessage when v has no custom message.
+func (v Validator) fail(defaultMessage string) error {
+	msg := v.Message
+	if msg == "" {
+		msg = defaultMessage
+	}
+
+	return &ValidationError{Message: msg}
+}
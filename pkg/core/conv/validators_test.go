@@ -0,0 +1,69 @@
+package conv
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		answer      string
+		wantMessage string
+		validator   Validator
+		wantErr     bool
+	}{
+		{name: "not empty accepts text", validator: NotEmpty(""), answer: "home"},
+		{name: "not empty rejects spaces", validator: NotEmpty(""), answer: "   ", wantErr: true, wantMessage: "Answer can't be empty."},
+		{name: "max length accepts limit", validator: MaxLength(4, ""), answer: "home"},
+		{name: "max length counts characters, not bytes", validator: MaxLength(4, ""), answer: "дача"},
+		{name: "max length ignores surrounding spaces", validator: MaxLength(4, ""), answer: " home "},
+		{name: "max length rejects longer", validator: MaxLength(4, "Too long"), answer: "homes", wantErr: true, wantMessage: "Too long"},
+		{name: "int range accepts bounds", validator: IntRange(1, 30, ""), answer: "30"},
+		{name: "int range rejects out of range", validator: IntRange(1, 30, ""), answer: "0", wantErr: true, wantMessage: "Enter a whole number from 1 to 30."},
+		{name: "int range rejects non numbers", validator: IntRange(1, 30, ""), answer: "ten", wantErr: true, wantMessage: "Enter a whole number from 1 to 30."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validator.Check(tt.answer)
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			var vErr *ValidationError
+
+			require.ErrorAs(t, err, &vErr)
+			assert.Equal(t, tt.wantMessage, vErr.Message)
+			assert.ErrorIs(t, err, ErrInvalidAnswer)
+		})
+	}
+}
+
+func TestValidator_UnknownType(t *testing.T) {
+	err := Validator{Type: "email"}.Check("a@b.c")
+
+	assert.ErrorContains(t, err, `unknown validator type "email"`)
+	assert.NotErrorIs(t, err, ErrInvalidAnswer)
+}
+
+func TestValidator_JSONRoundTrip(t *testing.T) {
+	qs := NewQuestions([]Question{
+		{Text: "Name?", Validators: []Validator{NotEmpty(""), MaxLength(20, "Name must be 3–20 characters")}},
+	})
+
+	data, err := json.Marshal(qs)
+	require.NoError(t, err)
+
+	var decoded Questions
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, qs, decoded)
+
+	_, err = decoded.ProcessAnswer("a name that is longer than twenty")
+	assert.EqualError(t, err, "invalid answer: Name must be 3–20 characters")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,22 +12,45 @@ import (
 )
 
 const (
-	maxWebTokensPerUser = 3
-	maxTCPTokensPerUser = 1
-	secondsInDay        = 24 * 60 * 60
-	tokenCreatedMessage = "🔑 Your New API Token\n\n%s\n\n⏱ Valid until: %s\n\nKeep this token secure and don't share it with others."
-	keyIDDisplayLen     = 8   // Number of characters shown from key ID in buttons
-	tokenFieldSep       = "|" // Separator between token type and key ID in conv.Question.Field
+	// defaultMaxWebTokensPerUser and defaultMaxTCPTokensPerUser are used when Config leaves the limits unset.
+	defaultMaxWebTokensPerUser = 3
+	defaultMaxTCPTokensPerUser = 1
+	secondsInDay               = 24 * 60 * 60
+	// defaultMaxExpirationDays caps custom expiration periods when Config.MaxExpirationDays is unset.
+	defaultMaxExpirationDays = 365
+	expirationQuestion       = "What is the expiration period for your new API token? Pick an option or enter a number of days."
+	// expirationPattern accepts custom periods such as "14", "14 days" or "1 day".
+	expirationPattern        = `(?i)^\s*\d+(\s*days?)?\s*$`
+	invalidExpirationMessage = "Invalid expiration period. Choose one of the options or enter a number of days, e.g. \"14\"."
+	ttlOutOfRangeMessage     = "The token service doesn't accept that expiration period. Please try again with a different one."
+	keyIDDisplayLen          = 8   // Number of characters shown from key ID in buttons
+	tokenFieldSep            = "|" // Separator between token type and key ID in conv.Question.Field
+	skipAnswer               = "Skip"
+	tokenNameQuestion        = "Enter a label for this token (e.g. \"home server\"), or send \"Skip\" to leave it unnamed."
+	maxTokenNameLen          = 32
+	tokenTypeQuestion        = "What type of token do you want to create?"
+	webTypeAnswer            = "Web"
+	tcpTypeAnswer            = "TCP"
+	keyIDQuestion            = "Enter a custom subdomain for your web token (e.g. \"myapp\" will give you myapp.make-it-public.dev), or send \"Skip\" to generate one automatically."
+)
+
+// Question IDs of the token flows, the result handlers look the answers up by them.
+const (
+	questionIDType       = "type"
+	questionIDKeyID      = "key_id"
+	questionIDExpiration = "ttl"
+	questionIDLabel      = "label"
 )
 
 const (
-	StateSelectTokenType         conv.State = "selectTokenType"
 	StateTokenRegenerate         conv.State = "tokenRegenerate"
 	StateTokenExists             conv.State = "tokenExists"
 	StateNewToken                conv.State = "newToken"
 	StateEnterKeyID              conv.State = "enterKeyID"
 	StateSelectTokenToRegenerate conv.State = "selectTokenToRegenerate"
 	StateSelectTokenToRevoke     conv.State = "selectTokenToRevoke"
+	StateSelectTokenToRenew      conv.State = "selectTokenToRenew"
+	StateRenewToken              conv.State = "renewToken"
 )
 
 var (
@@ -36,6 +60,16 @@ var (
 	ErrDuplicateKeyID = errors.New("key ID already in use")
 	// ErrInvalidKeyID is returned by MITProv.GenerateToken when the requested key ID has an invalid format.
 	ErrInvalidKeyID = errors.New("invalid key ID format")
+	// ErrInvalidTTL is returned by MITProv when the requested token TTL is outside of the range the provider accepts.
+	ErrInvalidTTL = errors.New("token TTL is outside of the accepted range")
+	// ErrProviderUnauthorized matches the *ProviderError returned by MITProv when the provider rejects the bot's credentials (401/403).
+	ErrProviderUnauthorized = errors.New("provider rejected credentials")
+	// ErrProviderUnavailable is returned by MITProv without calling the provider while it is considered down
+	// after a streak of failures.
+	ErrProviderUnavailable = errors.New("provider is temporarily unavailable")
+	// ErrTokenLimitReached is returned by UserRepo.AddAPIKeyWithLimit when the user already has the maximum
+	// number of tokens of the requested type.
+	ErrTokenLimitReached = errors.New("token limit reached")
 )
 
 // encodeTokenField encodes a token type and key ID into a single Field string.
@@ -57,12 +91,12 @@ func decodeTokenField(field string) (TokenType, string) {
 }
 
 // maxTokensForType returns the per-user token limit for the given type.
-func maxTokensForType(tokenType TokenType) int {
+func (s *Service) maxTokensForType(tokenType TokenType) int {
 	if tokenType == TokenTypeTCP {
-		return maxTCPTokensPerUser
+		return s.maxTCPTokens
 	}
 
-	return maxWebTokensPerUser
+	return s.maxWebTokens
 }
 
 // filterKeysByType returns only the KeyInfo entries matching the given token type.
@@ -77,21 +111,39 @@ func filterKeysByType(keys []KeyInfo, tokenType TokenType) []KeyInfo {
 	return result
 }
 
-// CreateToken starts a conversation asking the user what type of token they want to create (Web or TCP).
+// CreateToken starts a conversation asking the user for the token type (Web or TCP), the subdomain of a web token,
+// the expiration period and an optional label.
+// Returns a *RateLimitedError if the user has reached the daily token creation limit and ErrUserBusy while
+// another request of the user is being processed.
 func (s *Service) CreateToken(ctx context.Context, userID string) (*Response, error) {
+	unlock, err := s.lockUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return s.createToken(ctx, userID)
+}
+
+// createToken checks the creation limit and starts the token type question.
+func (s *Service) createToken(ctx context.Context, userID string) (*Response, error) {
+	if err := s.checkTokenCreationLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
 	c, err := s.repo.GetConversation(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
 
-	questions := conv.NewQuestions(
-		[]conv.Question{{
-			Text:    "What type of token do you want to create?",
-			Answers: []string{"Web", "TCP"},
-		}},
-	)
+	questions := conv.NewQuestions(s.newTokenQuestions(keys, s.defaultExpiresIn(ctx, userID) == 0))
 
-	if err := c.Start(StateSelectTokenType, questions); err != nil {
+	if err := s.startConversation(ctx, userID, c, StateNewToken, questions); err != nil {
 		return nil, fmt.Errorf("failed to start questions: %w", err)
 	}
 
@@ -107,24 +159,25 @@ func (s *Service) CreateToken(ctx context.Context, userID string) (*Response, er
 	}, nil
 }
 
-// handleSelectTokenTypeResult processes the type selection answer and branches into the appropriate flow.
-// If under the per-type limit it asks for expiration; if at the limit it asks to regenerate.
-func (s *Service) handleSelectTokenTypeResult(ctx context.Context, userID string, answers []conv.QuestionAnswer) (*Response, error) {
-	if len(answers) != 1 {
-		return nil, fmt.Errorf("expected exactly one answer for token type question, got %d", len(answers))
+// CreateTokenWithExpiration creates an unnamed web token with an auto-generated key ID that is valid for the given
+// number of days, skipping the interactive questions. If the user already has the maximum number of web tokens,
+// the regeneration flow is started instead.
+// Returns ErrInvalidExpirationPeriod if days is outside of 1..maxExpirationDays and
+// a *RateLimitedError if the user has reached the daily token creation limit.
+// Returns ErrUserBusy while another request of the user is being processed.
+func (s *Service) CreateTokenWithExpiration(ctx context.Context, userID string, days int) (*Response, error) {
+	if days <= 0 || days > s.maxExpirationDays {
+		return nil, ErrInvalidExpirationPeriod
 	}
 
-	var tokenType TokenType
+	unlock, err := s.lockUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	switch answers[0].Answer {
-	case "Web":
-		tokenType = TokenTypeWeb
-	case "TCP":
-		tokenType = TokenTypeTCP
-	default:
-		return &Response{
-			Message: "Invalid token type selected. Please choose Web or TCP.",
-		}, nil
+	if err := s.checkTokenCreationLimit(ctx, userID); err != nil {
+		return nil, err
 	}
 
 	keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
@@ -132,38 +185,92 @@ func (s *Service) handleSelectTokenTypeResult(ctx context.Context, userID string
 		return nil, fmt.Errorf("failed to get API keys: %w", err)
 	}
 
-	typeKeys := filterKeysByType(keys, tokenType)
-	limit := maxTokensForType(tokenType)
+	if len(filterKeysByType(keys, TokenTypeWeb)) >= s.maxTokensForType(TokenTypeWeb) {
+		return s.askToRegenerateToken(ctx, userID, TokenTypeWeb)
+	}
 
-	if len(typeKeys) >= limit {
-		return s.askToRegenerateToken(ctx, userID, tokenType)
+	token, err := s.prov.GenerateToken(ctx, "", TokenTypeWeb, int64(days)*secondsInDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	if tokenType == TokenTypeTCP {
-		return s.askForTokenExpirationWithKeyID(ctx, userID, StateNewToken, tokenType, "")
+	err = s.repo.AddAPIKeyWithLimit(ctx, userID, token.KeyID, TokenTypeWeb, "", token.ExpiresIn, s.maxTokensForType(TokenTypeWeb))
+
+	switch {
+	case errors.Is(err, ErrTokenLimitReached):
+		return s.discardTokenOverLimit(ctx, userID, TokenTypeWeb, token.KeyID)
+	case err != nil:
+		return nil, fmt.Errorf("failed to add API key: %w", err)
 	}
 
-	return s.askForKeyID(ctx, userID, tokenType)
+	s.audit(ctx, userID, AuditActionCreate, token.KeyID)
+
+	s.recordTokenCreation(ctx, userID)
+
+	expiresAt := formatExpiryWithTimeLeft(time.Now().Add(token.ExpiresIn), s.userLocation(ctx, userID))
+
+	return s.tokenCreatedResponse(token.Token, expiresAt)
 }
 
-// askForKeyID starts a conversation asking the user to enter a custom subdomain (key ID) or skip to auto-generate.
-// The token type is encoded in the question Field for use by handleEnterKeyIDResult.
-// This step is only used for web tokens, where the key ID defines the public subdomain
-// (e.g. "myapp" → myapp.make-it-public.dev).
-func (s *Service) askForKeyID(ctx context.Context, userID string, tokenType TokenType) (*Response, error) {
-	return s.askForKeyIDWithPrompt(ctx, userID, tokenType,
-		"Enter a custom subdomain for your web token (e.g. \"myapp\" will give you myapp.make-it-public.dev), or send \"Skip\" to generate one automatically.")
+// newTokenQuestions builds the questionnaire of a new token: its type, the subdomain of a web token,
+// the expiration period unless askExpiration is false, and an optional label. TCP tokens have no subdomain,
+// so their type answer skips it. A type of which the user already has the maximum number of tokens completes
+// the questionnaire right away, handleNewTokenResult then offers to regenerate one of them instead.
+func (s *Service) newTokenQuestions(keys []KeyInfo, askExpiration bool) []conv.Question {
+	typeQuestion := conv.Question{
+		ID:      questionIDType,
+		Text:    tokenTypeQuestion,
+		Answers: []string{webTypeAnswer, tcpTypeAnswer},
+	}
+
+	if s.tokenLimitReached(keys, TokenTypeWeb) {
+		typeQuestion.Branches = append(typeQuestion.Branches, conv.Branch{Answer: webTypeAnswer, Next: conv.EndQuestions})
+	}
+
+	tcpNext := questionIDExpiration
+	if !askExpiration {
+		tcpNext = questionIDLabel
+	}
+
+	if s.tokenLimitReached(keys, TokenTypeTCP) {
+		tcpNext = conv.EndQuestions
+	}
+
+	typeQuestion.Branches = append(typeQuestion.Branches, conv.Branch{Answer: tcpTypeAnswer, Next: tcpNext})
+
+	// The subdomain is free text, "Skip" generates one automatically.
+	keyIDQ := conv.Question{
+		ID:   questionIDKeyID,
+		Text: keyIDQuestion,
+	}
+
+	if !askExpiration {
+		return []conv.Question{typeQuestion, keyIDQ, tokenLabelQuestion()}
+	}
+
+	return []conv.Question{typeQuestion, keyIDQ, s.expirationQuestion(""), tokenLabelQuestion()}
 }
 
-// askForKeyIDWithError re-enters the key ID step with an error message prepended to the prompt.
+// tokenLimitReached reports whether keys already hold the maximum number of tokens of tokenType.
+func (s *Service) tokenLimitReached(keys []KeyInfo, tokenType TokenType) bool {
+	return len(filterKeysByType(keys, tokenType)) >= s.maxTokensForType(tokenType)
+}
+
+// parseTokenType maps the answer to the token type question to a TokenType.
+func parseTokenType(answer string) TokenType {
+	if answer == tcpTypeAnswer {
+		return TokenTypeTCP
+	}
+
+	return TokenTypeWeb
+}
+
+// askForKeyIDWithError asks for a different key ID with an error message prepended to the prompt.
 // Used when the API rejects the previously entered key ID (409 Conflict or 400 Bad Request).
+// The token type is encoded in the question Field for use by handleEnterKeyIDResult.
 func (s *Service) askForKeyIDWithError(ctx context.Context, userID string, tokenType TokenType, errMsg string) (*Response, error) {
 	prompt := errMsg + "\n\nEnter a different subdomain, or send \"Skip\" to generate one automatically."
-	return s.askForKeyIDWithPrompt(ctx, userID, tokenType, prompt)
-}
 
-// askForKeyIDWithPrompt is the shared implementation for askForKeyID and askForKeyIDWithError.
-func (s *Service) askForKeyIDWithPrompt(ctx context.Context, userID string, tokenType TokenType, prompt string) (*Response, error) {
 	c, err := s.repo.GetConversation(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
@@ -177,7 +284,7 @@ func (s *Service) askForKeyIDWithPrompt(ctx context.Context, userID string, toke
 		}},
 	)
 
-	if err := c.Start(StateEnterKeyID, questions); err != nil {
+	if err := s.startConversation(ctx, userID, c, StateEnterKeyID, questions); err != nil {
 		return nil, fmt.Errorf("failed to start questions: %w", err)
 	}
 
@@ -195,7 +302,7 @@ func (s *Service) askForKeyIDWithPrompt(ctx context.Context, userID string, toke
 
 // handleEnterKeyIDResult processes the key ID entered by the user.
 // "Skip" maps to an empty key ID (auto-generate); any other text is used as the explicit key ID.
-// The token type is extracted from the answer's Field (set by askForKeyID).
+// The token type is extracted from the answer's Field (set by askForKeyIDWithError).
 func (s *Service) handleEnterKeyIDResult(ctx context.Context, userID string, answers []conv.QuestionAnswer) (*Response, error) {
 	if len(answers) != 1 {
 		return nil, fmt.Errorf("expected exactly one answer for enterKeyID question, got %d", len(answers))
@@ -207,7 +314,7 @@ func (s *Service) handleEnterKeyIDResult(ctx context.Context, userID string, ans
 	}
 
 	keyID := answers[0].Answer
-	if keyID == "Skip" {
+	if keyID == skipAnswer {
 		keyID = ""
 	}
 
@@ -222,15 +329,19 @@ func (s *Service) askToRegenerateToken(ctx context.Context, userID string, token
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
 
-	limit := maxTokensForType(tokenType)
+	limit := s.maxTokensForType(tokenType)
+
+	typeName := "web"
+	if tokenType == TokenTypeTCP {
+		typeName = "TCP"
+	}
 
 	var text string
 
-	switch tokenType {
-	case TokenTypeTCP:
-		text = fmt.Sprintf("You've reached the maximum of %d TCP token. Do you want to regenerate it?", limit)
-	default:
-		text = fmt.Sprintf("You've reached the maximum of %d web tokens. Do you want to regenerate an existing one?", limit)
+	if limit == 1 {
+		text = fmt.Sprintf("You've reached the maximum of 1 %s token. Do you want to regenerate it?", typeName)
+	} else {
+		text = fmt.Sprintf("You've reached the maximum of %d %s tokens. Do you want to regenerate an existing one?", limit, typeName)
 	}
 
 	questions := conv.NewQuestions(
@@ -241,7 +352,7 @@ func (s *Service) askToRegenerateToken(ctx context.Context, userID string, token
 		}},
 	)
 
-	if err := c.Start(StateTokenExists, questions); err != nil {
+	if err := s.startConversation(ctx, userID, c, StateTokenExists, questions); err != nil {
 		return nil, fmt.Errorf("failed to start questions: %w", err)
 	}
 
@@ -303,7 +414,7 @@ func (s *Service) askToSelectTokenForRegeneration(ctx context.Context, userID st
 
 	questions := conv.NewQuestions([]conv.Question{q})
 
-	if err := c.Start(StateSelectTokenToRegenerate, questions); err != nil {
+	if err := s.startConversation(ctx, userID, c, StateSelectTokenToRegenerate, questions); err != nil {
 		return nil, fmt.Errorf("failed to start questions: %w", err)
 	}
 
@@ -357,21 +468,22 @@ func (s *Service) handleSelectTokenToRegenerateResult(ctx context.Context, userI
 // askForTokenExpirationWithKeyID starts a conversation asking the user for an expiration period.
 // Both tokenType and keyID are encoded into the question's Field for retrieval by the result handler.
 // Pass an empty keyID when creating a new token (as opposed to regenerating).
+// When creating a new token, an optional free-text label question follows the expiration question.
 func (s *Service) askForTokenExpirationWithKeyID(ctx context.Context, userID string, state conv.State, tokenType TokenType, keyID string) (*Response, error) {
 	c, err := s.repo.GetConversation(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
 
-	questions := conv.NewQuestions(
-		[]conv.Question{{
-			Text:    "What is the expiration period for your new API token?",
-			Answers: []string{"1 day", "7 days", "30 days", "90 days"},
-			Field:   encodeTokenField(tokenType, keyID),
-		}},
-	)
+	qs := []conv.Question{s.expirationQuestion(encodeTokenField(tokenType, keyID))}
+
+	if state == StateNewToken {
+		qs = append(qs, tokenLabelQuestion())
+	}
 
-	if err := c.Start(state, questions); err != nil {
+	questions := conv.NewQuestions(qs)
+
+	if err := s.startConversation(ctx, userID, c, state, questions); err != nil {
 		return nil, fmt.Errorf("failed to start questions: %w", err)
 	}
 
@@ -387,27 +499,96 @@ func (s *Service) askForTokenExpirationWithKeyID(ctx context.Context, userID str
 	}, nil
 }
 
-// handleNewTokenResult creates a brand-new token with the chosen expiration period.
-// The token type is decoded from the Field of the answer.
+// expirationQuestion returns the question for the expiration period of a new or regenerated token, field is
+// carried to its answer.
+func (s *Service) expirationQuestion(field string) conv.Question {
+	return conv.Question{
+		ID:          questionIDExpiration,
+		Text:        expirationQuestion,
+		Answers:     s.expirationAnswers(),
+		AllowCustom: true,
+		Pattern:     expirationPattern,
+		Field:       field,
+	}
+}
+
+// tokenLabelQuestion returns the optional free-text question for the label of a new token.
+func tokenLabelQuestion() conv.Question {
+	return conv.Question{
+		ID:         questionIDLabel,
+		Text:       tokenNameQuestion,
+		Validators: []conv.Validator{conv.MaxLength(maxTokenNameLen, fmt.Sprintf("The label must be at most %d characters.", maxTokenNameLen))},
+	}
+}
+
+// handleNewTokenResult creates a brand-new token from the answers to the new token questions: the type,
+// the subdomain of a web token, the expiration period or the user's default one and the optional label. If the user is at the limit
+// for the chosen type, they are asked to regenerate one of their tokens instead.
+// Questions asked again after the provider rejected the subdomain have no type answer, the type and key ID
+// are decoded from the Field of the expiration answer then.
 func (s *Service) handleNewTokenResult(ctx context.Context, userID string, answers []conv.QuestionAnswer) (*Response, error) {
-	expiresIn, err := s.parseExpirationAnswer(answers)
+	results := conv.AnswersByID(answers)
+	expiration, hasExpiration := conv.Lookup(answers, questionIDExpiration)
 
-	switch {
-	case errors.Is(err, ErrInvalidExpirationPeriod):
-		return &Response{
-			Message: "Invalid expiration period selected. Please select one of the available options.",
-		}, nil
-	case err != nil:
-		return nil, fmt.Errorf("failed to parse expiration answer: %w", err)
+	var (
+		tokenType TokenType
+		keyID     string
+		expiresIn int64
+	)
+
+	if typeAnswer, ok := results[questionIDType]; ok {
+		tokenType = parseTokenType(typeAnswer)
+
+		if keyID = results[questionIDKeyID]; keyID == skipAnswer {
+			keyID = ""
+		}
+
+		keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get API keys: %w", err)
+		}
+
+		if s.tokenLimitReached(keys, tokenType) {
+			return s.askToRegenerateToken(ctx, userID, tokenType)
+		}
+
+		// The expiration question is skipped when the user has a default expiration. Without one, the type was
+		// at its limit when the questions were built, but a token has expired or was revoked since.
+		if !hasExpiration {
+			if expiresIn = s.defaultExpiresIn(ctx, userID); expiresIn == 0 {
+				return s.askForTokenExpirationWithKeyID(ctx, userID, StateNewToken, tokenType, keyID)
+			}
+		}
+	} else {
+		if !hasExpiration {
+			return nil, fmt.Errorf("missing answer to the expiration question")
+		}
+
+		tokenType, keyID = decodeTokenField(expiration.Field)
 	}
 
-	if len(answers) == 0 {
-		return nil, fmt.Errorf("expected at least one answer, got none")
+	if hasExpiration {
+		var err error
+
+		expiresIn, err = s.parseExpirationAnswer(expiration.Answer)
+
+		switch {
+		case errors.Is(err, ErrInvalidExpirationPeriod):
+			return &Response{
+				Message: invalidExpirationMessage,
+			}, nil
+		case err != nil:
+			return nil, fmt.Errorf("failed to parse expiration answer: %w", err)
+		}
 	}
 
-	tokenType, keyID := decodeTokenField(answers[0].Field)
+	name := parseTokenName(answers)
+
+	if err := s.checkTokenCreationLimit(ctx, userID); err != nil {
+		return nil, err
+	}
 
-	token, err := s.prov.GenerateToken(keyID, tokenType, expiresIn)
+	token, err := s.prov.GenerateToken(ctx, keyID, tokenType, expiresIn)
 	if err != nil {
 		switch {
 		case errors.Is(err, ErrDuplicateKeyID):
@@ -416,46 +597,122 @@ func (s *Service) handleNewTokenResult(ctx context.Context, userID string, answe
 		case errors.Is(err, ErrInvalidKeyID):
 			return s.askForKeyIDWithError(ctx, userID, tokenType,
 				"That key ID format is invalid. Please enter a different one.")
+		case errors.Is(err, ErrInvalidTTL):
+			return &Response{Message: ttlOutOfRangeMessage}, nil
 		default:
 			return nil, fmt.Errorf("failed to generate token: %w", err)
 		}
 	}
 
-	if err = s.repo.AddAPIKey(ctx, userID, token.KeyID, tokenType, token.ExpiresIn); err != nil {
+	err = s.repo.AddAPIKeyWithLimit(ctx, userID, token.KeyID, tokenType, name, token.ExpiresIn, s.maxTokensForType(tokenType))
+
+	switch {
+	case errors.Is(err, ErrTokenLimitReached):
+		return s.discardTokenOverLimit(ctx, userID, tokenType, token.KeyID)
+	case err != nil:
 		return nil, fmt.Errorf("failed to add API key: %w", err)
 	}
 
-	expiresAt := time.Now().Add(token.ExpiresIn).Format(time.DateTime)
+	s.audit(ctx, userID, AuditActionCreate, token.KeyID)
+
+	s.recordTokenCreation(ctx, userID)
+
+	expiresAt := formatExpiryWithTimeLeft(time.Now().Add(token.ExpiresIn), s.userLocation(ctx, userID))
+
+	return s.tokenCreatedResponse(token.Token, expiresAt)
+}
+
+// discardTokenOverLimit revokes a freshly generated token that the repository refused to store because
+// a concurrent request used up the per-type limit first, and starts the regeneration flow instead.
+func (s *Service) discardTokenOverLimit(ctx context.Context, userID string, tokenType TokenType, keyID string) (*Response, error) {
+	if err := s.prov.RevokeToken(ctx, keyID); err != nil {
+		return nil, fmt.Errorf("failed to revoke token over the limit: %w", err)
+	}
+
+	return s.askToRegenerateToken(ctx, userID, tokenType)
+}
+
+// tokenCreatedResponse renders the token_created template as MarkdownV2 with the token in a code span,
+// so that it can be copied with a tap.
+func (s *Service) tokenCreatedResponse(token, expiresAt string) (*Response, error) {
+	msg, err := s.templates.render(templateTokenCreated, tokenCreatedData{Token: token, ExpiresAt: expiresAt})
+	if err != nil {
+		return nil, err
+	}
 
 	return &Response{
-		Message: fmt.Sprintf(tokenCreatedMessage, token.Token, expiresAt),
+		Message:  msg,
+		Markdown: true,
 	}, nil
 }
 
+// parseTokenName extracts the optional token label from the answer to the label question.
+// A missing answer or "Skip" yields an empty name.
+func parseTokenName(answers []conv.QuestionAnswer) string {
+	name := strings.TrimSpace(conv.AnswersByID(answers)[questionIDLabel])
+	if name == skipAnswer {
+		return ""
+	}
+
+	return name
+}
+
 // handleTokenRegenerateResult revokes the previously selected token and generates a new one.
 // The token type and key ID to revoke are decoded from the Field of the expiration question answer.
+// A user who regenerated a token within the regeneration cooldown is asked to wait instead.
 func (s *Service) handleTokenRegenerateResult(ctx context.Context, userID string, answers []conv.QuestionAnswer) (*Response, error) {
-	expiresIn, err := s.parseExpirationAnswer(answers)
+	expiration, ok := conv.Lookup(answers, questionIDExpiration)
+	if !ok {
+		return nil, fmt.Errorf("missing answer to the expiration question")
+	}
+
+	expiresIn, err := s.parseExpirationAnswer(expiration.Answer)
 
 	switch {
 	case errors.Is(err, ErrInvalidExpirationPeriod):
 		return &Response{
-			Message: "Invalid expiration period selected. Please select one of the available options.",
+			Message: invalidExpirationMessage,
 		}, nil
 	case err != nil:
 		return nil, fmt.Errorf("failed to parse expiration answer: %w", err)
 	}
 
-	if len(answers) == 0 {
-		return nil, fmt.Errorf("expected at least one answer, got none")
-	}
-
-	tokenType, keyID := decodeTokenField(answers[0].Field)
+	tokenType, keyID := decodeTokenField(expiration.Field)
 	if keyID == "" {
 		return nil, fmt.Errorf("missing key ID in regenerate answer field")
 	}
 
-	if err := s.prov.RevokeToken(keyID); err != nil {
+	wait, err := s.regenerateCooldownLeft(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if wait > 0 {
+		return &Response{
+			Message: fmt.Sprintf(regenerateCooldownMessage, formatWait(wait)),
+		}, nil
+	}
+
+	// Preserve the label of the token being regenerated.
+	keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	var name string
+
+	for _, k := range keys {
+		if k.KeyID == keyID {
+			name = k.Name
+			break
+		}
+	}
+
+	if err := s.checkTokenCreationLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.prov.RevokeToken(ctx, keyID); err != nil {
 		return nil, fmt.Errorf("failed to revoke existing token: %w", err)
 	}
 
@@ -463,44 +720,49 @@ func (s *Service) handleTokenRegenerateResult(ctx context.Context, userID string
 		return nil, fmt.Errorf("failed to remove API key from repository: %w", err)
 	}
 
-	token, err := s.prov.GenerateToken(keyID, tokenType, expiresIn)
+	token, err := s.prov.GenerateToken(ctx, keyID, tokenType, expiresIn)
+	if errors.Is(err, ErrDuplicateKeyID) || errors.Is(err, ErrInvalidKeyID) {
+		// The provider may refuse to reissue the old key ID, a generated one replaces it in the repository.
+		token, err = s.prov.GenerateToken(ctx, "", tokenType, expiresIn)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	if err = s.repo.AddAPIKey(ctx, userID, token.KeyID, tokenType, token.ExpiresIn); err != nil {
+	if err = s.repo.AddAPIKey(ctx, userID, token.KeyID, tokenType, name, token.ExpiresIn); err != nil {
 		return nil, fmt.Errorf("failed to add API key: %w", err)
 	}
 
-	expiresAt := time.Now().Add(token.ExpiresIn).Format(time.DateTime)
+	s.audit(ctx, userID, AuditActionRegenerate, token.KeyID)
+	s.recordRegeneration(ctx, userID)
 
-	return &Response{
-		Message: fmt.Sprintf(tokenCreatedMessage, token.Token, expiresAt),
-	}, nil
+	s.recordTokenCreation(ctx, userID)
+
+	expiresAt := formatExpiryWithTimeLeft(time.Now().Add(token.ExpiresIn), s.userLocation(ctx, userID))
+
+	return s.tokenCreatedResponse(token.Token, expiresAt)
 }
 
 // parseExpirationAnswer converts the user's textual expiration answer to a seconds value.
-func (s *Service) parseExpirationAnswer(answers []conv.QuestionAnswer) (int64, error) {
-	if len(answers) == 0 {
-		return 0, fmt.Errorf("expected at least one answer for expiration question, got 0")
-	}
-
-	var expiresIn int64
-
-	switch answers[0].Answer {
-	case "1 day":
-		expiresIn = secondsInDay
-	case "7 days":
-		expiresIn = 7 * secondsInDay
-	case "30 days":
-		expiresIn = 30 * secondsInDay
-	case "90 days":
-		expiresIn = 90 * secondsInDay
-	default:
+// Besides the preset options it accepts a custom number of days such as "14" or "14 days";
+// values above the configured maximum are capped to it.
+func (s *Service) parseExpirationAnswer(answer string) (int64, error) {
+	if period, ok := s.presetPeriod(answer); ok {
+		return int64(period / time.Second), nil
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	answer = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(answer, "days"), "day"))
+
+	days, err := strconv.Atoi(answer)
+	if err != nil || days <= 0 {
 		return 0, ErrInvalidExpirationPeriod
 	}
 
-	return expiresIn, nil
+	days = min(days, s.maxExpirationDays)
+
+	return int64(days) * secondsInDay, nil
 }
 
 // buildTokenSelectionQuestion creates a Question listing all provided keys as selectable buttons.
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -12,8 +13,19 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// typeAnswer returns the result of the token type question answered with answer.
+func typeAnswer(answer string) conv.QuestionAnswer {
+	return conv.QuestionAnswer{Answer: answer, Question: conv.Question{ID: questionIDType}}
+}
+
+// expirationAnswer returns the result of the expiration question answered with answer, carrying field.
+func expirationAnswer(answer, field string) conv.QuestionAnswer {
+	return conv.QuestionAnswer{Answer: answer, Field: field, Question: conv.Question{ID: questionIDExpiration}}
+}
+
 func TestCreateToken(t *testing.T) {
 	tests := []struct {
+		getKeysErr      error
 		getConvErr      error
 		saveConvErr     error
 		name            string
@@ -28,6 +40,12 @@ func TestCreateToken(t *testing.T) {
 			expectedMsg:     "What type of token do you want to create?",
 			expectedAnswers: []string{"Web", "TCP"},
 		},
+		{
+			name:        "get keys error",
+			userID:      "user123",
+			getKeysErr:  errors.New("redis error"),
+			expectedErr: "failed to get API keys: redis error",
+		},
 		{
 			name:        "get conversation error",
 			userID:      "user123",
@@ -45,16 +63,23 @@ func TestCreateToken(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := NewMockUserRepo(t)
+			expectUserLock(repo, tt.userID)
 			prov := NewMockMITProv(t)
 
-			if tt.getConvErr != nil {
+			repo.On("GetTokenCreationCount", mock.Anything, tt.userID).Return(0, time.Time{}, nil)
+			repo.On("GetAPIKeysWithExpiration", mock.Anything, tt.userID).Return(nil, tt.getKeysErr)
+
+			switch {
+			case tt.getKeysErr != nil:
+			case tt.getConvErr != nil:
 				repo.On("GetConversation", mock.Anything, tt.userID).Return(nil, tt.getConvErr)
-			} else {
+			default:
 				repo.On("GetConversation", mock.Anything, tt.userID).Return(conv.New(tt.userID), nil)
+				expectDefaultExpiration(repo, tt.userID, "")
 				repo.On("SaveConversation", mock.Anything, mock.AnythingOfType("*conv.Conversation")).Return(tt.saveConvErr)
 			}
 
-			svc := New(repo, prov)
+			svc := newTestService(t, Config{}, repo, prov)
 
 			resp, err := svc.CreateToken(context.Background(), tt.userID)
 
@@ -75,74 +100,181 @@ func TestCreateToken(t *testing.T) {
 	}
 }
 
-func TestHandleSelectTokenTypeResult(t *testing.T) {
+func TestCreateTokenWithExpiration(t *testing.T) {
 	userID := "user123"
+	token := &APIToken{KeyID: "key123", Token: "token123", ExpiresIn: 30 * 24 * time.Hour}
+	webKey := func(id string) KeyInfo {
+		return KeyInfo{KeyID: id, Type: TokenTypeWeb, ExpiresAt: time.Now().Add(time.Hour)}
+	}
 
 	tests := []struct {
-		getKeysErr      error
-		saveConvErr     error
-		name            string
-		answer          string
-		expectedMsg     string
-		expectedErr     string
-		existingKeys    []KeyInfo
-		expectedAnswers []string
-		expectSaveConv  bool
+		generateErr error
+		name        string
+		expectedMsg string
+		expectedErr string
+		wantExpiry  string
+		existing    []KeyInfo
+		days        int
+		wantInvalid bool
+		wantLimit   bool
 	}{
 		{
-			name:            "web under limit - asks for key ID",
-			answer:          "Web",
-			existingKeys:    []KeyInfo{},
-			expectedMsg:     "Enter a custom subdomain for your web token (e.g. \"myapp\" will give you myapp.make-it-public.dev), or send \"Skip\" to generate one automatically.",
-			expectedAnswers: nil,
-			expectSaveConv:  true,
+			name:        "creates web token",
+			days:        30,
+			expectedMsg: "token123",
+			wantExpiry:  "UTC \\(in 30 days\\)",
 		},
 		{
-			name:            "TCP under limit - skips key ID, asks for expiration",
-			answer:          "TCP",
-			existingKeys:    []KeyInfo{},
-			expectedMsg:     "What is the expiration period for your new API token?",
-			expectedAnswers: []string{"1 day", "7 days", "30 days", "90 days"},
-			expectSaveConv:  true,
+			name:        "web limit reached starts regeneration",
+			days:        30,
+			existing:    []KeyInfo{webKey("aaaaaaaa1"), webKey("bbbbbbbb2"), webKey("cccccccc3")},
+			wantLimit:   true,
+			expectedMsg: "Do you want to regenerate an existing one?",
 		},
 		{
-			name:   "web at limit - asks to regenerate",
-			answer: "Web",
-			existingKeys: []KeyInfo{
-				{KeyID: "key1", Type: TokenTypeWeb, ExpiresAt: time.Now().Add(24 * time.Hour)},
-				{KeyID: "key2", Type: TokenTypeWeb, ExpiresAt: time.Now().Add(24 * time.Hour)},
-				{KeyID: "key3", Type: TokenTypeWeb, ExpiresAt: time.Now().Add(24 * time.Hour)},
-			},
-			expectedMsg:     "You've reached the maximum of 3 web tokens. Do you want to regenerate an existing one?",
-			expectedAnswers: []string{"Yes", "No"},
-			expectSaveConv:  true,
+			name:        "provider error",
+			days:        30,
+			generateErr: errors.New("api error"),
+			expectedErr: "failed to generate token: api error",
+		},
+		{name: "zero days", days: 0, wantInvalid: true},
+		{name: "above maximum", days: defaultMaxExpirationDays + 1, wantInvalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			prov := NewMockMITProv(t)
+
+			if !tt.wantInvalid {
+				expectUserLock(repo, userID)
+				repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+				repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(tt.existing, nil)
+			}
+
+			switch {
+			case tt.wantInvalid:
+			case tt.wantLimit:
+				repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+				repo.On("SaveConversation", mock.Anything, mock.AnythingOfType("*conv.Conversation")).Return(nil)
+			case tt.generateErr != nil:
+				prov.On("GenerateToken", mock.Anything, "", TokenTypeWeb, int64(tt.days)*secondsInDay).Return(nil, tt.generateErr)
+			default:
+				prov.On("GenerateToken", mock.Anything, "", TokenTypeWeb, int64(tt.days)*secondsInDay).Return(token, nil)
+				repo.On("AddAPIKeyWithLimit", mock.Anything, userID, "key123", TokenTypeWeb, "", token.ExpiresIn, 3).Return(nil)
+				expectAudit(repo, userID, AuditActionCreate, "key123")
+				repo.On("IncrementTokenCreationCount", mock.Anything, userID, rateLimitWindow).Return(1, nil)
+				expectTimezone(repo, userID)
+			}
+
+			svc := newTestService(t, Config{}, repo, prov)
+
+			resp, err := svc.CreateTokenWithExpiration(context.Background(), userID, tt.days)
+
+			switch {
+			case tt.wantInvalid:
+				assert.ErrorIs(t, err, ErrInvalidExpirationPeriod)
+				assert.Nil(t, resp)
+			case tt.expectedErr != "":
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, resp)
+			default:
+				require.NoError(t, err)
+				assert.Contains(t, resp.Message, tt.expectedMsg)
+				assert.Contains(t, resp.Message, tt.wantExpiry)
+			}
+		})
+	}
+}
+
+func TestNewTokenFlow(t *testing.T) {
+	userID := "user123"
+	key := func(id string, tokenType TokenType) KeyInfo {
+		return KeyInfo{KeyID: id, Type: tokenType, ExpiresAt: time.Now().Add(24 * time.Hour)}
+	}
+	webKeys := []KeyInfo{key("key1", TokenTypeWeb), key("key2", TokenTypeWeb), key("key3", TokenTypeWeb)}
+
+	tests := []struct {
+		name          string
+		wantKeyID     string
+		wantName      string
+		wantMsg       string
+		wantType      TokenType
+		defaultDays   string
+		existing      []KeyInfo
+		answers       []string
+		wantQuestions []string
+		wantDays      int64
+	}{
+		{
+			name:          "web token with subdomain and label",
+			answers:       []string{"Web", "myapp", "7 days", "  home server "},
+			wantQuestions: []string{keyIDQuestion, expirationQuestion, tokenNameQuestion},
+			wantType:      TokenTypeWeb,
+			wantKeyID:     "myapp",
+			wantDays:      7,
+			wantName:      "home server",
 		},
 		{
-			name:   "TCP at limit - asks to regenerate",
-			answer: "TCP",
-			existingKeys: []KeyInfo{
-				{KeyID: "tcpkey1", Type: TokenTypeTCP, ExpiresAt: time.Now().Add(24 * time.Hour)},
-			},
-			expectedMsg:     "You've reached the maximum of 1 TCP token. Do you want to regenerate it?",
-			expectedAnswers: []string{"Yes", "No"},
-			expectSaveConv:  true,
+			name:          "web token with generated subdomain",
+			answers:       []string{"Web", "Skip", "1 day", "Skip"},
+			wantQuestions: []string{keyIDQuestion, expirationQuestion, tokenNameQuestion},
+			wantType:      TokenTypeWeb,
+			wantDays:      1,
 		},
 		{
-			name:         "invalid type selection",
-			answer:       "FTP",
-			existingKeys: []KeyInfo{},
-			expectedMsg:  "Invalid token type selected. Please choose Web or TCP.",
+			name:          "TCP token skips the subdomain",
+			answers:       []string{"TCP", "30 days", "db"},
+			wantQuestions: []string{expirationQuestion, tokenNameQuestion},
+			wantType:      TokenTypeTCP,
+			wantDays:      30,
+			wantName:      "db",
 		},
 		{
-			name:        "wrong number of answers",
-			answer:      "",
-			expectedErr: "expected exactly one answer for token type question, got 0",
+			name:          "web tokens at the limit do not count for TCP",
+			existing:      webKeys,
+			answers:       []string{"TCP", "7 days", "Skip"},
+			wantQuestions: []string{expirationQuestion, tokenNameQuestion},
+			wantType:      TokenTypeTCP,
+			wantDays:      7,
 		},
 		{
-			name:        "get keys error",
-			answer:      "Web",
-			getKeysErr:  errors.New("redis error"),
-			expectedErr: "failed to get API keys: redis error",
+			name:          "default expiration skips the question",
+			defaultDays:   "30",
+			answers:       []string{"Web", "myapp", "Skip"},
+			wantQuestions: []string{keyIDQuestion, tokenNameQuestion},
+			wantType:      TokenTypeWeb,
+			wantKeyID:     "myapp",
+			wantDays:      30,
+		},
+		{
+			name:          "default expiration for a TCP token",
+			defaultDays:   "14",
+			answers:       []string{"TCP", "db"},
+			wantQuestions: []string{tokenNameQuestion},
+			wantType:      TokenTypeTCP,
+			wantDays:      14,
+			wantName:      "db",
+		},
+		{
+			name:          "default above the maximum is ignored",
+			defaultDays:   "400",
+			answers:       []string{"TCP", "7 days", "Skip"},
+			wantQuestions: []string{expirationQuestion, tokenNameQuestion},
+			wantType:      TokenTypeTCP,
+			wantDays:      7,
+		},
+		{
+			name:     "web at limit asks to regenerate",
+			existing: webKeys,
+			answers:  []string{"Web"},
+			wantMsg:  "You've reached the maximum of 3 web tokens. Do you want to regenerate an existing one?",
+		},
+		{
+			name:     "TCP at limit asks to regenerate",
+			existing: []KeyInfo{key("tcpkey1", TokenTypeTCP)},
+			answers:  []string{"TCP"},
+			wantMsg:  "You've reached the maximum of 1 TCP token. Do you want to regenerate it?",
 		},
 	}
 
@@ -150,46 +282,134 @@ func TestHandleSelectTokenTypeResult(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := NewMockUserRepo(t)
 			prov := NewMockMITProv(t)
-
-			var answers []conv.QuestionAnswer
-			if tt.answer != "" {
-				answers = []conv.QuestionAnswer{{Answer: tt.answer}}
+			c := conv.New(userID)
+
+			expectUserLock(repo, userID)
+			repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+			repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(tt.existing, nil)
+			repo.On("GetConversation", mock.Anything, userID).Return(c, nil)
+			repo.On("SaveConversation", mock.Anything, c).Return(nil)
+			expectDefaultExpiration(repo, userID, tt.defaultDays)
+			expectAnswerMarkers(repo, userID)
+
+			if tt.wantType != "" {
+				token := &APIToken{KeyID: "newkey", Token: "token123", ExpiresIn: time.Duration(tt.wantDays) * 24 * time.Hour}
+
+				prov.On("GenerateToken", mock.Anything, tt.wantKeyID, tt.wantType, tt.wantDays*secondsInDay).Return(token, nil)
+				repo.On("AddAPIKeyWithLimit", mock.Anything, userID, "newkey", tt.wantType, tt.wantName, token.ExpiresIn, newTestService(t, Config{}, nil, nil).maxTokensForType(tt.wantType)).Return(nil)
+				expectAudit(repo, userID, AuditActionCreate, "newkey")
+				repo.On("IncrementTokenCreationCount", mock.Anything, userID, rateLimitWindow).Return(1, nil)
+				expectTimezone(repo, userID)
 			}
 
-			if tt.answer != "" && tt.answer != "FTP" && tt.expectedErr == "" {
-				repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(tt.existingKeys, tt.getKeysErr)
-			} else if tt.getKeysErr != nil {
-				repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(nil, tt.getKeysErr)
+			svc := newTestService(t, Config{}, repo, prov)
+
+			resp, err := svc.CreateToken(context.Background(), userID)
+			require.NoError(t, err)
+			assert.Equal(t, tokenTypeQuestion, resp.Message)
+			assert.Equal(t, []string{"Web", "TCP"}, resp.Answers)
+
+			var questions []string
+
+			for i, answer := range tt.answers {
+				resp, err = svc.HandleMessage(context.Background(), userID, answer)
+				require.NoError(t, err)
+
+				if i < len(tt.answers)-1 {
+					questions = append(questions, resp.Message)
+				}
 			}
 
-			if tt.expectSaveConv {
-				repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
-				repo.On("SaveConversation", mock.Anything, mock.AnythingOfType("*conv.Conversation")).Return(tt.saveConvErr)
+			assert.Equal(t, tt.wantQuestions, questions)
+
+			if tt.wantType == "" {
+				assert.Equal(t, tt.wantMsg, resp.Message)
+				assert.Equal(t, StateTokenExists, c.State)
+
+				return
 			}
 
-			svc := New(repo, prov)
+			assert.Contains(t, resp.Message, "token123")
+			assert.False(t, c.IsActive())
+		})
+	}
+}
 
-			resp, err := svc.handleSelectTokenTypeResult(context.Background(), userID, answers)
+func TestHandleNewTokenResult_TokenLimit(t *testing.T) {
+	userID := "user123"
+	expiration := expirationAnswer("7 days", "")
 
-			if tt.expectedErr != "" {
-				require.Error(t, err)
-				assert.Contains(t, err.Error(), tt.expectedErr)
-				assert.Nil(t, resp)
-			} else {
-				require.NoError(t, err)
-				require.NotNil(t, resp)
-				assert.Equal(t, tt.expectedMsg, resp.Message)
-				if tt.expectedAnswers != nil {
-					assert.Equal(t, tt.expectedAnswers, resp.Answers)
-				}
+	tests := []struct {
+		name     string
+		cfg      Config
+		answer   string
+		keyType  TokenType
+		wantText string
+	}{
+		{
+			name:     "three TCP tokens",
+			cfg:      Config{MaxTCPTokens: 3},
+			answer:   "TCP",
+			keyType:  TokenTypeTCP,
+			wantText: "You've reached the maximum of 3 TCP tokens. Do you want to regenerate an existing one?",
+		},
+		{
+			name:     "single web token",
+			cfg:      Config{MaxWebTokens: 1},
+			answer:   "Web",
+			keyType:  TokenTypeWeb,
+			wantText: "You've reached the maximum of 1 web token. Do you want to regenerate it?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(t, tt.cfg, nil, nil)
+			limit := svc.maxTokensForType(tt.keyType)
+
+			keys := make([]KeyInfo, 0, limit)
+			for i := range limit {
+				keys = append(keys, KeyInfo{KeyID: fmt.Sprintf("key%d", i), Type: tt.keyType, ExpiresAt: time.Now().Add(time.Hour)})
 			}
 
-			repo.AssertExpectations(t)
-			prov.AssertExpectations(t)
+			// The limit is checked again once all questions are answered, a token may have been created meanwhile.
+			repo := NewMockUserRepo(t)
+			repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(keys, nil)
+			repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+			repo.On("SaveConversation", mock.Anything, mock.AnythingOfType("*conv.Conversation")).Return(nil)
+
+			svc.repo = repo
+
+			resp, err := svc.handleNewTokenResult(context.Background(), userID, []conv.QuestionAnswer{typeAnswer(tt.answer), expiration})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Message)
+
+			// A token freed after the questions ended at the type question brings the user to the expiration question.
+			repo = NewMockUserRepo(t)
+			repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(keys[:limit-1], nil)
+			expectDefaultExpiration(repo, userID, "")
+			repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+			repo.On("SaveConversation", mock.Anything, mock.AnythingOfType("*conv.Conversation")).Return(nil)
+
+			svc.repo = repo
+
+			resp, err = svc.handleNewTokenResult(context.Background(), userID, []conv.QuestionAnswer{typeAnswer(tt.answer)})
+			require.NoError(t, err)
+			assert.Equal(t, expirationQuestion, resp.Message)
 		})
 	}
 }
 
+func TestNew_TokenLimits(t *testing.T) {
+	svc := newTestService(t, Config{}, nil, nil)
+	assert.Equal(t, defaultMaxWebTokensPerUser, svc.maxTokensForType(TokenTypeWeb))
+	assert.Equal(t, defaultMaxTCPTokensPerUser, svc.maxTokensForType(TokenTypeTCP))
+
+	svc = newTestService(t, Config{MaxWebTokens: 5, MaxTCPTokens: 2}, nil, nil)
+	assert.Equal(t, 5, svc.maxTokensForType(TokenTypeWeb))
+	assert.Equal(t, 2, svc.maxTokensForType(TokenTypeTCP))
+}
+
 func TestHandleTokenExistsResult(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -223,7 +443,7 @@ func TestHandleTokenExistsResult(t *testing.T) {
 			repo := NewMockUserRepo(t)
 			prov := NewMockMITProv(t)
 
-			svc := New(repo, prov)
+			svc := newTestService(t, Config{}, repo, prov)
 
 			resp, err := svc.handleTokenExistsResult(context.Background(), tt.userID, tt.answers)
 
@@ -259,7 +479,7 @@ func TestHandleTokenExistsResult_Yes(t *testing.T) {
 		return c.ID == userID && c.State == StateSelectTokenToRegenerate
 	})).Return(nil)
 
-	svc := New(repo, prov)
+	svc := newTestService(t, Config{}, repo, prov)
 
 	answers := []conv.QuestionAnswer{
 		{Answer: "Yes", Field: string(TokenTypeWeb)},
@@ -292,7 +512,7 @@ func TestHandleTokenExistsResult_Yes_TCP(t *testing.T) {
 		return c.ID == userID && c.State == StateTokenRegenerate
 	})).Return(nil)
 
-	svc := New(repo, prov)
+	svc := newTestService(t, Config{}, repo, prov)
 
 	answers := []conv.QuestionAnswer{
 		{Answer: "Yes", Field: string(TokenTypeTCP)},
@@ -302,7 +522,7 @@ func TestHandleTokenExistsResult_Yes_TCP(t *testing.T) {
 
 	require.NoError(t, err)
 	require.NotNil(t, resp)
-	assert.Equal(t, "What is the expiration period for your new API token?", resp.Message)
+	assert.Equal(t, expirationQuestion, resp.Message)
 	assert.Equal(t, []string{"1 day", "7 days", "30 days", "90 days"}, resp.Answers)
 
 	repo.AssertExpectations(t)
@@ -324,7 +544,7 @@ func TestHandleNewTokenResult(t *testing.T) {
 			name:   "success - 7 days",
 			userID: "user123",
 			answers: []conv.QuestionAnswer{
-				{Answer: "7 days"},
+				expirationAnswer("7 days", ""),
 			},
 			token: &APIToken{
 				KeyID:     "key123",
@@ -337,21 +557,21 @@ func TestHandleNewTokenResult(t *testing.T) {
 			name:   "invalid expiration period",
 			userID: "user123",
 			answers: []conv.QuestionAnswer{
-				{Answer: "invalid"},
+				expirationAnswer("invalid", ""),
 			},
-			expectedMsg: "Invalid expiration period selected.",
+			expectedMsg: "Invalid expiration period.",
 		},
 		{
 			name:        "empty answers",
 			userID:      "user123",
 			answers:     []conv.QuestionAnswer{},
-			expectedErr: "expected at least one answer for expiration question, got 0",
+			expectedErr: "missing answer to the expiration question",
 		},
 		{
 			name:   "generate token error",
 			userID: "user123",
 			answers: []conv.QuestionAnswer{
-				{Answer: "1 day"},
+				expirationAnswer("1 day", ""),
 			},
 			generateErr: errors.New("generate error"),
 			expectedErr: "failed to generate token: generate error",
@@ -360,7 +580,7 @@ func TestHandleNewTokenResult(t *testing.T) {
 			name:   "add key error",
 			userID: "user123",
 			answers: []conv.QuestionAnswer{
-				{Answer: "30 days"},
+				expirationAnswer("30 days", ""),
 			},
 			token: &APIToken{
 				KeyID:     "key123",
@@ -378,14 +598,21 @@ func TestHandleNewTokenResult(t *testing.T) {
 			prov := NewMockMITProv(t)
 
 			if tt.token != nil || tt.generateErr != nil {
-				prov.On("GenerateToken", "", TokenTypeWeb, mock.AnythingOfType("int64")).Return(tt.token, tt.generateErr)
+				repo.On("GetTokenCreationCount", mock.Anything, tt.userID).Return(0, time.Time{}, nil)
+				prov.On("GenerateToken", mock.Anything, "", TokenTypeWeb, mock.AnythingOfType("int64")).Return(tt.token, tt.generateErr)
 			}
 
 			if tt.token != nil && tt.generateErr == nil {
-				repo.On("AddAPIKey", mock.Anything, tt.userID, tt.token.KeyID, TokenTypeWeb, tt.token.ExpiresIn).Return(tt.addKeyErr)
+				repo.On("AddAPIKeyWithLimit", mock.Anything, tt.userID, tt.token.KeyID, TokenTypeWeb, "", tt.token.ExpiresIn, 3).Return(tt.addKeyErr)
+			}
+
+			if tt.token != nil && tt.generateErr == nil && tt.addKeyErr == nil {
+				expectAudit(repo, tt.userID, AuditActionCreate, tt.token.KeyID)
+				repo.On("IncrementTokenCreationCount", mock.Anything, tt.userID, rateLimitWindow).Return(1, nil)
+				expectTimezone(repo, tt.userID)
 			}
 
-			svc := New(repo, prov)
+			svc := newTestService(t, Config{}, repo, prov)
 
 			resp, err := svc.handleNewTokenResult(context.Background(), tt.userID, tt.answers)
 
@@ -419,15 +646,24 @@ func TestHandleTokenRegenerateResult(t *testing.T) {
 			ExpiresIn: 7 * 24 * time.Hour,
 		}
 
-		prov.On("RevokeToken", keyID).Return(nil)
+		repo.On("GetLastRegeneration", mock.Anything, userID).Return(time.Time{}, nil)
+		repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return([]KeyInfo{
+			{KeyID: keyID, Name: "home server", Type: TokenTypeWeb, ExpiresAt: time.Now().Add(time.Hour)},
+		}, nil)
+		repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+		prov.On("RevokeToken", mock.Anything, keyID).Return(nil)
 		repo.On("RevokeToken", mock.Anything, userID, keyID).Return(nil)
-		prov.On("GenerateToken", keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(token, nil)
-		repo.On("AddAPIKey", mock.Anything, userID, keyID, TokenTypeWeb, token.ExpiresIn).Return(nil)
+		prov.On("GenerateToken", mock.Anything, keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(token, nil)
+		repo.On("AddAPIKey", mock.Anything, userID, keyID, TokenTypeWeb, "home server", token.ExpiresIn).Return(nil)
+		expectAudit(repo, userID, AuditActionRegenerate, keyID)
+		repo.On("SetLastRegeneration", mock.Anything, userID, mock.AnythingOfType("time.Time"), defaultRegenerateCooldown).Return(nil)
+		repo.On("IncrementTokenCreationCount", mock.Anything, userID, rateLimitWindow).Return(1, nil)
+		expectTimezone(repo, userID)
 
-		svc := New(repo, prov)
+		svc := newTestService(t, Config{}, repo, prov)
 
 		answers := []conv.QuestionAnswer{
-			{Answer: "7 days", Field: encodeTokenField(TokenTypeWeb, keyID)},
+			expirationAnswer("7 days", encodeTokenField(TokenTypeWeb, keyID)),
 		}
 
 		resp, err := svc.handleTokenRegenerateResult(context.Background(), userID, answers)
@@ -435,16 +671,75 @@ func TestHandleTokenRegenerateResult(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, resp)
 		assert.Contains(t, resp.Message, "Your New API Token")
+		assert.True(t, resp.Markdown)
 
 		repo.AssertExpectations(t)
 		prov.AssertExpectations(t)
 	})
 
+	t.Run("falls back to a generated key ID when the old one is rejected", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		prov := NewMockMITProv(t)
+
+		token := &APIToken{
+			KeyID:     "generated",
+			Token:     "newtoken",
+			ExpiresIn: 7 * 24 * time.Hour,
+		}
+
+		repo.On("GetLastRegeneration", mock.Anything, userID).Return(time.Time{}, nil)
+		repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return([]KeyInfo{
+			{KeyID: keyID, Name: "home server", Type: TokenTypeWeb, ExpiresAt: time.Now().Add(time.Hour)},
+		}, nil)
+		repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+		prov.On("RevokeToken", mock.Anything, keyID).Return(nil)
+		repo.On("RevokeToken", mock.Anything, userID, keyID).Return(nil)
+		prov.On("GenerateToken", mock.Anything, keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(nil, ErrDuplicateKeyID)
+		prov.On("GenerateToken", mock.Anything, "", TokenTypeWeb, mock.AnythingOfType("int64")).Return(token, nil)
+		repo.On("AddAPIKey", mock.Anything, userID, "generated", TokenTypeWeb, "home server", token.ExpiresIn).Return(nil)
+		expectAudit(repo, userID, AuditActionRegenerate, "generated")
+		repo.On("SetLastRegeneration", mock.Anything, userID, mock.AnythingOfType("time.Time"), defaultRegenerateCooldown).Return(nil)
+		repo.On("IncrementTokenCreationCount", mock.Anything, userID, rateLimitWindow).Return(1, nil)
+		expectTimezone(repo, userID)
+
+		svc := newTestService(t, Config{}, repo, prov)
+
+		answers := []conv.QuestionAnswer{
+			expirationAnswer("7 days", encodeTokenField(TokenTypeWeb, keyID)),
+		}
+
+		resp, err := svc.handleTokenRegenerateResult(context.Background(), userID, answers)
+
+		require.NoError(t, err)
+		assert.Contains(t, resp.Message, "newtoken")
+	})
+
+	t.Run("generate error", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		prov := NewMockMITProv(t)
+
+		repo.On("GetLastRegeneration", mock.Anything, userID).Return(time.Time{}, nil)
+		repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return([]KeyInfo{{KeyID: keyID, Type: TokenTypeWeb}}, nil)
+		repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+		prov.On("RevokeToken", mock.Anything, keyID).Return(nil)
+		repo.On("RevokeToken", mock.Anything, userID, keyID).Return(nil)
+		prov.On("GenerateToken", mock.Anything, keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(nil, assert.AnError)
+
+		svc := newTestService(t, Config{}, repo, prov)
+
+		answers := []conv.QuestionAnswer{
+			expirationAnswer("7 days", encodeTokenField(TokenTypeWeb, keyID)),
+		}
+
+		_, err := svc.handleTokenRegenerateResult(context.Background(), userID, answers)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+
 	t.Run("missing key ID in field", func(t *testing.T) {
-		svc := New(NewMockUserRepo(t), NewMockMITProv(t))
+		svc := newTestService(t, Config{}, NewMockUserRepo(t), NewMockMITProv(t))
 
 		answers := []conv.QuestionAnswer{
-			{Answer: "7 days", Field: encodeTokenField(TokenTypeWeb, "")},
+			expirationAnswer("7 days", encodeTokenField(TokenTypeWeb, "")),
 		}
 
 		_, err := svc.handleTokenRegenerateResult(context.Background(), userID, answers)
@@ -453,10 +748,10 @@ func TestHandleTokenRegenerateResult(t *testing.T) {
 	})
 
 	t.Run("invalid expiration period", func(t *testing.T) {
-		svc := New(NewMockUserRepo(t), NewMockMITProv(t))
+		svc := newTestService(t, Config{}, NewMockUserRepo(t), NewMockMITProv(t))
 
 		answers := []conv.QuestionAnswer{
-			{Answer: "invalid", Field: encodeTokenField(TokenTypeWeb, keyID)},
+			expirationAnswer("invalid", encodeTokenField(TokenTypeWeb, keyID)),
 		}
 
 		resp, err := svc.handleTokenRegenerateResult(context.Background(), userID, answers)
@@ -481,7 +776,7 @@ func TestHandleSelectTokenToRegenerateResult(t *testing.T) {
 			return c.State == StateTokenRegenerate
 		})).Return(nil)
 
-		svc := New(repo, prov)
+		svc := newTestService(t, Config{}, repo, prov)
 
 		answers := []conv.QuestionAnswer{
 			{Answer: keyID[:keyIDDisplayLen] + " (exp: 2026-03-01)", Field: string(TokenTypeWeb)},
@@ -491,14 +786,14 @@ func TestHandleSelectTokenToRegenerateResult(t *testing.T) {
 
 		require.NoError(t, err)
 		require.NotNil(t, resp)
-		assert.Equal(t, "What is the expiration period for your new API token?", resp.Message)
+		assert.Equal(t, expirationQuestion, resp.Message)
 
 		repo.AssertExpectations(t)
 		prov.AssertExpectations(t)
 	})
 
 	t.Run("wrong number of answers", func(t *testing.T) {
-		svc := New(NewMockUserRepo(t), NewMockMITProv(t))
+		svc := newTestService(t, Config{}, NewMockUserRepo(t), NewMockMITProv(t))
 		_, err := svc.handleSelectTokenToRegenerateResult(context.Background(), userID, nil)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "expected exactly one answer")
@@ -564,7 +859,7 @@ func TestHandleEnterKeyIDResult(t *testing.T) {
 			answers: []conv.QuestionAnswer{
 				{Answer: "my-homelab-web", Field: string(TokenTypeWeb)},
 			},
-			expectedMsg:     "What is the expiration period for your new API token?",
+			expectedMsg:     expirationQuestion,
 			expectedAnswers: []string{"1 day", "7 days", "30 days", "90 days"},
 		},
 		{
@@ -572,7 +867,7 @@ func TestHandleEnterKeyIDResult(t *testing.T) {
 			answers: []conv.QuestionAnswer{
 				{Answer: "Skip", Field: string(TokenTypeWeb)},
 			},
-			expectedMsg:     "What is the expiration period for your new API token?",
+			expectedMsg:     expirationQuestion,
 			expectedAnswers: []string{"1 day", "7 days", "30 days", "90 days"},
 		},
 		{
@@ -580,7 +875,7 @@ func TestHandleEnterKeyIDResult(t *testing.T) {
 			answers: []conv.QuestionAnswer{
 				{Answer: "my-tcp-key", Field: string(TokenTypeTCP)},
 			},
-			expectedMsg:     "What is the expiration period for your new API token?",
+			expectedMsg:     expirationQuestion,
 			expectedAnswers: []string{"1 day", "7 days", "30 days", "90 days"},
 		},
 		{
@@ -602,7 +897,7 @@ func TestHandleEnterKeyIDResult(t *testing.T) {
 				})).Return(tt.saveConvErr)
 			}
 
-			svc := New(repo, prov)
+			svc := newTestService(t, Config{}, repo, prov)
 
 			resp, err := svc.handleEnterKeyIDResult(context.Background(), userID, tt.answers)
 
@@ -625,6 +920,84 @@ func TestHandleEnterKeyIDResult(t *testing.T) {
 	}
 }
 
+func TestHandleNewTokenResult_Name(t *testing.T) {
+	userID := "user123"
+
+	tests := []struct {
+		name         string
+		nameAnswer   string
+		expectedName string
+		labelFirst   bool
+	}{
+		{name: "label is stored", nameAnswer: "  home server ", expectedName: "home server"},
+		{name: "skip leaves token unnamed", nameAnswer: "Skip", expectedName: ""},
+		{name: "answers are found by question ID", nameAnswer: "home server", expectedName: "home server", labelFirst: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			prov := NewMockMITProv(t)
+
+			token := &APIToken{KeyID: "key123", Token: "token123", ExpiresIn: 24 * time.Hour}
+
+			repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+			prov.On("GenerateToken", mock.Anything, "", TokenTypeWeb, int64(secondsInDay)).Return(token, nil)
+			repo.On("AddAPIKeyWithLimit", mock.Anything, userID, "key123", TokenTypeWeb, tt.expectedName, token.ExpiresIn, 3).Return(nil)
+			expectAudit(repo, userID, AuditActionCreate, "key123")
+			repo.On("IncrementTokenCreationCount", mock.Anything, userID, rateLimitWindow).Return(1, nil)
+			expectTimezone(repo, userID)
+
+			svc := newTestService(t, Config{}, repo, prov)
+
+			answers := []conv.QuestionAnswer{
+				expirationAnswer("1 day", encodeTokenField(TokenTypeWeb, "")),
+				{Answer: tt.nameAnswer, Question: conv.Question{ID: questionIDLabel}},
+			}
+
+			if tt.labelFirst {
+				answers[0], answers[1] = answers[1], answers[0]
+			}
+
+			resp, err := svc.handleNewTokenResult(context.Background(), userID, answers)
+
+			require.NoError(t, err)
+			assert.Contains(t, resp.Message, "token123")
+		})
+	}
+}
+
+func TestAskForTokenExpirationWithKeyID_AsksForNameOnlyForNewTokens(t *testing.T) {
+	userID := "user123"
+
+	tests := []struct {
+		state             conv.State
+		name              string
+		expectedQuestions int
+	}{
+		{name: "new token asks for label", state: StateNewToken, expectedQuestions: 2},
+		{name: "regenerate keeps existing label", state: StateTokenRegenerate, expectedQuestions: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+
+			repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+			repo.On("SaveConversation", mock.Anything, mock.MatchedBy(func(c *conv.Conversation) bool {
+				return len(c.Questions.QAPairs) == tt.expectedQuestions
+			})).Return(nil)
+
+			svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+			resp, err := svc.askForTokenExpirationWithKeyID(context.Background(), userID, tt.state, TokenTypeWeb, "key123")
+
+			require.NoError(t, err)
+			assert.Equal(t, expirationQuestion, resp.Message)
+		})
+	}
+}
+
 func TestHandleNewTokenResult_CustomKeyID(t *testing.T) {
 	userID := "user123"
 	keyID := "my-homelab-web"
@@ -639,13 +1012,17 @@ func TestHandleNewTokenResult_CustomKeyID(t *testing.T) {
 			ExpiresIn: 7 * 24 * time.Hour,
 		}
 
-		mockProv.On("GenerateToken", keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(token, nil)
-		repo.On("AddAPIKey", mock.Anything, userID, keyID, TokenTypeWeb, token.ExpiresIn).Return(nil)
+		repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+		mockProv.On("GenerateToken", mock.Anything, keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(token, nil)
+		repo.On("AddAPIKeyWithLimit", mock.Anything, userID, keyID, TokenTypeWeb, "", token.ExpiresIn, 3).Return(nil)
+		expectAudit(repo, userID, AuditActionCreate, keyID)
+		repo.On("IncrementTokenCreationCount", mock.Anything, userID, rateLimitWindow).Return(1, nil)
+		expectTimezone(repo, userID)
 
-		svc := New(repo, mockProv)
+		svc := newTestService(t, Config{}, repo, mockProv)
 
 		answers := []conv.QuestionAnswer{
-			{Answer: "7 days", Field: encodeTokenField(TokenTypeWeb, keyID)},
+			expirationAnswer("7 days", encodeTokenField(TokenTypeWeb, keyID)),
 		}
 
 		resp, err := svc.handleNewTokenResult(context.Background(), userID, answers)
@@ -653,6 +1030,7 @@ func TestHandleNewTokenResult_CustomKeyID(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, resp)
 		assert.Contains(t, resp.Message, "Your New API Token")
+		assert.True(t, resp.Markdown)
 
 		repo.AssertExpectations(t)
 		mockProv.AssertExpectations(t)
@@ -662,16 +1040,17 @@ func TestHandleNewTokenResult_CustomKeyID(t *testing.T) {
 		repo := NewMockUserRepo(t)
 		mockProv := NewMockMITProv(t)
 
-		mockProv.On("GenerateToken", keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(nil, ErrDuplicateKeyID)
+		repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+		mockProv.On("GenerateToken", mock.Anything, keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(nil, ErrDuplicateKeyID)
 		repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
 		repo.On("SaveConversation", mock.Anything, mock.MatchedBy(func(c *conv.Conversation) bool {
 			return c.State == StateEnterKeyID
 		})).Return(nil)
 
-		svc := New(repo, mockProv)
+		svc := newTestService(t, Config{}, repo, mockProv)
 
 		answers := []conv.QuestionAnswer{
-			{Answer: "7 days", Field: encodeTokenField(TokenTypeWeb, keyID)},
+			expirationAnswer("7 days", encodeTokenField(TokenTypeWeb, keyID)),
 		}
 
 		resp, err := svc.handleNewTokenResult(context.Background(), userID, answers)
@@ -684,20 +1063,40 @@ func TestHandleNewTokenResult_CustomKeyID(t *testing.T) {
 		mockProv.AssertExpectations(t)
 	})
 
+	t.Run("ttl outside of the provider range", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		mockProv := NewMockMITProv(t)
+
+		repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+		mockProv.On("GenerateToken", mock.Anything, keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(nil, ErrInvalidTTL)
+
+		svc := newTestService(t, Config{}, repo, mockProv)
+
+		answers := []conv.QuestionAnswer{
+			expirationAnswer("7 days", encodeTokenField(TokenTypeWeb, keyID)),
+		}
+
+		resp, err := svc.handleNewTokenResult(context.Background(), userID, answers)
+
+		require.NoError(t, err)
+		assert.Equal(t, ttlOutOfRangeMessage, resp.Message)
+	})
+
 	t.Run("invalid key ID format - re-asks for key ID", func(t *testing.T) {
 		repo := NewMockUserRepo(t)
 		mockProv := NewMockMITProv(t)
 
-		mockProv.On("GenerateToken", keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(nil, ErrInvalidKeyID)
+		repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+		mockProv.On("GenerateToken", mock.Anything, keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(nil, ErrInvalidKeyID)
 		repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
 		repo.On("SaveConversation", mock.Anything, mock.MatchedBy(func(c *conv.Conversation) bool {
 			return c.State == StateEnterKeyID
 		})).Return(nil)
 
-		svc := New(repo, mockProv)
+		svc := newTestService(t, Config{}, repo, mockProv)
 
 		answers := []conv.QuestionAnswer{
-			{Answer: "7 days", Field: encodeTokenField(TokenTypeWeb, keyID)},
+			expirationAnswer("7 days", encodeTokenField(TokenTypeWeb, keyID)),
 		}
 
 		resp, err := svc.handleNewTokenResult(context.Background(), userID, answers)
@@ -710,3 +1109,97 @@ func TestHandleNewTokenResult_CustomKeyID(t *testing.T) {
 		mockProv.AssertExpectations(t)
 	})
 }
+
+func TestParseExpirationAnswer(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		want    int64
+		wantErr bool
+	}{
+		{name: "preset option", answer: "30 days", want: 30 * secondsInDay},
+		{name: "single day", answer: "1 day", want: secondsInDay},
+		{name: "bare number", answer: "14", want: 14 * secondsInDay},
+		{name: "number with suffix", answer: " 14 Days ", want: 14 * secondsInDay},
+		{name: "capped to maximum", answer: "1000", want: defaultMaxExpirationDays * secondsInDay},
+		{name: "zero", answer: "0", wantErr: true},
+		{name: "negative", answer: "-3", wantErr: true},
+		{name: "not a number", answer: "forever", wantErr: true},
+	}
+
+	svc := newTestService(t, Config{}, NewMockUserRepo(t), NewMockMITProv(t))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.parseExpirationAnswer(tt.answer)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidExpirationPeriod)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseExpirationAnswer_ConfiguredMaximum(t *testing.T) {
+	svc := newTestService(t, Config{MaxExpirationDays: 10}, NewMockUserRepo(t), NewMockMITProv(t))
+
+	got, err := svc.parseExpirationAnswer("30 days")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(10*secondsInDay), got)
+}
+
+func TestHandleNewTokenResult_LimitReachedConcurrently(t *testing.T) {
+	userID := "user123"
+
+	token := &APIToken{
+		KeyID:     "key123",
+		Token:     "token-abc",
+		ExpiresIn: 7 * 24 * time.Hour,
+	}
+
+	answers := []conv.QuestionAnswer{
+		expirationAnswer("7 days", encodeTokenField(TokenTypeTCP, "")),
+	}
+
+	t.Run("discards the token and asks to regenerate", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		prov := NewMockMITProv(t)
+
+		repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+		prov.On("GenerateToken", mock.Anything, "", TokenTypeTCP, mock.AnythingOfType("int64")).Return(token, nil)
+		repo.On("AddAPIKeyWithLimit", mock.Anything, userID, "key123", TokenTypeTCP, "", token.ExpiresIn, 1).Return(ErrTokenLimitReached)
+		prov.On("RevokeToken", mock.Anything, "key123").Return(nil)
+		repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+		repo.On("SaveConversation", mock.Anything, mock.MatchedBy(func(c *conv.Conversation) bool {
+			return c.State == StateTokenExists
+		})).Return(nil)
+
+		svc := newTestService(t, Config{}, repo, prov)
+
+		resp, err := svc.handleNewTokenResult(context.Background(), userID, answers)
+
+		require.NoError(t, err)
+		assert.Equal(t, "You've reached the maximum of 1 TCP token. Do you want to regenerate it?", resp.Message)
+		assert.Equal(t, []string{"Yes", "No"}, resp.Answers)
+	})
+
+	t.Run("revoke failure", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		prov := NewMockMITProv(t)
+
+		repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+		prov.On("GenerateToken", mock.Anything, "", TokenTypeTCP, mock.AnythingOfType("int64")).Return(token, nil)
+		repo.On("AddAPIKeyWithLimit", mock.Anything, userID, "key123", TokenTypeTCP, "", token.ExpiresIn, 1).Return(ErrTokenLimitReached)
+		prov.On("RevokeToken", mock.Anything, "key123").Return(assert.AnError)
+
+		svc := newTestService(t, Config{}, repo, prov)
+
+		_, err := svc.handleNewTokenResult(context.Background(), userID, answers)
+
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
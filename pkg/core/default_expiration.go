@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+const (
+	// SettingDefaultExpiration is the user setting holding the number of days new tokens are valid for,
+	// which skips the expiration question of /new_token.
+	SettingDefaultExpiration = "default_ttl"
+
+	defaultExpirationSetMessage     = "⏱ New tokens are now valid for %s without asking. Use /clear_default_ttl to be asked again."
+	defaultExpirationClearedMessage = "⏱ Default expiration cleared, you'll be asked for the expiration period of new tokens again."
+)
+
+// SetDefaultExpiration stores the number of days new tokens of the user are valid for. Creating a token then skips
+// the expiration question, while an explicit /new_token argument still overrides the default.
+// Returns ErrInvalidExpirationPeriod if days is outside of 1..maxExpirationDays.
+func (s *Service) SetDefaultExpiration(ctx context.Context, userID string, days int) (*Response, error) {
+	if days <= 0 || days > s.maxExpirationDays {
+		return nil, ErrInvalidExpirationPeriod
+	}
+
+	if err := s.repo.SetUserSetting(ctx, userID, SettingDefaultExpiration, strconv.Itoa(days)); err != nil {
+		return nil, fmt.Errorf("failed to set default expiration: %w", err)
+	}
+
+	return &Response{
+		Message: fmt.Sprintf(defaultExpirationSetMessage, pluralize(days, "day")),
+	}, nil
+}
+
+// ClearDefaultExpiration removes the default expiration of the user, so that creating a token asks for it again.
+func (s *Service) ClearDefaultExpiration(ctx context.Context, userID string) (*Response, error) {
+	if err := s.repo.DeleteUserSetting(ctx, userID, SettingDefaultExpiration); err != nil {
+		return nil, fmt.Errorf("failed to clear default expiration: %w", err)
+	}
+
+	return &Response{
+		Message: defaultExpirationClearedMessage,
+	}, nil
+}
+
+// defaultExpiresIn returns the default expiration of the user in seconds, zero if the user has none.
+// The question is asked when the default can't be used, so a failure to read it is logged and ignored.
+// A default above the maximum expiration period, which may have been lowered since, is ignored as well.
+func (s *Service) defaultExpiresIn(ctx context.Context, userID string) int64 {
+	value, err := s.repo.GetUserSetting(ctx, userID, SettingDefaultExpiration)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get user default expiration", slog.String("user_id", userID), slog.Any("error", err))
+		return 0
+	}
+
+	if value == "" {
+		return 0
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 || days > s.maxExpirationDays {
+		slog.WarnContext(ctx, "Ignoring invalid user default expiration", slog.String("user_id", userID), slog.String("value", value))
+		return 0
+	}
+
+	return int64(days) * secondsInDay
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// expectDefaultExpiration makes the user's stored default expiration days, "" for none.
+func expectDefaultExpiration(repo *MockUserRepo, userID, days string) {
+	repo.On("GetUserSetting", mock.Anything, userID, SettingDefaultExpiration).Return(days, nil)
+}
+
+func TestSetDefaultExpiration(t *testing.T) {
+	userID := "user123"
+
+	tests := []struct {
+		setupMocks func(repo *MockUserRepo)
+		wantErr    error
+		name       string
+		wantMsg    string
+		days       int
+	}{
+		{
+			name: "stores the days",
+			days: 30,
+			setupMocks: func(repo *MockUserRepo) {
+				repo.On("SetUserSetting", mock.Anything, userID, SettingDefaultExpiration, "30").Return(nil)
+			},
+			wantMsg: "⏱ New tokens are now valid for 30 days without asking. Use /clear_default_ttl to be asked again.",
+		},
+		{
+			name: "maximum period",
+			days: defaultMaxExpirationDays,
+			setupMocks: func(repo *MockUserRepo) {
+				repo.On("SetUserSetting", mock.Anything, userID, SettingDefaultExpiration, "365").Return(nil)
+			},
+			wantMsg: "⏱ New tokens are now valid for 365 days without asking. Use /clear_default_ttl to be asked again.",
+		},
+		{
+			name:    "zero days",
+			days:    0,
+			wantErr: ErrInvalidExpirationPeriod,
+		},
+		{
+			name:    "above the maximum",
+			days:    defaultMaxExpirationDays + 1,
+			wantErr: ErrInvalidExpirationPeriod,
+		},
+		{
+			name: "repository error",
+			days: 7,
+			setupMocks: func(repo *MockUserRepo) {
+				repo.On("SetUserSetting", mock.Anything, userID, SettingDefaultExpiration, "7").Return(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			if tt.setupMocks != nil {
+				tt.setupMocks(repo)
+			}
+
+			svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+			resp, err := svc.SetDefaultExpiration(context.Background(), userID, tt.days)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, resp)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMsg, resp.Message)
+		})
+	}
+}
+
+func TestClearDefaultExpiration(t *testing.T) {
+	repo := NewMockUserRepo(t)
+	repo.On("DeleteUserSetting", mock.Anything, "user123", SettingDefaultExpiration).Return(nil).Once()
+	repo.On("DeleteUserSetting", mock.Anything, "user123", SettingDefaultExpiration).Return(assert.AnError).Once()
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	resp, err := svc.ClearDefaultExpiration(context.Background(), "user123")
+	require.NoError(t, err)
+	assert.Equal(t, defaultExpirationClearedMessage, resp.Message)
+
+	_, err = svc.ClearDefaultExpiration(context.Background(), "user123")
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ErrorContains(t, err, "failed to clear default expiration")
+}
+
+func TestDefaultExpiresIn(t *testing.T) {
+	tests := []struct {
+		err     error
+		name    string
+		value   string
+		maxDays int
+		want    int64
+	}{
+		{name: "not set", value: "", want: 0},
+		{name: "set", value: "30", want: 30 * secondsInDay},
+		{name: "not a number", value: "soon", want: 0},
+		{name: "above a lowered maximum", value: "10", maxDays: 7, want: 0},
+		{name: "repository error", err: assert.AnError, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			repo.On("GetUserSetting", mock.Anything, "user123", SettingDefaultExpiration).Return(tt.value, tt.err)
+
+			svc := newTestService(t, Config{MaxExpirationDays: tt.maxDays}, repo, NewMockMITProv(t))
+
+			assert.Equal(t, tt.want, svc.defaultExpiresIn(context.Background(), "user123"))
+		})
+	}
+}
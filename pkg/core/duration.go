@@ -0,0 +1,50 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// humanizeDuration renders the time left until an expiry in whole days and hours, e.g. "in 29 days 3 hours".
+// It is rounded to the minute first, so that a token created for 30 days reads "in 30 days" rather than
+// "in 29 days 23 hours". A shorter duration reads "in less than an hour" and a non-positive one "expired".
+func humanizeDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+
+	switch {
+	case d <= 0:
+		return "expired"
+	case d < time.Hour:
+		return "in less than an hour"
+	}
+
+	var parts []string
+
+	if days := int(d / day); days > 0 {
+		parts = append(parts, pluralize(days, "day"))
+	}
+
+	if hours := int(d % day / time.Hour); hours > 0 {
+		parts = append(parts, pluralize(hours, "hour"))
+	}
+
+	return "in " + strings.Join(parts, " ")
+}
+
+// pluralize formats n with unit, adding the plural "s" unless n is 1.
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// formatExpiryWithTimeLeft formats an expiry time in loc like formatExpiry, followed by the time left until it,
+// e.g. "2026-03-15 10:00:00 UTC (in 29 days)".
+func formatExpiryWithTimeLeft(t time.Time, loc *time.Location) string {
+	return fmt.Sprintf("%s (%s)", formatExpiry(t, loc), humanizeDuration(time.Until(t)))
+}
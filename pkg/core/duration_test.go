@@ -0,0 +1,40 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanizeDuration(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		d    time.Duration
+	}{
+		{name: "already expired", d: -time.Hour, want: "expired"},
+		{name: "expires now", d: 0, want: "expired"},
+		{name: "under a minute left rounds to expired", d: 20 * time.Second, want: "expired"},
+		{name: "sub-hour", d: 45 * time.Minute, want: "in less than an hour"},
+		{name: "one hour", d: time.Hour, want: "in 1 hour"},
+		{name: "hours", d: 5*time.Hour + 30*time.Minute, want: "in 5 hours"},
+		{name: "one day", d: day, want: "in 1 day"},
+		{name: "days and an hour", d: 2*day + time.Hour, want: "in 2 days 1 hour"},
+		{name: "multi-day", d: 29*day + 3*time.Hour, want: "in 29 days 3 hours"},
+		{name: "just under whole days rounds up", d: 30*day - time.Second, want: "in 30 days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, humanizeDuration(tt.d))
+		})
+	}
+}
+
+func TestFormatExpiryWithTimeLeft(t *testing.T) {
+	expiresAt := time.Now().Add(7 * day)
+
+	assert.Equal(t, formatExpiry(expiresAt, time.UTC)+" (in 7 days)", formatExpiryWithTimeLeft(expiresAt, time.UTC))
+	assert.Equal(t, "2020-01-02 03:04:05 UTC (expired)", formatExpiryWithTimeLeft(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), time.UTC))
+}
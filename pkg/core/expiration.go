@@ -0,0 +1,105 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultExpirationPresets are the expiration periods offered when Config.ExpirationPresets is unset.
+var defaultExpirationPresets = []string{"1 day", "7 days", "30 days", "90 days"}
+
+// expirationPresetPattern matches a preset period such as "12 hours" or "1 day".
+var expirationPresetPattern = regexp.MustCompile(`(?i)^\s*(\d+)\s*(hours?|days?)\s*$`)
+
+// expirationPreset is an expiration period offered as an answer button.
+// Label is both the button text and the answer it is recognized by.
+type expirationPreset struct {
+	Label  string
+	Period time.Duration
+}
+
+// parseExpirationPreset parses a preset period given as a number of hours or days, e.g. "12 hours" or "7 days".
+// The label is normalized, so that "7days" and "7 Days" are both offered as "7 days".
+func parseExpirationPreset(s string) (expirationPreset, error) {
+	m := expirationPresetPattern.FindStringSubmatch(s)
+	if m == nil {
+		return expirationPreset{}, fmt.Errorf("invalid expiration preset %q, expected a number of hours or days", s)
+	}
+
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n <= 0 {
+		return expirationPreset{}, fmt.Errorf("invalid expiration preset %q, the period must be positive", s)
+	}
+
+	if strings.HasPrefix(strings.ToLower(m[2]), "hour") {
+		return expirationPreset{Label: pluralize(n, "hour"), Period: time.Duration(n) * time.Hour}, nil
+	}
+
+	return expirationPreset{Label: pluralize(n, "day"), Period: time.Duration(n) * day}, nil
+}
+
+// newExpirationPresets parses the configured presets, dropping with a warning the ones that are invalid, duplicate
+// or longer than maxDays. The defaults are used when nothing is configured or nothing valid is left.
+func newExpirationPresets(labels []string, maxDays int) []expirationPreset {
+	if len(labels) == 0 {
+		labels = defaultExpirationPresets
+	}
+
+	presets := make([]expirationPreset, 0, len(labels))
+	seen := make(map[time.Duration]bool, len(labels))
+
+	for _, label := range labels {
+		p, err := parseExpirationPreset(label)
+
+		switch {
+		case err != nil:
+			slog.Warn("Ignoring expiration preset", slog.Any("error", err))
+			continue
+		case p.Period > time.Duration(maxDays)*day:
+			slog.Warn("Ignoring expiration preset above the maximum expiration period",
+				slog.String("preset", p.Label), slog.Int("max_expiration_days", maxDays))
+
+			continue
+		case seen[p.Period]:
+			continue
+		}
+
+		seen[p.Period] = true
+		presets = append(presets, p)
+	}
+
+	if len(presets) == 0 {
+		slog.Warn("No valid expiration presets configured, using defaults")
+
+		return newExpirationPresets(defaultExpirationPresets, maxDays)
+	}
+
+	return presets
+}
+
+// expirationAnswers returns the labels of the expiration presets, offered as answers to expiration questions.
+func (s *Service) expirationAnswers() []string {
+	answers := make([]string, len(s.expirationPresets))
+	for i, p := range s.expirationPresets {
+		answers[i] = p.Label
+	}
+
+	return answers
+}
+
+// presetPeriod returns the period of the expiration preset labeled answer, ignoring case and surrounding spaces.
+func (s *Service) presetPeriod(answer string) (time.Duration, bool) {
+	answer = strings.TrimSpace(answer)
+
+	for _, p := range s.expirationPresets {
+		if strings.EqualFold(p.Label, answer) {
+			return p.Period, true
+		}
+	}
+
+	return 0, false
+}
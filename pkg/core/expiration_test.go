@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpirationPreset(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		wantLabel  string
+		wantErr    string
+		wantPeriod time.Duration
+	}{
+		{name: "one hour", in: "1 hour", wantLabel: "1 hour", wantPeriod: time.Hour},
+		{name: "hours", in: "12 hours", wantLabel: "12 hours", wantPeriod: 12 * time.Hour},
+		{name: "one day", in: "1 day", wantLabel: "1 day", wantPeriod: day},
+		{name: "label is normalized", in: " 365Days ", wantLabel: "365 days", wantPeriod: 365 * day},
+		{name: "singular unit with plural count", in: "2 day", wantLabel: "2 days", wantPeriod: 2 * day},
+		{name: "zero", in: "0 days", wantErr: "must be positive"},
+		{name: "unknown unit", in: "2 weeks", wantErr: "expected a number of hours or days"},
+		{name: "bare number", in: "7", wantErr: "expected a number of hours or days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExpirationPreset(tt.in)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, expirationPreset{Label: tt.wantLabel, Period: tt.wantPeriod}, got)
+		})
+	}
+}
+
+func TestNewExpirationPresets(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  []string
+		want    []string
+		maxDays int
+	}{
+		{name: "defaults", maxDays: 365, want: []string{"1 day", "7 days", "30 days", "90 days"}},
+		{name: "custom", labels: []string{"1 hour", "12 hours", "365 days"}, maxDays: 365, want: []string{"1 hour", "12 hours", "365 days"}},
+		{name: "invalid and duplicate dropped", labels: []string{"forever", "1 day", "24 hours", "7 days"}, maxDays: 365, want: []string{"1 day", "7 days"}},
+		{name: "above maximum dropped", labels: []string{"7 days", "30 days"}, maxDays: 10, want: []string{"7 days"}},
+		{name: "nothing valid falls back to defaults", labels: []string{"forever"}, maxDays: 10, want: []string{"1 day", "7 days"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &Service{expirationPresets: newExpirationPresets(tt.labels, tt.maxDays)}
+
+			assert.Equal(t, tt.want, svc.expirationAnswers())
+		})
+	}
+}
+
+func TestExpirationPresets_RoundTrip(t *testing.T) {
+	userID := "user123"
+	presets := []string{"1 hour", "12 hours", "365 days"}
+	want := map[string]int64{
+		"1 hour":   3600,
+		"12 hours": 12 * 3600,
+		"365 days": 365 * secondsInDay,
+	}
+
+	ask := map[string]func(svc *Service) (*Response, error){
+		"new token": func(svc *Service) (*Response, error) {
+			return svc.askForTokenExpirationWithKeyID(context.Background(), userID, StateTokenRegenerate, TokenTypeWeb, "key123")
+		},
+		"renewal": func(svc *Service) (*Response, error) {
+			return svc.askForRenewalPeriod(context.Background(), userID, KeyInfo{KeyID: "key123", Type: TokenTypeWeb})
+		},
+	}
+
+	for name, askFn := range ask {
+		for _, answer := range presets {
+			t.Run(name+"/"+answer, func(t *testing.T) {
+				repo := NewMockUserRepo(t)
+
+				var saved *conv.Conversation
+
+				repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+				repo.On("SaveConversation", mock.Anything, mock.AnythingOfType("*conv.Conversation")).
+					Run(func(args mock.Arguments) { saved = args.Get(1).(*conv.Conversation) }).
+					Return(nil)
+
+				svc := newTestService(t, Config{ExpirationPresets: presets}, repo, NewMockMITProv(t))
+
+				resp, err := askFn(svc)
+				require.NoError(t, err)
+				assert.Equal(t, presets, resp.Answers)
+				require.NotNil(t, saved)
+
+				_, err = saved.Submit(answer)
+				require.NoError(t, err)
+
+				results, err := saved.Results()
+				require.NoError(t, err)
+
+				expiration, ok := conv.Lookup(results, questionIDExpiration)
+				require.True(t, ok)
+
+				got, err := svc.parseExpirationAnswer(expiration.Answer)
+				require.NoError(t, err)
+				assert.Equal(t, want[answer], got)
+			})
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+)
+
+const (
+	sessionExpiredMessage  = "That session expired, here's where we were:"
+	sessionNoTokensMessage = "That session expired, and you have no tokens left. Send /new_token to create one."
+)
+
+// RestartExpiredConversation starts the flow of the user's conversation again from its first question after
+// the conversation expired while waiting for an answer, so that a late answer isn't taken for a stray message.
+// Returns ErrNoActiveConversation if there is no expired conversation to restart, a *RateLimitedError if a token
+// creation flow can't be restarted because of the daily limit and ErrUserBusy while another request of the user
+// is being processed.
+func (s *Service) RestartExpiredConversation(ctx context.Context, userID string) (*Response, error) {
+	unlock, err := s.lockUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	flow, err := s.repo.GetExpiredFlow(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired conversation: %w", err)
+	}
+
+	restart := s.flowStarter(flow)
+	if restart == nil {
+		return nil, ErrNoActiveConversation
+	}
+
+	// Forget the expired flow first, so that it is restarted only once even if the flow doesn't start a conversation.
+	if err := s.repo.DeleteConversation(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete conversation: %w", err)
+	}
+
+	slog.DebugContext(ctx, "Restarting expired conversation", slog.String("user_id", userID), slog.String("flow", string(flow)))
+
+	resp, err := restart(ctx, userID)
+
+	switch {
+	case errors.Is(err, ErrTokenNotFound):
+		return &Response{Message: sessionNoTokensMessage}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	prefix := sessionExpiredMessage
+	if resp.Markdown {
+		prefix = EscapeMarkdown(prefix)
+	}
+
+	resp.Message = prefix + "\n\n" + resp.Message
+
+	return resp, nil
+}
+
+// flowStarter returns the function starting the conversation flow from its first question, nil for an unknown flow.
+// The steps of token creation and regeneration all go back to the start of /new_token, which asks again whether to
+// regenerate once a limit is reached.
+func (s *Service) flowStarter(flow conv.State) func(ctx context.Context, userID string) (*Response, error) {
+	switch flow {
+	case StateNewToken, StateEnterKeyID, StateTokenExists, StateSelectTokenToRegenerate, StateTokenRegenerate:
+		return s.createToken
+	case StateSelectTokenToRevoke:
+		return s.restartRevokeToken
+	case StateSelectTokenToRenew, StateRenewToken:
+		return s.RenewToken
+	case StateRevokeAll:
+		return s.ConfirmRevokeAll
+	case StateFeedback:
+		return s.askForFeedback
+	default:
+		return nil
+	}
+}
+
+// restartRevokeToken asks again which token to revoke. Unlike RevokeToken it never revokes a single token
+// straight away, the user has to confirm the choice after the break.
+func (s *Service) restartRevokeToken(ctx context.Context, userID string) (*Response, error) {
+	keys, err := s.repo.GetAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrTokenNotFound
+	}
+
+	return s.askToSelectTokenForRevocation(ctx, userID)
+}
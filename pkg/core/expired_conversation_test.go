@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartExpiredConversation(t *testing.T) {
+	userID := "user123"
+
+	t.Run("late answer restarts the flow", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		expectUserLock(repo, userID)
+
+		repo.On("GetExpiredFlow", mock.Anything, userID).Return(StateRevokeAll, nil)
+		repo.On("DeleteConversation", mock.Anything, userID).Return(nil)
+		repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{"key1", "key2"}, nil)
+		repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+		repo.On("SaveConversation", mock.Anything, mock.MatchedBy(func(c *conv.Conversation) bool {
+			return c.State == StateRevokeAll
+		})).Return(nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		resp, err := svc.RestartExpiredConversation(context.Background(), userID)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(resp.Message, sessionExpiredMessage+"\n\n"), resp.Message)
+		assert.Contains(t, resp.Message, "revoke all 2 of your tokens")
+		assert.Equal(t, []string{"Yes", "No"}, resp.Answers)
+	})
+
+	t.Run("revocation asks even for a single token", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		expectUserLock(repo, userID)
+
+		repo.On("GetExpiredFlow", mock.Anything, userID).Return(StateSelectTokenToRevoke, nil)
+		repo.On("DeleteConversation", mock.Anything, userID).Return(nil)
+		repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{"key1"}, nil)
+		repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return([]KeyInfo{{KeyID: "key1", Type: TokenTypeWeb}}, nil)
+		repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+		repo.On("SaveConversation", mock.Anything, mock.MatchedBy(func(c *conv.Conversation) bool {
+			return c.State == StateSelectTokenToRevoke
+		})).Return(nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		resp, err := svc.RestartExpiredConversation(context.Background(), userID)
+		require.NoError(t, err)
+		assert.Contains(t, resp.Message, "Which token do you want to revoke?")
+	})
+
+	t.Run("no tokens left", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		expectUserLock(repo, userID)
+
+		repo.On("GetExpiredFlow", mock.Anything, userID).Return(StateSelectTokenToRevoke, nil)
+		repo.On("DeleteConversation", mock.Anything, userID).Return(nil)
+		repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{}, nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		resp, err := svc.RestartExpiredConversation(context.Background(), userID)
+		require.NoError(t, err)
+		assert.Equal(t, sessionNoTokensMessage, resp.Message)
+	})
+
+	t.Run("nothing expired", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		expectUserLock(repo, userID)
+
+		repo.On("GetExpiredFlow", mock.Anything, userID).Return(conv.State(""), nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.RestartExpiredConversation(context.Background(), userID)
+		assert.ErrorIs(t, err, ErrNoActiveConversation)
+	})
+
+	t.Run("repo error", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		expectUserLock(repo, userID)
+
+		repo.On("GetExpiredFlow", mock.Anything, userID).Return(conv.State(""), assert.AnError)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.RestartExpiredConversation(context.Background(), userID)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "failed to get expired conversation")
+	})
+}
+
+func TestFlowStarter(t *testing.T) {
+	svc := newTestService(t, Config{}, NewMockUserRepo(t), NewMockMITProv(t))
+
+	for _, state := range []conv.State{
+		StateNewToken, StateEnterKeyID, StateTokenExists, StateSelectTokenToRegenerate, StateTokenRegenerate,
+		StateSelectTokenToRevoke, StateSelectTokenToRenew, StateRenewToken, StateRevokeAll, StateFeedback,
+	} {
+		assert.NotNil(t, svc.flowStarter(state), state)
+	}
+
+	assert.Nil(t, svc.flowStarter("unknown"))
+	assert.Nil(t, svc.flowStarter(""))
+}
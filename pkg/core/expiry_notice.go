@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+const tokenExpiredMessage = "⌛ Your token %s has just expired. Send /new_token to create a new one."
+
+// ErrExpiryNotificationsDisabled is returned by UserRepo.SubscribeExpiredTokens when expiry notifications are
+// not enabled in the repository config.
+var ErrExpiryNotificationsDisabled = errors.New("expiry notifications are disabled")
+
+// ExpiredToken identifies an API key that has just expired.
+type ExpiredToken struct {
+	UserID string
+	KeyID  string
+}
+
+// ExpiryNotice is a message to send to a user whose token has just expired.
+type ExpiryNotice struct {
+	Response *Response
+	UserID   string
+	ChatID   int64
+}
+
+// WatchExpiredTokens returns a channel of notices for tokens as they expire, closed once ctx is done.
+// Expired tokens of users without a known or active chat are skipped. Returns ErrExpiryNotificationsDisabled
+// when the repository doesn't track token expiry.
+func (s *Service) WatchExpiredTokens(ctx context.Context) (<-chan ExpiryNotice, error) {
+	expired, err := s.repo.SubscribeExpiredTokens(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to expired tokens: %w", err)
+	}
+
+	notices := make(chan ExpiryNotice)
+
+	go func() {
+		defer close(notices)
+
+		for token := range expired {
+			notice, ok := s.expiryNotice(ctx, token)
+			if !ok {
+				continue
+			}
+
+			select {
+			case notices <- notice:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return notices, nil
+}
+
+// expiryNotice builds the notice for the expired token, it reports false if the user can't be reached.
+func (s *Service) expiryNotice(ctx context.Context, token ExpiredToken) (ExpiryNotice, bool) {
+	chatID, err := s.repo.GetUserChat(ctx, token.UserID)
+
+	switch {
+	case errors.Is(err, ErrUserChatNotFound):
+		slog.DebugContext(ctx, "No chat to notify about expired token", slog.String("user_id", token.UserID))
+
+		return ExpiryNotice{}, false
+	case errors.Is(err, ErrUserChatInactive):
+		slog.DebugContext(ctx, "Chat to notify about expired token is inactive", slog.String("user_id", token.UserID))
+
+		return ExpiryNotice{}, false
+	case err != nil:
+		slog.ErrorContext(ctx, "Failed to get chat to notify about expired token",
+			slog.String("user_id", token.UserID),
+			slog.Any("error", err),
+		)
+
+		return ExpiryNotice{}, false
+	}
+
+	return ExpiryNotice{
+		UserID:   token.UserID,
+		ChatID:   chatID,
+		Response: &Response{Message: fmt.Sprintf(tokenExpiredMessage, token.KeyID)},
+	}, true
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchExpiredTokens(t *testing.T) {
+	repo := NewMockUserRepo(t)
+
+	src := make(chan ExpiredToken, 4)
+	src <- ExpiredToken{UserID: "user1", KeyID: "key1"}
+	src <- ExpiredToken{UserID: "unknown", KeyID: "key2"}
+	src <- ExpiredToken{UserID: "broken", KeyID: "key3"}
+	src <- ExpiredToken{UserID: "blocked", KeyID: "key4"}
+	close(src)
+
+	var expired <-chan ExpiredToken = src
+
+	repo.On("SubscribeExpiredTokens", mock.Anything).Return(expired, nil)
+	repo.On("GetUserChat", mock.Anything, "user1").Return(int64(100), nil)
+	repo.On("GetUserChat", mock.Anything, "unknown").Return(int64(0), ErrUserChatNotFound)
+	repo.On("GetUserChat", mock.Anything, "broken").Return(int64(0), assert.AnError)
+	repo.On("GetUserChat", mock.Anything, "blocked").Return(int64(0), ErrUserChatInactive)
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	notices, err := svc.WatchExpiredTokens(context.Background())
+	require.NoError(t, err)
+
+	var got []ExpiryNotice
+	for n := range notices {
+		got = append(got, n)
+	}
+
+	require.Len(t, got, 1, "users that can't be reached are skipped")
+	assert.Equal(t, "user1", got[0].UserID)
+	assert.Equal(t, int64(100), got[0].ChatID)
+	assert.Equal(t, "⌛ Your token key1 has just expired. Send /new_token to create a new one.", got[0].Response.Message)
+}
+
+func TestWatchExpiredTokens_SubscribeError(t *testing.T) {
+	repo := NewMockUserRepo(t)
+	repo.On("SubscribeExpiredTokens", mock.Anything).Return(nil, ErrExpiryNotificationsDisabled)
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	_, err := svc.WatchExpiredTokens(context.Background())
+
+	assert.ErrorIs(t, err, ErrExpiryNotificationsDisabled)
+	assert.ErrorContains(t, err, "failed to subscribe to expired tokens")
+}
+
+func TestWatchExpiredTokens_StopsWithContext(t *testing.T) {
+	repo := NewMockUserRepo(t)
+
+	src := make(chan ExpiredToken, 1)
+	src <- ExpiredToken{UserID: "user1", KeyID: "key1"}
+
+	var expired <-chan ExpiredToken = src
+
+	repo.On("SubscribeExpiredTokens", mock.Anything).Return(expired, nil)
+	repo.On("GetUserChat", mock.Anything, "user1").Return(int64(100), nil)
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	notices, err := svc.WatchExpiredTokens(ctx)
+	require.NoError(t, err)
+
+	// Nobody reads the notice, cancelling the context must still end the watcher. The repository closes
+	// its channel once the context is done.
+	cancel()
+	close(src)
+
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-notices:
+			return !ok
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+}
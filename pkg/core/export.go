@@ -0,0 +1,42 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// TokenExport describes an active token of a user for export. The token value itself is never stored,
+// so it can't be exported.
+type TokenExport struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	KeyID     string    `json:"key_id"`
+	Type      TokenType `json:"type"`
+	Label     string    `json:"label,omitempty"`
+}
+
+// ExportTokens returns the active tokens of the user in a serializable form.
+// Keys are reconciled with the provider first, like for ListTokens.
+// Returns ErrTokenNotFound if the user has no active tokens.
+func (s *Service) ExportTokens(ctx context.Context, userID string) ([]TokenExport, error) {
+	keys, err := s.reconcileKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrTokenNotFound
+	}
+
+	tokens := make([]TokenExport, 0, len(keys))
+
+	for _, k := range keys {
+		tokens = append(tokens, TokenExport{
+			ExpiresAt: k.ExpiresAt.UTC(),
+			KeyID:     k.KeyID,
+			Type:      k.Type,
+			Label:     k.Name,
+		})
+	}
+
+	return tokens, nil
+}
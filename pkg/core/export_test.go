@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportTokens(t *testing.T) {
+	userID := "user123"
+
+	repo := NewMockUserRepo(t)
+	prov := NewMockMITProv(t)
+
+	repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return([]KeyInfo{
+		{KeyID: "web-key", Name: "home", Type: TokenTypeWeb},
+		{KeyID: "tcp-key", Type: TokenTypeTCP},
+	}, nil)
+	prov.On("GetToken", mock.Anything, "web-key").Return(&TokenDetails{KeyID: "web-key", ExpiresIn: time.Hour}, nil)
+	prov.On("GetToken", mock.Anything, "tcp-key").Return(&TokenDetails{KeyID: "tcp-key", ExpiresIn: 2 * time.Hour}, nil)
+
+	svc := newTestService(t, Config{}, repo, prov)
+
+	got, err := svc.ExportTokens(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	assert.Equal(t, "web-key", got[0].KeyID)
+	assert.Equal(t, TokenTypeWeb, got[0].Type)
+	assert.Equal(t, "home", got[0].Label)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), got[0].ExpiresAt, time.Minute)
+	assert.Equal(t, time.UTC, got[0].ExpiresAt.Location())
+
+	assert.Equal(t, "tcp-key", got[1].KeyID)
+	assert.Equal(t, TokenTypeTCP, got[1].Type)
+	assert.Empty(t, got[1].Label)
+}
+
+func TestExportTokens_Errors(t *testing.T) {
+	userID := "user123"
+
+	t.Run("no tokens", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(nil, nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.ExportTokens(context.Background(), userID)
+		assert.ErrorIs(t, err, ErrTokenNotFound)
+	})
+
+	t.Run("repo error", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(nil, assert.AnError)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.ExportTokens(context.Background(), userID)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
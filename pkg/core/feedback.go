@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+)
+
+const (
+	StateFeedback conv.State = "feedback"
+
+	feedbackQuestion    = "What would you like to tell us? Describe the problem or idea in one message."
+	feedbackThanks      = "Thanks, we got your feedback."
+	feedbackLongMessage = "Feedback must be at most 1000 characters."
+	maxFeedbackLen      = 1000
+)
+
+// Feedback is a message a user left for the bot operators.
+type Feedback struct {
+	Time   time.Time `json:"time"`
+	UserID string    `json:"user_id"`
+	Text   string    `json:"text"`
+}
+
+// SubmitFeedback stores the user's feedback. The stored feedback is returned in Response.Feedback, so that
+// the caller can forward it to the operators. When text is empty, a conversation asking for it is started instead.
+func (s *Service) SubmitFeedback(ctx context.Context, userID, text string) (*Response, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return s.askForFeedback(ctx, userID)
+	}
+
+	if utf8.RuneCountInString(text) > maxFeedbackLen {
+		return &Response{Message: feedbackLongMessage}, nil
+	}
+
+	fb := &Feedback{
+		Time:   time.Now().UTC(),
+		UserID: userID,
+		Text:   text,
+	}
+
+	if err := s.repo.SaveFeedback(ctx, *fb); err != nil {
+		return nil, fmt.Errorf("failed to save feedback: %w", err)
+	}
+
+	return &Response{
+		Message:  feedbackThanks,
+		Feedback: fb,
+	}, nil
+}
+
+// askForFeedback starts a conversation asking the user for the feedback text.
+func (s *Service) askForFeedback(ctx context.Context, userID string) (*Response, error) {
+	c, err := s.repo.GetConversation(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	questions := conv.NewQuestions(
+		[]conv.Question{{
+			Text: feedbackQuestion,
+			Validators: []conv.Validator{
+				conv.MaxLength(maxFeedbackLen, feedbackLongMessage),
+			},
+		}},
+	)
+
+	if err := s.startConversation(ctx, userID, c, StateFeedback, questions); err != nil {
+		return nil, fmt.Errorf("failed to start questions: %w", err)
+	}
+
+	q, _ := c.Current()
+
+	if err := s.repo.SaveConversation(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+
+	return newQuestionResponse(c, q), nil
+}
+
+// handleFeedbackResult stores the feedback entered in reply to askForFeedback.
+func (s *Service) handleFeedbackResult(ctx context.Context, userID string, answers []conv.QuestionAnswer) (*Response, error) {
+	if len(answers) != 1 {
+		return nil, fmt.Errorf("expected exactly one answer for feedback question, got %d", len(answers))
+	}
+
+	return s.SubmitFeedback(ctx, userID, answers[0].Answer)
+}
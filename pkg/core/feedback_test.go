@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitFeedback(t *testing.T) {
+	userID := "user123"
+
+	t.Run("stores feedback", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("SaveFeedback", mock.Anything, mock.MatchedBy(func(fb Feedback) bool {
+			return fb.UserID == userID && fb.Text == "The bot is great" && !fb.Time.IsZero()
+		})).Return(nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		resp, err := svc.SubmitFeedback(context.Background(), userID, "  The bot is great ")
+		require.NoError(t, err)
+
+		assert.Equal(t, feedbackThanks, resp.Message)
+		require.NotNil(t, resp.Feedback)
+		assert.Equal(t, "The bot is great", resp.Feedback.Text)
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		svc := newTestService(t, Config{}, NewMockUserRepo(t), NewMockMITProv(t))
+
+		resp, err := svc.SubmitFeedback(context.Background(), userID, strings.Repeat("a", maxFeedbackLen+1))
+		require.NoError(t, err)
+
+		assert.Equal(t, feedbackLongMessage, resp.Message)
+		assert.Nil(t, resp.Feedback)
+	})
+
+	t.Run("save error", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("SaveFeedback", mock.Anything, mock.Anything).Return(assert.AnError)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.SubmitFeedback(context.Background(), userID, "hello")
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestSubmitFeedback_AsksForText(t *testing.T) {
+	userID := "user123"
+
+	repo := NewMockUserRepo(t)
+	expectUserLock(repo, userID)
+	repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+
+	var saved *conv.Conversation
+
+	repo.On("SaveConversation", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*conv.Conversation)
+	}).Return(nil)
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	resp, err := svc.SubmitFeedback(context.Background(), userID, " ")
+	require.NoError(t, err)
+
+	assert.Equal(t, feedbackQuestion, resp.Message)
+	assert.Nil(t, resp.Feedback)
+	require.NotNil(t, saved)
+	assert.Equal(t, StateFeedback, saved.State)
+
+	repo.On("GetConversation", mock.Anything, userID).Unset()
+	repo.On("GetConversation", mock.Anything, userID).Return(saved, nil)
+	expectAnswerMarkers(repo, userID)
+
+	resp, err = svc.HandleMessage(context.Background(), userID, strings.Repeat("a", maxFeedbackLen+1))
+	require.NoError(t, err)
+	assert.Contains(t, resp.Message, feedbackLongMessage)
+
+	repo.On("SaveFeedback", mock.Anything, mock.MatchedBy(func(fb Feedback) bool {
+		return fb.UserID == userID && fb.Text == "Renew doesn't work"
+	})).Return(nil)
+
+	resp, err = svc.HandleMessage(context.Background(), userID, "Renew doesn't work")
+	require.NoError(t, err)
+
+	assert.Equal(t, feedbackThanks, resp.Message)
+	require.NotNil(t, resp.Feedback)
+	assert.Equal(t, "Renew doesn't work", resp.Feedback.Text)
+}
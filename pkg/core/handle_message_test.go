@@ -35,7 +35,7 @@ func TestHandleMessage(t *testing.T) {
 			expectedErr: "failed to get conversation: get conversation error",
 		},
 		{
-			name:    "submit message error",
+			name:    "idle conversation",
 			userID:  "user123",
 			message: "invalid message",
 			setupMocks: func(t *testing.T) (*MockUserRepo, *MockMITProv, *conv.Conversation) {
@@ -51,7 +51,24 @@ func TestHandleMessage(t *testing.T) {
 
 				return repo, prov, conversation
 			},
-			expectedErr: "failed to submit message: conversation is not in questions state",
+			expectedErr: ErrNoActiveConversation.Error(),
+		},
+		{
+			name:    "malformed conversation",
+			userID:  "user123",
+			message: "Yes",
+			setupMocks: func(t *testing.T) (*MockUserRepo, *MockMITProv, *conv.Conversation) {
+				repo := NewMockUserRepo(t)
+				prov := NewMockMITProv(t)
+
+				// A questions state without questions can't take an answer.
+				conversation := &conv.Conversation{ID: "user123", State: StateNewToken}
+
+				repo.On("GetConversation", mock.Anything, "user123").Return(conversation, nil)
+
+				return repo, prov, conversation
+			},
+			expectedErr: "failed to submit message: no more questions",
 		},
 		{
 			name:    "conversation not complete - return current question",
@@ -84,11 +101,11 @@ func TestHandleMessage(t *testing.T) {
 			},
 			expectedResp: &Response{
 				Message: "Are you sure?",
-				Answers: []string{"Yes", "No"},
+				Answers: []string{"Yes", "No", BackAnswer},
 			},
 		},
 		{
-			name:    "get current question error",
+			name:    "completed conversation",
 			userID:  "user123",
 			message: "Yes",
 			setupMocks: func(t *testing.T) (*MockUserRepo, *MockMITProv, *conv.Conversation) {
@@ -111,13 +128,13 @@ func TestHandleMessage(t *testing.T) {
 				_, err = conversation.Submit("Yes")
 				require.NoError(t, err)
 
-				// Now Current() should fail because there are no more questions
+				// The conversation is complete and waits for no more answers.
 
 				repo.On("GetConversation", mock.Anything, "user123").Return(conversation, nil)
 
 				return repo, prov, conversation
 			},
-			expectedErr: "failed to submit message: conversation is not in questions state",
+			expectedErr: ErrNoActiveConversation.Error(),
 		},
 		{
 			name:    "save conversation error",
@@ -149,6 +166,63 @@ func TestHandleMessage(t *testing.T) {
 			},
 			expectedErr: "failed to save conversation: save conversation error",
 		},
+		{
+			name:    "invalid answer - question asked again",
+			userID:  "user123",
+			message: "forever",
+			setupMocks: func(t *testing.T) (*MockUserRepo, *MockMITProv, *conv.Conversation) {
+				repo := NewMockUserRepo(t)
+				prov := NewMockMITProv(t)
+
+				conversation := conv.New("user123")
+				questions := conv.NewQuestions([]conv.Question{
+					{
+						Text:        expirationQuestion,
+						Answers:     []string{"1 day", "7 days"},
+						Pattern:     expirationPattern,
+						AllowCustom: true,
+					},
+				})
+
+				err := conversation.Start(StateNewToken, questions)
+				require.NoError(t, err)
+
+				repo.On("GetConversation", mock.Anything, "user123").Return(conversation, nil)
+
+				return repo, prov, conversation
+			},
+			expectedResp: &Response{
+				Message: invalidAnswerMessage + "\n\n" + expirationQuestion,
+				Answers: []string{"1 day", "7 days"},
+			},
+		},
+		{
+			name:    "validator rejects answer - reason shown",
+			userID:  "user123",
+			message: "a label that is definitely way too long for a token",
+			setupMocks: func(t *testing.T) (*MockUserRepo, *MockMITProv, *conv.Conversation) {
+				repo := NewMockUserRepo(t)
+				prov := NewMockMITProv(t)
+
+				conversation := conv.New("user123")
+				questions := conv.NewQuestions([]conv.Question{
+					{
+						Text:       tokenNameQuestion,
+						Validators: []conv.Validator{conv.MaxLength(maxTokenNameLen, "The label must be at most 32 characters.")},
+					},
+				})
+
+				err := conversation.Start(StateNewToken, questions)
+				require.NoError(t, err)
+
+				repo.On("GetConversation", mock.Anything, "user123").Return(conversation, nil)
+
+				return repo, prov, conversation
+			},
+			expectedResp: &Response{
+				Message: "The label must be at most 32 characters.\n\n" + tokenNameQuestion,
+			},
+		},
 		{
 			name:    "unsupported conversation state",
 			userID:  "user123",
@@ -182,8 +256,9 @@ func TestHandleMessage(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo, prov, _ := tt.setupMocks(t)
+			expectUserLock(repo, tt.userID)
 
-			svc := New(repo, prov)
+			svc := newTestService(t, Config{}, repo, prov)
 
 			resp, err := svc.HandleMessage(context.Background(), tt.userID, tt.message)
 
@@ -203,3 +278,66 @@ func TestHandleMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestHasActiveConversation(t *testing.T) {
+	userID := "user123"
+
+	active := conv.New(userID)
+	require.NoError(t, active.Start(StateNewToken, conv.NewQuestions([]conv.Question{{Text: "Type?", Answers: []string{"Web", "TCP"}}})))
+
+	tests := []struct {
+		cnv     *conv.Conversation
+		getErr  error
+		name    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "idle", cnv: conv.New(userID)},
+		{name: "waiting for an answer", cnv: active, want: true},
+		{name: "repo error", getErr: assert.AnError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			repo.On("GetConversation", mock.Anything, userID).Return(tt.cnv, tt.getErr)
+
+			svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+			got, err := svc.HasActiveConversation(context.Background(), userID)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, assert.AnError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHandleMessage_NoActiveConversation(t *testing.T) {
+	t.Run("idle conversation", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		expectUserLock(repo, "user123")
+		repo.On("GetConversation", mock.Anything, "user123").Return(conv.New("user123"), nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.HandleMessage(context.Background(), "user123", "hello")
+		assert.ErrorIs(t, err, ErrNoActiveConversation)
+	})
+
+	t.Run("malformed conversation", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		expectUserLock(repo, "user123")
+		repo.On("GetConversation", mock.Anything, "user123").Return(&conv.Conversation{ID: "user123", State: StateNewToken}, nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.HandleMessage(context.Background(), "user123", "hello")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNoActiveConversation)
+		assert.ErrorIs(t, err, conv.ErrNoMoreQuestions)
+	})
+}
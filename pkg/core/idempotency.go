@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+)
+
+const (
+	// idempotencyTTL is how long an answer is remembered as handled.
+	idempotencyTTL = 10 * time.Minute
+
+	alreadyHandledMessage = "This answer has already been handled."
+)
+
+// answerKey returns the idempotency key of an answer to the question the conversation is waiting for,
+// empty if it isn't waiting for one. The key names the run of the conversation and the step within it, so that
+// a repeated answer, e.g. a double-tapped "Yes", is told apart from an answer to a later question.
+func answerKey(cnv *conv.Conversation) string {
+	if !cnv.IsActive() || cnv.Session == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s:%d", cnv.Session, cnv.Step)
+}
+
+// idempotent runs op, which handles an answer to the question cnv is waiting for, at most once per question.
+// A repeated answer gets a short reply saying it has been handled already, or ErrUserBusy while the first one is
+// still being handled. Only a marker is stored for the question, never the response, which may hold a token secret.
+// A failed op, or an answer that was rejected and left the conversation waiting for the same question, releases
+// the marker, so that the question can be answered again.
+func (s *Service) idempotent(ctx context.Context, userID string, cnv *conv.Conversation, op func() (*Response, error)) (*Response, error) {
+	key := answerKey(cnv)
+	if key == "" {
+		return op()
+	}
+
+	done, err := s.repo.ClaimIdempotencyKey(ctx, userID, key, idempotencyTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	if done {
+		slog.InfoContext(ctx, "Repeated answer, it has been handled already", slog.String("user_id", userID), slog.String("idempotency_key", key))
+
+		return &Response{Message: alreadyHandledMessage}, nil
+	}
+
+	resp, err := op()
+	if err != nil || resp == nil || answerKey(cnv) == key {
+		if relErr := s.repo.ReleaseIdempotencyKey(context.WithoutCancel(ctx), userID, key); relErr != nil {
+			slog.ErrorContext(ctx, "Failed to release idempotency key", slog.String("user_id", userID), slog.Any("error", relErr))
+		}
+
+		return resp, err
+	}
+
+	if err := s.repo.MarkIdempotencyKeyDone(ctx, userID, key); err != nil {
+		slog.ErrorContext(ctx, "Failed to mark idempotency key as done", slog.String("user_id", userID), slog.Any("error", err))
+	}
+
+	return resp, nil
+}
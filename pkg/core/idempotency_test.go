@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// expectAnswerMarkers accepts the idempotency markers of the answers to conversations started during a test.
+func expectAnswerMarkers(repo *MockUserRepo, userID string) {
+	repo.On("ClaimIdempotencyKey", mock.Anything, userID, mock.Anything, idempotencyTTL).Return(false, nil)
+	repo.On("MarkIdempotencyKeyDone", mock.Anything, userID, mock.Anything).Return(nil).Maybe()
+	repo.On("ReleaseIdempotencyKey", mock.Anything, userID, mock.Anything).Return(nil).Maybe()
+}
+
+// newAnsweringConversation returns a conversation of the given session waiting for the answer to a yes/no question.
+func newAnsweringConversation(t *testing.T, userID, session string) *conv.Conversation {
+	t.Helper()
+
+	cnv := conv.New(userID)
+	require.NoError(t, cnv.Start(StateTokenRegenerate, conv.NewQuestions([]conv.Question{
+		{Text: "Regenerate?", Answers: []string{"Yes", "No"}},
+	})))
+	cnv.Session = session
+
+	return cnv
+}
+
+func TestAnswerKey(t *testing.T) {
+	assert.Empty(t, answerKey(conv.New("user123")), "an idle conversation waits for no answer")
+
+	cnv := newAnsweringConversation(t, "user123", "")
+	assert.Empty(t, answerKey(cnv), "a conversation started without a session has no key")
+
+	cnv = newAnsweringConversation(t, "user123", "sess")
+	assert.Equal(t, "sess:0", answerKey(cnv))
+
+	cnv.Step = 2
+	assert.Equal(t, "sess:2", answerKey(cnv))
+}
+
+func TestIdempotent(t *testing.T) {
+	userID := "user123"
+	result := &Response{Message: "Your New API Token: secret"}
+
+	t.Run("without a key the operation always runs", func(t *testing.T) {
+		svc := newTestService(t, Config{}, NewMockUserRepo(t), NewMockMITProv(t))
+
+		runs := 0
+
+		for range 2 {
+			resp, err := svc.idempotent(context.Background(), userID, conv.New(userID), func() (*Response, error) {
+				runs++
+				return result, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, result, resp)
+		}
+
+		assert.Equal(t, 2, runs)
+	})
+
+	t.Run("handled answer marks the question done", func(t *testing.T) {
+		cnv := newAnsweringConversation(t, userID, "sess")
+
+		repo := NewMockUserRepo(t)
+		repo.On("ClaimIdempotencyKey", mock.Anything, userID, "sess:0", idempotencyTTL).Return(false, nil)
+		repo.On("MarkIdempotencyKeyDone", mock.Anything, userID, "sess:0").Return(nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		resp, err := svc.idempotent(context.Background(), userID, cnv, func() (*Response, error) {
+			_, err := cnv.Submit("Yes")
+			return result, err
+		})
+		require.NoError(t, err)
+		assert.Same(t, result, resp)
+	})
+
+	t.Run("repeated answer is a no-op", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("ClaimIdempotencyKey", mock.Anything, userID, "sess:0", idempotencyTTL).Return(true, nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		resp, err := svc.idempotent(context.Background(), userID, newAnsweringConversation(t, userID, "sess"), func() (*Response, error) {
+			t.Fatal("operation must not run again")
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &Response{Message: alreadyHandledMessage}, resp)
+	})
+
+	t.Run("failed operation releases the key", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("ClaimIdempotencyKey", mock.Anything, userID, "sess:0", idempotencyTTL).Return(false, nil)
+		repo.On("ReleaseIdempotencyKey", mock.Anything, userID, "sess:0").Return(nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.idempotent(context.Background(), userID, newAnsweringConversation(t, userID, "sess"), func() (*Response, error) {
+			return nil, assert.AnError
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("rejected answer releases the key", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("ClaimIdempotencyKey", mock.Anything, userID, "sess:0", idempotencyTTL).Return(false, nil)
+		repo.On("ReleaseIdempotencyKey", mock.Anything, userID, "sess:0").Return(nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		reask := &Response{Message: "Please choose one of the options.\n\nRegenerate?"}
+
+		resp, err := svc.idempotent(context.Background(), userID, newAnsweringConversation(t, userID, "sess"), func() (*Response, error) {
+			return reask, nil
+		})
+		require.NoError(t, err)
+		assert.Same(t, reask, resp)
+	})
+
+	t.Run("answer in progress", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("ClaimIdempotencyKey", mock.Anything, userID, "sess:0", idempotencyTTL).Return(false, ErrUserBusy)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.idempotent(context.Background(), userID, newAnsweringConversation(t, userID, "sess"), func() (*Response, error) {
+			t.Fatal("operation must not run")
+			return nil, nil
+		})
+		assert.ErrorIs(t, err, ErrUserBusy)
+		assert.ErrorContains(t, err, "failed to claim idempotency key")
+	})
+}
+
+func TestHandleMessage_RepeatedAnswer(t *testing.T) {
+	userID := "user123"
+
+	repo := NewMockUserRepo(t)
+	expectUserLock(repo, userID)
+	repo.On("GetConversation", mock.Anything, userID).Return(newAnsweringConversation(t, userID, "sess"), nil)
+	repo.On("ClaimIdempotencyKey", mock.Anything, userID, "sess:0", idempotencyTTL).Return(true, nil)
+
+	// The provider mock has no expectations, so a repeated "Yes" can't revoke and regenerate again.
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	resp, err := svc.HandleMessage(context.Background(), userID, "Yes")
+	require.NoError(t, err)
+	assert.Equal(t, alreadyHandledMessage, resp.Message)
+}
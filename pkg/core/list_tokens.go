@@ -2,57 +2,66 @@ package core
 
 import (
 	"context"
-	"fmt"
-	"strings"
-	"time"
 )
 
 const (
-	listTokensHeader = "🔑 Your Active API Tokens (Web: %d/%d, TCP: %d/%d)\n\n"
-	listTokensEntry  = "%d. [%s] %s...\n   ⏱ Expires: %s\n"
-	listTokensFooter = "\nUse /new_token to create a new token or /revoke_token to revoke one."
 	listTokensKeyLen = 12 // number of key ID characters shown in the listing
+	unnamedTokenName = "(unnamed)"
 )
 
 // ListTokens retrieves and formats all active API tokens for the specified user.
+// Keys are reconciled with the provider first, so revoked or expired tokens are pruned instead of listed.
 // Returns ErrTokenNotFound if the user has no active tokens.
 func (s *Service) ListTokens(ctx context.Context, userID string) (*Response, error) {
-	keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
+	keys, err := s.reconcileKeys(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get API keys: %w", err)
+		return nil, err
 	}
 
 	if len(keys) == 0 {
 		return nil, ErrTokenNotFound
 	}
 
-	var webCount, tcpCount int
+	data := tokenListData{
+		Tokens: make([]tokenListEntry, 0, len(keys)),
+		MaxWeb: s.maxWebTokens,
+		MaxTCP: s.maxTCPTokens,
+	}
+
+	loc := s.userLocation(ctx, userID)
 
-	for _, k := range keys {
+	for i, k := range keys {
 		if k.Type == TokenTypeTCP {
-			tcpCount++
+			data.TCPCount++
 		} else {
-			webCount++
+			data.WebCount++
 		}
-	}
-
-	var sb strings.Builder
-
-	fmt.Fprintf(&sb, listTokensHeader, webCount, maxWebTokensPerUser, tcpCount, maxTCPTokensPerUser)
 
-	for i, k := range keys {
 		keyDisplay := k.KeyID
 		if len(keyDisplay) > listTokensKeyLen {
 			keyDisplay = keyDisplay[:listTokensKeyLen]
 		}
 
-		expiresAt := k.ExpiresAt.Format(time.DateTime)
-		fmt.Fprintf(&sb, listTokensEntry, i+1, string(k.Type), keyDisplay, expiresAt)
+		name := k.Name
+		if name == "" {
+			name = unnamedTokenName
+		}
+
+		data.Tokens = append(data.Tokens, tokenListEntry{
+			Number:    i + 1,
+			Type:      string(k.Type),
+			Name:      name,
+			KeyID:     keyDisplay,
+			ExpiresAt: formatExpiryWithTimeLeft(k.ExpiresAt, loc),
+		})
 	}
 
-	sb.WriteString(listTokensFooter)
+	msg, err := s.templates.render(templateListTokens, data)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Response{
-		Message: sb.String(),
+		Message: msg,
 	}, nil
 }
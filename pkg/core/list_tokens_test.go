@@ -36,21 +36,22 @@ func TestListTokens(t *testing.T) {
 				t.Helper()
 				assert.Contains(t, resp.Message, "1/3")
 				assert.Contains(t, resp.Message, "abcdef123456")
-				assert.Contains(t, resp.Message, "2026-03-15")
+				assert.Contains(t, resp.Message, "2026-03-15 10:00:00 UTC (expired)")
 			},
 		},
 		{
 			name:   "multiple tokens",
 			userID: "user123",
 			keys: []KeyInfo{
-				{KeyID: "aaabbb123456789", Type: TokenTypeWeb, ExpiresAt: time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)},
+				{KeyID: "aaabbb123456789", Name: "home server", Type: TokenTypeWeb, ExpiresAt: time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)},
 				{KeyID: "cccddd987654321", Type: TokenTypeWeb, ExpiresAt: time.Date(2026, 4, 20, 12, 0, 0, 0, time.UTC)},
 				{KeyID: "eeefff111222333", Type: TokenTypeWeb, ExpiresAt: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)},
 			},
 			checkResp: func(t *testing.T, resp *Response) {
 				t.Helper()
 				assert.Contains(t, resp.Message, "3/3")
-				assert.Contains(t, resp.Message, "aaabbb123456")
+				assert.Contains(t, resp.Message, "home server — aaabbb123456")
+				assert.Contains(t, resp.Message, "(unnamed) — cccddd987654")
 				assert.Contains(t, resp.Message, "cccddd987654")
 				assert.Contains(t, resp.Message, "eeefff111222")
 				assert.Contains(t, resp.Message, "/new_token")
@@ -71,8 +72,14 @@ func TestListTokens(t *testing.T) {
 			prov := NewMockMITProv(t)
 
 			repo.On("GetAPIKeysWithExpiration", mock.Anything, tt.userID).Return(tt.keys, tt.getKeysErr)
+			// Provider failures leave keys as stored, reconciliation is covered by TestReconcileKeys.
+			prov.On("GetToken", mock.Anything, mock.Anything).Return(nil, errors.New("provider unavailable")).Maybe()
 
-			svc := New(repo, prov)
+			if len(tt.keys) > 0 {
+				expectTimezone(repo, tt.userID)
+			}
+
+			svc := newTestService(t, Config{}, repo, prov)
 
 			resp, err := svc.ListTokens(context.Background(), tt.userID)
 
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// userLockTTL bounds how long the per-user lock is held when its holder dies before releasing it.
+// It is longer than a request is expected to run.
+const userLockTTL = 30 * time.Second
+
+// ErrUserBusy is returned when another request of the same user is being processed, possibly by another bot instance.
+var ErrUserBusy = errors.New("another request of the user is in progress")
+
+// ReleaseFunc releases a lock taken with UserRepo.AcquireUserLock.
+type ReleaseFunc func(ctx context.Context) error
+
+// lockUser takes the per-user lock that keeps concurrent requests of one user from managing tokens at the same time.
+// It returns ErrUserBusy when the lock is held by another request. Failing to release the lock is only logged,
+// it expires after userLockTTL anyway.
+func (s *Service) lockUser(ctx context.Context, userID string) (func(), error) {
+	release, err := s.repo.AcquireUserLock(ctx, userID, userLockTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire user lock: %w", err)
+	}
+
+	return func() {
+		if err := release(context.WithoutCancel(ctx)); err != nil {
+			slog.ErrorContext(ctx, "Failed to release user lock", slog.String("user_id", userID), slog.Any("error", err))
+		}
+	}, nil
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func expectUserLock(repo *MockUserRepo, userID string) {
+	repo.On("AcquireUserLock", mock.Anything, userID, userLockTTL).Return(ReleaseFunc(func(context.Context) error { return nil }), nil)
+}
+
+func TestLockUser_ReleasesLock(t *testing.T) {
+	repo := NewMockUserRepo(t)
+
+	released := false
+
+	repo.On("AcquireUserLock", mock.Anything, "user123", userLockTTL).Return(ReleaseFunc(func(context.Context) error {
+		released = true
+		return nil
+	}), nil)
+	repo.On("GetConversation", mock.Anything, "user123").Return(nil, assert.AnError)
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	_, err := svc.HandleMessage(context.Background(), "user123", "hello")
+	require.ErrorIs(t, err, assert.AnError)
+	assert.True(t, released, "lock must be released once the request is handled")
+}
+
+func TestLockUser_Busy(t *testing.T) {
+	tests := []struct {
+		call func(svc *Service) error
+		name string
+	}{
+		{
+			name: "create token",
+			call: func(svc *Service) error {
+				_, err := svc.CreateToken(context.Background(), "user123")
+				return err
+			},
+		},
+		{
+			name: "create token with expiration",
+			call: func(svc *Service) error {
+				_, err := svc.CreateTokenWithExpiration(context.Background(), "user123", 7)
+				return err
+			},
+		},
+		{
+			name: "revoke token",
+			call: func(svc *Service) error {
+				_, err := svc.RevokeToken(context.Background(), "user123")
+				return err
+			},
+		},
+		{
+			name: "revoke token by ID",
+			call: func(svc *Service) error {
+				return svc.RevokeTokenByID(context.Background(), "user123", "key123")
+			},
+		},
+		{
+			name: "handle message",
+			call: func(svc *Service) error {
+				_, err := svc.HandleMessage(context.Background(), "user123", "Yes")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			repo.On("AcquireUserLock", mock.Anything, "user123", userLockTTL).Return(ReleaseFunc(nil), ErrUserBusy)
+
+			svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+			assert.ErrorIs(t, tt.call(svc), ErrUserBusy)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package core
+
+import "strings"
+
+// markdownEscaper escapes the characters reserved by Telegram MarkdownV2 outside of entities.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+	">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// codeEscaper escapes the characters reserved by Telegram MarkdownV2 inside code spans.
+var codeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+
+// EscapeMarkdown escapes s so that it is rendered literally in a MarkdownV2 message.
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// markdownCode wraps s in a MarkdownV2 code span, which Telegram clients copy on tap.
+func markdownCode(s string) string {
+	return "`" + codeEscaper.Replace(s) + "`"
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain text", in: "token", want: "token"},
+		{name: "date", in: "2030-01-02 15:04:05", want: `2030\-01\-02 15:04:05`},
+		{name: "all reserved characters", in: "_*[]()~`>#+-=|{}.!", want: "\\_\\*\\[\\]\\(\\)\\~\\`\\>\\#\\+\\-\\=\\|\\{\\}\\.\\!"},
+		{name: "backslash", in: `a\b`, want: `a\\b`},
+		{name: "emoji and newlines untouched", in: "🔑\n⏱", want: "🔑\n⏱"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, EscapeMarkdown(tt.in))
+		})
+	}
+}
+
+func TestMarkdownCode(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain token", in: "abc123", want: "`abc123`"},
+		{name: "markdown characters are kept inside code", in: "a_b*c.d-e", want: "`a_b*c.d-e`"},
+		{name: "backtick and backslash escaped", in: "a`b\\c", want: "`a\\`b\\\\c`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, markdownCode(tt.in))
+		})
+	}
+}
+
+func TestNewTokenCreatedResponse(t *testing.T) {
+	resp, err := newTestService(t, Config{}, nil, nil).tokenCreatedResponse("tok_en*`x", "2030-01-02 15:04:05")
+
+	require.NoError(t, err)
+	assert.True(t, resp.Markdown)
+	assert.Equal(t,
+		"🔑 *Your New API Token*\n\n`tok_en*\\`x`\n\n⏱ *Valid until:* 2030\\-01\\-02 15:04:05\n\nKeep this token secure and don't share it with others\\.",
+		resp.Message,
+	)
+}
@@ -4,7 +4,11 @@
 
 package core
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // MockMITProv is an autogenerated mock type for the MITProv type
 type MockMITProv struct {
@@ -19,9 +23,9 @@ func (_m *MockMITProv) EXPECT() *MockMITProv_Expecter {
 	return &MockMITProv_Expecter{mock: &_m.Mock}
 }
 
-// GenerateToken provides a mock function with given fields: keyID, tokenType, ttl
-func (_m *MockMITProv) GenerateToken(keyID string, tokenType TokenType, ttl int64) (*APIToken, error) {
-	ret := _m.Called(keyID, tokenType, ttl)
+// GenerateToken provides a mock function with given fields: ctx, keyID, tokenType, ttl
+func (_m *MockMITProv) GenerateToken(ctx context.Context, keyID string, tokenType TokenType, ttl int64) (*APIToken, error) {
+	ret := _m.Called(ctx, keyID, tokenType, ttl)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GenerateToken")
@@ -29,19 +33,19 @@ func (_m *MockMITProv) GenerateToken(keyID string, tokenType TokenType, ttl int6
 
 	var r0 *APIToken
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, TokenType, int64) (*APIToken, error)); ok {
-		return rf(keyID, tokenType, ttl)
+	if rf, ok := ret.Get(0).(func(context.Context, string, TokenType, int64) (*APIToken, error)); ok {
+		return rf(ctx, keyID, tokenType, ttl)
 	}
-	if rf, ok := ret.Get(0).(func(string, TokenType, int64) *APIToken); ok {
-		r0 = rf(keyID, tokenType, ttl)
+	if rf, ok := ret.Get(0).(func(context.Context, string, TokenType, int64) *APIToken); ok {
+		r0 = rf(ctx, keyID, tokenType, ttl)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*APIToken)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, TokenType, int64) error); ok {
-		r1 = rf(keyID, tokenType, ttl)
+	if rf, ok := ret.Get(1).(func(context.Context, string, TokenType, int64) error); ok {
+		r1 = rf(ctx, keyID, tokenType, ttl)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -55,16 +59,17 @@ type MockMITProv_GenerateToken_Call struct {
 }
 
 // GenerateToken is a helper method to define mock.On call
+//   - ctx context.Context
 //   - keyID string
 //   - tokenType TokenType
 //   - ttl int64
-func (_e *MockMITProv_Expecter) GenerateToken(keyID interface{}, tokenType interface{}, ttl interface{}) *MockMITProv_GenerateToken_Call {
-	return &MockMITProv_GenerateToken_Call{Call: _e.mock.On("GenerateToken", keyID, tokenType, ttl)}
+func (_e *MockMITProv_Expecter) GenerateToken(ctx interface{}, keyID interface{}, tokenType interface{}, ttl interface{}) *MockMITProv_GenerateToken_Call {
+	return &MockMITProv_GenerateToken_Call{Call: _e.mock.On("GenerateToken", ctx, keyID, tokenType, ttl)}
 }
 
-func (_c *MockMITProv_GenerateToken_Call) Run(run func(keyID string, tokenType TokenType, ttl int64)) *MockMITProv_GenerateToken_Call {
+func (_c *MockMITProv_GenerateToken_Call) Run(run func(ctx context.Context, keyID string, tokenType TokenType, ttl int64)) *MockMITProv_GenerateToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(TokenType), args[2].(int64))
+		run(args[0].(context.Context), args[1].(string), args[2].(TokenType), args[3].(int64))
 	})
 	return _c
 }
@@ -74,22 +79,129 @@ func (_c *MockMITProv_GenerateToken_Call) Return(_a0 *APIToken, _a1 error) *Mock
 	return _c
 }
 
-func (_c *MockMITProv_GenerateToken_Call) RunAndReturn(run func(string, TokenType, int64) (*APIToken, error)) *MockMITProv_GenerateToken_Call {
+func (_c *MockMITProv_GenerateToken_Call) RunAndReturn(run func(context.Context, string, TokenType, int64) (*APIToken, error)) *MockMITProv_GenerateToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetToken provides a mock function with given fields: ctx, keyID
+func (_m *MockMITProv) GetToken(ctx context.Context, keyID string) (*TokenDetails, error) {
+	ret := _m.Called(ctx, keyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetToken")
+	}
+
+	var r0 *TokenDetails
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*TokenDetails, error)); ok {
+		return rf(ctx, keyID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *TokenDetails); ok {
+		r0 = rf(ctx, keyID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*TokenDetails)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, keyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockMITProv_GetToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetToken'
+type MockMITProv_GetToken_Call struct {
+	*mock.Call
+}
+
+// GetToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keyID string
+func (_e *MockMITProv_Expecter) GetToken(ctx interface{}, keyID interface{}) *MockMITProv_GetToken_Call {
+	return &MockMITProv_GetToken_Call{Call: _e.mock.On("GetToken", ctx, keyID)}
+}
+
+func (_c *MockMITProv_GetToken_Call) Run(run func(ctx context.Context, keyID string)) *MockMITProv_GetToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockMITProv_GetToken_Call) Return(_a0 *TokenDetails, _a1 error) *MockMITProv_GetToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockMITProv_GetToken_Call) RunAndReturn(run func(context.Context, string) (*TokenDetails, error)) *MockMITProv_GetToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenewToken provides a mock function with given fields: ctx, keyID, ttl
+func (_m *MockMITProv) RenewToken(ctx context.Context, keyID string, ttl int64) error {
+	ret := _m.Called(ctx, keyID, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenewToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) error); ok {
+		r0 = rf(ctx, keyID, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMITProv_RenewToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenewToken'
+type MockMITProv_RenewToken_Call struct {
+	*mock.Call
+}
+
+// RenewToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keyID string
+//   - ttl int64
+func (_e *MockMITProv_Expecter) RenewToken(ctx interface{}, keyID interface{}, ttl interface{}) *MockMITProv_RenewToken_Call {
+	return &MockMITProv_RenewToken_Call{Call: _e.mock.On("RenewToken", ctx, keyID, ttl)}
+}
+
+func (_c *MockMITProv_RenewToken_Call) Run(run func(ctx context.Context, keyID string, ttl int64)) *MockMITProv_RenewToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockMITProv_RenewToken_Call) Return(_a0 error) *MockMITProv_RenewToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMITProv_RenewToken_Call) RunAndReturn(run func(context.Context, string, int64) error) *MockMITProv_RenewToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// RevokeToken provides a mock function with given fields: keyID
-func (_m *MockMITProv) RevokeToken(keyID string) error {
-	ret := _m.Called(keyID)
+// RevokeToken provides a mock function with given fields: ctx, keyID
+func (_m *MockMITProv) RevokeToken(ctx context.Context, keyID string) error {
+	ret := _m.Called(ctx, keyID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RevokeToken")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(keyID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, keyID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -103,14 +215,15 @@ type MockMITProv_RevokeToken_Call struct {
 }
 
 // RevokeToken is a helper method to define mock.On call
+//   - ctx context.Context
 //   - keyID string
-func (_e *MockMITProv_Expecter) RevokeToken(keyID interface{}) *MockMITProv_RevokeToken_Call {
-	return &MockMITProv_RevokeToken_Call{Call: _e.mock.On("RevokeToken", keyID)}
+func (_e *MockMITProv_Expecter) RevokeToken(ctx interface{}, keyID interface{}) *MockMITProv_RevokeToken_Call {
+	return &MockMITProv_RevokeToken_Call{Call: _e.mock.On("RevokeToken", ctx, keyID)}
 }
 
-func (_c *MockMITProv_RevokeToken_Call) Run(run func(keyID string)) *MockMITProv_RevokeToken_Call {
+func (_c *MockMITProv_RevokeToken_Call) Run(run func(ctx context.Context, keyID string)) *MockMITProv_RevokeToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -120,7 +233,7 @@ func (_c *MockMITProv_RevokeToken_Call) Return(_a0 error) *MockMITProv_RevokeTok
 	return _c
 }
 
-func (_c *MockMITProv_RevokeToken_Call) RunAndReturn(run func(string) error) *MockMITProv_RevokeToken_Call {
+func (_c *MockMITProv_RevokeToken_Call) RunAndReturn(run func(context.Context, string) error) *MockMITProv_RevokeToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	previewMessage      = "🔍 Preview of a new web token\n\n⏱ Valid until: %s\n🔑 Web: %d/%d"
+	previewLimitMessage = "\n\nYou're at the limit, so creating it will offer to regenerate an existing token instead."
+)
+
+// PreviewToken shows what /new_token with the given number of days would produce: the expiration date of
+// the new web token and the user's web token usage against the limit. Nothing is created and the user's
+// conversation is left as is, so the preview can be shown at any point of another flow.
+// Returns ErrInvalidExpirationPeriod if days is outside of 1..maxExpirationDays.
+func (s *Service) PreviewToken(ctx context.Context, userID string, days int) (*Response, error) {
+	if days <= 0 || days > s.maxExpirationDays {
+		return nil, ErrInvalidExpirationPeriod
+	}
+
+	keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	webCount := len(filterKeysByType(keys, TokenTypeWeb))
+	expiresAt := formatExpiry(time.Now().Add(time.Duration(days)*secondsInDay*time.Second), s.userLocation(ctx, userID))
+
+	msg := fmt.Sprintf(previewMessage, expiresAt, webCount, s.maxWebTokens)
+	if webCount >= s.maxWebTokens {
+		msg += previewLimitMessage
+	}
+
+	return &Response{
+		Message: msg,
+	}, nil
+}
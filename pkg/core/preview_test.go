@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewToken(t *testing.T) {
+	userID := "user123"
+
+	tests := []struct {
+		name        string
+		keys        []KeyInfo
+		wantUsage   string
+		days        int
+		wantAtLimit bool
+	}{
+		{
+			name:      "no tokens",
+			days:      30,
+			wantUsage: "🔑 Web: 0/3",
+		},
+		{
+			name: "tcp tokens don't count",
+			days: 1,
+			keys: []KeyInfo{
+				{KeyID: "web1", Type: TokenTypeWeb},
+				{KeyID: "tcp1", Type: TokenTypeTCP},
+			},
+			wantUsage: "🔑 Web: 1/3",
+		},
+		{
+			name: "at the limit",
+			days: 7,
+			keys: []KeyInfo{
+				{KeyID: "web1", Type: TokenTypeWeb},
+				{KeyID: "web2", Type: TokenTypeWeb},
+				{KeyID: "web3", Type: TokenTypeWeb},
+			},
+			wantUsage:   "🔑 Web: 3/3",
+			wantAtLimit: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Strict mocks ensure that neither the provider nor the conversation is touched.
+			repo := NewMockUserRepo(t)
+			repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(tt.keys, nil)
+			expectTimezone(repo, userID)
+
+			svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+			resp, err := svc.PreviewToken(context.Background(), userID, tt.days)
+			require.NoError(t, err)
+
+			wantExpiry := time.Now().Add(time.Duration(tt.days) * 24 * time.Hour)
+
+			assert.Contains(t, resp.Message, tt.wantUsage)
+			assert.Contains(t, resp.Message, "Valid until: "+wantExpiry.UTC().Format("2006-01-02"))
+			assert.Empty(t, resp.Answers)
+
+			if tt.wantAtLimit {
+				assert.Contains(t, resp.Message, previewLimitMessage)
+			} else {
+				assert.NotContains(t, resp.Message, previewLimitMessage)
+			}
+		})
+	}
+}
+
+func TestPreviewToken_Errors(t *testing.T) {
+	t.Run("invalid period", func(t *testing.T) {
+		svc := newTestService(t, Config{MaxExpirationDays: 90}, NewMockUserRepo(t), NewMockMITProv(t))
+
+		for _, days := range []int{0, -1, 91} {
+			_, err := svc.PreviewToken(context.Background(), "user123", days)
+			assert.ErrorIs(t, err, ErrInvalidExpirationPeriod)
+		}
+	})
+
+	t.Run("repo error", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("GetAPIKeysWithExpiration", mock.Anything, "user123").Return(nil, assert.AnError)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.PreviewToken(context.Background(), "user123", 30)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ProviderError reports an unexpected HTTP status returned by the MIT API for the operation Op, e.g. "generate token".
+// Use errors.As to tell client errors (4xx), which point to a user or configuration problem, from transient
+// provider failures (5xx). 401 and 403 responses match ErrProviderUnauthorized with errors.Is.
+type ProviderError struct {
+	Op         string
+	StatusCode int
+}
+
+// Error returns the error message including the operation and the status code.
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("failed to %s, status code: %d", e.Op, e.StatusCode)
+}
+
+// Is makes 401 and 403 responses match ErrProviderUnauthorized.
+func (e *ProviderError) Is(target error) bool {
+	return target == ErrProviderUnauthorized &&
+		(e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden)
+}
+
+// Temporary reports whether the provider failed on its side (5xx), so the same request may succeed later.
+func (e *ProviderError) Temporary() bool {
+	return e.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderError(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		wantUnauthorized bool
+		wantTemporary    bool
+	}{
+		{name: "bad request", status: http.StatusBadRequest},
+		{name: "unauthorized", status: http.StatusUnauthorized, wantUnauthorized: true},
+		{name: "forbidden", status: http.StatusForbidden, wantUnauthorized: true},
+		{name: "internal server error", status: http.StatusInternalServerError, wantTemporary: true},
+		{name: "bad gateway", status: http.StatusBadGateway, wantTemporary: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("failed to create token: %w", &ProviderError{Op: "generate token", StatusCode: tt.status})
+
+			var provErr *ProviderError
+			require.ErrorAs(t, err, &provErr)
+
+			assert.Equal(t, fmt.Sprintf("failed to generate token, status code: %d", tt.status), provErr.Error())
+			assert.Equal(t, tt.status, provErr.StatusCode)
+			assert.Equal(t, tt.wantUnauthorized, errors.Is(err, ErrProviderUnauthorized))
+			assert.Equal(t, tt.wantTemporary, provErr.Temporary())
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	defaultDailyTokenLimit = 10
+	rateLimitWindow        = 24 * time.Hour
+)
+
+// ErrRateLimited is returned when the user has created too many tokens within the rate limit window.
+var ErrRateLimited = errors.New("token creation rate limit exceeded")
+
+// RateLimitedError reports an exceeded token creation limit together with the time the limit resets.
+// It matches ErrRateLimited with errors.Is.
+type RateLimitedError struct {
+	ResetAt time.Time
+	// ResetAtText is ResetAt as shown to the user, in their timezone and with the zone abbreviation.
+	ResetAtText string
+}
+
+// Error returns the error message including the reset time.
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("%s, resets at %s", ErrRateLimited, e.ResetAt.Format(time.DateTime))
+}
+
+// Unwrap returns ErrRateLimited so callers can match the error with errors.Is.
+func (e *RateLimitedError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// checkTokenCreationLimit returns a *RateLimitedError if the user has reached the daily token creation limit.
+func (s *Service) checkTokenCreationLimit(ctx context.Context, userID string) error {
+	count, resetAt, err := s.repo.GetTokenCreationCount(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get token creation count: %w", err)
+	}
+
+	if count >= s.dailyTokenLimit {
+		return &RateLimitedError{
+			ResetAt:     resetAt,
+			ResetAtText: formatExpiry(resetAt, s.userLocation(ctx, userID)),
+		}
+	}
+
+	return nil
+}
+
+// recordTokenCreation counts a successfully created token against the user's daily limit. The token exists
+// at this point and its secret is shown only once, so a failure only loses the count and is logged rather
+// than reported to the user.
+func (s *Service) recordTokenCreation(ctx context.Context, userID string) {
+	if _, err := s.repo.IncrementTokenCreationCount(ctx, userID, rateLimitWindow); err != nil {
+		slog.ErrorContext(ctx, "Failed to record token creation",
+			slog.String("user_id", userID),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateToken_RateLimited(t *testing.T) {
+	userID := "user123"
+	resetAt := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		cfg         Config
+		count       int
+		wantLimited bool
+	}{
+		{name: "under default limit", count: defaultDailyTokenLimit - 1},
+		{name: "at default limit", count: defaultDailyTokenLimit, wantLimited: true},
+		{name: "at configured limit", cfg: Config{DailyTokenLimit: 2}, count: 2, wantLimited: true},
+		{name: "under configured limit", cfg: Config{DailyTokenLimit: 20}, count: 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			expectUserLock(repo, userID)
+			prov := NewMockMITProv(t)
+
+			repo.On("GetTokenCreationCount", mock.Anything, userID).Return(tt.count, resetAt, nil)
+
+			if tt.wantLimited {
+				repo.On("GetUserSetting", mock.Anything, userID, SettingTimezone).Return("Europe/Berlin", nil)
+			} else {
+				repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(nil, nil)
+				repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+				expectDefaultExpiration(repo, userID, "")
+				repo.On("SaveConversation", mock.Anything, mock.AnythingOfType("*conv.Conversation")).Return(nil)
+			}
+
+			svc := newTestService(t, tt.cfg, repo, prov)
+
+			resp, err := svc.CreateToken(context.Background(), userID)
+
+			if !tt.wantLimited {
+				require.NoError(t, err)
+				assert.NotNil(t, resp)
+
+				return
+			}
+
+			assert.Nil(t, resp)
+			assert.ErrorIs(t, err, ErrRateLimited)
+
+			var rlErr *RateLimitedError
+			require.ErrorAs(t, err, &rlErr)
+			assert.Equal(t, resetAt, rlErr.ResetAt)
+			assert.Equal(t, "2030-01-02 16:04:05 CET", rlErr.ResetAtText, "reset time must be shown in the user's timezone")
+		})
+	}
+}
+
+func TestCreateToken_RateLimitLookupError(t *testing.T) {
+	repo := NewMockUserRepo(t)
+	expectUserLock(repo, "user123")
+	repo.On("GetTokenCreationCount", mock.Anything, "user123").Return(0, time.Time{}, errors.New("redis error"))
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	_, err := svc.CreateToken(context.Background(), "user123")
+
+	assert.EqualError(t, err, "failed to get token creation count: redis error")
+}
+
+func TestHandleNewTokenResult_RateLimited(t *testing.T) {
+	repo := NewMockUserRepo(t)
+	prov := NewMockMITProv(t)
+
+	repo.On("GetTokenCreationCount", mock.Anything, "user123").Return(defaultDailyTokenLimit, time.Now().Add(time.Hour), nil)
+	expectTimezone(repo, "user123")
+
+	svc := newTestService(t, Config{}, repo, prov)
+
+	answers := []conv.QuestionAnswer{expirationAnswer("7 days", encodeTokenField(TokenTypeWeb, ""))}
+
+	resp, err := svc.handleNewTokenResult(context.Background(), "user123", answers)
+
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
+
+func TestHandleNewTokenResult_RecordCreationError(t *testing.T) {
+	repo := NewMockUserRepo(t)
+	prov := NewMockMITProv(t)
+
+	token := &APIToken{KeyID: "key123", Token: "token123", ExpiresIn: 24 * time.Hour}
+
+	repo.On("GetTokenCreationCount", mock.Anything, "user123").Return(0, time.Time{}, nil)
+	prov.On("GenerateToken", mock.Anything, "", TokenTypeWeb, int64(secondsInDay)).Return(token, nil)
+	repo.On("AddAPIKeyWithLimit", mock.Anything, "user123", "key123", TokenTypeWeb, "", token.ExpiresIn, 3).Return(nil)
+	expectAudit(repo, "user123", AuditActionCreate, "key123")
+	repo.On("IncrementTokenCreationCount", mock.Anything, "user123", rateLimitWindow).Return(0, errors.New("redis error"))
+	expectTimezone(repo, "user123")
+
+	svc := newTestService(t, Config{}, repo, prov)
+
+	answers := []conv.QuestionAnswer{expirationAnswer("1 day", encodeTokenField(TokenTypeWeb, ""))}
+
+	resp, err := svc.handleNewTokenResult(context.Background(), "user123", answers)
+
+	// The token already exists, so the user still gets its secret.
+	require.NoError(t, err)
+	assert.Contains(t, resp.Message, "token123")
+}
+
+func TestRateLimitedError(t *testing.T) {
+	err := &RateLimitedError{ResetAt: time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)}
+
+	assert.Equal(t, "token creation rate limit exceeded, resets at 2030-01-02 15:04:05", err.Error())
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// tokenStatusActive is the provider status of a token that can still be used.
+const tokenStatusActive = "active"
+
+// reconcileKeys checks every stored key of the user against the provider and removes from the repository
+// the keys the provider no longer knows or reports as inactive or expired. The remaining keys get the
+// provider's expiration, which is more accurate than the one derived from the repository score.
+// A provider error for one key leaves that key as stored, so that an outage can't wipe the user's tokens.
+func (s *Service) reconcileKeys(ctx context.Context, userID string) ([]KeyInfo, error) {
+	keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	result := make([]KeyInfo, 0, len(keys))
+
+	for _, k := range keys {
+		details, err := s.prov.GetToken(ctx, k.KeyID)
+		if err != nil && !errors.Is(err, ErrTokenNotFound) {
+			result = append(result, k)
+			continue
+		}
+
+		if err == nil && isTokenActive(details) {
+			k.ExpiresAt = time.Now().Add(details.ExpiresIn)
+			result = append(result, k)
+
+			continue
+		}
+
+		if err := s.repo.RevokeToken(ctx, userID, k.KeyID); err != nil {
+			return nil, fmt.Errorf("failed to remove stale API key: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// isTokenActive reports whether the provider considers the token usable.
+// An empty status is treated as active for providers that only report the TTL.
+func isTokenActive(details *TokenDetails) bool {
+	return (details.Status == "" || details.Status == tokenStatusActive) && details.ExpiresIn > 0
+}
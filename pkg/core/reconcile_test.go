@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileKeys(t *testing.T) {
+	userID := "user123"
+	storedExpiry := time.Now().Add(48 * time.Hour)
+
+	keys := []KeyInfo{
+		{KeyID: "active", Name: "home", Type: TokenTypeWeb, ExpiresAt: storedExpiry},
+		{KeyID: "missing", Type: TokenTypeWeb, ExpiresAt: storedExpiry},
+		{KeyID: "revoked", Type: TokenTypeWeb, ExpiresAt: storedExpiry},
+		{KeyID: "expired", Type: TokenTypeTCP, ExpiresAt: storedExpiry},
+		{KeyID: "unreachable", Type: TokenTypeWeb, ExpiresAt: storedExpiry},
+	}
+
+	repo := NewMockUserRepo(t)
+	prov := NewMockMITProv(t)
+
+	repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(keys, nil)
+
+	prov.On("GetToken", mock.Anything, "active").Return(&TokenDetails{KeyID: "active", Status: "active", ExpiresIn: time.Hour}, nil)
+	prov.On("GetToken", mock.Anything, "missing").Return(nil, ErrTokenNotFound)
+	prov.On("GetToken", mock.Anything, "revoked").Return(&TokenDetails{KeyID: "revoked", Status: "revoked", ExpiresIn: time.Hour}, nil)
+	prov.On("GetToken", mock.Anything, "expired").Return(&TokenDetails{KeyID: "expired", Status: "active"}, nil)
+	prov.On("GetToken", mock.Anything, "unreachable").Return(nil, errors.New("connection refused"))
+
+	repo.On("RevokeToken", mock.Anything, userID, "missing").Return(nil)
+	repo.On("RevokeToken", mock.Anything, userID, "revoked").Return(nil)
+	repo.On("RevokeToken", mock.Anything, userID, "expired").Return(nil)
+
+	svc := newTestService(t, Config{}, repo, prov)
+
+	got, err := svc.reconcileKeys(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	assert.Equal(t, "active", got[0].KeyID)
+	assert.Equal(t, "home", got[0].Name)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), got[0].ExpiresAt, time.Minute)
+
+	// A provider error keeps the key with its stored expiration.
+	assert.Equal(t, keys[4], got[1])
+}
+
+func TestReconcileKeys_Errors(t *testing.T) {
+	userID := "user123"
+
+	t.Run("get keys error", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(nil, assert.AnError)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.reconcileKeys(context.Background(), userID)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "failed to get API keys")
+	})
+
+	t.Run("remove stale key error", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		prov := NewMockMITProv(t)
+
+		repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return([]KeyInfo{{KeyID: "missing"}}, nil)
+		prov.On("GetToken", mock.Anything, "missing").Return(nil, ErrTokenNotFound)
+		repo.On("RevokeToken", mock.Anything, userID, "missing").Return(assert.AnError)
+
+		svc := newTestService(t, Config{}, repo, prov)
+
+		_, err := svc.reconcileKeys(context.Background(), userID)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "failed to remove stale API key")
+	})
+}
+
+func TestListTokens_PrunesStaleKeys(t *testing.T) {
+	userID := "user123"
+
+	repo := NewMockUserRepo(t)
+	prov := NewMockMITProv(t)
+
+	repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return([]KeyInfo{{KeyID: "gone", Type: TokenTypeWeb}}, nil)
+	prov.On("GetToken", mock.Anything, "gone").Return(nil, ErrTokenNotFound)
+	repo.On("RevokeToken", mock.Anything, userID, "gone").Return(nil)
+
+	svc := newTestService(t, Config{}, repo, prov)
+
+	_, err := svc.ListTokens(context.Background(), userID)
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	defaultRegenerateCooldown = time.Minute
+
+	regenerateCooldownMessage = "⏳ Please wait before regenerating again, you can do it %s."
+)
+
+// regenerateCooldownLeft returns how long the user still has to wait before regenerating a token again,
+// zero if the user hasn't regenerated one within the cooldown.
+func (s *Service) regenerateCooldownLeft(ctx context.Context, userID string) (time.Duration, error) {
+	last, err := s.repo.GetLastRegeneration(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last regeneration: %w", err)
+	}
+
+	if last.IsZero() {
+		return 0, nil
+	}
+
+	return max(s.regenerateCooldown-time.Since(last), 0), nil
+}
+
+// recordRegeneration starts the user's regeneration cooldown. The token is already regenerated at this point,
+// so a failure only loses the cooldown and is logged rather than reported to the user.
+func (s *Service) recordRegeneration(ctx context.Context, userID string) {
+	if err := s.repo.SetLastRegeneration(ctx, userID, time.Now(), s.regenerateCooldown); err != nil {
+		slog.ErrorContext(ctx, "Failed to record token regeneration",
+			slog.String("user_id", userID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// formatWait renders the time left until an action is allowed again, e.g. "in 42 seconds" or "in 3 minutes".
+// It is rounded up, so that the user never retries too early.
+func formatWait(d time.Duration) string {
+	if d <= time.Minute {
+		return "in " + pluralize(int((d+time.Second-1)/time.Second), "second")
+	}
+
+	return "in " + pluralize(int((d+time.Minute-1)/time.Minute), "minute")
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleTokenRegenerateResult_Cooldown(t *testing.T) {
+	userID := "user123"
+	keyID := "key123"
+	answers := []conv.QuestionAnswer{
+		expirationAnswer("7 days", encodeTokenField(TokenTypeWeb, keyID)),
+	}
+
+	t.Run("rejected within the cooldown", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("GetLastRegeneration", mock.Anything, userID).Return(time.Now().Add(-20*time.Second), nil)
+
+		svc := newTestService(t, Config{RegenerateCooldown: time.Minute}, repo, NewMockMITProv(t))
+
+		resp, err := svc.handleTokenRegenerateResult(context.Background(), userID, answers)
+
+		require.NoError(t, err)
+		assert.Contains(t, resp.Message, "Please wait before regenerating again")
+		assert.Contains(t, resp.Message, "in 40 seconds")
+	})
+
+	t.Run("allowed after the cooldown", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		prov := NewMockMITProv(t)
+
+		token := &APIToken{KeyID: keyID, Token: "newtoken", ExpiresIn: 7 * 24 * time.Hour}
+
+		repo.On("GetLastRegeneration", mock.Anything, userID).Return(time.Now().Add(-2*time.Minute), nil)
+		repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return([]KeyInfo{{KeyID: keyID, Type: TokenTypeWeb}}, nil)
+		repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+		prov.On("RevokeToken", mock.Anything, keyID).Return(nil)
+		repo.On("RevokeToken", mock.Anything, userID, keyID).Return(nil)
+		prov.On("GenerateToken", mock.Anything, keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(token, nil)
+		repo.On("AddAPIKey", mock.Anything, userID, keyID, TokenTypeWeb, "", token.ExpiresIn).Return(nil)
+		expectAudit(repo, userID, AuditActionRegenerate, keyID)
+		repo.On("SetLastRegeneration", mock.Anything, userID, mock.AnythingOfType("time.Time"), time.Minute).Return(nil)
+		repo.On("IncrementTokenCreationCount", mock.Anything, userID, rateLimitWindow).Return(1, nil)
+		expectTimezone(repo, userID)
+
+		svc := newTestService(t, Config{RegenerateCooldown: time.Minute}, repo, prov)
+
+		resp, err := svc.handleTokenRegenerateResult(context.Background(), userID, answers)
+
+		require.NoError(t, err)
+		assert.Contains(t, resp.Message, "newtoken")
+	})
+
+	t.Run("failure to record the regeneration is ignored", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		prov := NewMockMITProv(t)
+
+		token := &APIToken{KeyID: keyID, Token: "newtoken", ExpiresIn: 7 * 24 * time.Hour}
+
+		repo.On("GetLastRegeneration", mock.Anything, userID).Return(time.Time{}, nil)
+		repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return([]KeyInfo{{KeyID: keyID, Type: TokenTypeWeb}}, nil)
+		repo.On("GetTokenCreationCount", mock.Anything, userID).Return(0, time.Time{}, nil)
+		prov.On("RevokeToken", mock.Anything, keyID).Return(nil)
+		repo.On("RevokeToken", mock.Anything, userID, keyID).Return(nil)
+		prov.On("GenerateToken", mock.Anything, keyID, TokenTypeWeb, mock.AnythingOfType("int64")).Return(token, nil)
+		repo.On("AddAPIKey", mock.Anything, userID, keyID, TokenTypeWeb, "", token.ExpiresIn).Return(nil)
+		expectAudit(repo, userID, AuditActionRegenerate, keyID)
+		repo.On("SetLastRegeneration", mock.Anything, userID, mock.AnythingOfType("time.Time"), defaultRegenerateCooldown).Return(assert.AnError)
+		repo.On("IncrementTokenCreationCount", mock.Anything, userID, rateLimitWindow).Return(1, nil)
+		expectTimezone(repo, userID)
+
+		svc := newTestService(t, Config{}, repo, prov)
+
+		resp, err := svc.handleTokenRegenerateResult(context.Background(), userID, answers)
+
+		require.NoError(t, err)
+		assert.Contains(t, resp.Message, "newtoken")
+	})
+
+	t.Run("get last regeneration error", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("GetLastRegeneration", mock.Anything, userID).Return(time.Time{}, assert.AnError)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.handleTokenRegenerateResult(context.Background(), userID, answers)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "failed to get last regeneration")
+	})
+}
+
+func TestFormatWait(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		d    time.Duration
+	}{
+		{name: "seconds are rounded up", d: 41*time.Second + time.Millisecond, want: "in 42 seconds"},
+		{name: "single second", d: 300 * time.Millisecond, want: "in 1 second"},
+		{name: "a minute", d: time.Minute, want: "in 60 seconds"},
+		{name: "minutes are rounded up", d: 2*time.Minute + time.Second, want: "in 3 minutes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatWait(tt.d))
+		})
+	}
+}
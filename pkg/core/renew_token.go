@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+)
+
+const (
+	renewalQuestion = "How much longer should your token stay valid? Pick an option or enter a number of days."
+
+	// tokenRenewedMessage is formatted as MarkdownV2, arguments must be escaped.
+	tokenRenewedMessage = "⏳ Your API token has been extended\\.\n\nThe token value is unchanged\\.\n⏱ *New expiration:* %s"
+
+	renewNotSupportedMessage = "Extending tokens is not supported by the provider yet. Your token was left unchanged.\n\nYou can still regenerate it with /new_token."
+)
+
+// ErrRenewNotSupported is returned by MITProv.RenewToken when the provider cannot extend tokens in place.
+var ErrRenewNotSupported = errors.New("token renewal is not supported by the provider")
+
+// RenewToken starts a conversation to extend the expiration of one of the user's existing tokens
+// without changing the token value. With a single token the selection step is skipped.
+// Returns ErrTokenNotFound if the user has no tokens.
+func (s *Service) RenewToken(ctx context.Context, userID string) (*Response, error) {
+	keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrTokenNotFound
+	}
+
+	if len(keys) == 1 {
+		return s.askForRenewalPeriod(ctx, userID, keys[0])
+	}
+
+	q := buildTokenSelectionQuestion(keys, "Which token do you want to extend?")
+
+	c, err := s.repo.GetConversation(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	if err := s.startConversation(ctx, userID, c, StateSelectTokenToRenew, conv.NewQuestions([]conv.Question{q})); err != nil {
+		return nil, fmt.Errorf("failed to start questions: %w", err)
+	}
+
+	current, _ := c.Current()
+
+	if err := s.repo.SaveConversation(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+
+	return &Response{
+		Message: current.Text,
+		Answers: current.Answers,
+	}, nil
+}
+
+// handleSelectTokenToRenewResult resolves the selected token and asks for the additional period.
+func (s *Service) handleSelectTokenToRenewResult(ctx context.Context, userID string, answers []conv.QuestionAnswer) (*Response, error) {
+	if len(answers) != 1 {
+		return nil, fmt.Errorf("expected exactly one answer for token selection question, got %d", len(answers))
+	}
+
+	keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	keyIDs := make([]string, len(keys))
+	for i, k := range keys {
+		keyIDs[i] = k.KeyID
+	}
+
+	keyID, err := resolveKeyIDFromPrefix(keyIDs, answers[0].Answer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve key ID: %w", err)
+	}
+
+	for _, k := range keys {
+		if k.KeyID == keyID {
+			return s.askForRenewalPeriod(ctx, userID, k)
+		}
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+// askForRenewalPeriod starts a conversation asking how long to extend the given key.
+// The token type and key ID are carried in the question Field for handleRenewTokenResult.
+func (s *Service) askForRenewalPeriod(ctx context.Context, userID string, key KeyInfo) (*Response, error) {
+	c, err := s.repo.GetConversation(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	questions := conv.NewQuestions([]conv.Question{{
+		ID:          questionIDExpiration,
+		Text:        renewalQuestion,
+		Answers:     s.expirationAnswers(),
+		AllowCustom: true,
+		Pattern:     expirationPattern,
+		Field:       encodeTokenField(key.Type, key.KeyID),
+	}})
+
+	if err := s.startConversation(ctx, userID, c, StateRenewToken, questions); err != nil {
+		return nil, fmt.Errorf("failed to start questions: %w", err)
+	}
+
+	q, _ := c.Current()
+
+	if err := s.repo.SaveConversation(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+
+	return &Response{
+		Message: q.Text,
+		Answers: q.Answers,
+	}, nil
+}
+
+// handleRenewTokenResult extends the selected key by the chosen period, both at the provider and in the repository.
+// The token value stays the same; if the provider cannot extend tokens the user is told so and nothing is changed.
+func (s *Service) handleRenewTokenResult(ctx context.Context, userID string, answers []conv.QuestionAnswer) (*Response, error) {
+	expiration, ok := conv.Lookup(answers, questionIDExpiration)
+	if !ok {
+		return nil, fmt.Errorf("missing answer to the renewal question")
+	}
+
+	extension, err := s.parseExpirationAnswer(expiration.Answer)
+
+	switch {
+	case errors.Is(err, ErrInvalidExpirationPeriod):
+		return &Response{
+			Message: invalidExpirationMessage,
+		}, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to parse expiration answer: %w", err)
+	}
+
+	_, keyID := decodeTokenField(expiration.Field)
+	if keyID == "" {
+		return nil, fmt.Errorf("missing key ID in renew answer field")
+	}
+
+	keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	var key *KeyInfo
+
+	for i := range keys {
+		if keys[i].KeyID == keyID {
+			key = &keys[i]
+			break
+		}
+	}
+
+	if key == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	// The provider expects the TTL counted from now, so add the extension to the remaining lifetime.
+	ttl := max(time.Until(key.ExpiresAt), 0) + time.Duration(extension)*time.Second
+
+	err = s.prov.RenewToken(ctx, keyID, int64(ttl.Seconds()))
+
+	switch {
+	case errors.Is(err, ErrRenewNotSupported):
+		return &Response{
+			Message: renewNotSupportedMessage,
+		}, nil
+	case errors.Is(err, ErrInvalidTTL):
+		return &Response{
+			Message: ttlOutOfRangeMessage,
+		}, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to renew token: %w", err)
+	}
+
+	if err := s.repo.AddAPIKey(ctx, userID, keyID, key.Type, key.Name, ttl); err != nil {
+		return nil, fmt.Errorf("failed to update API key expiration: %w", err)
+	}
+
+	s.audit(ctx, userID, AuditActionRenew, keyID)
+
+	return &Response{
+		Message:  fmt.Sprintf(tokenRenewedMessage, EscapeMarkdown(formatExpiry(time.Now().Add(ttl), s.userLocation(ctx, userID)))),
+		Markdown: true,
+	}, nil
+}
@@ -0,0 +1,222 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenewToken(t *testing.T) {
+	userID := "user123"
+
+	tests := []struct {
+		getKeysErr      error
+		expectedErr     error
+		name            string
+		expectedMsg     string
+		expectedState   conv.State
+		existingKeys    []KeyInfo
+		expectedAnswers []string
+	}{
+		{
+			name:         "no tokens",
+			existingKeys: []KeyInfo{},
+			expectedErr:  ErrTokenNotFound,
+		},
+		{
+			name: "single token - asks for period directly",
+			existingKeys: []KeyInfo{
+				{KeyID: "key1", Type: TokenTypeWeb, ExpiresAt: time.Now().Add(24 * time.Hour)},
+			},
+			expectedMsg:     renewalQuestion,
+			expectedAnswers: []string{"1 day", "7 days", "30 days", "90 days"},
+			expectedState:   StateRenewToken,
+		},
+		{
+			name: "multiple tokens - asks which one",
+			existingKeys: []KeyInfo{
+				{KeyID: "key1", Type: TokenTypeWeb, ExpiresAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)},
+				{KeyID: "key2", Type: TokenTypeTCP, ExpiresAt: time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)},
+			},
+			expectedMsg:     "Which token do you want to extend?",
+			expectedAnswers: []string{"key1 (exp: 2030-01-01)", "key2 (exp: 2030-01-02)"},
+			expectedState:   StateSelectTokenToRenew,
+		},
+		{
+			name:        "get keys error",
+			getKeysErr:  errors.New("redis error"),
+			expectedErr: errors.New("failed to get API keys: redis error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			prov := NewMockMITProv(t)
+
+			repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(tt.existingKeys, tt.getKeysErr)
+
+			c := conv.New(userID)
+
+			if tt.expectedState != "" {
+				repo.On("GetConversation", mock.Anything, userID).Return(c, nil)
+				repo.On("SaveConversation", mock.Anything, c).Return(nil)
+			}
+
+			svc := newTestService(t, Config{}, repo, prov)
+
+			resp, err := svc.RenewToken(context.Background(), userID)
+
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+				assert.Nil(t, resp)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMsg, resp.Message)
+			assert.Equal(t, tt.expectedAnswers, resp.Answers)
+			assert.Equal(t, tt.expectedState, c.State)
+		})
+	}
+}
+
+func TestHandleSelectTokenToRenewResult(t *testing.T) {
+	userID := "user123"
+	keys := []KeyInfo{
+		{KeyID: "a1b2c3d4e5f6", Type: TokenTypeWeb, ExpiresAt: time.Now().Add(24 * time.Hour)},
+		{KeyID: "f6e5d4c3b2a1", Type: TokenTypeTCP, ExpiresAt: time.Now().Add(48 * time.Hour)},
+	}
+
+	repo := NewMockUserRepo(t)
+	prov := NewMockMITProv(t)
+
+	c := conv.New(userID)
+
+	repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(keys, nil)
+	repo.On("GetConversation", mock.Anything, userID).Return(c, nil)
+	repo.On("SaveConversation", mock.Anything, c).Return(nil)
+
+	svc := newTestService(t, Config{}, repo, prov)
+
+	resp, err := svc.handleSelectTokenToRenewResult(context.Background(), userID, []conv.QuestionAnswer{{Answer: "f6e5d4c3 (exp: 2030-01-02)"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, renewalQuestion, resp.Message)
+	assert.Equal(t, StateRenewToken, c.State)
+
+	q, err := c.Current()
+	require.NoError(t, err)
+	assert.Equal(t, encodeTokenField(TokenTypeTCP, "f6e5d4c3b2a1"), q.Field)
+}
+
+func TestHandleRenewTokenResult(t *testing.T) {
+	userID := "user123"
+	field := encodeTokenField(TokenTypeWeb, "key1")
+
+	tests := []struct {
+		renewErr    error
+		name        string
+		answer      string
+		expectedMsg string
+		expectedErr string
+		existing    []KeyInfo
+		expectAdd   bool
+	}{
+		{
+			name:   "success - extends remaining lifetime and keeps label",
+			answer: "7 days",
+			existing: []KeyInfo{
+				{KeyID: "key1", Name: "home", Type: TokenTypeWeb, ExpiresAt: time.Now().Add(24 * time.Hour)},
+			},
+			expectedMsg: "Your API token has been extended",
+			expectAdd:   true,
+		},
+		{
+			name:   "provider does not support renewal",
+			answer: "7 days",
+			existing: []KeyInfo{
+				{KeyID: "key1", Type: TokenTypeWeb, ExpiresAt: time.Now().Add(24 * time.Hour)},
+			},
+			renewErr:    ErrRenewNotSupported,
+			expectedMsg: renewNotSupportedMessage,
+		},
+		{
+			name:   "renewed lifetime outside of the provider range",
+			answer: "7 days",
+			existing: []KeyInfo{
+				{KeyID: "key1", Type: TokenTypeWeb, ExpiresAt: time.Now().Add(24 * time.Hour)},
+			},
+			renewErr:    fmt.Errorf("%w: above the maximum", ErrInvalidTTL),
+			expectedMsg: ttlOutOfRangeMessage,
+		},
+		{
+			name:   "provider error",
+			answer: "7 days",
+			existing: []KeyInfo{
+				{KeyID: "key1", Type: TokenTypeWeb, ExpiresAt: time.Now().Add(24 * time.Hour)},
+			},
+			renewErr:    errors.New("provider down"),
+			expectedErr: "failed to renew token: provider down",
+		},
+		{
+			name:        "token no longer exists",
+			answer:      "7 days",
+			existing:    []KeyInfo{},
+			expectedErr: ErrKeyNotFound.Error(),
+		},
+		{
+			name:        "invalid period",
+			answer:      "0",
+			expectedMsg: "Invalid expiration period",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			prov := NewMockMITProv(t)
+
+			if tt.existing != nil {
+				repo.On("GetAPIKeysWithExpiration", mock.Anything, userID).Return(tt.existing, nil)
+			}
+
+			if len(tt.existing) > 0 {
+				// 1 day remaining + 7 days extension.
+				prov.On("RenewToken", mock.Anything, "key1", mock.MatchedBy(func(ttl int64) bool {
+					return ttl > 8*secondsInDay-60 && ttl <= 8*secondsInDay
+				})).Return(tt.renewErr)
+			}
+
+			if tt.expectAdd {
+				repo.On("AddAPIKey", mock.Anything, userID, "key1", TokenTypeWeb, "home", mock.MatchedBy(func(d time.Duration) bool {
+					return d > 8*24*time.Hour-time.Minute && d <= 8*24*time.Hour
+				})).Return(nil)
+				expectAudit(repo, userID, AuditActionRenew, "key1")
+				expectTimezone(repo, userID)
+			}
+
+			svc := newTestService(t, Config{}, repo, prov)
+
+			resp, err := svc.handleRenewTokenResult(context.Background(), userID, []conv.QuestionAnswer{expirationAnswer(tt.answer, field)})
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				assert.Nil(t, resp)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, resp.Message, tt.expectedMsg)
+		})
+	}
+}
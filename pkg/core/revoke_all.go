@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+)
+
+const (
+	StateRevokeAll conv.State = "revokeAll"
+
+	revokeAllQuestion       = "Do you want to revoke all %d of your tokens? Services using them will stop working."
+	revokeAllDoneMessage    = "🔒 Revoked %d token(s).\n\nYou can create a new one using /new_token command."
+	revokeAllPartialMessage = "⚠️ Revoked %d token(s), but some couldn't be revoked. Run /revoke_all again to retry."
+	revokeAllNoTokens       = "You have no tokens to revoke."
+	revokeAllCancelled      = "No changes made. You can continue using your existing API tokens."
+)
+
+// ConfirmRevokeAll starts a conversation asking the user to confirm revoking all of their tokens.
+// Returns ErrTokenNotFound if the user has no tokens.
+func (s *Service) ConfirmRevokeAll(ctx context.Context, userID string) (*Response, error) {
+	keys, err := s.repo.GetAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrTokenNotFound
+	}
+
+	c, err := s.repo.GetConversation(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	questions := conv.NewQuestions([]conv.Question{{
+		Text:    fmt.Sprintf(revokeAllQuestion, len(keys)),
+		Answers: []string{"Yes", "No"},
+	}})
+
+	if err := s.startConversation(ctx, userID, c, StateRevokeAll, questions); err != nil {
+		return nil, fmt.Errorf("failed to start questions: %w", err)
+	}
+
+	q, _ := c.Current()
+
+	if err := s.repo.SaveConversation(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+
+	return &Response{
+		Message: q.Text,
+		Answers: q.Answers,
+	}, nil
+}
+
+// RevokeAllTokens revokes every token of the user from both the provider and the repository and returns
+// the number of revoked tokens. A failure to revoke one token at the provider doesn't stop the others from
+// being revoked, the failures are joined into the returned error. The tokens revoked at the provider are
+// removed from the repository at once. Returns ErrTokenNotFound if the user has no tokens.
+func (s *Service) RevokeAllTokens(ctx context.Context, userID string) (int, error) {
+	keys, err := s.repo.GetAPIKeys(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return 0, ErrTokenNotFound
+	}
+
+	var (
+		revoked []string
+		errs    []error
+	)
+
+	for _, keyID := range keys {
+		if err := s.prov.RevokeToken(ctx, keyID); err != nil {
+			errs = append(errs, fmt.Errorf("key %s: failed to revoke token: %w", keyID, err))
+			continue
+		}
+
+		revoked = append(revoked, keyID)
+	}
+
+	if len(revoked) == 0 {
+		return 0, errors.Join(errs...)
+	}
+
+	if _, err := s.repo.RevokeTokens(ctx, userID, revoked); err != nil {
+		errs = append(errs, fmt.Errorf("failed to remove API keys from repository: %w", err))
+		return 0, errors.Join(errs...)
+	}
+
+	for _, keyID := range revoked {
+		s.audit(ctx, userID, AuditActionRevoke, keyID)
+	}
+
+	return len(revoked), errors.Join(errs...)
+}
+
+// handleRevokeAllResult revokes all tokens of the user once the revocation is confirmed.
+// A partial failure is reported to the user, the error is only returned when no token could be revoked.
+func (s *Service) handleRevokeAllResult(ctx context.Context, userID string, answers []conv.QuestionAnswer) (*Response, error) {
+	if len(answers) != 1 {
+		return nil, fmt.Errorf("expected exactly one answer for revoke all question, got %d", len(answers))
+	}
+
+	if answers[0].Answer != "Yes" {
+		return &Response{Message: revokeAllCancelled}, nil
+	}
+
+	revoked, err := s.RevokeAllTokens(ctx, userID)
+
+	switch {
+	case errors.Is(err, ErrTokenNotFound):
+		return &Response{Message: revokeAllNoTokens}, nil
+	case err != nil && revoked == 0:
+		return nil, fmt.Errorf("failed to revoke tokens: %w", err)
+	case err != nil:
+		slog.ErrorContext(ctx, "Failed to revoke some tokens",
+			slog.String("user_id", userID),
+			slog.Int("revoked", revoked),
+			slog.Any("error", err),
+		)
+
+		return &Response{Message: fmt.Sprintf(revokeAllPartialMessage, revoked)}, nil
+	}
+
+	return &Response{Message: fmt.Sprintf(revokeAllDoneMessage, revoked)}, nil
+}
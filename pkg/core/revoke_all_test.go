@@ -0,0 +1,220 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmRevokeAll(t *testing.T) {
+	userID := "user123"
+
+	t.Run("asks for confirmation", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+
+		repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{"key1", "key2"}, nil)
+		repo.On("GetConversation", mock.Anything, userID).Return(conv.New(userID), nil)
+		repo.On("SaveConversation", mock.Anything, mock.MatchedBy(func(c *conv.Conversation) bool {
+			return c.State == StateRevokeAll
+		})).Return(nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		resp, err := svc.ConfirmRevokeAll(context.Background(), userID)
+		require.NoError(t, err)
+		assert.Contains(t, resp.Message, "revoke all 2 of your tokens")
+		assert.Equal(t, []string{"Yes", "No"}, resp.Answers)
+	})
+
+	t.Run("no tokens", func(t *testing.T) {
+		repo := NewMockUserRepo(t)
+		repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{}, nil)
+
+		svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+		_, err := svc.ConfirmRevokeAll(context.Background(), userID)
+		assert.ErrorIs(t, err, ErrTokenNotFound)
+	})
+}
+
+func TestRevokeAllTokens(t *testing.T) {
+	userID := "user123"
+
+	tests := []struct {
+		provErrs    map[string]error
+		name        string
+		keys        []string
+		wantErr     []string
+		wantRevoked int
+	}{
+		{
+			name:        "revokes every token",
+			keys:        []string{"key1", "key2", "key3"},
+			wantRevoked: 3,
+		},
+		{
+			name:        "provider failure doesn't stop the rest",
+			keys:        []string{"key1", "key2", "key3"},
+			provErrs:    map[string]error{"key2": errors.New("provider down")},
+			wantRevoked: 2,
+			wantErr:     []string{"key key2", "provider down"},
+		},
+		{
+			name:     "all fail",
+			keys:     []string{"key1", "key2"},
+			provErrs: map[string]error{"key1": errors.New("boom 1"), "key2": errors.New("boom 2")},
+			wantErr:  []string{"key key1: failed to revoke token: boom 1", "key key2: failed to revoke token: boom 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			prov := NewMockMITProv(t)
+
+			repo.On("GetAPIKeys", mock.Anything, userID).Return(tt.keys, nil)
+
+			var removed []string
+
+			for _, keyID := range tt.keys {
+				provErr := tt.provErrs[keyID]
+				prov.On("RevokeToken", mock.Anything, keyID).Return(provErr)
+
+				if provErr == nil {
+					removed = append(removed, keyID)
+					expectAudit(repo, userID, AuditActionRevoke, keyID)
+				}
+			}
+
+			if len(removed) > 0 {
+				repo.On("RevokeTokens", mock.Anything, userID, removed).Return(len(removed), nil)
+			}
+
+			svc := newTestService(t, Config{}, repo, prov)
+
+			revoked, err := svc.RevokeAllTokens(context.Background(), userID)
+			assert.Equal(t, tt.wantRevoked, revoked)
+
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			for _, want := range tt.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestRevokeAllTokens_RepoError(t *testing.T) {
+	userID := "user123"
+
+	repo := NewMockUserRepo(t)
+	prov := NewMockMITProv(t)
+
+	repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{"key1", "key2"}, nil)
+	prov.On("RevokeToken", mock.Anything, mock.Anything).Return(nil)
+	repo.On("RevokeTokens", mock.Anything, userID, []string{"key1", "key2"}).Return(0, assert.AnError)
+
+	svc := newTestService(t, Config{}, repo, prov)
+
+	revoked, err := svc.RevokeAllTokens(context.Background(), userID)
+	assert.Zero(t, revoked)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ErrorContains(t, err, "failed to remove API keys from repository")
+}
+
+func TestRevokeAllTokens_NoTokens(t *testing.T) {
+	repo := NewMockUserRepo(t)
+	repo.On("GetAPIKeys", mock.Anything, "user123").Return([]string{}, nil)
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	_, err := svc.RevokeAllTokens(context.Background(), "user123")
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+}
+
+func TestHandleRevokeAllResult(t *testing.T) {
+	userID := "user123"
+
+	tests := []struct {
+		setupMocks func(repo *MockUserRepo, prov *MockMITProv)
+		name       string
+		answer     string
+		wantMsg    string
+		wantErr    string
+	}{
+		{
+			name:       "declined",
+			answer:     "No",
+			setupMocks: func(*MockUserRepo, *MockMITProv) {},
+			wantMsg:    revokeAllCancelled,
+		},
+		{
+			name:   "all revoked",
+			answer: "Yes",
+			setupMocks: func(repo *MockUserRepo, prov *MockMITProv) {
+				repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{"key1", "key2"}, nil)
+				prov.On("RevokeToken", mock.Anything, mock.Anything).Return(nil)
+				repo.On("RevokeTokens", mock.Anything, userID, []string{"key1", "key2"}).Return(2, nil)
+				repo.On("AppendAuditLog", mock.Anything, mock.Anything).Return(nil)
+			},
+			wantMsg: "Revoked 2 token(s).",
+		},
+		{
+			name:   "partial failure",
+			answer: "Yes",
+			setupMocks: func(repo *MockUserRepo, prov *MockMITProv) {
+				repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{"key1", "key2"}, nil)
+				prov.On("RevokeToken", mock.Anything, "key1").Return(nil)
+				prov.On("RevokeToken", mock.Anything, "key2").Return(errors.New("provider down"))
+				repo.On("RevokeTokens", mock.Anything, userID, []string{"key1"}).Return(1, nil)
+				expectAudit(repo, userID, AuditActionRevoke, "key1")
+			},
+			wantMsg: "Revoked 1 token(s), but some couldn't be revoked.",
+		},
+		{
+			name:   "nothing revoked",
+			answer: "Yes",
+			setupMocks: func(repo *MockUserRepo, prov *MockMITProv) {
+				repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{"key1"}, nil)
+				prov.On("RevokeToken", mock.Anything, "key1").Return(errors.New("provider down"))
+			},
+			wantErr: "failed to revoke tokens",
+		},
+		{
+			name:   "tokens already gone",
+			answer: "Yes",
+			setupMocks: func(repo *MockUserRepo, _ *MockMITProv) {
+				repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{}, nil)
+			},
+			wantMsg: revokeAllNoTokens,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			prov := NewMockMITProv(t)
+
+			tt.setupMocks(repo, prov)
+
+			svc := newTestService(t, Config{}, repo, prov)
+
+			resp, err := svc.handleRevokeAllResult(context.Background(), userID, []conv.QuestionAnswer{{Answer: tt.answer}})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, resp.Message, tt.wantMsg)
+		})
+	}
+}
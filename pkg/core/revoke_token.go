@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
 )
@@ -10,8 +11,15 @@ import (
 // RevokeToken revokes a user's API token.
 // If the user has exactly one token, it is revoked directly and nil is returned for the response.
 // If the user has multiple tokens, a conversation is started to ask which token to revoke.
-// Returns an error if no tokens exist or if any step in the process fails.
+// Returns an error if no tokens exist or if any step in the process fails, and ErrUserBusy while
+// another request of the user is being processed.
 func (s *Service) RevokeToken(ctx context.Context, userID string) (*Response, error) {
+	unlock, err := s.lockUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	keys, err := s.repo.GetAPIKeys(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get API keys: %w", err)
@@ -32,6 +40,28 @@ func (s *Service) RevokeToken(ctx context.Context, userID string) (*Response, er
 	return s.askToSelectTokenForRevocation(ctx, userID)
 }
 
+// RevokeTokenByID revokes the API key identified by keyID on behalf of the given user.
+// It returns ErrKeyNotFound if the key does not belong to the user, so one user can never revoke another user's key,
+// and ErrUserBusy while another request of the user is being processed.
+func (s *Service) RevokeTokenByID(ctx context.Context, userID string, keyID string) error {
+	unlock, err := s.lockUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	keys, err := s.repo.GetAPIKeys(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	if !slices.Contains(keys, keyID) {
+		return ErrKeyNotFound
+	}
+
+	return s.revokeKeyByID(ctx, userID, keyID)
+}
+
 // askToSelectTokenForRevocation starts a conversation asking the user which of their tokens to revoke.
 func (s *Service) askToSelectTokenForRevocation(ctx context.Context, userID string) (*Response, error) {
 	keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
@@ -48,7 +78,7 @@ func (s *Service) askToSelectTokenForRevocation(ctx context.Context, userID stri
 
 	questions := conv.NewQuestions([]conv.Question{q})
 
-	if err := c.Start(StateSelectTokenToRevoke, questions); err != nil {
+	if err := s.startConversation(ctx, userID, c, StateSelectTokenToRevoke, questions); err != nil {
 		return nil, fmt.Errorf("failed to start questions: %w", err)
 	}
 
@@ -93,7 +123,7 @@ func (s *Service) handleSelectTokenToRevokeResult(ctx context.Context, userID st
 
 // revokeKeyByID revokes the given key ID from both the provider and the repository.
 func (s *Service) revokeKeyByID(ctx context.Context, userID string, keyID string) error {
-	if err := s.prov.RevokeToken(keyID); err != nil {
+	if err := s.prov.RevokeToken(ctx, keyID); err != nil {
 		return fmt.Errorf("failed to revoke token: %w", err)
 	}
 
@@ -101,5 +131,7 @@ func (s *Service) revokeKeyByID(ctx context.Context, userID string, keyID string
 		return fmt.Errorf("failed to remove API key from repository: %w", err)
 	}
 
+	s.audit(ctx, userID, AuditActionRevoke, keyID)
+
 	return nil
 }
@@ -62,19 +62,24 @@ func TestRevokeToken(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := NewMockUserRepo(t)
+			expectUserLock(repo, tt.userID)
 			prov := NewMockMITProv(t)
 
 			repo.On("GetAPIKeys", mock.Anything, tt.userID).Return(tt.existingKeys, tt.getKeysErr)
 
 			if len(tt.existingKeys) == 1 && tt.getKeysErr == nil {
-				prov.On("RevokeToken", tt.existingKeys[0]).Return(tt.revokeProvErr)
+				prov.On("RevokeToken", mock.Anything, tt.existingKeys[0]).Return(tt.revokeProvErr)
 
 				if tt.revokeProvErr == nil {
 					repo.On("RevokeToken", mock.Anything, tt.userID, tt.existingKeys[0]).Return(tt.revokeRepoErr)
 				}
+
+				if tt.revokeProvErr == nil && tt.revokeRepoErr == nil {
+					expectAudit(repo, tt.userID, AuditActionRevoke, tt.existingKeys[0])
+				}
 			}
 
-			svc := New(repo, prov)
+			svc := newTestService(t, Config{}, repo, prov)
 
 			resp, err := svc.RevokeToken(context.Background(), tt.userID)
 
@@ -101,6 +106,7 @@ func TestRevokeToken_MultipleKeys(t *testing.T) {
 	}
 
 	repo := NewMockUserRepo(t)
+	expectUserLock(repo, userID)
 	prov := NewMockMITProv(t)
 
 	repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{"abcdef1234", "xyz9876543"}, nil)
@@ -110,7 +116,7 @@ func TestRevokeToken_MultipleKeys(t *testing.T) {
 		return c.ID == userID && c.State == StateSelectTokenToRevoke
 	})).Return(nil)
 
-	svc := New(repo, prov)
+	svc := newTestService(t, Config{}, repo, prov)
 
 	resp, err := svc.RevokeToken(context.Background(), userID)
 
@@ -132,10 +138,11 @@ func TestHandleSelectTokenToRevokeResult(t *testing.T) {
 		prov := NewMockMITProv(t)
 
 		repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{keyID}, nil)
-		prov.On("RevokeToken", keyID).Return(nil)
+		prov.On("RevokeToken", mock.Anything, keyID).Return(nil)
 		repo.On("RevokeToken", mock.Anything, userID, keyID).Return(nil)
+		expectAudit(repo, userID, AuditActionRevoke, keyID)
 
-		svc := New(repo, prov)
+		svc := newTestService(t, Config{}, repo, prov)
 
 		answers := []conv.QuestionAnswer{
 			{Answer: keyID[:keyIDDisplayLen] + " (exp: 2026-03-01)"},
@@ -152,7 +159,7 @@ func TestHandleSelectTokenToRevokeResult(t *testing.T) {
 	})
 
 	t.Run("wrong number of answers", func(t *testing.T) {
-		svc := New(NewMockUserRepo(t), NewMockMITProv(t))
+		svc := newTestService(t, Config{}, NewMockUserRepo(t), NewMockMITProv(t))
 
 		_, err := svc.handleSelectTokenToRevokeResult(context.Background(), userID, nil)
 		require.Error(t, err)
@@ -165,7 +172,7 @@ func TestHandleSelectTokenToRevokeResult(t *testing.T) {
 
 		repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{"different123456"}, nil)
 
-		svc := New(repo, prov)
+		svc := newTestService(t, Config{}, repo, prov)
 
 		answers := []conv.QuestionAnswer{
 			{Answer: "nomatch1 (exp: 2026-03-01)"},
@@ -178,3 +185,73 @@ func TestHandleSelectTokenToRevokeResult(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 }
+
+func TestRevokeTokenByID(t *testing.T) {
+	tests := []struct {
+		getKeysErr    error
+		revokeProvErr error
+		expectedErr   error
+		name          string
+		keyID         string
+		existingKeys  []string
+		expectRevoke  bool
+	}{
+		{
+			name:         "revokes owned key among several",
+			keyID:        "key2",
+			existingKeys: []string{"key1", "key2", "key3"},
+			expectRevoke: true,
+		},
+		{
+			name:         "rejects key owned by another user",
+			keyID:        "foreign",
+			existingKeys: []string{"key1", "key2"},
+			expectedErr:  ErrKeyNotFound,
+		},
+		{
+			name:        "get keys error",
+			keyID:       "key1",
+			getKeysErr:  errors.New("redis error"),
+			expectedErr: errors.New("failed to get API keys: redis error"),
+		},
+		{
+			name:          "provider error",
+			keyID:         "key1",
+			existingKeys:  []string{"key1"},
+			revokeProvErr: errors.New("provider down"),
+			expectedErr:   errors.New("failed to revoke token: provider down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			expectUserLock(repo, "user123")
+			prov := NewMockMITProv(t)
+
+			repo.On("GetAPIKeys", mock.Anything, "user123").Return(tt.existingKeys, tt.getKeysErr)
+
+			if tt.expectRevoke || tt.revokeProvErr != nil {
+				prov.On("RevokeToken", mock.Anything, tt.keyID).Return(tt.revokeProvErr)
+			}
+
+			if tt.expectRevoke {
+				repo.On("RevokeToken", mock.Anything, "user123", tt.keyID).Return(nil)
+				expectAudit(repo, "user123", AuditActionRevoke, tt.keyID)
+			}
+
+			svc := newTestService(t, Config{}, repo, prov)
+
+			err := svc.RevokeTokenByID(context.Background(), "user123", tt.keyID)
+
+			switch {
+			case tt.expectedErr == nil:
+				assert.NoError(t, err)
+			case errors.Is(tt.expectedErr, ErrKeyNotFound):
+				assert.ErrorIs(t, err, ErrKeyNotFound)
+			default:
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// TokenStats aggregates the active tokens of all users.
+type TokenStats struct {
+	// Users is the number of users with at least one active token.
+	Users int
+	// Web is the number of active web tokens.
+	Web int
+	// TCP is the number of active TCP tokens.
+	TCP int
+}
+
+// Total returns the number of active tokens of all types.
+func (s TokenStats) Total() int {
+	return s.Web + s.TCP
+}
+
+// Stats returns the usage summary of the bot across all users.
+func (s *Service) Stats(ctx context.Context) (*TokenStats, error) {
+	stats, err := s.repo.GetTokenStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token stats: %w", err)
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	repo := NewMockUserRepo(t)
+	repo.On("GetTokenStats", mock.Anything).Return(TokenStats{Users: 2, Web: 3, TCP: 1}, nil)
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	stats, err := svc.Stats(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, &TokenStats{Users: 2, Web: 3, TCP: 1}, stats)
+	assert.Equal(t, 4, stats.Total())
+}
+
+func TestStats_Error(t *testing.T) {
+	repo := NewMockUserRepo(t)
+	repo.On("GetTokenStats", mock.Anything).Return(TokenStats{}, assert.AnError)
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	_, err := svc.Stats(context.Background())
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ErrorContains(t, err, "failed to get token stats")
+}
@@ -4,49 +4,174 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
 )
 
+const (
+	invalidAnswerMessage = "Sorry, I can't accept that answer."
+)
+
 var (
 	ErrTokenNotFound = fmt.Errorf("token not found")
+	// ErrNoActiveConversation is returned by HandleMessage when the user has no question to answer.
+	ErrNoActiveConversation = errors.New("no active conversation")
+	// ErrUserChatNotFound is returned by UserRepo.GetUserChat when no chat is known for the user.
+	ErrUserChatNotFound = errors.New("user chat not found")
+	// ErrUserChatInactive is returned by UserRepo.GetUserChat when the user's chat was deactivated,
+	// e.g. because the user blocked the bot.
+	ErrUserChatInactive = errors.New("user chat is inactive")
 )
 
 // UserRepo defines the storage operations required by the core service.
 type UserRepo interface {
-	AddAPIKey(ctx context.Context, userID string, apiKeyID string, tokenType TokenType, expiresIn time.Duration) error
+	AddAPIKey(ctx context.Context, userID string, apiKeyID string, tokenType TokenType, name string, expiresIn time.Duration) error
+	AddAPIKeyWithLimit(ctx context.Context, userID string, apiKeyID string, tokenType TokenType, name string, expiresIn time.Duration, limit int) error
 	GetAPIKeys(ctx context.Context, userID string) ([]string, error)
 	GetAPIKeysWithExpiration(ctx context.Context, userID string) ([]KeyInfo, error)
 	RevokeToken(ctx context.Context, userID string, apiKeyID string) error
+	RevokeTokens(ctx context.Context, userID string, apiKeyIDs []string) (int, error)
 	SaveConversation(ctx context.Context, conversation *conv.Conversation) error
 	GetConversation(ctx context.Context, conversationID string) (*conv.Conversation, error)
 	DeleteConversation(ctx context.Context, conversationID string) error
+	GetExpiredFlow(ctx context.Context, conversationID string) (conv.State, error)
+	IncrementTokenCreationCount(ctx context.Context, userID string, window time.Duration) (int, error)
+	GetTokenCreationCount(ctx context.Context, userID string) (int, time.Time, error)
+	SetLastRegeneration(ctx context.Context, userID string, at time.Time, ttl time.Duration) error
+	GetLastRegeneration(ctx context.Context, userID string) (time.Time, error)
+	SaveUserChat(ctx context.Context, userID string, chatID int64) error
+	GetUserChat(ctx context.Context, userID string) (int64, error)
+	DeactivateUserChat(ctx context.Context, userID string) error
+	SubscribeExpiredTokens(ctx context.Context) (<-chan ExpiredToken, error)
+	AppendAuditLog(ctx context.Context, entry AuditEntry) error
+	GetAuditLog(ctx context.Context, userID string, limit int) ([]AuditEntry, error)
+	GetTokenStats(ctx context.Context) (TokenStats, error)
+	SaveFeedback(ctx context.Context, feedback Feedback) error
+	SetUserSetting(ctx context.Context, userID, key, value string) error
+	GetUserSetting(ctx context.Context, userID, key string) (string, error)
+	DeleteUserSetting(ctx context.Context, userID, key string) error
+	AcquireUserLock(ctx context.Context, userID string, ttl time.Duration) (ReleaseFunc, error)
+	ClaimIdempotencyKey(ctx context.Context, userID, key string, ttl time.Duration) (bool, error)
+	MarkIdempotencyKeyDone(ctx context.Context, userID, key string) error
+	ReleaseIdempotencyKey(ctx context.Context, userID, key string) error
 }
 
 // MITProv defines the external API operations for managing tokens.
 // GenerateToken creates a new token of the given type; RevokeToken removes it.
+// GetToken returns the provider's details of an existing token or ErrTokenNotFound.
 type MITProv interface {
-	GenerateToken(keyID string, tokenType TokenType, ttl int64) (*APIToken, error)
-	RevokeToken(keyID string) error
+	GenerateToken(ctx context.Context, keyID string, tokenType TokenType, ttl int64) (*APIToken, error)
+	RevokeToken(ctx context.Context, keyID string) error
+	RenewToken(ctx context.Context, keyID string, ttl int64) error
+	GetToken(ctx context.Context, keyID string) (*TokenDetails, error)
 }
 
 type Response struct {
-	Message string   `json:"message"` // Main response message
-	Answers []string `json:"answers"` // Possible answers for the follow-up question
+	Message  string   `json:"message"`  // Main response message
+	Answers  []string `json:"answers"`  // Possible answers for the follow-up question
+	Markdown bool     `json:"markdown"` // Message is formatted as Telegram MarkdownV2
+	// Feedback is set when the user submitted feedback that should be forwarded to the operators.
+	Feedback *Feedback `json:"feedback,omitempty"`
+}
+
+// Config holds the tunable settings of the core service.
+type Config struct {
+	MaxExpirationDays int `mapstructure:"max_expiration_days"`
+	DailyTokenLimit   int `mapstructure:"daily_token_limit"`
+	MaxWebTokens      int `mapstructure:"max_web_tokens"`
+	MaxTCPTokens      int `mapstructure:"max_tcp_tokens"`
+	// Timezone is the IANA name of the timezone expiry times are shown in to users without a preference.
+	Timezone string `mapstructure:"timezone"`
+	// ExpirationPresets are the expiration periods offered as answers, given as hours or days, e.g. "12 hours" or "7 days".
+	ExpirationPresets []string `mapstructure:"expiration_presets"`
+	// RegenerateCooldown is the minimum time between two token regenerations of a user, a minute by default.
+	RegenerateCooldown time.Duration `mapstructure:"regenerate_cooldown"`
+	// MessageTemplates is the path to a text/template file overriding the built-in token messages by name.
+	MessageTemplates string `mapstructure:"message_templates"`
+}
+
+// Validate checks the settings New can't fall back from: the message templates must load and render.
+func (c *Config) Validate() error {
+	if _, err := loadMessageTemplates(c.MessageTemplates); err != nil {
+		return fmt.Errorf("message_templates is invalid: %w", err)
+	}
+
+	return nil
 }
 
 type Service struct {
-	repo UserRepo
-	prov MITProv
+	repo               UserRepo
+	prov               MITProv
+	location           *time.Location
+	templates          *messageTemplates
+	expirationPresets  []expirationPreset
+	maxExpirationDays  int
+	dailyTokenLimit    int
+	maxWebTokens       int
+	maxTCPTokens       int
+	regenerateCooldown time.Duration
 }
 
-// New initializes and returns a new Service instance with the provided UserRepo and MITProv.
-func New(repo UserRepo, prov MITProv) *Service {
-	return &Service{
-		repo: repo,
-		prov: prov,
+// New initializes and returns a new Service instance with the provided Config, UserRepo and MITProv.
+// Zero values in cfg are replaced with defaults, an unknown timezone is replaced with UTC and invalid
+// expiration presets are dropped. It returns an error if the message templates can't be loaded.
+func New(cfg Config, repo UserRepo, prov MITProv) (*Service, error) {
+	maxExpirationDays := cfg.MaxExpirationDays
+	if maxExpirationDays <= 0 {
+		maxExpirationDays = defaultMaxExpirationDays
+	}
+
+	dailyTokenLimit := cfg.DailyTokenLimit
+	if dailyTokenLimit <= 0 {
+		dailyTokenLimit = defaultDailyTokenLimit
+	}
+
+	maxWebTokens := cfg.MaxWebTokens
+	if maxWebTokens <= 0 {
+		maxWebTokens = defaultMaxWebTokensPerUser
+	}
+
+	maxTCPTokens := cfg.MaxTCPTokens
+	if maxTCPTokens <= 0 {
+		maxTCPTokens = defaultMaxTCPTokensPerUser
 	}
+
+	regenerateCooldown := cfg.RegenerateCooldown
+	if regenerateCooldown <= 0 {
+		regenerateCooldown = defaultRegenerateCooldown
+	}
+
+	timezone := cfg.Timezone
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+
+	location, err := loadLocation(timezone)
+	if err != nil {
+		slog.Warn("Unknown timezone, falling back to UTC", slog.String("timezone", timezone), slog.Any("error", err))
+
+		location = time.UTC
+	}
+
+	templates, err := loadMessageTemplates(cfg.MessageTemplates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		repo:               repo,
+		prov:               prov,
+		location:           location,
+		templates:          templates,
+		expirationPresets:  newExpirationPresets(cfg.ExpirationPresets, maxExpirationDays),
+		maxExpirationDays:  maxExpirationDays,
+		dailyTokenLimit:    dailyTokenLimit,
+		maxWebTokens:       maxWebTokens,
+		maxTCPTokens:       maxTCPTokens,
+		regenerateCooldown: regenerateCooldown,
+	}, nil
 }
 
 // ResetConversation deletes the conversation associated with a user.
@@ -55,18 +180,75 @@ func (s *Service) ResetConversation(ctx context.Context, userID string) error {
 		return fmt.Errorf("failed to delete conversation: %w", err)
 	}
 
+	slog.DebugContext(ctx, "Conversation reset", slog.String("user_id", userID))
+
 	return nil
 }
 
+// SaveUserChat remembers the chat used to reach the user, so that messages can be sent outside of an incoming update.
+func (s *Service) SaveUserChat(ctx context.Context, userID string, chatID int64) error {
+	if err := s.repo.SaveUserChat(ctx, userID, chatID); err != nil {
+		return fmt.Errorf("failed to save user chat: %w", err)
+	}
+
+	return nil
+}
+
+// DeactivateUserChat stops proactive messages to the user, whose chat can't be reached anymore,
+// until the user writes to the bot again.
+func (s *Service) DeactivateUserChat(ctx context.Context, userID string) error {
+	if err := s.repo.DeactivateUserChat(ctx, userID); err != nil {
+		return fmt.Errorf("failed to deactivate user chat: %w", err)
+	}
+
+	return nil
+}
+
+// HasActiveConversation reports whether the user's conversation is waiting for an answer,
+// so that free text can be told apart from an answer to a question.
+func (s *Service) HasActiveConversation(ctx context.Context, userID string) (bool, error) {
+	cnv, err := s.repo.GetConversation(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	return cnv.IsActive(), nil
+}
+
 // HandleMessage processes an incoming user message within a conversation context and returns a response or an error.
+// It returns ErrUserBusy while another request of the user is being processed. An answer to a question that
+// has been answered already gets a short reply instead of being handled again.
 func (s *Service) HandleMessage(ctx context.Context, userID string, message string) (*Response, error) {
+	unlock, err := s.lockUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	cnv, err := s.repo.GetConversation(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
 
+	return s.idempotent(ctx, userID, cnv, func() (*Response, error) {
+		return s.handleMessage(ctx, userID, cnv, message)
+	})
+}
+
+// handleMessage submits the message as the answer to the current question of the user's conversation
+// and runs the result handler of the conversation state once all questions are answered.
+func (s *Service) handleMessage(ctx context.Context, userID string, cnv *conv.Conversation, message string) (*Response, error) {
 	state, err := cnv.Submit(message)
-	if err != nil {
+	if err == nil {
+		logTransition(ctx, userID, state, cnv.State)
+	}
+
+	switch {
+	case errors.Is(err, conv.ErrNotActive):
+		return nil, ErrNoActiveConversation
+	case errors.Is(err, conv.ErrInvalidAnswer):
+		return s.reaskCurrentQuestion(cnv, err)
+	case err != nil:
 		return nil, fmt.Errorf("failed to submit message: %w", err)
 	}
 
@@ -83,21 +265,18 @@ func (s *Service) HandleMessage(ctx context.Context, userID string, message stri
 			return nil, fmt.Errorf("failed to save conversation: %w", err)
 		}
 
-		return &Response{
-			Message: q.Text,
-			Answers: q.Answers,
-		}, nil
+		return newQuestionResponse(cnv, q), nil
 	case err != nil:
 		return nil, fmt.Errorf("failed to get results: %w", err)
 	}
 
+	logTransition(ctx, userID, conv.StateComplete, cnv.State)
+
 	if err := s.repo.SaveConversation(ctx, cnv); err != nil {
 		return nil, fmt.Errorf("failed to save conversation: %w", err)
 	}
 
 	switch state {
-	case StateSelectTokenType:
-		return s.handleSelectTokenTypeResult(ctx, userID, res)
 	case StateTokenExists:
 		return s.handleTokenExistsResult(ctx, userID, res)
 	case StateEnterKeyID:
@@ -110,7 +289,34 @@ func (s *Service) HandleMessage(ctx context.Context, userID string, message stri
 		return s.handleSelectTokenToRegenerateResult(ctx, userID, res)
 	case StateSelectTokenToRevoke:
 		return s.handleSelectTokenToRevokeResult(ctx, userID, res)
+	case StateSelectTokenToRenew:
+		return s.handleSelectTokenToRenewResult(ctx, userID, res)
+	case StateRenewToken:
+		return s.handleRenewTokenResult(ctx, userID, res)
+	case StateFeedback:
+		return s.handleFeedbackResult(ctx, userID, res)
+	case StateRevokeAll:
+		return s.handleRevokeAllResult(ctx, userID, res)
 	default:
 		return nil, fmt.Errorf("unsupported conversation state: %s", state)
 	}
 }
+
+// reaskCurrentQuestion repeats the current question after the user's answer was rejected with answerErr.
+// A validator message explaining the rejection is shown instead of the generic one when available.
+func (s *Service) reaskCurrentQuestion(cnv *conv.Conversation, answerErr error) (*Response, error) {
+	q, err := cnv.Current()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current question: %w", err)
+	}
+
+	reason := invalidAnswerMessage
+	if vErr := (*conv.ValidationError)(nil); errors.As(answerErr, &vErr) {
+		reason = vErr.Message
+	}
+
+	resp := newQuestionResponse(cnv, q)
+	resp.Message = reason + "\n\n" + resp.Message
+
+	return resp, nil
+}
@@ -1,18 +1,73 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+// newTestService creates a Service with New and fails the test if New rejects cfg.
+func newTestService(t *testing.T, cfg Config, repo UserRepo, prov MITProv) *Service {
+	t.Helper()
+
+	svc, err := New(cfg, repo, prov)
+	require.NoError(t, err)
+
+	return svc
+}
+
 func TestNewService(t *testing.T) {
 	repo := NewMockUserRepo(t)
 	prov := NewMockMITProv(t)
 
-	svc := New(repo, prov)
+	svc, err := New(Config{}, repo, prov)
+	require.NoError(t, err)
 
 	assert.NotNil(t, svc)
 	assert.Equal(t, repo, svc.repo)
 	assert.Equal(t, prov, svc.prov)
 }
+
+func TestSaveUserChat(t *testing.T) {
+	tests := []struct {
+		repoErr error
+		name    string
+		wantErr string
+	}{
+		{name: "saved"},
+		{name: "repository error", repoErr: errors.New("redis error"), wantErr: "failed to save user chat: redis error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			repo.On("SaveUserChat", mock.Anything, "user123", int64(42)).Return(tt.repoErr)
+
+			svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+			err := svc.SaveUserChat(context.Background(), "user123", 42)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDeactivateUserChat(t *testing.T) {
+	repo := NewMockUserRepo(t)
+	repo.On("DeactivateUserChat", mock.Anything, "user123").Return(nil).Once()
+	repo.On("DeactivateUserChat", mock.Anything, "user123").Return(errors.New("redis error")).Once()
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	assert.NoError(t, svc.DeactivateUserChat(context.Background(), "user123"))
+	assert.EqualError(t, svc.DeactivateUserChat(context.Background(), "user123"), "failed to deactivate user chat: redis error")
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/template"
+)
+
+const (
+	templateTokenCreated = "token_created"
+	templateListTokens   = "list_tokens"
+)
+
+//go:embed templates/messages.tmpl
+var defaultTemplatesFS embed.FS
+
+// templateFuncs are available to all message templates.
+var templateFuncs = template.FuncMap{
+	"code":   markdownCode,
+	"escape": EscapeMarkdown,
+}
+
+// defaultTemplates are the built-in message templates.
+var defaultTemplates = template.Must(
+	template.New("messages").Funcs(templateFuncs).ParseFS(defaultTemplatesFS, "templates/messages.tmpl"),
+)
+
+// tokenCreatedData is rendered by the token_created template, which is sent as MarkdownV2.
+type tokenCreatedData struct {
+	Token     string
+	ExpiresAt string
+}
+
+// tokenListData is rendered by the list_tokens template.
+type tokenListData struct {
+	Tokens   []tokenListEntry
+	WebCount int
+	MaxWeb   int
+	TCPCount int
+	MaxTCP   int
+}
+
+// tokenListEntry is a single token of tokenListData.
+type tokenListEntry struct {
+	Type      string
+	Name      string
+	KeyID     string
+	ExpiresAt string
+	Number    int
+}
+
+// messageTemplates renders messages by template name. Templates defined in the custom file take precedence,
+// the built-in defaults are used for the rest. A nil messageTemplates renders the defaults only.
+type messageTemplates struct {
+	custom *template.Template
+}
+
+// sampleTemplateData holds example data for every message template, used to check custom templates on load.
+var sampleTemplateData = map[string]any{
+	templateTokenCreated: tokenCreatedData{Token: "token", ExpiresAt: "2030-01-02 15:04:05 UTC (in 7 days)"},
+	templateListTokens: tokenListData{
+		Tokens:   []tokenListEntry{{Number: 1, Type: string(TokenTypeWeb), Name: "home server", KeyID: "abcdef123456", ExpiresAt: "2030-01-02 15:04:05 UTC (in 7 days)"}},
+		WebCount: 1,
+		MaxWeb:   defaultMaxWebTokensPerUser,
+		MaxTCP:   defaultMaxTCPTokensPerUser,
+	},
+}
+
+// loadMessageTemplates parses the custom message templates from the file at path and renders each of them
+// with sample data, so that a broken template is reported up front instead of when a user first needs it.
+// An empty path yields the built-in defaults only.
+func loadMessageTemplates(path string) (*messageTemplates, error) {
+	if path == "" {
+		return &messageTemplates{}, nil
+	}
+
+	custom, err := template.New("custom").Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message templates: %w", err)
+	}
+
+	for name, data := range sampleTemplateData {
+		tmpl := custom.Lookup(name)
+		if tmpl == nil {
+			continue
+		}
+
+		if err := tmpl.Execute(io.Discard, data); err != nil {
+			return nil, fmt.Errorf("failed to render message template %q: %w", name, err)
+		}
+	}
+
+	return &messageTemplates{custom: custom}, nil
+}
+
+// render executes the template called name with data. A custom template that fails to execute
+// is logged and replaced with the default one.
+func (t *messageTemplates) render(name string, data any) (string, error) {
+	if tmpl := t.lookupCustom(name); tmpl != nil {
+		var buf bytes.Buffer
+
+		err := tmpl.Execute(&buf, data)
+		if err == nil {
+			return buf.String(), nil
+		}
+
+		slog.Error("Failed to render custom message template, using the default",
+			slog.String("template", name),
+			slog.Any("error", err),
+		)
+	}
+
+	var buf bytes.Buffer
+	if err := defaultTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("failed to render message template %q: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// lookupCustom returns the custom template called name or nil if it isn't defined.
+func (t *messageTemplates) lookupCustom(name string) *template.Template {
+	if t == nil || t.custom == nil {
+		return nil
+	}
+
+	return t.custom.Lookup(name)
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTemplates writes content to a template file in a temporary directory and returns its path.
+func writeTemplates(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "messages.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestMessageTemplates_Defaults(t *testing.T) {
+	tests := []struct {
+		data any
+		name string
+		tmpl string
+		want string
+	}{
+		{
+			name: "token created",
+			tmpl: templateTokenCreated,
+			data: tokenCreatedData{Token: "tok_en", ExpiresAt: "2030-01-02 15:04:05 UTC"},
+			want: "🔑 *Your New API Token*\n\n`tok_en`\n\n⏱ *Valid until:* 2030\\-01\\-02 15:04:05 UTC\n\n" +
+				"Keep this token secure and don't share it with others\\.",
+		},
+		{
+			name: "token list",
+			tmpl: templateListTokens,
+			data: tokenListData{
+				Tokens: []tokenListEntry{
+					{Number: 1, Type: "web", Name: "home server", KeyID: "aaabbb123456", ExpiresAt: "2030-01-02 15:04:05 UTC"},
+					{Number: 2, Type: "tcp", Name: unnamedTokenName, KeyID: "cccddd", ExpiresAt: "2030-02-03 10:00:00 UTC"},
+				},
+				WebCount: 1,
+				MaxWeb:   3,
+				TCPCount: 1,
+				MaxTCP:   1,
+			},
+			want: "🔑 Your Active API Tokens (Web: 1/3, TCP: 1/1)\n\n" +
+				"1. [web] home server — aaabbb123456...\n   ⏱ Expires: 2030-01-02 15:04:05 UTC\n" +
+				"2. [tcp] (unnamed) — cccddd...\n   ⏱ Expires: 2030-02-03 10:00:00 UTC\n" +
+				"\nUse /new_token to create a new token or /revoke_token to revoke one.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tmpls *messageTemplates
+
+			got, err := tmpls.render(tt.tmpl, tt.data)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMessageTemplates_Custom(t *testing.T) {
+	path := writeTemplates(t, `{{define "list_tokens"}}{{len .Tokens}} of {{.MaxWeb}}:{{range .Tokens}} {{.Name}}{{end}}{{end}}`+
+		`{{define "token_created"}}{{if gt (len .Token) 5}}{{.Missing}}{{end}}{{.Token}}{{end}}`)
+
+	tmpls, err := loadMessageTemplates(path)
+	require.NoError(t, err)
+
+	got, err := tmpls.render(templateListTokens, tokenListData{
+		Tokens: []tokenListEntry{{Name: "one"}, {Name: "two"}},
+		MaxWeb: 3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2 of 3: one two", got)
+
+	// A custom template that passed the check on load but fails to render for some data falls back to the default one.
+	got, err = tmpls.render(templateTokenCreated, tokenCreatedData{Token: "longer token"})
+	require.NoError(t, err)
+	assert.Contains(t, got, "Your New API Token")
+}
+
+func TestMessageTemplates_MissingFallsBackToDefault(t *testing.T) {
+	tmpls, err := loadMessageTemplates(writeTemplates(t, `{{define "list_tokens"}}custom{{end}}`))
+	require.NoError(t, err)
+
+	got, err := tmpls.render(templateTokenCreated, tokenCreatedData{Token: "tok", ExpiresAt: "never"})
+	require.NoError(t, err)
+	assert.Contains(t, got, "`tok`")
+
+	_, err = tmpls.render("unknown", nil)
+	assert.ErrorContains(t, err, `failed to render message template "unknown"`)
+}
+
+func TestLoadMessageTemplates_Error(t *testing.T) {
+	_, err := loadMessageTemplates(filepath.Join(t.TempDir(), "missing.tmpl"))
+	assert.ErrorContains(t, err, "failed to parse message templates")
+
+	_, err = loadMessageTemplates(writeTemplates(t, `{{define "list_tokens"}}{{.Tokens`))
+	assert.ErrorContains(t, err, "failed to parse message templates")
+
+	_, err = loadMessageTemplates(writeTemplates(t, `{{define "list_tokens"}}{{range .Tokens}}{{.Missing}}{{end}}{{end}}`))
+	assert.ErrorContains(t, err, `failed to render message template "list_tokens"`)
+
+	_, err = loadMessageTemplates(writeTemplates(t, `{{define "token_created"}}{{code .ExpiresAt.Missing}}{{end}}`))
+	assert.ErrorContains(t, err, `failed to render message template "token_created"`)
+}
+
+func TestNew_MessageTemplates(t *testing.T) {
+	svc := newTestService(t, Config{MessageTemplates: writeTemplates(t, `{{define "token_created"}}token {{.Token}}{{end}}`)}, nil, nil)
+
+	resp, err := svc.tokenCreatedResponse("tok", "never")
+	require.NoError(t, err)
+	assert.Equal(t, "token tok", resp.Message)
+
+	_, err = New(Config{MessageTemplates: filepath.Join(t.TempDir(), "missing.tmpl")}, nil, nil)
+	assert.ErrorContains(t, err, "failed to parse message templates")
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&Config{}).Validate())
+	assert.NoError(t, (&Config{MessageTemplates: writeTemplates(t, `{{define "list_tokens"}}{{.WebCount}}{{end}}`)}).Validate())
+
+	err := (&Config{MessageTemplates: writeTemplates(t, `{{define "list_tokens"}}{{.Count}}{{end}}`)}).Validate()
+	assert.ErrorContains(t, err, `message_templates is invalid: failed to render message template "list_tokens"`)
+}
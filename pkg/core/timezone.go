@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// SettingTimezone is the user setting holding the IANA name of the user's preferred timezone.
+	SettingTimezone = "timezone"
+	// defaultTimezone is used for expiry times when Config.Timezone is unset.
+	defaultTimezone = "UTC"
+	// expiryLayout formats expiry times with the zone abbreviation, e.g. "2025-01-02 15:04:05 CET".
+	expiryLayout = "2006-01-02 15:04:05 MST"
+
+	timezoneMessage    = "🕒 Expiry times are shown in %s.\n\nUse /timezone <name> to change it, e.g. /timezone Europe/Berlin."
+	timezoneSetMessage = "🕒 Timezone set to %s, expiry times are now shown in it."
+)
+
+// ErrInvalidTimezone is returned by SetTimezone when the name is not a known IANA timezone.
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// SetTimezone stores the preferred timezone of the user, given as an IANA name such as "Europe/Berlin".
+// An empty name leaves the preference as is and reports the timezone currently used for the user.
+// Returns ErrInvalidTimezone if the name is not a known timezone.
+func (s *Service) SetTimezone(ctx context.Context, userID, name string) (*Response, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return &Response{
+			Message: fmt.Sprintf(timezoneMessage, s.userLocation(ctx, userID)),
+		}, nil
+	}
+
+	loc, err := loadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetUserSetting(ctx, userID, SettingTimezone, loc.String()); err != nil {
+		return nil, fmt.Errorf("failed to set timezone: %w", err)
+	}
+
+	return &Response{
+		Message: fmt.Sprintf(timezoneSetMessage, loc),
+	}, nil
+}
+
+// userLocation returns the preferred timezone of the user, falling back to the configured one when the user
+// has none. Expiry times are only displayed with it, so a failure to read the preference is logged and ignored.
+func (s *Service) userLocation(ctx context.Context, userID string) *time.Location {
+	name, err := s.repo.GetUserSetting(ctx, userID, SettingTimezone)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get user timezone", slog.String("user_id", userID), slog.Any("error", err))
+		return s.location
+	}
+
+	if name == "" {
+		return s.location
+	}
+
+	loc, err := loadLocation(name)
+	if err != nil {
+		return s.location
+	}
+
+	return loc
+}
+
+// loadLocation loads the IANA timezone name. "Local" is rejected, as it means the timezone of the server.
+func loadLocation(name string) (*time.Location, error) {
+	if strings.EqualFold(name, "local") {
+		return nil, ErrInvalidTimezone
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTimezone, name)
+	}
+
+	return loc, nil
+}
+
+// formatExpiry formats an expiry time in loc, including the zone abbreviation.
+func formatExpiry(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(expiryLayout)
+}
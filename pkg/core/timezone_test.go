@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// expectTimezone sets up the lookup of the user's timezone preference for a user without one.
+func expectTimezone(repo *MockUserRepo, userID string) {
+	repo.On("GetUserSetting", mock.Anything, userID, SettingTimezone).Return("", nil)
+}
+
+func TestSetTimezone(t *testing.T) {
+	userID := "user123"
+
+	tests := []struct {
+		setupMocks func(repo *MockUserRepo)
+		name       string
+		timezone   string
+		wantMsg    string
+		wantErr    error
+	}{
+		{
+			name:     "stores known timezone",
+			timezone: " Europe/Berlin ",
+			setupMocks: func(repo *MockUserRepo) {
+				repo.On("SetUserSetting", mock.Anything, userID, SettingTimezone, "Europe/Berlin").Return(nil)
+			},
+			wantMsg: "Timezone set to Europe/Berlin",
+		},
+		{
+			name:     "shows configured timezone without preference",
+			timezone: "",
+			setupMocks: func(repo *MockUserRepo) {
+				expectTimezone(repo, userID)
+			},
+			wantMsg: "Expiry times are shown in UTC.",
+		},
+		{
+			name:     "shows stored preference",
+			timezone: "",
+			setupMocks: func(repo *MockUserRepo) {
+				repo.On("GetUserSetting", mock.Anything, userID, SettingTimezone).Return("Asia/Tokyo", nil)
+			},
+			wantMsg: "Expiry times are shown in Asia/Tokyo.",
+		},
+		{
+			name:       "rejects unknown timezone",
+			timezone:   "Mars/Olympus",
+			setupMocks: func(*MockUserRepo) {},
+			wantErr:    ErrInvalidTimezone,
+		},
+		{
+			name:       "rejects server local timezone",
+			timezone:   "Local",
+			setupMocks: func(*MockUserRepo) {},
+			wantErr:    ErrInvalidTimezone,
+		},
+		{
+			name:     "repo error",
+			timezone: "UTC",
+			setupMocks: func(repo *MockUserRepo) {
+				repo.On("SetUserSetting", mock.Anything, userID, SettingTimezone, "UTC").Return(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			tt.setupMocks(repo)
+
+			svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+			resp, err := svc.SetTimezone(context.Background(), userID, tt.timezone)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, resp.Message, tt.wantMsg)
+		})
+	}
+}
+
+func TestUserLocation(t *testing.T) {
+	userID := "user123"
+
+	tests := []struct {
+		getErr   error
+		name     string
+		cfgZone  string
+		stored   string
+		wantZone string
+	}{
+		{name: "defaults to UTC", wantZone: "UTC"},
+		{name: "configured timezone", cfgZone: "America/New_York", wantZone: "America/New_York"},
+		{name: "user preference wins", cfgZone: "America/New_York", stored: "Asia/Tokyo", wantZone: "Asia/Tokyo"},
+		{name: "unknown configured timezone falls back to UTC", cfgZone: "Nowhere/Land", wantZone: "UTC"},
+		{name: "unknown stored timezone falls back", cfgZone: "Europe/Paris", stored: "Nowhere/Land", wantZone: "Europe/Paris"},
+		{name: "repo error falls back", cfgZone: "Europe/Paris", getErr: assert.AnError, wantZone: "Europe/Paris"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			repo.On("GetUserSetting", mock.Anything, userID, SettingTimezone).Return(tt.stored, tt.getErr)
+
+			svc := newTestService(t, Config{Timezone: tt.cfgZone}, repo, NewMockMITProv(t))
+
+			assert.Equal(t, tt.wantZone, svc.userLocation(context.Background(), userID).String())
+		})
+	}
+}
+
+func TestFormatExpiry(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	expiresAt := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "2025-01-02 12:00:00 UTC", formatExpiry(expiresAt, time.UTC))
+	assert.Equal(t, "2025-01-02 13:00:00 CET", formatExpiry(expiresAt, berlin))
+}
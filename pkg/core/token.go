@@ -6,9 +6,9 @@ import "time"
 type TokenType string
 
 const (
-	// TokenTypeWeb is a web-tunneling token (max 3 per user).
+	// TokenTypeWeb is a web-tunneling token (3 per user by default).
 	TokenTypeWeb TokenType = "web"
-	// TokenTypeTCP is a raw TCP-tunneling token (max 1 per user).
+	// TokenTypeTCP is a raw TCP-tunneling token (1 per user by default).
 	TokenTypeTCP TokenType = "tcp"
 )
 
@@ -20,9 +20,18 @@ type APIToken struct {
 	ExpiresIn time.Duration
 }
 
+// TokenDetails is the provider's authoritative view of an existing token.
+type TokenDetails struct {
+	KeyID     string
+	Type      TokenType
+	Status    string        // Provider-reported status, e.g. "active"
+	ExpiresIn time.Duration // Remaining lifetime of the token
+}
+
 // KeyInfo holds the display information for an existing API key.
 type KeyInfo struct {
 	ExpiresAt time.Time
 	KeyID     string
+	Name      string // Optional user-supplied label, empty when unnamed
 	Type      TokenType
 }
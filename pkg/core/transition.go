@@ -0,0 +1,39 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+)
+
+// startConversation starts the questions of the given state in the user's conversation as a new session and logs
+// the transition.
+func (s *Service) startConversation(ctx context.Context, userID string, c *conv.Conversation, state conv.State, questions conv.Questions) error {
+	from := c.State
+
+	if err := c.Start(state, questions); err != nil {
+		return err
+	}
+
+	c.Session = uuid.NewString()
+
+	logTransition(ctx, userID, from, c.State)
+
+	return nil
+}
+
+// logTransition records a change of the conversation state at debug level, so that stuck conversations can be
+// traced without flooding the logs. The request and chat IDs are added by the logger from the context.
+func logTransition(ctx context.Context, userID string, from, to conv.State) {
+	if from == to {
+		return
+	}
+
+	slog.DebugContext(ctx, "Conversation state changed",
+		slog.String("user_id", userID),
+		slog.String("from", string(from)),
+		slog.String("to", string(to)),
+	)
+}
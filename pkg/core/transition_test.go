@@ -0,0 +1,85 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// captureDebugLogs sends the default logger to a buffer at debug level for the rest of the test.
+func captureDebugLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	return &buf
+}
+
+func TestConversationTransitionsAreLogged(t *testing.T) {
+	userID := "user123"
+	buf := captureDebugLogs(t)
+
+	cnv := conv.New(userID)
+
+	repo := NewMockUserRepo(t)
+	repo.On("GetAPIKeys", mock.Anything, userID).Return([]string{"key1"}, nil)
+	repo.On("GetConversation", mock.Anything, userID).Return(cnv, nil)
+	repo.On("SaveConversation", mock.Anything, cnv).Return(nil)
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+	ctx := context.Background()
+
+	_, err := svc.ConfirmRevokeAll(ctx, userID)
+	require.NoError(t, err)
+
+	resp, err := svc.handleMessage(ctx, userID, cnv, "No")
+	require.NoError(t, err)
+	assert.Equal(t, revokeAllCancelled, resp.Message)
+
+	var transitions []string
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if strings.Contains(line, `msg="Conversation state changed"`) {
+			assert.Contains(t, line, "user_id=user123")
+
+			transitions = append(transitions, line[strings.Index(line, "from="):])
+		}
+	}
+
+	assert.Equal(t, []string{
+		"from=idle to=revokeAll",
+		"from=revokeAll to=complete",
+		"from=complete to=idle",
+	}, transitions)
+}
+
+func TestLogTransition_SameState(t *testing.T) {
+	buf := captureDebugLogs(t)
+
+	logTransition(context.Background(), "user123", StateRevokeAll, StateRevokeAll)
+
+	assert.Empty(t, buf.String())
+}
+
+func TestResetConversation_Logged(t *testing.T) {
+	buf := captureDebugLogs(t)
+
+	repo := NewMockUserRepo(t)
+	repo.On("DeleteConversation", mock.Anything, "user123").Return(nil)
+
+	svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+	require.NoError(t, svc.ResetConversation(context.Background(), "user123"))
+	assert.Contains(t, buf.String(), `level=DEBUG msg="Conversation reset" user_id=user123`)
+}
@@ -26,17 +26,75 @@ func (_m *MockUserRepo) EXPECT() *MockUserRepo_Expecter {
 	return &MockUserRepo_Expecter{mock: &_m.Mock}
 }
 
-// AddAPIKey provides a mock function with given fields: ctx, userID, apiKeyID, tokenType, expiresIn
-func (_m *MockUserRepo) AddAPIKey(ctx context.Context, userID string, apiKeyID string, tokenType TokenType, expiresIn time.Duration) error {
-	ret := _m.Called(ctx, userID, apiKeyID, tokenType, expiresIn)
+// AcquireUserLock provides a mock function with given fields: ctx, userID, ttl
+func (_m *MockUserRepo) AcquireUserLock(ctx context.Context, userID string, ttl time.Duration) (ReleaseFunc, error) {
+	ret := _m.Called(ctx, userID, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcquireUserLock")
+	}
+
+	var r0 ReleaseFunc
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (ReleaseFunc, error)); ok {
+		return rf(ctx, userID, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) ReleaseFunc); ok {
+		r0 = rf(ctx, userID, ttl)
+	} else {
+		r0 = ret.Get(0).(ReleaseFunc)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, userID, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepo_AcquireUserLock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AcquireUserLock'
+type MockUserRepo_AcquireUserLock_Call struct {
+	*mock.Call
+}
+
+// AcquireUserLock is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - ttl time.Duration
+func (_e *MockUserRepo_Expecter) AcquireUserLock(ctx interface{}, userID interface{}, ttl interface{}) *MockUserRepo_AcquireUserLock_Call {
+	return &MockUserRepo_AcquireUserLock_Call{Call: _e.mock.On("AcquireUserLock", ctx, userID, ttl)}
+}
+
+func (_c *MockUserRepo_AcquireUserLock_Call) Run(run func(ctx context.Context, userID string, ttl time.Duration)) *MockUserRepo_AcquireUserLock_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_AcquireUserLock_Call) Return(_a0 ReleaseFunc, _a1 error) *MockUserRepo_AcquireUserLock_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepo_AcquireUserLock_Call) RunAndReturn(run func(context.Context, string, time.Duration) (ReleaseFunc, error)) *MockUserRepo_AcquireUserLock_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddAPIKey provides a mock function with given fields: ctx, userID, apiKeyID, tokenType, name, expiresIn
+func (_m *MockUserRepo) AddAPIKey(ctx context.Context, userID string, apiKeyID string, tokenType TokenType, name string, expiresIn time.Duration) error {
+	ret := _m.Called(ctx, userID, apiKeyID, tokenType, name, expiresIn)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AddAPIKey")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, TokenType, time.Duration) error); ok {
-		r0 = rf(ctx, userID, apiKeyID, tokenType, expiresIn)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, TokenType, string, time.Duration) error); ok {
+		r0 = rf(ctx, userID, apiKeyID, tokenType, name, expiresIn)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -54,14 +112,15 @@ type MockUserRepo_AddAPIKey_Call struct {
 //   - userID string
 //   - apiKeyID string
 //   - tokenType TokenType
+//   - name string
 //   - expiresIn time.Duration
-func (_e *MockUserRepo_Expecter) AddAPIKey(ctx interface{}, userID interface{}, apiKeyID interface{}, tokenType interface{}, expiresIn interface{}) *MockUserRepo_AddAPIKey_Call {
-	return &MockUserRepo_AddAPIKey_Call{Call: _e.mock.On("AddAPIKey", ctx, userID, apiKeyID, tokenType, expiresIn)}
+func (_e *MockUserRepo_Expecter) AddAPIKey(ctx interface{}, userID interface{}, apiKeyID interface{}, tokenType interface{}, name interface{}, expiresIn interface{}) *MockUserRepo_AddAPIKey_Call {
+	return &MockUserRepo_AddAPIKey_Call{Call: _e.mock.On("AddAPIKey", ctx, userID, apiKeyID, tokenType, name, expiresIn)}
 }
 
-func (_c *MockUserRepo_AddAPIKey_Call) Run(run func(ctx context.Context, userID string, apiKeyID string, tokenType TokenType, expiresIn time.Duration)) *MockUserRepo_AddAPIKey_Call {
+func (_c *MockUserRepo_AddAPIKey_Call) Run(run func(ctx context.Context, userID string, apiKeyID string, tokenType TokenType, name string, expiresIn time.Duration)) *MockUserRepo_AddAPIKey_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(TokenType), args[4].(time.Duration))
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(TokenType), args[4].(string), args[5].(time.Duration))
 	})
 	return _c
 }
@@ -71,7 +130,212 @@ func (_c *MockUserRepo_AddAPIKey_Call) Return(_a0 error) *MockUserRepo_AddAPIKey
 	return _c
 }
 
-func (_c *MockUserRepo_AddAPIKey_Call) RunAndReturn(run func(context.Context, string, string, TokenType, time.Duration) error) *MockUserRepo_AddAPIKey_Call {
+func (_c *MockUserRepo_AddAPIKey_Call) RunAndReturn(run func(context.Context, string, string, TokenType, string, time.Duration) error) *MockUserRepo_AddAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddAPIKeyWithLimit provides a mock function with given fields: ctx, userID, apiKeyID, tokenType, name, expiresIn, limit
+func (_m *MockUserRepo) AddAPIKeyWithLimit(ctx context.Context, userID string, apiKeyID string, tokenType TokenType, name string, expiresIn time.Duration, limit int) error {
+	ret := _m.Called(ctx, userID, apiKeyID, tokenType, name, expiresIn, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAPIKeyWithLimit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, TokenType, string, time.Duration, int) error); ok {
+		r0 = rf(ctx, userID, apiKeyID, tokenType, name, expiresIn, limit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepo_AddAPIKeyWithLimit_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddAPIKeyWithLimit'
+type MockUserRepo_AddAPIKeyWithLimit_Call struct {
+	*mock.Call
+}
+
+// AddAPIKeyWithLimit is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - apiKeyID string
+//   - tokenType TokenType
+//   - name string
+//   - expiresIn time.Duration
+//   - limit int
+func (_e *MockUserRepo_Expecter) AddAPIKeyWithLimit(ctx interface{}, userID interface{}, apiKeyID interface{}, tokenType interface{}, name interface{}, expiresIn interface{}, limit interface{}) *MockUserRepo_AddAPIKeyWithLimit_Call {
+	return &MockUserRepo_AddAPIKeyWithLimit_Call{Call: _e.mock.On("AddAPIKeyWithLimit", ctx, userID, apiKeyID, tokenType, name, expiresIn, limit)}
+}
+
+func (_c *MockUserRepo_AddAPIKeyWithLimit_Call) Run(run func(ctx context.Context, userID string, apiKeyID string, tokenType TokenType, name string, expiresIn time.Duration, limit int)) *MockUserRepo_AddAPIKeyWithLimit_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(TokenType), args[4].(string), args[5].(time.Duration), args[6].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_AddAPIKeyWithLimit_Call) Return(_a0 error) *MockUserRepo_AddAPIKeyWithLimit_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepo_AddAPIKeyWithLimit_Call) RunAndReturn(run func(context.Context, string, string, TokenType, string, time.Duration, int) error) *MockUserRepo_AddAPIKeyWithLimit_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AppendAuditLog provides a mock function with given fields: ctx, entry
+func (_m *MockUserRepo) AppendAuditLog(ctx context.Context, entry AuditEntry) error {
+	ret := _m.Called(ctx, entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AppendAuditLog")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, AuditEntry) error); ok {
+		r0 = rf(ctx, entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepo_AppendAuditLog_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AppendAuditLog'
+type MockUserRepo_AppendAuditLog_Call struct {
+	*mock.Call
+}
+
+// AppendAuditLog is a helper method to define mock.On call
+//   - ctx context.Context
+//   - entry AuditEntry
+func (_e *MockUserRepo_Expecter) AppendAuditLog(ctx interface{}, entry interface{}) *MockUserRepo_AppendAuditLog_Call {
+	return &MockUserRepo_AppendAuditLog_Call{Call: _e.mock.On("AppendAuditLog", ctx, entry)}
+}
+
+func (_c *MockUserRepo_AppendAuditLog_Call) Run(run func(ctx context.Context, entry AuditEntry)) *MockUserRepo_AppendAuditLog_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(AuditEntry))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_AppendAuditLog_Call) Return(_a0 error) *MockUserRepo_AppendAuditLog_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepo_AppendAuditLog_Call) RunAndReturn(run func(context.Context, AuditEntry) error) *MockUserRepo_AppendAuditLog_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ClaimIdempotencyKey provides a mock function with given fields: ctx, userID, key, ttl
+func (_m *MockUserRepo) ClaimIdempotencyKey(ctx context.Context, userID string, key string, ttl time.Duration) (bool, error) {
+	ret := _m.Called(ctx, userID, key, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimIdempotencyKey")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) (bool, error)); ok {
+		return rf(ctx, userID, key, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) bool); ok {
+		r0 = rf(ctx, userID, key, ttl)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Duration) error); ok {
+		r1 = rf(ctx, userID, key, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepo_ClaimIdempotencyKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimIdempotencyKey'
+type MockUserRepo_ClaimIdempotencyKey_Call struct {
+	*mock.Call
+}
+
+// ClaimIdempotencyKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - key string
+//   - ttl time.Duration
+func (_e *MockUserRepo_Expecter) ClaimIdempotencyKey(ctx interface{}, userID interface{}, key interface{}, ttl interface{}) *MockUserRepo_ClaimIdempotencyKey_Call {
+	return &MockUserRepo_ClaimIdempotencyKey_Call{Call: _e.mock.On("ClaimIdempotencyKey", ctx, userID, key, ttl)}
+}
+
+func (_c *MockUserRepo_ClaimIdempotencyKey_Call) Run(run func(ctx context.Context, userID string, key string, ttl time.Duration)) *MockUserRepo_ClaimIdempotencyKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_ClaimIdempotencyKey_Call) Return(_a0 bool, _a1 error) *MockUserRepo_ClaimIdempotencyKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepo_ClaimIdempotencyKey_Call) RunAndReturn(run func(context.Context, string, string, time.Duration) (bool, error)) *MockUserRepo_ClaimIdempotencyKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeactivateUserChat provides a mock function with given fields: ctx, userID
+func (_m *MockUserRepo) DeactivateUserChat(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeactivateUserChat")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepo_DeactivateUserChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeactivateUserChat'
+type MockUserRepo_DeactivateUserChat_Call struct {
+	*mock.Call
+}
+
+// DeactivateUserChat is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockUserRepo_Expecter) DeactivateUserChat(ctx interface{}, userID interface{}) *MockUserRepo_DeactivateUserChat_Call {
+	return &MockUserRepo_DeactivateUserChat_Call{Call: _e.mock.On("DeactivateUserChat", ctx, userID)}
+}
+
+func (_c *MockUserRepo_DeactivateUserChat_Call) Run(run func(ctx context.Context, userID string)) *MockUserRepo_DeactivateUserChat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_DeactivateUserChat_Call) Return(_a0 error) *MockUserRepo_DeactivateUserChat_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepo_DeactivateUserChat_Call) RunAndReturn(run func(context.Context, string) error) *MockUserRepo_DeactivateUserChat_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -123,6 +387,54 @@ func (_c *MockUserRepo_DeleteConversation_Call) RunAndReturn(run func(context.Co
 	return _c
 }
 
+// DeleteUserSetting provides a mock function with given fields: ctx, userID, key
+func (_m *MockUserRepo) DeleteUserSetting(ctx context.Context, userID string, key string) error {
+	ret := _m.Called(ctx, userID, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUserSetting")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepo_DeleteUserSetting_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUserSetting'
+type MockUserRepo_DeleteUserSetting_Call struct {
+	*mock.Call
+}
+
+// DeleteUserSetting is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - key string
+func (_e *MockUserRepo_Expecter) DeleteUserSetting(ctx interface{}, userID interface{}, key interface{}) *MockUserRepo_DeleteUserSetting_Call {
+	return &MockUserRepo_DeleteUserSetting_Call{Call: _e.mock.On("DeleteUserSetting", ctx, userID, key)}
+}
+
+func (_c *MockUserRepo_DeleteUserSetting_Call) Run(run func(ctx context.Context, userID string, key string)) *MockUserRepo_DeleteUserSetting_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_DeleteUserSetting_Call) Return(_a0 error) *MockUserRepo_DeleteUserSetting_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepo_DeleteUserSetting_Call) RunAndReturn(run func(context.Context, string, string) error) *MockUserRepo_DeleteUserSetting_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetAPIKeys provides a mock function with given fields: ctx, userID
 func (_m *MockUserRepo) GetAPIKeys(ctx context.Context, userID string) ([]string, error) {
 	ret := _m.Called(ctx, userID)
@@ -241,6 +553,66 @@ func (_c *MockUserRepo_GetAPIKeysWithExpiration_Call) RunAndReturn(run func(cont
 	return _c
 }
 
+// GetAuditLog provides a mock function with given fields: ctx, userID, limit
+func (_m *MockUserRepo) GetAuditLog(ctx context.Context, userID string, limit int) ([]AuditEntry, error) {
+	ret := _m.Called(ctx, userID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuditLog")
+	}
+
+	var r0 []AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]AuditEntry, error)); ok {
+		return rf(ctx, userID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []AuditEntry); ok {
+		r0 = rf(ctx, userID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, userID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepo_GetAuditLog_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAuditLog'
+type MockUserRepo_GetAuditLog_Call struct {
+	*mock.Call
+}
+
+// GetAuditLog is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - limit int
+func (_e *MockUserRepo_Expecter) GetAuditLog(ctx interface{}, userID interface{}, limit interface{}) *MockUserRepo_GetAuditLog_Call {
+	return &MockUserRepo_GetAuditLog_Call{Call: _e.mock.On("GetAuditLog", ctx, userID, limit)}
+}
+
+func (_c *MockUserRepo_GetAuditLog_Call) Run(run func(ctx context.Context, userID string, limit int)) *MockUserRepo_GetAuditLog_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_GetAuditLog_Call) Return(_a0 []AuditEntry, _a1 error) *MockUserRepo_GetAuditLog_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepo_GetAuditLog_Call) RunAndReturn(run func(context.Context, string, int) ([]AuditEntry, error)) *MockUserRepo_GetAuditLog_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetConversation provides a mock function with given fields: ctx, conversationID
 func (_m *MockUserRepo) GetConversation(ctx context.Context, conversationID string) (*conv.Conversation, error) {
 	ret := _m.Called(ctx, conversationID)
@@ -300,19 +672,522 @@ func (_c *MockUserRepo_GetConversation_Call) RunAndReturn(run func(context.Conte
 	return _c
 }
 
-// RevokeToken provides a mock function with given fields: ctx, userID, apiKeyID
-func (_m *MockUserRepo) RevokeToken(ctx context.Context, userID string, apiKeyID string) error {
-	ret := _m.Called(ctx, userID, apiKeyID)
+// GetExpiredFlow provides a mock function with given fields: ctx, conversationID
+func (_m *MockUserRepo) GetExpiredFlow(ctx context.Context, conversationID string) (conv.State, error) {
+	ret := _m.Called(ctx, conversationID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for RevokeToken")
+		panic("no return value specified for GetExpiredFlow")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = rf(ctx, userID, apiKeyID)
-	} else {
-		r0 = ret.Error(0)
+	var r0 conv.State
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (conv.State, error)); ok {
+		return rf(ctx, conversationID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) conv.State); ok {
+		r0 = rf(ctx, conversationID)
+	} else {
+		r0 = ret.Get(0).(conv.State)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, conversationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepo_GetExpiredFlow_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetExpiredFlow'
+type MockUserRepo_GetExpiredFlow_Call struct {
+	*mock.Call
+}
+
+// GetExpiredFlow is a helper method to define mock.On call
+//   - ctx context.Context
+//   - conversationID string
+func (_e *MockUserRepo_Expecter) GetExpiredFlow(ctx interface{}, conversationID interface{}) *MockUserRepo_GetExpiredFlow_Call {
+	return &MockUserRepo_GetExpiredFlow_Call{Call: _e.mock.On("GetExpiredFlow", ctx, conversationID)}
+}
+
+func (_c *MockUserRepo_GetExpiredFlow_Call) Run(run func(ctx context.Context, conversationID string)) *MockUserRepo_GetExpiredFlow_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_GetExpiredFlow_Call) Return(_a0 conv.State, _a1 error) *MockUserRepo_GetExpiredFlow_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepo_GetExpiredFlow_Call) RunAndReturn(run func(context.Context, string) (conv.State, error)) *MockUserRepo_GetExpiredFlow_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetLastRegeneration provides a mock function with given fields: ctx, userID
+func (_m *MockUserRepo) GetLastRegeneration(ctx context.Context, userID string) (time.Time, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLastRegeneration")
+	}
+
+	var r0 time.Time
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (time.Time, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) time.Time); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepo_GetLastRegeneration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLastRegeneration'
+type MockUserRepo_GetLastRegeneration_Call struct {
+	*mock.Call
+}
+
+// GetLastRegeneration is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockUserRepo_Expecter) GetLastRegeneration(ctx interface{}, userID interface{}) *MockUserRepo_GetLastRegeneration_Call {
+	return &MockUserRepo_GetLastRegeneration_Call{Call: _e.mock.On("GetLastRegeneration", ctx, userID)}
+}
+
+func (_c *MockUserRepo_GetLastRegeneration_Call) Run(run func(ctx context.Context, userID string)) *MockUserRepo_GetLastRegeneration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_GetLastRegeneration_Call) Return(_a0 time.Time, _a1 error) *MockUserRepo_GetLastRegeneration_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepo_GetLastRegeneration_Call) RunAndReturn(run func(context.Context, string) (time.Time, error)) *MockUserRepo_GetLastRegeneration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTokenCreationCount provides a mock function with given fields: ctx, userID
+func (_m *MockUserRepo) GetTokenCreationCount(ctx context.Context, userID string) (int, time.Time, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokenCreationCount")
+	}
+
+	var r0 int
+	var r1 time.Time
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, time.Time, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) time.Time); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Get(1).(time.Time)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, userID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockUserRepo_GetTokenCreationCount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTokenCreationCount'
+type MockUserRepo_GetTokenCreationCount_Call struct {
+	*mock.Call
+}
+
+// GetTokenCreationCount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockUserRepo_Expecter) GetTokenCreationCount(ctx interface{}, userID interface{}) *MockUserRepo_GetTokenCreationCount_Call {
+	return &MockUserRepo_GetTokenCreationCount_Call{Call: _e.mock.On("GetTokenCreationCount", ctx, userID)}
+}
+
+func (_c *MockUserRepo_GetTokenCreationCount_Call) Run(run func(ctx context.Context, userID string)) *MockUserRepo_GetTokenCreationCount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_GetTokenCreationCount_Call) Return(_a0 int, _a1 time.Time, _a2 error) *MockUserRepo_GetTokenCreationCount_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockUserRepo_GetTokenCreationCount_Call) RunAndReturn(run func(context.Context, string) (int, time.Time, error)) *MockUserRepo_GetTokenCreationCount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTokenStats provides a mock function with given fields: ctx
+func (_m *MockUserRepo) GetTokenStats(ctx context.Context) (TokenStats, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokenStats")
+	}
+
+	var r0 TokenStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (TokenStats, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) TokenStats); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(TokenStats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepo_GetTokenStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTokenStats'
+type MockUserRepo_GetTokenStats_Call struct {
+	*mock.Call
+}
+
+// GetTokenStats is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockUserRepo_Expecter) GetTokenStats(ctx interface{}) *MockUserRepo_GetTokenStats_Call {
+	return &MockUserRepo_GetTokenStats_Call{Call: _e.mock.On("GetTokenStats", ctx)}
+}
+
+func (_c *MockUserRepo_GetTokenStats_Call) Run(run func(ctx context.Context)) *MockUserRepo_GetTokenStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_GetTokenStats_Call) Return(_a0 TokenStats, _a1 error) *MockUserRepo_GetTokenStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepo_GetTokenStats_Call) RunAndReturn(run func(context.Context) (TokenStats, error)) *MockUserRepo_GetTokenStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserChat provides a mock function with given fields: ctx, userID
+func (_m *MockUserRepo) GetUserChat(ctx context.Context, userID string) (int64, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserChat")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepo_GetUserChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserChat'
+type MockUserRepo_GetUserChat_Call struct {
+	*mock.Call
+}
+
+// GetUserChat is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockUserRepo_Expecter) GetUserChat(ctx interface{}, userID interface{}) *MockUserRepo_GetUserChat_Call {
+	return &MockUserRepo_GetUserChat_Call{Call: _e.mock.On("GetUserChat", ctx, userID)}
+}
+
+func (_c *MockUserRepo_GetUserChat_Call) Run(run func(ctx context.Context, userID string)) *MockUserRepo_GetUserChat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_GetUserChat_Call) Return(_a0 int64, _a1 error) *MockUserRepo_GetUserChat_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepo_GetUserChat_Call) RunAndReturn(run func(context.Context, string) (int64, error)) *MockUserRepo_GetUserChat_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserSetting provides a mock function with given fields: ctx, userID, key
+func (_m *MockUserRepo) GetUserSetting(ctx context.Context, userID string, key string) (string, error) {
+	ret := _m.Called(ctx, userID, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserSetting")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
+		return rf(ctx, userID, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, userID, key)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepo_GetUserSetting_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserSetting'
+type MockUserRepo_GetUserSetting_Call struct {
+	*mock.Call
+}
+
+// GetUserSetting is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - key string
+func (_e *MockUserRepo_Expecter) GetUserSetting(ctx interface{}, userID interface{}, key interface{}) *MockUserRepo_GetUserSetting_Call {
+	return &MockUserRepo_GetUserSetting_Call{Call: _e.mock.On("GetUserSetting", ctx, userID, key)}
+}
+
+func (_c *MockUserRepo_GetUserSetting_Call) Run(run func(ctx context.Context, userID string, key string)) *MockUserRepo_GetUserSetting_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_GetUserSetting_Call) Return(_a0 string, _a1 error) *MockUserRepo_GetUserSetting_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepo_GetUserSetting_Call) RunAndReturn(run func(context.Context, string, string) (string, error)) *MockUserRepo_GetUserSetting_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IncrementTokenCreationCount provides a mock function with given fields: ctx, userID, window
+func (_m *MockUserRepo) IncrementTokenCreationCount(ctx context.Context, userID string, window time.Duration) (int, error) {
+	ret := _m.Called(ctx, userID, window)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementTokenCreationCount")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (int, error)); ok {
+		return rf(ctx, userID, window)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) int); ok {
+		r0 = rf(ctx, userID, window)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, userID, window)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepo_IncrementTokenCreationCount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IncrementTokenCreationCount'
+type MockUserRepo_IncrementTokenCreationCount_Call struct {
+	*mock.Call
+}
+
+// IncrementTokenCreationCount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - window time.Duration
+func (_e *MockUserRepo_Expecter) IncrementTokenCreationCount(ctx interface{}, userID interface{}, window interface{}) *MockUserRepo_IncrementTokenCreationCount_Call {
+	return &MockUserRepo_IncrementTokenCreationCount_Call{Call: _e.mock.On("IncrementTokenCreationCount", ctx, userID, window)}
+}
+
+func (_c *MockUserRepo_IncrementTokenCreationCount_Call) Run(run func(ctx context.Context, userID string, window time.Duration)) *MockUserRepo_IncrementTokenCreationCount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_IncrementTokenCreationCount_Call) Return(_a0 int, _a1 error) *MockUserRepo_IncrementTokenCreationCount_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepo_IncrementTokenCreationCount_Call) RunAndReturn(run func(context.Context, string, time.Duration) (int, error)) *MockUserRepo_IncrementTokenCreationCount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkIdempotencyKeyDone provides a mock function with given fields: ctx, userID, key
+func (_m *MockUserRepo) MarkIdempotencyKeyDone(ctx context.Context, userID string, key string) error {
+	ret := _m.Called(ctx, userID, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkIdempotencyKeyDone")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepo_MarkIdempotencyKeyDone_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkIdempotencyKeyDone'
+type MockUserRepo_MarkIdempotencyKeyDone_Call struct {
+	*mock.Call
+}
+
+// MarkIdempotencyKeyDone is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - key string
+func (_e *MockUserRepo_Expecter) MarkIdempotencyKeyDone(ctx interface{}, userID interface{}, key interface{}) *MockUserRepo_MarkIdempotencyKeyDone_Call {
+	return &MockUserRepo_MarkIdempotencyKeyDone_Call{Call: _e.mock.On("MarkIdempotencyKeyDone", ctx, userID, key)}
+}
+
+func (_c *MockUserRepo_MarkIdempotencyKeyDone_Call) Run(run func(ctx context.Context, userID string, key string)) *MockUserRepo_MarkIdempotencyKeyDone_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_MarkIdempotencyKeyDone_Call) Return(_a0 error) *MockUserRepo_MarkIdempotencyKeyDone_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepo_MarkIdempotencyKeyDone_Call) RunAndReturn(run func(context.Context, string, string) error) *MockUserRepo_MarkIdempotencyKeyDone_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReleaseIdempotencyKey provides a mock function with given fields: ctx, userID, key
+func (_m *MockUserRepo) ReleaseIdempotencyKey(ctx context.Context, userID string, key string) error {
+	ret := _m.Called(ctx, userID, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseIdempotencyKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepo_ReleaseIdempotencyKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReleaseIdempotencyKey'
+type MockUserRepo_ReleaseIdempotencyKey_Call struct {
+	*mock.Call
+}
+
+// ReleaseIdempotencyKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - key string
+func (_e *MockUserRepo_Expecter) ReleaseIdempotencyKey(ctx interface{}, userID interface{}, key interface{}) *MockUserRepo_ReleaseIdempotencyKey_Call {
+	return &MockUserRepo_ReleaseIdempotencyKey_Call{Call: _e.mock.On("ReleaseIdempotencyKey", ctx, userID, key)}
+}
+
+func (_c *MockUserRepo_ReleaseIdempotencyKey_Call) Run(run func(ctx context.Context, userID string, key string)) *MockUserRepo_ReleaseIdempotencyKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_ReleaseIdempotencyKey_Call) Return(_a0 error) *MockUserRepo_ReleaseIdempotencyKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepo_ReleaseIdempotencyKey_Call) RunAndReturn(run func(context.Context, string, string) error) *MockUserRepo_ReleaseIdempotencyKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeToken provides a mock function with given fields: ctx, userID, apiKeyID
+func (_m *MockUserRepo) RevokeToken(ctx context.Context, userID string, apiKeyID string) error {
+	ret := _m.Called(ctx, userID, apiKeyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, apiKeyID)
+	} else {
+		r0 = ret.Error(0)
 	}
 
 	return r0
@@ -348,6 +1223,64 @@ func (_c *MockUserRepo_RevokeToken_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// RevokeTokens provides a mock function with given fields: ctx, userID, apiKeyIDs
+func (_m *MockUserRepo) RevokeTokens(ctx context.Context, userID string, apiKeyIDs []string) (int, error) {
+	ret := _m.Called(ctx, userID, apiKeyIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeTokens")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (int, error)); ok {
+		return rf(ctx, userID, apiKeyIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) int); ok {
+		r0 = rf(ctx, userID, apiKeyIDs)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, userID, apiKeyIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepo_RevokeTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeTokens'
+type MockUserRepo_RevokeTokens_Call struct {
+	*mock.Call
+}
+
+// RevokeTokens is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - apiKeyIDs []string
+func (_e *MockUserRepo_Expecter) RevokeTokens(ctx interface{}, userID interface{}, apiKeyIDs interface{}) *MockUserRepo_RevokeTokens_Call {
+	return &MockUserRepo_RevokeTokens_Call{Call: _e.mock.On("RevokeTokens", ctx, userID, apiKeyIDs)}
+}
+
+func (_c *MockUserRepo_RevokeTokens_Call) Run(run func(ctx context.Context, userID string, apiKeyIDs []string)) *MockUserRepo_RevokeTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_RevokeTokens_Call) Return(_a0 int, _a1 error) *MockUserRepo_RevokeTokens_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepo_RevokeTokens_Call) RunAndReturn(run func(context.Context, string, []string) (int, error)) *MockUserRepo_RevokeTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SaveConversation provides a mock function with given fields: ctx, conversation
 func (_m *MockUserRepo) SaveConversation(ctx context.Context, conversation *conv.Conversation) error {
 	ret := _m.Called(ctx, conversation)
@@ -395,6 +1328,257 @@ func (_c *MockUserRepo_SaveConversation_Call) RunAndReturn(run func(context.Cont
 	return _c
 }
 
+// SaveFeedback provides a mock function with given fields: ctx, feedback
+func (_m *MockUserRepo) SaveFeedback(ctx context.Context, feedback Feedback) error {
+	ret := _m.Called(ctx, feedback)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveFeedback")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, Feedback) error); ok {
+		r0 = rf(ctx, feedback)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepo_SaveFeedback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveFeedback'
+type MockUserRepo_SaveFeedback_Call struct {
+	*mock.Call
+}
+
+// SaveFeedback is a helper method to define mock.On call
+//   - ctx context.Context
+//   - feedback Feedback
+func (_e *MockUserRepo_Expecter) SaveFeedback(ctx interface{}, feedback interface{}) *MockUserRepo_SaveFeedback_Call {
+	return &MockUserRepo_SaveFeedback_Call{Call: _e.mock.On("SaveFeedback", ctx, feedback)}
+}
+
+func (_c *MockUserRepo_SaveFeedback_Call) Run(run func(ctx context.Context, feedback Feedback)) *MockUserRepo_SaveFeedback_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(Feedback))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_SaveFeedback_Call) Return(_a0 error) *MockUserRepo_SaveFeedback_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepo_SaveFeedback_Call) RunAndReturn(run func(context.Context, Feedback) error) *MockUserRepo_SaveFeedback_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveUserChat provides a mock function with given fields: ctx, userID, chatID
+func (_m *MockUserRepo) SaveUserChat(ctx context.Context, userID string, chatID int64) error {
+	ret := _m.Called(ctx, userID, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveUserChat")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) error); ok {
+		r0 = rf(ctx, userID, chatID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepo_SaveUserChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveUserChat'
+type MockUserRepo_SaveUserChat_Call struct {
+	*mock.Call
+}
+
+// SaveUserChat is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - chatID int64
+func (_e *MockUserRepo_Expecter) SaveUserChat(ctx interface{}, userID interface{}, chatID interface{}) *MockUserRepo_SaveUserChat_Call {
+	return &MockUserRepo_SaveUserChat_Call{Call: _e.mock.On("SaveUserChat", ctx, userID, chatID)}
+}
+
+func (_c *MockUserRepo_SaveUserChat_Call) Run(run func(ctx context.Context, userID string, chatID int64)) *MockUserRepo_SaveUserChat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_SaveUserChat_Call) Return(_a0 error) *MockUserRepo_SaveUserChat_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepo_SaveUserChat_Call) RunAndReturn(run func(context.Context, string, int64) error) *MockUserRepo_SaveUserChat_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetLastRegeneration provides a mock function with given fields: ctx, userID, at, ttl
+func (_m *MockUserRepo) SetLastRegeneration(ctx context.Context, userID string, at time.Time, ttl time.Duration) error {
+	ret := _m.Called(ctx, userID, at, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLastRegeneration")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Duration) error); ok {
+		r0 = rf(ctx, userID, at, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepo_SetLastRegeneration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetLastRegeneration'
+type MockUserRepo_SetLastRegeneration_Call struct {
+	*mock.Call
+}
+
+// SetLastRegeneration is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - at time.Time
+//   - ttl time.Duration
+func (_e *MockUserRepo_Expecter) SetLastRegeneration(ctx interface{}, userID interface{}, at interface{}, ttl interface{}) *MockUserRepo_SetLastRegeneration_Call {
+	return &MockUserRepo_SetLastRegeneration_Call{Call: _e.mock.On("SetLastRegeneration", ctx, userID, at, ttl)}
+}
+
+func (_c *MockUserRepo_SetLastRegeneration_Call) Run(run func(ctx context.Context, userID string, at time.Time, ttl time.Duration)) *MockUserRepo_SetLastRegeneration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_SetLastRegeneration_Call) Return(_a0 error) *MockUserRepo_SetLastRegeneration_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepo_SetLastRegeneration_Call) RunAndReturn(run func(context.Context, string, time.Time, time.Duration) error) *MockUserRepo_SetLastRegeneration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetUserSetting provides a mock function with given fields: ctx, userID, key, value
+func (_m *MockUserRepo) SetUserSetting(ctx context.Context, userID string, key string, value string) error {
+	ret := _m.Called(ctx, userID, key, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserSetting")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, key, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepo_SetUserSetting_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUserSetting'
+type MockUserRepo_SetUserSetting_Call struct {
+	*mock.Call
+}
+
+// SetUserSetting is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - key string
+//   - value string
+func (_e *MockUserRepo_Expecter) SetUserSetting(ctx interface{}, userID interface{}, key interface{}, value interface{}) *MockUserRepo_SetUserSetting_Call {
+	return &MockUserRepo_SetUserSetting_Call{Call: _e.mock.On("SetUserSetting", ctx, userID, key, value)}
+}
+
+func (_c *MockUserRepo_SetUserSetting_Call) Run(run func(ctx context.Context, userID string, key string, value string)) *MockUserRepo_SetUserSetting_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_SetUserSetting_Call) Return(_a0 error) *MockUserRepo_SetUserSetting_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepo_SetUserSetting_Call) RunAndReturn(run func(context.Context, string, string, string) error) *MockUserRepo_SetUserSetting_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SubscribeExpiredTokens provides a mock function with given fields: ctx
+func (_m *MockUserRepo) SubscribeExpiredTokens(ctx context.Context) (<-chan ExpiredToken, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubscribeExpiredTokens")
+	}
+
+	var r0 <-chan ExpiredToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (<-chan ExpiredToken, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) <-chan ExpiredToken); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan ExpiredToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepo_SubscribeExpiredTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SubscribeExpiredTokens'
+type MockUserRepo_SubscribeExpiredTokens_Call struct {
+	*mock.Call
+}
+
+// SubscribeExpiredTokens is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockUserRepo_Expecter) SubscribeExpiredTokens(ctx interface{}) *MockUserRepo_SubscribeExpiredTokens_Call {
+	return &MockUserRepo_SubscribeExpiredTokens_Call{Call: _e.mock.On("SubscribeExpiredTokens", ctx)}
+}
+
+func (_c *MockUserRepo_SubscribeExpiredTokens_Call) Run(run func(ctx context.Context)) *MockUserRepo_SubscribeExpiredTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockUserRepo_SubscribeExpiredTokens_Call) Return(_a0 <-chan ExpiredToken, _a1 error) *MockUserRepo_SubscribeExpiredTokens_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepo_SubscribeExpiredTokens_Call) RunAndReturn(run func(context.Context) (<-chan ExpiredToken, error)) *MockUserRepo_SubscribeExpiredTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockUserRepo creates a new instance of MockUserRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockUserRepo(t interface {
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	whoAmIMessage   = "🆔 User ID: %s\n🔑 Web: %d/%d, TCP: %d/%d\n⏱ Nearest expiry: %s"
+	noExpiryMessage = "none"
+)
+
+// WhoAmI summarizes the user's identity for support requests: the user ID used as the storage key,
+// the number of active tokens of each type against their limits, and the nearest token expiry.
+// Users without tokens get a summary with zero counts.
+func (s *Service) WhoAmI(ctx context.Context, userID string) (*Response, error) {
+	keys, err := s.repo.GetAPIKeysWithExpiration(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	var (
+		webCount, tcpCount int
+		nearest            time.Time
+	)
+
+	for _, k := range keys {
+		if k.Type == TokenTypeTCP {
+			tcpCount++
+		} else {
+			webCount++
+		}
+
+		if nearest.IsZero() || k.ExpiresAt.Before(nearest) {
+			nearest = k.ExpiresAt
+		}
+	}
+
+	expiry := noExpiryMessage
+	if !nearest.IsZero() {
+		expiry = formatExpiry(nearest, s.userLocation(ctx, userID))
+	}
+
+	return &Response{
+		Message: fmt.Sprintf(whoAmIMessage, userID, webCount, s.maxWebTokens, tcpCount, s.maxTCPTokens, expiry),
+	}, nil
+}
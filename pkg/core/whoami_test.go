@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhoAmI(t *testing.T) {
+	tests := []struct {
+		getKeysErr  error
+		name        string
+		expectedMsg string
+		expectedErr string
+		keys        []KeyInfo
+	}{
+		{
+			name:        "no tokens",
+			keys:        []KeyInfo{},
+			expectedMsg: "🆔 User ID: 456\n🔑 Web: 0/3, TCP: 0/1\n⏱ Nearest expiry: none",
+		},
+		{
+			name: "mixed tokens",
+			keys: []KeyInfo{
+				{KeyID: "web1", Type: TokenTypeWeb, ExpiresAt: time.Date(2030, 5, 1, 10, 0, 0, 0, time.UTC)},
+				{KeyID: "tcp1", Type: TokenTypeTCP, ExpiresAt: time.Date(2030, 3, 1, 10, 0, 0, 0, time.UTC)},
+				{KeyID: "web2", Type: TokenTypeWeb, ExpiresAt: time.Date(2030, 4, 1, 10, 0, 0, 0, time.UTC)},
+			},
+			expectedMsg: "🆔 User ID: 456\n🔑 Web: 2/3, TCP: 1/1\n⏱ Nearest expiry: 2030-03-01 10:00:00 UTC",
+		},
+		{
+			name:        "repository error",
+			getKeysErr:  errors.New("redis error"),
+			expectedErr: "failed to get API keys: redis error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepo(t)
+			repo.On("GetAPIKeysWithExpiration", mock.Anything, "456").Return(tt.keys, tt.getKeysErr)
+
+			if len(tt.keys) > 0 {
+				expectTimezone(repo, "456")
+			}
+
+			svc := newTestService(t, Config{}, repo, NewMockMITProv(t))
+
+			resp, err := svc.WhoAmI(context.Background(), "456")
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, resp)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMsg, resp.Message)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package i18n
+
+var en = map[MessageID]string{
+	Welcome: `👋 Welcome to Make It Public Bot!
+
+I help you manage API tokens for https://make-it-public.dev - a service that allows you to securely publish services hidden behind NAT.
+
+Use /help to see available commands.`,
+	Help: `Available Commands:
+
+%s
+Send /help <command> to see how to use a command, e.g. /help new_token.
+
+Token Types:
+Web  - HTTP/HTTPS tunnel token, supports a custom subdomain (e.g. myapp.make-it-public.dev)
+TCP  - Raw TCP tunnel token
+
+About Make It Public:
+Make It Public allows you to securely expose services that are behind NAT or firewalls to the internet.`,
+	HelpFooter:         "mitbot %s",
+	UnknownCommand:     "❓ Unknown command.\n\nUse /help to see the list of available commands.",
+	NotCommand:         "I can only respond to commands. Try /help to see what I can do.",
+	TokenRevoked:       "🔒 Your API token has been successfully revoked.\n\nYou can create a new one using /new_token command.",
+	NoTokenToRevoke:    "❌ You don't have an active API token to revoke.\n\nUse /new_token to create one.",
+	NoTokens:           "You have no active tokens yet, use /new_token to create one.",
+	NoTokenToRenew:     "❌ You don't have an active API token to extend.\n\nUse /new_token to create one.",
+	PrivateChatOnly:    "I only work in private chats. Please message me directly to manage your tokens.",
+	PrivateCommand:     "This command only works in a private chat with me.",
+	RateLimited:        "⏳ You've created too many tokens today, try again tomorrow.\n\nThe limit resets at %s.",
+	ConversationReset:  "Conversation has been reset. You can start over with /new_token.",
+	NewTokenUsage:      "Usage: /new_token [days]\n\nFor example, /new_token 30 or /new_token 30d creates a web token valid for 30 days. Send /new_token without arguments to choose the options step by step.",
+	PreviewUsage:       "Usage: /preview <days>\n\nFor example, /preview 30 shows when a web token created for 30 days would expire, without creating it.",
+	TimezoneUsage:      "Unknown timezone.\n\nUsage: /timezone <name>, where name is an IANA timezone such as Europe/Berlin or America/New_York. Send /timezone without arguments to see the current one.",
+	SetDefaultTTLUsage: "Usage: /set_default_ttl <days>\n\nFor example, /set_default_ttl 30 makes new tokens valid for 30 days without asking for the expiration period. /new_token 7 still creates a token valid for 7 days. Use /clear_default_ttl to be asked again.",
+	AuditUsage:         "Usage: /audit <user ID> [count]\n\nFor example, /audit 123456 20 shows the last 20 token events of user 123456.",
+	Stats:              "📊 Bot usage\n\nActive tokens: %d\nWeb: %d\nTCP: %d\nUsers with tokens: %d",
+	Pong:               "🏓 pong\n\nmitbot %s",
+	History:            "🕘 Your recent token actions:\n\n%s",
+	NoHistory:          "You haven't created, regenerated, renewed or revoked any tokens yet.",
+
+	StartSummary:           "Show the welcome message",
+	StartDetails:           "Usage: /start\n\nShows the welcome message and cancels the question you are answering, if any.",
+	HelpSummary:            "Show the available commands",
+	HelpDetails:            "Usage: /help [command]\n\nWithout arguments, lists the available commands. With a command, shows how to use it, e.g. /help new_token.",
+	NewTokenSummary:        "Generate a new API token (see /whoami for your limits)",
+	PreviewSummary:         "See when a token would expire without creating it",
+	ListTokensSummary:      "List your active API tokens",
+	ListTokensDetails:      "Usage: /list_tokens\n\nLists your active tokens with their type, label and expiry time. /my_tokens does the same.",
+	HistorySummary:         "Show your recent token actions",
+	HistoryDetails:         "Usage: /history\n\nShows your last token actions with their time: tokens created, regenerated, renewed and revoked. Unlike /list_tokens it shows what you did, not which tokens you have.",
+	SetDefaultTTLSummary:   "Skip the expiration question with a default period",
+	ClearDefaultTTLSummary: "Ask for the expiration period of new tokens again",
+	ClearDefaultTTLDetails: "Usage: /clear_default_ttl\n\nRemoves the default expiration period set with /set_default_ttl, so that /new_token asks for it again.",
+	ExportSummary:          "Download your active tokens as a JSON file",
+	ExportDetails:          "Usage: /export\n\nSends a JSON file with the key ID, type, label and expiry time of each active token. The tokens themselves are not included.",
+	RenewTokenSummary:      "Extend an API token without changing it",
+	RenewTokenDetails:      "Usage: /renew_token\n\nExtends a token and keeps its value, so you don't need to update your clients. If you have several tokens, you pick the one to extend, then the new validity period.",
+	RevokeTokenSummary:     "Revoke an API token",
+	RevokeTokenDetails:     "Usage: /revoke_token\n\nRevokes a token, so that it can no longer be used. If you have several tokens, you pick the one to revoke.",
+	RevokeAllSummary:       "Revoke all of your API tokens",
+	RevokeAllDetails:       "Usage: /revoke_all\n\nRevokes all of your tokens after you confirm it.",
+	WhoAmISummary:          "Show your user ID and token usage",
+	WhoAmIDetails:          "Usage: /whoami\n\nShows your Telegram user ID, how many web and TCP tokens you have and when the next one expires.",
+	TimezoneSummary:        "Show expiry times in your timezone",
+	TimezoneDetails:        "Usage: /timezone [name]\n\nSets the timezone expiry times are shown in, where name is an IANA timezone such as Europe/Berlin or America/New_York. Send /timezone without arguments to see the current one.",
+	FeedbackSummary:        "Report a problem or share an idea with the bot operators",
+	FeedbackDetails:        "Usage: /feedback [text]\n\nSends your message to the bot operators, e.g. /feedback Please add a dark theme. Send /feedback without text to be asked for it.",
+	BackSummary:            "Go back to the previous question",
+	BackDetails:            "Usage: /back\n\nReturns to the previous question, e.g. to choose another token type while creating a token.",
+	CancelSummary:          "Cancel the current question",
+	CancelDetails:          "Usage: /cancel\n\nCancels the questions in progress, nothing is created or changed.",
+	PingSummary:            "Check that the bot is alive",
+	PingDetails:            "Usage: /ping\n\nReplies with pong and the version of the bot, so you can check that it is running.",
+
+	ActionCreated:     "created",
+	ActionRevoked:     "revoked",
+	ActionRegenerated: "regenerated",
+	ActionRenewed:     "renewed",
+}
@@ -0,0 +1,117 @@
+// Package i18n holds the user-facing texts of the bot translated into the supported languages.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultLang is the language used when a text is not translated into the user's language.
+const DefaultLang = "en"
+
+// MessageID identifies a text in the catalog.
+type MessageID string
+
+const (
+	Welcome            MessageID = "welcome"
+	Help               MessageID = "help"
+	HelpFooter         MessageID = "help_footer"
+	UnknownCommand     MessageID = "unknown_command"
+	NotCommand         MessageID = "not_command"
+	TokenRevoked       MessageID = "token_revoked"
+	NoTokenToRevoke    MessageID = "no_token_to_revoke"
+	NoTokens           MessageID = "no_tokens"
+	NoTokenToRenew     MessageID = "no_token_to_renew"
+	PrivateChatOnly    MessageID = "private_chat_only"
+	PrivateCommand     MessageID = "private_command"
+	RateLimited        MessageID = "rate_limited"
+	ConversationReset  MessageID = "conversation_reset"
+	NewTokenUsage      MessageID = "new_token_usage"
+	PreviewUsage       MessageID = "preview_usage"
+	AuditUsage         MessageID = "audit_usage"
+	TimezoneUsage      MessageID = "timezone_usage"
+	SetDefaultTTLUsage MessageID = "set_default_ttl_usage"
+	Stats              MessageID = "stats"
+	History            MessageID = "history"
+	NoHistory          MessageID = "no_history"
+	Pong               MessageID = "pong"
+)
+
+// Command summaries are shown in the /help list and the Telegram command menu,
+// command details are shown by /help <command>.
+const (
+	StartSummary           MessageID = "start_summary"
+	StartDetails           MessageID = "start_details"
+	HelpSummary            MessageID = "help_summary"
+	HelpDetails            MessageID = "help_details"
+	NewTokenSummary        MessageID = "new_token_summary"
+	PreviewSummary         MessageID = "preview_summary"
+	ListTokensSummary      MessageID = "list_tokens_summary"
+	ListTokensDetails      MessageID = "list_tokens_details"
+	ExportSummary          MessageID = "export_summary"
+	ExportDetails          MessageID = "export_details"
+	RenewTokenSummary      MessageID = "renew_token_summary"
+	RenewTokenDetails      MessageID = "renew_token_details"
+	RevokeTokenSummary     MessageID = "revoke_token_summary"
+	RevokeTokenDetails     MessageID = "revoke_token_details"
+	RevokeAllSummary       MessageID = "revoke_all_summary"
+	RevokeAllDetails       MessageID = "revoke_all_details"
+	WhoAmISummary          MessageID = "whoami_summary"
+	WhoAmIDetails          MessageID = "whoami_details"
+	TimezoneSummary        MessageID = "timezone_summary"
+	TimezoneDetails        MessageID = "timezone_details"
+	FeedbackSummary        MessageID = "feedback_summary"
+	FeedbackDetails        MessageID = "feedback_details"
+	BackSummary            MessageID = "back_summary"
+	BackDetails            MessageID = "back_details"
+	CancelSummary          MessageID = "cancel_summary"
+	CancelDetails          MessageID = "cancel_details"
+	PingSummary            MessageID = "ping_summary"
+	PingDetails            MessageID = "ping_details"
+	HistorySummary         MessageID = "history_summary"
+	HistoryDetails         MessageID = "history_details"
+	SetDefaultTTLSummary   MessageID = "set_default_ttl_summary"
+	ClearDefaultTTLSummary MessageID = "clear_default_ttl_summary"
+	ClearDefaultTTLDetails MessageID = "clear_default_ttl_details"
+)
+
+// Token lifecycle actions as shown by /history.
+const (
+	ActionCreated     MessageID = "action_created"
+	ActionRevoked     MessageID = "action_revoked"
+	ActionRegenerated MessageID = "action_regenerated"
+	ActionRenewed     MessageID = "action_renewed"
+)
+
+// catalog maps a language code to its translations; a new language only needs an entry here.
+var catalog = map[string]map[MessageID]string{
+	"en": en,
+	"ru": ru,
+}
+
+// Message returns the text for id in the language lang, falling back to DefaultLang when the language
+// or the text is not translated. lang is a Telegram language code, region subtags such as "pt-br" are ignored.
+// When args are given they are applied to the text with fmt.Sprintf.
+func Message(lang string, id MessageID, args ...any) string {
+	text, ok := catalog[baseLang(lang)][id]
+	if !ok {
+		text = catalog[DefaultLang][id]
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+
+	return text
+}
+
+// baseLang normalizes a language code to its lowercase primary subtag.
+func baseLang(lang string) string {
+	lang = strings.ToLower(lang)
+
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+
+	return lang
+}
@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		id   MessageID
+		args []any
+		want string
+	}{
+		{name: "english", lang: "en", id: NoTokens, want: en[NoTokens]},
+		{name: "russian", lang: "ru", id: NoTokens, want: ru[NoTokens]},
+		{name: "region subtag ignored", lang: "ru-RU", id: NoTokens, want: ru[NoTokens]},
+		{name: "uppercase code", lang: "RU", id: NoTokens, want: ru[NoTokens]},
+		{name: "unsupported language falls back to english", lang: "xx", id: NoTokens, want: en[NoTokens]},
+		{name: "empty language falls back to english", lang: "", id: NoTokens, want: en[NoTokens]},
+		{
+			name: "arguments are formatted",
+			lang: "en",
+			id:   RateLimited,
+			args: []any{"2030-01-02 15:04:05"},
+			want: "⏳ You've created too many tokens today, try again tomorrow.\n\nThe limit resets at 2030-01-02 15:04:05.",
+		},
+		{name: "unknown message is empty", lang: "en", id: "missing", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Message(tt.lang, tt.id, tt.args...))
+		})
+	}
+}
+
+func TestMessage_MissingTranslationFallsBack(t *testing.T) {
+	catalog["test"] = map[MessageID]string{Welcome: "hi"}
+	defer delete(catalog, "test")
+
+	assert.Equal(t, "hi", Message("test", Welcome))
+	assert.Equal(t, en[Help], Message("test", Help))
+}
+
+func TestCatalog_Complete(t *testing.T) {
+	for lang, texts := range catalog {
+		for id, text := range en {
+			translated, ok := texts[id]
+			if !assert.Truef(t, ok, "%s is missing %q", lang, id) {
+				continue
+			}
+
+			assert.Equalf(t, strings.Count(text, "%"), strings.Count(translated, "%"), "%s: %q has different format verbs", lang, id)
+		}
+	}
+}
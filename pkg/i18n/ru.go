@@ -0,0 +1,79 @@
+package i18n
+
+var ru = map[MessageID]string{
+	Welcome: `👋 Добро пожаловать в Make It Public Bot!
+
+Я помогаю управлять API-токенами для https://make-it-public.dev - сервиса, который позволяет безопасно публиковать сервисы, скрытые за NAT.
+
+Используйте /help, чтобы увидеть доступные команды.`,
+	Help: `Доступные команды:
+
+%s
+Отправьте /help <команда>, чтобы узнать, как пользоваться командой, например /help new_token.
+
+Типы токенов:
+Web  - токен HTTP/HTTPS-туннеля, поддерживает свой поддомен (например, myapp.make-it-public.dev)
+TCP  - токен TCP-туннеля
+
+О Make It Public:
+Make It Public позволяет безопасно открыть доступ из интернета к сервисам, находящимся за NAT или файрволом.`,
+	HelpFooter:         "mitbot %s",
+	UnknownCommand:     "❓ Неизвестная команда.\n\nИспользуйте /help, чтобы увидеть список доступных команд.",
+	NotCommand:         "Я отвечаю только на команды. Попробуйте /help, чтобы узнать, что я умею.",
+	TokenRevoked:       "🔒 Ваш API-токен успешно отозван.\n\nВы можете создать новый командой /new_token.",
+	NoTokenToRevoke:    "❌ У вас нет активного API-токена для отзыва.\n\nИспользуйте /new_token, чтобы создать его.",
+	NoTokens:           "У вас пока нет активных токенов, используйте /new_token, чтобы создать токен.",
+	NoTokenToRenew:     "❌ У вас нет активного API-токена для продления.\n\nИспользуйте /new_token, чтобы создать его.",
+	PrivateChatOnly:    "Я работаю только в личных чатах. Напишите мне напрямую, чтобы управлять токенами.",
+	PrivateCommand:     "Эта команда работает только в личном чате со мной.",
+	RateLimited:        "⏳ Сегодня вы создали слишком много токенов, попробуйте завтра.\n\nЛимит сбросится в %s.",
+	ConversationReset:  "Диалог сброшен. Можно начать заново с /new_token.",
+	NewTokenUsage:      "Использование: /new_token [дни]\n\nНапример, /new_token 30 или /new_token 30d создаёт web-токен на 30 дней. Отправьте /new_token без аргументов, чтобы выбрать параметры по шагам.",
+	PreviewUsage:       "Использование: /preview <дни>\n\nНапример, /preview 30 покажет, когда истечёт web-токен, созданный на 30 дней, не создавая его.",
+	TimezoneUsage:      "Неизвестный часовой пояс.\n\nИспользование: /timezone <название>, где название - часовой пояс IANA, например Europe/Moscow или Asia/Yekaterinburg. Отправьте /timezone без аргументов, чтобы увидеть текущий.",
+	SetDefaultTTLUsage: "Использование: /set_default_ttl <дни>\n\nНапример, /set_default_ttl 30 делает новые токены действительными 30 дней без вопроса о сроке действия. /new_token 7 по-прежнему создаёт токен на 7 дней. Используйте /clear_default_ttl, чтобы снова получать вопрос.",
+	AuditUsage:         "Использование: /audit <ID пользователя> [количество]\n\nНапример, /audit 123456 20 покажет последние 20 событий с токенами пользователя 123456.",
+	Stats:              "📊 Использование бота\n\nАктивных токенов: %d\nWeb: %d\nTCP: %d\nПользователей с токенами: %d",
+	Pong:               "🏓 pong\n\nmitbot %s",
+	History:            "🕘 Ваши последние действия с токенами:\n\n%s",
+	NoHistory:          "Вы ещё не создавали, не перевыпускали, не продлевали и не отзывали токены.",
+
+	StartSummary:           "Показать приветствие",
+	StartDetails:           "Использование: /start\n\nПоказывает приветствие и отменяет текущий вопрос, если он есть.",
+	HelpSummary:            "Показать доступные команды",
+	HelpDetails:            "Использование: /help [команда]\n\nБез аргументов показывает список команд. С названием команды показывает, как ей пользоваться, например /help new_token.",
+	NewTokenSummary:        "Создать новый API-токен (лимиты покажет /whoami)",
+	PreviewSummary:         "Узнать, когда истечёт токен, не создавая его",
+	ListTokensSummary:      "Показать ваши активные API-токены",
+	ListTokensDetails:      "Использование: /list_tokens\n\nПоказывает ваши активные токены с типом, меткой и сроком действия. /my_tokens делает то же самое.",
+	HistorySummary:         "Показать ваши последние действия с токенами",
+	HistoryDetails:         "Использование: /history\n\nПоказывает ваши последние действия с токенами и их время: создание, перевыпуск, продление и отзыв. В отличие от /list_tokens показывает, что вы делали, а не какие токены у вас есть.",
+	SetDefaultTTLSummary:   "Пропускать вопрос о сроке действия с периодом по умолчанию",
+	ClearDefaultTTLSummary: "Снова спрашивать срок действия новых токенов",
+	ClearDefaultTTLDetails: "Использование: /clear_default_ttl\n\nУдаляет срок действия по умолчанию, заданный через /set_default_ttl, чтобы /new_token снова спрашивал его.",
+	ExportSummary:          "Скачать ваши активные токены в виде JSON-файла",
+	ExportDetails:          "Использование: /export\n\nПрисылает JSON-файл с ID ключа, типом, меткой и сроком действия каждого активного токена. Сами токены в файл не попадают.",
+	RenewTokenSummary:      "Продлить API-токен, не меняя его",
+	RenewTokenDetails:      "Использование: /renew_token\n\nПродлевает токен, сохраняя его значение, поэтому клиенты не нужно перенастраивать. Если токенов несколько, вы выберете нужный, а затем новый срок действия.",
+	RevokeTokenSummary:     "Отозвать API-токен",
+	RevokeTokenDetails:     "Использование: /revoke_token\n\nОтзывает токен, после чего им нельзя пользоваться. Если токенов несколько, вы выберете, какой отозвать.",
+	RevokeAllSummary:       "Отозвать все ваши API-токены",
+	RevokeAllDetails:       "Использование: /revoke_all\n\nОтзывает все ваши токены после подтверждения.",
+	WhoAmISummary:          "Показать ваш ID и использование токенов",
+	WhoAmIDetails:          "Использование: /whoami\n\nПоказывает ваш ID в Telegram, сколько у вас web- и TCP-токенов и когда истекает ближайший.",
+	TimezoneSummary:        "Показывать сроки действия в вашем часовом поясе",
+	TimezoneDetails:        "Использование: /timezone [название]\n\nЗадаёт часовой пояс для сроков действия, где название - часовой пояс IANA, например Europe/Moscow или Asia/Yekaterinburg. Отправьте /timezone без аргументов, чтобы увидеть текущий.",
+	FeedbackSummary:        "Сообщить о проблеме или предложить идею операторам бота",
+	FeedbackDetails:        "Использование: /feedback [текст]\n\nОтправляет ваше сообщение операторам бота, например /feedback Добавьте тёмную тему. Отправьте /feedback без текста, и бот попросит его ввести.",
+	BackSummary:            "Вернуться к предыдущему вопросу",
+	BackDetails:            "Использование: /back\n\nВозвращает к предыдущему вопросу, например чтобы выбрать другой тип токена при создании.",
+	CancelSummary:          "Отменить текущий вопрос",
+	CancelDetails:          "Использование: /cancel\n\nОтменяет текущие вопросы, ничего не создаётся и не меняется.",
+	PingSummary:            "Проверить, что бот работает",
+	PingDetails:            "Использование: /ping\n\nОтвечает pong и версией бота, чтобы можно было проверить, что он работает.",
+
+	ActionCreated:     "создан",
+	ActionRevoked:     "отозван",
+	ActionRegenerated: "перевыпущен",
+	ActionRenewed:     "продлён",
+}
@@ -0,0 +1,163 @@
+package prov
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker stops calling the provider after threshold consecutive failed calls.
+// While open, calls fail fast with core.ErrProviderUnavailable until cooldown passes. Then a single probe call
+// is let through (half-open): its success closes the breaker, its failure opens it for another cooldown.
+// A nil circuitBreaker lets every call through.
+type circuitBreaker struct {
+	openedAt  time.Time
+	now       func() time.Time
+	mu        sync.Mutex
+	state     breakerState
+	failures  int
+	threshold int
+	cooldown  time.Duration
+	probing   bool
+}
+
+// newCircuitBreaker creates a closed circuit breaker, zero settings are replaced with defaults.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a call may be sent to the provider and returns core.ErrProviderUnavailable if it may not.
+// Every allowed call must be followed by done.
+func (b *circuitBreaker) allow(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return core.ErrProviderUnavailable
+		}
+
+		b.setState(ctx, breakerHalfOpen)
+		b.probing = true
+
+		return nil
+	case breakerHalfOpen:
+		if b.probing {
+			return core.ErrProviderUnavailable
+		}
+
+		b.probing = true
+
+		return nil
+	default:
+		return nil
+	}
+}
+
+// done records the outcome of a call let through by allow. A call cut short by its own context says nothing
+// about the provider, so it only releases the probe slot.
+func (b *circuitBreaker) done(ctx context.Context, resp *http.Response, err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	if !isProviderFailure(resp, err) {
+		b.failures = 0
+		b.setState(ctx, breakerClosed)
+
+		return
+	}
+
+	b.failures++
+
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.setState(ctx, breakerOpen)
+	}
+}
+
+// setState switches the breaker to state and logs the change. The caller must hold b.mu.
+func (b *circuitBreaker) setState(ctx context.Context, state breakerState) {
+	if b.state == state {
+		return
+	}
+
+	attrs := []any{
+		slog.String("from", b.state.String()),
+		slog.String("to", state.String()),
+		slog.Int("failures", b.failures),
+	}
+
+	b.state = state
+
+	if state == breakerOpen {
+		slog.WarnContext(ctx, "Provider circuit breaker opened", append(attrs, slog.Duration("cooldown", b.cooldown))...)
+		return
+	}
+
+	slog.InfoContext(ctx, "Provider circuit breaker state changed", attrs...)
+}
+
+// isProviderFailure reports whether a call failed on the provider side: no response at all,
+// rate limiting or a 5xx status. Client errors mean the provider is up and don't count.
+func isProviderFailure(resp *http.Response, err error) bool {
+	if err != nil || resp == nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,228 @@
+package prov
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced time source for the circuit breaker.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newBreakerMIT returns a MIT client without retries whose circuit breaker opens after threshold failures
+// and uses clock as its time source.
+func newBreakerMIT(url string, threshold int, cooldown time.Duration, clock *fakeClock) *MIT {
+	mit := New(Config{
+		Url:              url,
+		DefaultTTL:       3600,
+		RetryBaseDelay:   time.Millisecond,
+		BreakerThreshold: threshold,
+		BreakerCooldown:  cooldown,
+	})
+	mit.maxRetries = 0
+	mit.breaker.now = clock.Now
+
+	return mit
+}
+
+func TestCircuitBreaker_OpensAfterFailureStreak(t *testing.T) {
+	var (
+		calls   atomic.Int32
+		healthy atomic.Bool
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	mit := newBreakerMIT(server.URL, 3, time.Minute, clock)
+	ctx := context.Background()
+
+	for range 3 {
+		err := mit.RevokeToken(ctx, "key123")
+		assertProviderStatus(t, err, http.StatusInternalServerError)
+	}
+
+	assert.Equal(t, breakerOpen, mit.breaker.state)
+
+	err := mit.RevokeToken(ctx, "key123")
+	assert.ErrorIs(t, err, core.ErrProviderUnavailable)
+	assert.Equal(t, int32(3), calls.Load(), "open breaker must not call the provider")
+
+	// The first call after the cooldown probes the provider, which is still down.
+	clock.Advance(time.Minute)
+
+	err = mit.RevokeToken(ctx, "key123")
+	assertProviderStatus(t, err, http.StatusInternalServerError)
+	assert.Equal(t, int32(4), calls.Load())
+	assert.Equal(t, breakerOpen, mit.breaker.state)
+
+	err = mit.RevokeToken(ctx, "key123")
+	assert.ErrorIs(t, err, core.ErrProviderUnavailable)
+
+	// Once the provider recovers, a successful probe closes the breaker.
+	healthy.Store(true)
+	clock.Advance(time.Minute)
+
+	require.NoError(t, mit.RevokeToken(ctx, "key123"))
+	assert.Equal(t, breakerClosed, mit.breaker.state)
+	assert.Equal(t, 0, mit.breaker.failures)
+
+	require.NoError(t, mit.RevokeToken(ctx, "key123"))
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+func TestCircuitBreaker_SuccessResetsStreak(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1)%2 == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	mit := newBreakerMIT(server.URL, 2, time.Minute, &fakeClock{now: time.Now()})
+
+	for range 6 {
+		err := mit.RevokeToken(context.Background(), "key123")
+		assert.NotErrorIs(t, err, core.ErrProviderUnavailable)
+	}
+
+	assert.Equal(t, breakerClosed, mit.breaker.state)
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+func TestCircuitBreaker_ClientErrorsDoNotCount(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	mit := newBreakerMIT(server.URL, 2, time.Minute, &fakeClock{now: time.Now()})
+
+	for range 4 {
+		_, err := mit.GetToken(context.Background(), "key123")
+		assert.ErrorIs(t, err, core.ErrTokenNotFound)
+	}
+
+	assert.Equal(t, breakerClosed, mit.breaker.state)
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestCircuitBreaker_ConnectionErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.Close()
+
+	rec := &fakeRecorder{}
+	mit := newBreakerMIT(server.URL, 2, time.Minute, &fakeClock{now: time.Now()})
+	mit.metrics = rec
+
+	for range 3 {
+		_, err := mit.GenerateToken(context.Background(), "", core.TokenTypeWeb, 60)
+		require.Error(t, err)
+	}
+
+	_, err := mit.GenerateToken(context.Background(), "", core.TokenTypeWeb, 60)
+	assert.ErrorIs(t, err, core.ErrProviderUnavailable)
+	assert.Equal(t, map[string]int{"generate/error": 2, "generate/circuit_open": 2}, rec.calls)
+}
+
+func TestCircuitBreaker_HalfOpenAllowsSingleProbe(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	b := newCircuitBreaker(1, time.Second)
+	b.now = clock.Now
+	ctx := context.Background()
+
+	require.NoError(t, b.allow(ctx))
+	b.done(ctx, nil, errors.New("connection refused"))
+	assert.ErrorIs(t, b.allow(ctx), core.ErrProviderUnavailable)
+
+	clock.Advance(time.Second)
+
+	require.NoError(t, b.allow(ctx))
+	assert.Equal(t, breakerHalfOpen, b.state)
+	assert.ErrorIs(t, b.allow(ctx), core.ErrProviderUnavailable, "only one probe may be in flight")
+
+	// A probe cut short by its own context releases the slot without changing the state.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	b.done(canceled, nil, context.Canceled)
+	assert.Equal(t, breakerHalfOpen, b.state)
+
+	require.NoError(t, b.allow(ctx))
+	b.done(ctx, &http.Response{StatusCode: http.StatusOK}, nil)
+	assert.Equal(t, breakerClosed, b.state)
+}
+
+func TestCircuitBreaker_Nil(t *testing.T) {
+	var b *circuitBreaker
+
+	assert.NoError(t, b.allow(context.Background()))
+	b.done(context.Background(), nil, errors.New("connection refused"))
+}
+
+func TestNewCircuitBreaker(t *testing.T) {
+	b := newCircuitBreaker(0, 0)
+	assert.Equal(t, defaultBreakerThreshold, b.threshold)
+	assert.Equal(t, defaultBreakerCooldown, b.cooldown)
+
+	b = newCircuitBreaker(2, time.Second)
+	assert.Equal(t, 2, b.threshold)
+	assert.Equal(t, time.Second, b.cooldown)
+}
+
+func TestIsProviderFailure(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		code int
+		want bool
+	}{
+		{name: "transport error", err: errors.New("connection refused"), want: true},
+		{name: "server error", code: http.StatusInternalServerError, want: true},
+		{name: "rate limited", code: http.StatusTooManyRequests, want: true},
+		{name: "not found", code: http.StatusNotFound, want: false},
+		{name: "success", code: http.StatusCreated, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.code}
+			}
+
+			assert.Equal(t, tt.want, isProviderFailure(resp, tt.err))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package prov
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	// maxResponseSize bounds the response body read from the MIT API.
+	maxResponseSize = 1 << 20
+	// bodySnippetLen is the number of bytes of an unexpected response body included in errors.
+	bodySnippetLen = 200
+)
+
+// tokenValuePattern matches the value of a "token" field, which is redacted from body snippets,
+// so that a token from a malformed response never ends up in the logs.
+var tokenValuePattern = regexp.MustCompile(`("token"\s*:\s*")[^"]*`)
+
+// decodeResponse decodes the JSON body of resp into v. A body that isn't JSON, e.g. an HTML error page
+// of a proxy, is reported with the status code and a redacted, truncated snippet of the body.
+func decodeResponse(resp *http.Response, v any) error {
+	if ct := resp.Header.Get("Content-Type"); !isJSONContentType(ct) {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, bodySnippetLen+1))
+
+		return fmt.Errorf("unexpected response content-type %q, status code: %d, body: %s", ct, resp.StatusCode, bodySnippet(body))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode response, status code: %d, body: %s: %w", resp.StatusCode, bodySnippet(body), err)
+	}
+
+	return nil
+}
+
+// isJSONContentType reports whether a response with the content type ct may hold JSON. A missing type and
+// text/plain are accepted, as the Go HTTP server labels a JSON body text/plain when no type is set.
+func isJSONContentType(ct string) bool {
+	if ct == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || mediaType == "text/plain"
+}
+
+// bodySnippet returns the quoted beginning of body with token values redacted.
+func bodySnippet(body []byte) string {
+	s := tokenValuePattern.ReplaceAllString(string(body), "${1}[REDACTED]")
+
+	if len(s) > bodySnippetLen {
+		s = s[:bodySnippetLen] + "..."
+	}
+
+	return fmt.Sprintf("%q", s)
+}
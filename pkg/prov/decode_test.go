@@ -0,0 +1,109 @@
+package prov
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateToken_UnexpectedResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     []string
+	}{
+		{
+			name:        "html error page",
+			contentType: "text/html; charset=utf-8",
+			body:        "<html><body><h1>502 Bad Gateway</h1></body></html>",
+			wantErr: []string{
+				`unexpected response content-type "text/html; charset=utf-8", status code: 201`,
+				`<h1>502 Bad Gateway</h1>`,
+			},
+		},
+		{
+			name:        "long body is truncated",
+			contentType: "text/html",
+			body:        "<html>" + strings.Repeat("x", 1000),
+			wantErr:     []string{`"<html>` + strings.Repeat("x", bodySnippetLen-len("<html>")) + `..."`},
+		},
+		{
+			name:        "malformed json",
+			contentType: "application/json",
+			body:        `{"token": "secret-token", "key_id": `,
+			wantErr: []string{
+				"failed to decode response, status code: 201",
+				`\"token\": \"[REDACTED]\"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			mit := New(Config{Url: server.URL, DefaultTTL: 3600})
+
+			token, err := mit.GenerateToken(context.Background(), "", core.TokenTypeWeb, 0)
+			require.Error(t, err)
+			assert.Nil(t, token)
+
+			for _, want := range tt.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+
+			assert.NotContains(t, err.Error(), "secret-token")
+		})
+	}
+}
+
+func TestGetToken_HTMLResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("<html><body>Service temporarily unavailable</body></html>"))
+	}))
+	defer server.Close()
+
+	mit := New(Config{Url: server.URL, DefaultTTL: 3600})
+
+	details, err := mit.GetToken(context.Background(), "key123")
+
+	assert.Nil(t, details)
+	assert.EqualError(t, err,
+		`unexpected response content-type "text/html", status code: 200, body: "<html><body>Service temporarily unavailable</body></html>"`)
+}
+
+func TestIsJSONContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{contentType: "", want: true},
+		{contentType: "application/json", want: true},
+		{contentType: "application/json; charset=utf-8", want: true},
+		{contentType: "application/problem+json", want: true},
+		{contentType: "text/plain; charset=utf-8", want: true},
+		{contentType: "text/html", want: false},
+		{contentType: "application/xml", want: false},
+		{contentType: "not a media type;;", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			assert.Equal(t, tt.want, isJSONContentType(tt.contentType))
+		})
+	}
+}
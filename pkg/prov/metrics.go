@@ -0,0 +1,97 @@
+package prov
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	opGenerate = "generate"
+	opRevoke   = "revoke"
+	opRenew    = "renew"
+	opGet      = "get"
+
+	// statusClassError labels calls that got no response, e.g. because of a connection error or a timeout.
+	statusClassError = "error"
+	// statusClassCircuitOpen labels calls rejected by the open circuit breaker without reaching the provider.
+	statusClassCircuitOpen = "circuit_open"
+)
+
+// callLatencyBuckets covers fast API responses as well as calls that wait out several retries.
+var callLatencyBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// MetricsRecorder receives the outcome of every call to the MIT API, retries included.
+// statusClass is the class of the final response status such as "2xx", or "error" when there was no response.
+type MetricsRecorder interface {
+	ObserveCall(op, statusClass string, duration time.Duration)
+}
+
+// Option configures optional dependencies of MIT.
+type Option func(*MIT)
+
+// WithMetrics makes MIT report every API call to rec. Without it no metrics are recorded.
+func WithMetrics(rec MetricsRecorder) Option {
+	return func(m *MIT) {
+		m.metrics = rec
+	}
+}
+
+// observe reports a finished call to the metrics recorder, if there is one.
+func (m *MIT) observe(op string, resp *http.Response, err error, duration time.Duration) {
+	if m.metrics == nil {
+		return
+	}
+
+	m.metrics.ObserveCall(op, statusClass(resp, err), duration)
+}
+
+// observeStatus reports a call with a known status class to the metrics recorder, if there is one.
+func (m *MIT) observeStatus(op, class string, duration time.Duration) {
+	if m.metrics == nil {
+		return
+	}
+
+	m.metrics.ObserveCall(op, class, duration)
+}
+
+// statusClass returns the class of the response status, e.g. "2xx" for 201, or statusClassError without a response.
+func statusClass(resp *http.Response, err error) string {
+	if err != nil || resp == nil {
+		return statusClassError
+	}
+
+	return fmt.Sprintf("%dxx", resp.StatusCode/100)
+}
+
+// PrometheusMetrics is a MetricsRecorder backed by Prometheus collectors.
+type PrometheusMetrics struct {
+	calls    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewPrometheusMetrics creates the provider collectors and registers them with reg, they are left unregistered when reg is nil.
+func NewPrometheusMetrics(reg prometheus.Registerer) *PrometheusMetrics {
+	factory := promauto.With(reg)
+
+	return &PrometheusMetrics{
+		calls: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "mitbot_provider_requests_total",
+			Help: "Total number of MIT API calls by operation and response status class.",
+		}, []string{"op", "status_class"}),
+		duration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mitbot_provider_request_duration_seconds",
+			Help:    "Time spent on MIT API calls, retries included, by operation and response status class.",
+			Buckets: callLatencyBuckets,
+		}, []string{"op", "status_class"}),
+	}
+}
+
+// ObserveCall counts the call and records its duration.
+func (p *PrometheusMetrics) ObserveCall(op, statusClass string, duration time.Duration) {
+	p.calls.WithLabelValues(op, statusClass).Inc()
+	p.duration.WithLabelValues(op, statusClass).Observe(duration.Seconds())
+}
@@ -0,0 +1,134 @@
+package prov
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRecorder counts observed calls by operation and status class.
+type fakeRecorder struct {
+	calls map[string]int
+	mu    sync.Mutex
+}
+
+func (f *fakeRecorder) ObserveCall(op, statusClass string, duration time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+
+	f.calls[op+"/"+statusClass]++
+}
+
+func TestMetrics_ObserveCalls(t *testing.T) {
+	tests := []struct {
+		call   func(m *MIT) error
+		name   string
+		want   string
+		status int
+	}{
+		{
+			name:   "generate success",
+			status: http.StatusCreated,
+			call: func(m *MIT) error {
+				_, err := m.GenerateToken(context.Background(), "", core.TokenTypeWeb, 3600)
+				return err
+			},
+			want: "generate/2xx",
+		},
+		{
+			name:   "generate server error",
+			status: http.StatusInternalServerError,
+			call: func(m *MIT) error {
+				_, err := m.GenerateToken(context.Background(), "", core.TokenTypeWeb, 3600)
+				return err
+			},
+			want: "generate/5xx",
+		},
+		{
+			name:   "revoke success",
+			status: http.StatusNoContent,
+			call:   func(m *MIT) error { return m.RevokeToken(context.Background(), "key123") },
+			want:   "revoke/2xx",
+		},
+		{
+			name:   "revoke client error",
+			status: http.StatusForbidden,
+			call:   func(m *MIT) error { return m.RevokeToken(context.Background(), "key123") },
+			want:   "revoke/4xx",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+
+				if tt.status == http.StatusCreated {
+					_, _ = w.Write([]byte(`{"token":"tok","key_id":"key123","type":"web","ttl":3600}`))
+				}
+			}))
+			defer server.Close()
+
+			rec := &fakeRecorder{}
+			mit := New(Config{Url: server.URL, DefaultTTL: 3600, RetryBaseDelay: time.Millisecond}, WithMetrics(rec))
+
+			_ = tt.call(mit)
+
+			// Retried attempts are reported as a single call.
+			assert.Equal(t, map[string]int{tt.want: 1}, rec.calls)
+		})
+	}
+}
+
+func TestMetrics_TransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.Close()
+
+	rec := &fakeRecorder{}
+	mit := New(Config{Url: server.URL, DefaultTTL: 3600, MaxRetries: intPtr(1), RetryBaseDelay: time.Millisecond}, WithMetrics(rec))
+
+	require.Error(t, mit.RevokeToken(context.Background(), "key123"))
+	assert.Equal(t, map[string]int{"revoke/error": 1}, rec.calls)
+}
+
+func TestMetrics_NotConfigured(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	mit := New(Config{Url: server.URL, DefaultTTL: 3600})
+
+	assert.Nil(t, mit.metrics)
+	assert.NoError(t, mit.RevokeToken(context.Background(), "key123"))
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewPrometheusMetrics(reg)
+
+	m.ObserveCall(opGenerate, "2xx", 100*time.Millisecond)
+	m.ObserveCall(opGenerate, "2xx", 200*time.Millisecond)
+	m.ObserveCall(opRevoke, "5xx", time.Second)
+
+	assert.InDelta(t, 2, testutil.ToFloat64(m.calls.WithLabelValues(opGenerate, "2xx")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.calls.WithLabelValues(opRevoke, "5xx")), 0)
+	assert.Equal(t, 2, testutil.CollectAndCount(m.duration))
+
+	count, err := testutil.GatherAndCount(reg, "mitbot_provider_requests_total", "mitbot_provider_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+}
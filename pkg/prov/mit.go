@@ -2,38 +2,145 @@ package prov
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
 )
 
+// DefaultTimeout bounds a single HTTP request to the MIT API when Config.Timeout is unset.
+// It must be shorter than the bot request timeout, which every provider call runs within.
+const DefaultTimeout = 5 * time.Second
+
 type Config struct {
 	Url        string `mapstructure:"url"`
 	DefaultTTL int64  `mapstructure:"default_ttl"`
+	// MinTTL and MaxTTL bound the token TTL in seconds accepted by the provider, zero leaves a bound unchecked.
+	MinTTL         int64         `mapstructure:"min_ttl"`
+	MaxTTL         int64         `mapstructure:"max_ttl"`
+	APIKey         string        `mapstructure:"api_key"`
+	RetryBaseDelay time.Duration `mapstructure:"retry_base_delay"`
+	// MaxRetries is the number of extra attempts for transient failures, 3 when unset and none when 0.
+	MaxRetries *int `mapstructure:"max_retries"`
+	// Timeout bounds a single HTTP request attempt, DefaultTimeout by default.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// BreakerThreshold is the number of consecutive failed calls that opens the circuit breaker, 5 by default.
+	BreakerThreshold int `mapstructure:"breaker_threshold"`
+	// BreakerCooldown is how long the open circuit breaker rejects calls before probing the provider, 30s by default.
+	BreakerCooldown time.Duration `mapstructure:"breaker_cooldown"`
+}
+
+// Validate checks that the provider URL is an absolute http(s) URL and the default TTL is positive and within
+// the TTL bounds. It returns every problem found joined into a single error.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Url == "" {
+		errs = append(errs, errors.New("url is required"))
+	} else if u, err := url.Parse(c.Url); err != nil {
+		errs = append(errs, fmt.Errorf("url is invalid: %w", err))
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("url %q must be an absolute http or https URL", c.Url))
+	}
+
+	if c.DefaultTTL <= 0 {
+		errs = append(errs, fmt.Errorf("default_ttl must be positive, got %d", c.DefaultTTL))
+	}
+
+	switch {
+	case c.MinTTL < 0:
+		errs = append(errs, fmt.Errorf("min_ttl must not be negative, got %d", c.MinTTL))
+	case c.MaxTTL < 0:
+		errs = append(errs, fmt.Errorf("max_ttl must not be negative, got %d", c.MaxTTL))
+	case c.MaxTTL > 0 && c.MinTTL > c.MaxTTL:
+		errs = append(errs, fmt.Errorf("min_ttl %d must not be greater than max_ttl %d", c.MinTTL, c.MaxTTL))
+	case c.DefaultTTL > 0 && (c.DefaultTTL < c.MinTTL || (c.MaxTTL > 0 && c.DefaultTTL > c.MaxTTL)):
+		errs = append(errs, fmt.Errorf("default_ttl %d must be within min_ttl and max_ttl", c.DefaultTTL))
+	}
+
+	if c.MaxRetries != nil && *c.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("max_retries must not be negative, got %d", *c.MaxRetries))
+	}
+
+	if c.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout must not be negative, got %s", c.Timeout))
+	}
+
+	if c.BreakerThreshold < 0 {
+		errs = append(errs, fmt.Errorf("breaker_threshold must not be negative, got %d", c.BreakerThreshold))
+	}
+
+	if c.BreakerCooldown < 0 {
+		errs = append(errs, fmt.Errorf("breaker_cooldown must not be negative, got %s", c.BreakerCooldown))
+	}
+
+	return errors.Join(errs...)
+}
+
+// EffectiveTimeout returns the configured HTTP request timeout or DefaultTimeout when it is unset.
+func (c *Config) EffectiveTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+
+	return DefaultTimeout
 }
 
 type MIT struct {
-	cl         *http.Client
-	baseUrl    string
-	defaultTTL int64
+	cl             *http.Client
+	metrics        MetricsRecorder
+	breaker        *circuitBreaker
+	baseUrl        string
+	apiKey         string
+	defaultTTL     int64
+	minTTL         int64
+	maxTTL         int64
+	maxRetries     int
+	retryBaseDelay time.Duration
 }
 
 // New creates and returns a new instance of the MIT struct initialized with the provided configuration.
-func New(cfg Config) *MIT {
-	return &MIT{
-		defaultTTL: cfg.DefaultTTL,
-		baseUrl:    cfg.Url,
+// An unset MaxRetries and zero retry delay, timeout and circuit breaker settings are replaced with defaults.
+func New(cfg Config, opts ...Option) *MIT {
+	maxRetries := defaultMaxRetries
+	if cfg.MaxRetries != nil {
+		maxRetries = max(*cfg.MaxRetries, 0)
+	}
+
+	retryBaseDelay := cfg.RetryBaseDelay
+	if retryBaseDelay <= 0 {
+		retryBaseDelay = defaultRetryBaseDelay
+	}
+
+	m := &MIT{
+		defaultTTL:     cfg.DefaultTTL,
+		minTTL:         cfg.MinTTL,
+		maxTTL:         cfg.MaxTTL,
+		baseUrl:        cfg.Url,
+		apiKey:         cfg.APIKey,
+		maxRetries:     maxRetries,
+		retryBaseDelay: retryBaseDelay,
+		breaker:        newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
 		cl: &http.Client{
-			Timeout: 5 * time.Second,
+			Timeout: cfg.EffectiveTimeout(),
 		},
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
 }
 
 type generateTokenRequest struct {
-	KeyID string `json:"key_id"`
+	KeyID string `json:"key_id,omitempty"`
 	Type  string `json:"type"`
 	TTL   int64  `json:"ttl"`
 }
@@ -46,11 +153,18 @@ type generateTokenResponse struct {
 }
 
 // GenerateToken sends a request to generate an API token of the given type and returns the token along with its metadata or an error.
-func (m *MIT) GenerateToken(keyID string, tokenType core.TokenType, ttl int64) (*core.APIToken, error) {
-	if ttl <= 0 {
+// A zero ttl is replaced with the default TTL, a ttl outside of the configured bounds is rejected with core.ErrInvalidTTL
+// without calling the provider. Creating a token isn't idempotent, so the request is only retried when it couldn't
+// be sent at all: a retry after a failed response could create a second token or report the key ID as taken.
+func (m *MIT) GenerateToken(ctx context.Context, keyID string, tokenType core.TokenType, ttl int64) (*core.APIToken, error) {
+	if ttl == 0 {
 		ttl = m.defaultTTL
 	}
 
+	if err := m.checkTTL(ttl); err != nil {
+		return nil, err
+	}
+
 	req := generateTokenRequest{
 		KeyID: keyID,
 		Type:  string(tokenType),
@@ -62,7 +176,16 @@ func (m *MIT) GenerateToken(keyID string, tokenType core.TokenType, ttl int64) (
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := m.cl.Post(m.baseUrl+"/token", "application/json", bytes.NewBuffer(jsonReq))
+	resp, err := m.doWithRetry(ctx, opGenerate, isNotSent, func() (*http.Request, error) {
+		r, err := m.newRequest(ctx, http.MethodPost, "/token", bytes.NewReader(jsonReq))
+		if err != nil {
+			return nil, err
+		}
+
+		r.Header.Set("Content-Type", "application/json")
+
+		return r, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -77,13 +200,13 @@ func (m *MIT) GenerateToken(keyID string, tokenType core.TokenType, ttl int64) (
 	case http.StatusBadRequest:
 		return nil, core.ErrInvalidKeyID
 	default:
-		return nil, fmt.Errorf("failed to generate token, status code: %d", resp.StatusCode)
+		return nil, &core.ProviderError{Op: "generate token", StatusCode: resp.StatusCode}
 	}
 
 	var tkn generateTokenResponse
 
-	if err := json.NewDecoder(resp.Body).Decode(&tkn); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := decodeResponse(resp, &tkn); err != nil {
+		return nil, err
 	}
 
 	return &core.APIToken{
@@ -95,21 +218,143 @@ func (m *MIT) GenerateToken(keyID string, tokenType core.TokenType, ttl int64) (
 }
 
 // RevokeToken sends a request to revoke an API token based on the provided key ID and returns an error if the request fails.
-func (m *MIT) RevokeToken(keyID string) error {
-	req, err := http.NewRequest("DELETE", m.baseUrl+"/token/"+keyID, http.NoBody)
+// Transient failures are retried according to the configured retry policy.
+func (m *MIT) RevokeToken(ctx context.Context, keyID string) error {
+	resp, err := m.doWithRetry(ctx, opRevoke, isRetryable, func() (*http.Request, error) {
+		return m.newRequest(ctx, http.MethodDelete, tokenPath(keyID), http.NoBody)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return &core.ProviderError{Op: "revoke token", StatusCode: resp.StatusCode}
+	}
+}
+
+type renewTokenRequest struct {
+	TTL int64 `json:"ttl"`
+}
+
+// RenewToken asks the provider to extend the key's expiration to ttl seconds from now, keeping the token value.
+// It returns core.ErrRenewNotSupported if the provider has no renewal endpoint and core.ErrInvalidTTL, without calling
+// the provider, if ttl is outside of the configured bounds.
+func (m *MIT) RenewToken(ctx context.Context, keyID string, ttl int64) error {
+	if err := m.checkTTL(ttl); err != nil {
+		return err
+	}
+
+	jsonReq, err := json.Marshal(renewTokenRequest{TTL: ttl})
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := m.cl.Do(req)
+	resp, err := m.doWithRetry(ctx, opRenew, isRetryable, func() (*http.Request, error) {
+		r, err := m.newRequest(ctx, http.MethodPatch, tokenPath(keyID), bytes.NewReader(jsonReq))
+		if err != nil {
+			return nil, err
+		}
+
+		r.Header.Set("Content-Type", "application/json")
+
+		return r, nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 
 	defer func() { _ = resp.Body.Close() }()
-	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
-		return fmt.Errorf("failed to revoke token, status code: %d", resp.StatusCode)
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return core.ErrKeyNotFound
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return core.ErrRenewNotSupported
+	default:
+		return &core.ProviderError{Op: "renew token", StatusCode: resp.StatusCode}
+	}
+}
+
+type getTokenResponse struct {
+	KeyID  string `json:"key_id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	TTL    int64  `json:"ttl"`
+}
+
+// GetToken fetches the provider's details of the token with the given key ID: its type, status and remaining TTL.
+// It returns core.ErrTokenNotFound if the provider doesn't know the key.
+// Transient failures are retried according to the configured retry policy.
+func (m *MIT) GetToken(ctx context.Context, keyID string) (*core.TokenDetails, error) {
+	resp, err := m.doWithRetry(ctx, opGet, isRetryable, func() (*http.Request, error) {
+		return m.newRequest(ctx, http.MethodGet, tokenPath(keyID), http.NoBody)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// success, decode response below
+	case http.StatusNotFound:
+		return nil, core.ErrTokenNotFound
+	default:
+		return nil, &core.ProviderError{Op: "get token", StatusCode: resp.StatusCode}
+	}
+
+	var tkn getTokenResponse
+
+	if err := decodeResponse(resp, &tkn); err != nil {
+		return nil, err
+	}
+
+	return &core.TokenDetails{
+		KeyID:     tkn.KeyID,
+		Type:      core.TokenType(tkn.Type),
+		Status:    tkn.Status,
+		ExpiresIn: time.Duration(tkn.TTL) * time.Second,
+	}, nil
+}
+
+// checkTTL returns core.ErrInvalidTTL if ttl is negative or outside of the configured bounds.
+func (m *MIT) checkTTL(ttl int64) error {
+	switch {
+	case ttl < 0:
+		return fmt.Errorf("%w: %d seconds must not be negative", core.ErrInvalidTTL, ttl)
+	case m.minTTL > 0 && ttl < m.minTTL:
+		return fmt.Errorf("%w: %d seconds is below the minimum of %d", core.ErrInvalidTTL, ttl, m.minTTL)
+	case m.maxTTL > 0 && ttl > m.maxTTL:
+		return fmt.Errorf("%w: %d seconds is above the maximum of %d", core.ErrInvalidTTL, ttl, m.maxTTL)
+	default:
+		return nil
+	}
+}
+
+// tokenPath returns the API path of the token with the given key ID. The key ID is escaped, so that characters
+// like "/", "?" or "#" can't point the request at another resource.
+func tokenPath(keyID string) string {
+	return "/token/" + url.PathEscape(keyID)
+}
+
+// newRequest builds a request to the MIT API for the given path, attaching the API key as a bearer token when configured.
+func (m *MIT) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, m.baseUrl+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
 	}
 
-	return nil
+	return req, nil
 }
@@ -1,9 +1,11 @@
 package prov
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,6 +14,9 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// MIT must keep satisfying the provider interface core depends on.
+var _ core.MITProv = (*MIT)(nil)
+
 func TestNew(t *testing.T) {
 	cfg := Config{
 		Url:        "https://example.com",
@@ -23,7 +28,54 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, mit)
 	assert.Equal(t, cfg.Url, mit.baseUrl)
 	assert.Equal(t, cfg.DefaultTTL, mit.defaultTTL)
-	assert.NotNil(t, mit.cl)
+	assert.Equal(t, defaultMaxRetries, mit.maxRetries)
+	assert.Equal(t, defaultRetryBaseDelay, mit.retryBaseDelay)
+	require.NotNil(t, mit.cl)
+	assert.Equal(t, DefaultTimeout, mit.cl.Timeout)
+	require.NotNil(t, mit.breaker)
+	assert.Equal(t, defaultBreakerThreshold, mit.breaker.threshold)
+	assert.Equal(t, defaultBreakerCooldown, mit.breaker.cooldown)
+
+	cfg.Timeout = 2 * time.Second
+	assert.Equal(t, 2*time.Second, New(cfg).cl.Timeout)
+}
+
+func TestNew_MaxRetries(t *testing.T) {
+	tests := []struct {
+		maxRetries *int
+		name       string
+		want       int
+	}{
+		{name: "unset", want: defaultMaxRetries},
+		{name: "disabled", maxRetries: intPtr(0), want: 0},
+		{name: "configured", maxRetries: intPtr(5), want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mit := New(Config{Url: "https://example.com", DefaultTTL: 3600, MaxRetries: tt.maxRetries})
+
+			assert.Equal(t, tt.want, mit.maxRetries)
+		})
+	}
+}
+
+// intPtr returns a pointer to n, for optional config settings.
+func intPtr(n int) *int {
+	return &n
+}
+
+// assertProviderStatus checks that err is a *core.ProviderError with the given status code. A zero status skips the check.
+func assertProviderStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	if status == 0 {
+		return
+	}
+
+	var provErr *core.ProviderError
+	require.ErrorAs(t, err, &provErr)
+	assert.Equal(t, status, provErr.StatusCode)
 }
 
 func TestGenerateToken(t *testing.T) {
@@ -35,6 +87,7 @@ func TestGenerateToken(t *testing.T) {
 		tokenType        core.TokenType
 		expectedError    string
 		defaultTTL       int64
+		expectedStatus   int
 	}{
 		{
 			name:       "success web token",
@@ -100,7 +153,8 @@ func TestGenerateToken(t *testing.T) {
 			serverResponse: func(w http.ResponseWriter, r *http.Request) {
 				w.WriteHeader(http.StatusInternalServerError)
 			},
-			expectedError: "failed to generate token, status code: 500",
+			expectedError:  "failed to generate token, status code: 500",
+			expectedStatus: http.StatusInternalServerError,
 		},
 		{
 			name:      "invalid response",
@@ -128,6 +182,24 @@ func TestGenerateToken(t *testing.T) {
 			},
 			expectedSentinel: core.ErrInvalidKeyID,
 		},
+		{
+			name:      "unauthorized",
+			tokenType: core.TokenTypeWeb,
+			serverResponse: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			expectedSentinel: core.ErrProviderUnauthorized,
+			expectedStatus:   http.StatusUnauthorized,
+		},
+		{
+			name:      "forbidden",
+			tokenType: core.TokenTypeWeb,
+			serverResponse: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			},
+			expectedSentinel: core.ErrProviderUnauthorized,
+			expectedStatus:   http.StatusForbidden,
+		},
 	}
 
 	for _, tt := range tests {
@@ -141,7 +213,7 @@ func TestGenerateToken(t *testing.T) {
 				cl:         &http.Client{},
 			}
 
-			token, err := mit.GenerateToken("", tt.tokenType, tt.defaultTTL)
+			token, err := mit.GenerateToken(context.Background(), "", tt.tokenType, tt.defaultTTL)
 
 			if tt.expectedSentinel != nil {
 				require.Error(t, err)
@@ -155,6 +227,8 @@ func TestGenerateToken(t *testing.T) {
 				require.NoError(t, err)
 				assert.Equal(t, tt.expectedToken, token)
 			}
+
+			assertProviderStatus(t, err, tt.expectedStatus)
 		})
 	}
 }
@@ -165,6 +239,7 @@ func TestRevokeToken(t *testing.T) {
 		keyID          string
 		serverResponse func(w http.ResponseWriter, r *http.Request)
 		expectedError  string
+		expectedStatus int
 	}{
 		{
 			name:  "success",
@@ -192,7 +267,8 @@ func TestRevokeToken(t *testing.T) {
 				assert.Equal(t, "/token/error-key", r.URL.Path)
 				w.WriteHeader(http.StatusInternalServerError)
 			},
-			expectedError: "failed to revoke token, status code: 500",
+			expectedError:  "failed to revoke token, status code: 500",
+			expectedStatus: http.StatusInternalServerError,
 		},
 		{
 			name:  "bad request",
@@ -202,7 +278,17 @@ func TestRevokeToken(t *testing.T) {
 				assert.Equal(t, "/token/bad-request-key", r.URL.Path)
 				w.WriteHeader(http.StatusBadRequest)
 			},
-			expectedError: "failed to revoke token, status code: 400",
+			expectedError:  "failed to revoke token, status code: 400",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "unauthorized",
+			keyID: "some-key",
+			serverResponse: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			expectedError:  "failed to revoke token, status code: 401",
+			expectedStatus: http.StatusUnauthorized,
 		},
 	}
 
@@ -216,7 +302,7 @@ func TestRevokeToken(t *testing.T) {
 				cl:      &http.Client{},
 			}
 
-			err := mit.RevokeToken(tt.keyID)
+			err := mit.RevokeToken(context.Background(), tt.keyID)
 
 			if tt.expectedError != "" {
 				require.Error(t, err)
@@ -224,6 +310,443 @@ func TestRevokeToken(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 			}
+
+			assertProviderStatus(t, err, tt.expectedStatus)
+		})
+	}
+}
+
+func TestGenerateToken_ContextCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+
+	defer server.Close()
+	defer close(release)
+
+	mit := &MIT{
+		baseUrl: server.URL,
+		cl:      &http.Client{},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	token, err := mit.GenerateToken(ctx, "", core.TokenTypeWeb, 60)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, token)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestAuthorizationHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiKey     string
+		wantHeader string
+	}{
+		{name: "api key configured", apiKey: "secret", wantHeader: "Bearer secret"},
+		{name: "no api key", apiKey: "", wantHeader: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers []string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				headers = append(headers, r.Header.Get("Authorization"))
+
+				if r.Method == http.MethodDelete {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(generateTokenResponse{Token: "token", KeyID: "key", Type: "web", TTL: 60})
+			}))
+			defer server.Close()
+
+			mit := New(Config{Url: server.URL, APIKey: tt.apiKey})
+
+			_, err := mit.GenerateToken(context.Background(), "", core.TokenTypeWeb, 60)
+			require.NoError(t, err)
+
+			err = mit.RevokeToken(context.Background(), "key")
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{tt.wantHeader, tt.wantHeader}, headers)
+		})
+	}
+}
+
+func TestRenewToken(t *testing.T) {
+	tests := []struct {
+		expectedSentinel error
+		name             string
+		expectedError    string
+		status           int
+		wantProviderErr  bool
+	}{
+		{name: "success", status: http.StatusOK},
+		{name: "no content", status: http.StatusNoContent},
+		{name: "key not found", status: http.StatusNotFound, expectedSentinel: core.ErrKeyNotFound},
+		{name: "method not allowed", status: http.StatusMethodNotAllowed, expectedSentinel: core.ErrRenewNotSupported},
+		{name: "not implemented", status: http.StatusNotImplemented, expectedSentinel: core.ErrRenewNotSupported},
+		{name: "unauthorized", status: http.StatusUnauthorized, expectedSentinel: core.ErrProviderUnauthorized, wantProviderErr: true},
+		{name: "server error", status: http.StatusInternalServerError, expectedError: "failed to renew token, status code: 500", wantProviderErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPatch, r.Method)
+				assert.Equal(t, "/token/key1", r.URL.Path)
+
+				var req renewTokenRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, int64(3600), req.TTL)
+
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			mit := &MIT{
+				baseUrl: server.URL,
+				cl:      &http.Client{},
+			}
+
+			err := mit.RenewToken(context.Background(), "key1", 3600)
+
+			switch {
+			case tt.expectedSentinel != nil:
+				assert.ErrorIs(t, err, tt.expectedSentinel)
+			case tt.expectedError != "":
+				assert.ErrorContains(t, err, tt.expectedError)
+			default:
+				assert.NoError(t, err)
+			}
+
+			if tt.wantProviderErr {
+				assertProviderStatus(t, err, tt.status)
+			}
+		})
+	}
+}
+
+func TestGetToken(t *testing.T) {
+	tests := []struct {
+		expectedSentinel error
+		expected         *core.TokenDetails
+		name             string
+		body             string
+		expectedError    string
+		status           int
+		wantProviderErr  bool
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"key_id":"key1","type":"tcp","status":"active","ttl":3600}`,
+			expected: &core.TokenDetails{
+				KeyID:     "key1",
+				Type:      core.TokenTypeTCP,
+				Status:    "active",
+				ExpiresIn: time.Hour,
+			},
+		},
+		{name: "not found", status: http.StatusNotFound, expectedSentinel: core.ErrTokenNotFound},
+		{name: "forbidden", status: http.StatusForbidden, expectedSentinel: core.ErrProviderUnauthorized, wantProviderErr: true},
+		{name: "server error", status: http.StatusInternalServerError, expectedError: "failed to get token, status code: 500", wantProviderErr: true},
+		{name: "invalid body", status: http.StatusOK, body: "not json", expectedError: "failed to decode response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "/token/key1", r.URL.Path)
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			mit := &MIT{
+				baseUrl: server.URL,
+				cl:      &http.Client{},
+			}
+
+			details, err := mit.GetToken(context.Background(), "key1")
+
+			switch {
+			case tt.expectedSentinel != nil:
+				assert.ErrorIs(t, err, tt.expectedSentinel)
+			case tt.expectedError != "":
+				assert.ErrorContains(t, err, tt.expectedError)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, details)
+			}
+
+			if tt.wantProviderErr {
+				assertProviderStatus(t, err, tt.status)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr []string
+	}{
+		{
+			name: "valid",
+			cfg:  Config{Url: "http://localhost:8082", DefaultTTL: 3600},
+		},
+		{
+			name:    "empty config",
+			cfg:     Config{},
+			wantErr: []string{"url is required", "default_ttl must be positive"},
+		},
+		{
+			name:    "relative url",
+			cfg:     Config{Url: "localhost:8082", DefaultTTL: 3600},
+			wantErr: []string{"must be an absolute http or https URL"},
+		},
+		{
+			name:    "unparsable url",
+			cfg:     Config{Url: "http://[::1", DefaultTTL: 3600},
+			wantErr: []string{"url is invalid"},
+		},
+		{
+			name:    "negative ttl",
+			cfg:     Config{Url: "https://mit.example.com", DefaultTTL: -1},
+			wantErr: []string{"default_ttl must be positive, got -1"},
+		},
+		{
+			name:    "negative timeout",
+			cfg:     Config{Url: "https://mit.example.com", DefaultTTL: 3600, Timeout: -time.Second},
+			wantErr: []string{"timeout must not be negative"},
+		},
+		{
+			name:    "negative max retries",
+			cfg:     Config{Url: "https://mit.example.com", DefaultTTL: 3600, MaxRetries: intPtr(-1)},
+			wantErr: []string{"max_retries must not be negative, got -1"},
+		},
+		{
+			name: "retries disabled",
+			cfg:  Config{Url: "https://mit.example.com", DefaultTTL: 3600, MaxRetries: intPtr(0)},
+		},
+		{
+			name:    "negative ttl bounds",
+			cfg:     Config{Url: "https://mit.example.com", DefaultTTL: 3600, MinTTL: -1},
+			wantErr: []string{"min_ttl must not be negative, got -1"},
+		},
+		{
+			name:    "inverted ttl bounds",
+			cfg:     Config{Url: "https://mit.example.com", DefaultTTL: 3600, MinTTL: 7200, MaxTTL: 60},
+			wantErr: []string{"min_ttl 7200 must not be greater than max_ttl 60"},
+		},
+		{
+			name:    "default ttl outside of bounds",
+			cfg:     Config{Url: "https://mit.example.com", DefaultTTL: 3600, MaxTTL: 60},
+			wantErr: []string{"default_ttl 3600 must be within min_ttl and max_ttl"},
+		},
+		{
+			name: "ttl bounds",
+			cfg:  Config{Url: "https://mit.example.com", DefaultTTL: 3600, MinTTL: 60, MaxTTL: 3600},
+		},
+		{
+			name:    "negative breaker settings",
+			cfg:     Config{Url: "https://mit.example.com", DefaultTTL: 3600, BreakerThreshold: -1, BreakerCooldown: -time.Second},
+			wantErr: []string{"breaker_threshold must not be negative, got -1", "breaker_cooldown must not be negative"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			for _, want := range tt.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestGenerateToken_ForwardsKeyIDAndTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyID   string
+		ttl     int64
+		wantTTL int64
+	}{
+		{name: "requested ttl is forwarded", keyID: "myapp", ttl: 7 * 24 * 60 * 60, wantTTL: 7 * 24 * 60 * 60},
+		{name: "zero ttl falls back to default", ttl: 0, wantTTL: 3600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req map[string]any
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+				if tt.keyID == "" {
+					assert.NotContains(t, req, "key_id", "an empty key ID lets the provider generate one")
+				} else {
+					assert.Equal(t, tt.keyID, req["key_id"])
+				}
+
+				assert.InDelta(t, tt.wantTTL, req["ttl"], 0)
+
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(generateTokenResponse{Token: "token", KeyID: "key", Type: "web", TTL: tt.wantTTL})
+			}))
+			defer server.Close()
+
+			mit := New(Config{Url: server.URL, DefaultTTL: 3600})
+
+			token, err := mit.GenerateToken(context.Background(), tt.keyID, core.TokenTypeWeb, tt.ttl)
+			require.NoError(t, err)
+
+			assert.Equal(t, time.Duration(tt.wantTTL)*time.Second, token.ExpiresIn)
+		})
+	}
+}
+
+func TestGenerateToken_TTLBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     int64
+		wantTTL int64
+		wantErr bool
+	}{
+		{name: "zero ttl falls back to default", ttl: 0, wantTTL: 600},
+		{name: "minimum", ttl: 60, wantTTL: 60},
+		{name: "maximum", ttl: 3600, wantTTL: 3600},
+		{name: "below minimum", ttl: 59, wantErr: true},
+		{name: "above maximum", ttl: 3601, wantErr: true},
+		{name: "negative", ttl: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+
+				var req generateTokenRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, tt.wantTTL, req.TTL)
+
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(generateTokenResponse{Token: "token", KeyID: "key", Type: "web", TTL: req.TTL})
+			}))
+			defer server.Close()
+
+			mit := New(Config{Url: server.URL, DefaultTTL: 600, MinTTL: 60, MaxTTL: 3600})
+
+			token, err := mit.GenerateToken(context.Background(), "", core.TokenTypeWeb, tt.ttl)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, core.ErrInvalidTTL)
+				assert.Nil(t, token)
+				assert.Zero(t, calls.Load(), "a rejected ttl must not reach the provider")
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, time.Duration(tt.wantTTL)*time.Second, token.ExpiresIn)
+			assert.Equal(t, int32(1), calls.Load())
+		})
+	}
+}
+
+func TestRenewToken_TTLBounds(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	mit := New(Config{Url: server.URL, DefaultTTL: 600, MaxTTL: 3600})
+
+	require.NoError(t, mit.RenewToken(context.Background(), "key1", 3600))
+
+	err := mit.RenewToken(context.Background(), "key1", 3601)
+	assert.ErrorIs(t, err, core.ErrInvalidTTL)
+	assert.ErrorContains(t, err, "3601 seconds is above the maximum of 3600")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTokenRequests_EscapeKeyID(t *testing.T) {
+	const keyID = "../admin/key?x=1#frag"
+
+	tests := []struct {
+		call   func(ctx context.Context, mit *MIT) error
+		name   string
+		method string
+		status int
+	}{
+		{
+			name:   "get",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			call: func(ctx context.Context, mit *MIT) error {
+				_, err := mit.GetToken(ctx, keyID)
+				return err
+			},
+		},
+		{
+			name:   "revoke",
+			method: http.MethodDelete,
+			status: http.StatusNoContent,
+			call: func(ctx context.Context, mit *MIT) error {
+				return mit.RevokeToken(ctx, keyID)
+			},
+		},
+		{
+			name:   "renew",
+			method: http.MethodPatch,
+			status: http.StatusNoContent,
+			call: func(ctx context.Context, mit *MIT) error {
+				return mit.RenewToken(ctx, keyID, 60)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.method, r.Method)
+				assert.Equal(t, "/token/..%2Fadmin%2Fkey%3Fx=1%23frag", r.URL.EscapedPath())
+				assert.Equal(t, "/token/"+keyID, r.URL.Path)
+				assert.Empty(t, r.URL.RawQuery)
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"key_id":"key1","type":"web","status":"active","ttl":60}`))
+			}))
+			defer server.Close()
+
+			mit := &MIT{
+				baseUrl: server.URL,
+				cl:      &http.Client{},
+			}
+
+			require.NoError(t, tt.call(context.Background(), mit))
 		})
 	}
 }
@@ -0,0 +1,127 @@
+package prov
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = 200 * time.Millisecond
+)
+
+// retryPolicy reports whether a failed attempt may be repeated.
+type retryPolicy func(resp *http.Response, err error) bool
+
+// doWithRetry sends the request built by newReq, retrying the failures accepted by retryable, and reports
+// the outcome of the whole call, retries included, to the metrics recorder under op. While the circuit breaker
+// is open the request is not sent and core.ErrProviderUnavailable is returned right away.
+func (m *MIT) doWithRetry(ctx context.Context, op string, retryable retryPolicy, newReq func() (*http.Request, error)) (*http.Response, error) {
+	if err := m.breaker.allow(ctx); err != nil {
+		m.observeStatus(op, statusClassCircuitOpen, 0)
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := m.retry(ctx, retryable, newReq)
+	m.observe(op, resp, err, time.Since(start))
+	m.breaker.done(ctx, resp, err)
+
+	return resp, err
+}
+
+// retry sends the request built by newReq and retries the failures accepted by retryable
+// with exponential backoff and jitter, up to maxRetries extra attempts.
+// newReq is called for every attempt so that request bodies can be replayed.
+// The response or error of the last attempt is returned as is; waiting stops early once ctx is done.
+func (m *MIT) retry(ctx context.Context, retryable retryPolicy, newReq func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := m.cl.Do(req)
+		logResponse(ctx, req, resp, err, attempt, time.Since(start))
+
+		if attempt >= m.maxRetries || ctx.Err() != nil || !retryable(resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(m.backoff(attempt)):
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt: retryBaseDelay doubled per attempt,
+// plus up to 50% random jitter so that concurrent clients don't retry in lockstep.
+func (m *MIT) backoff(attempt int) time.Duration {
+	delay := m.retryBaseDelay << attempt
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay + rand.N(delay/2+1)
+}
+
+// isRetryable reports whether a failed attempt of an idempotent request is worth repeating.
+// Connection errors, rate limiting and gateway failures are treated as transient.
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isNotSent reports whether a failed attempt provably never reached the provider because the connection couldn't
+// be established. Only such attempts of a request that isn't idempotent, like creating a token, may be repeated:
+// after any later failure the provider may have acted on the request already.
+func isNotSent(_ *http.Response, err error) bool {
+	var opErr *net.OpError
+
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// logResponse logs the outcome of a single provider call. Successful calls are logged at debug level,
+// transport errors and error statuses at error level. The context carries the request and chat IDs.
+func logResponse(ctx context.Context, req *http.Request, resp *http.Response, err error, attempt int, latency time.Duration) {
+	attrs := []any{
+		slog.String("method", req.Method),
+		slog.String("endpoint", req.URL.Path),
+		slog.Int("attempt", attempt+1),
+		slog.Duration("latency", latency),
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Provider request failed", append(attrs, slog.Any("error", err))...)
+		return
+	}
+
+	attrs = append(attrs, slog.Int("status", resp.StatusCode))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		slog.ErrorContext(ctx, "Provider request failed", attrs...)
+		return
+	}
+
+	slog.DebugContext(ctx, "Provider request completed", attrs...)
+}
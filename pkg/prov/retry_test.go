@@ -0,0 +1,386 @@
+package prov
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingServer responds with failStatus for the first failures requests and delegates to success afterwards.
+func failingServer(t *testing.T, failures int32, failStatus int, success http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(failStatus)
+			return
+		}
+
+		success(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func TestGenerateToken_NoRetryAfterSending(t *testing.T) {
+	tests := []struct {
+		name       string
+		failStatus int
+	}{
+		{name: "service unavailable", failStatus: http.StatusServiceUnavailable},
+		{name: "bad gateway", failStatus: http.StatusBadGateway},
+		{name: "gateway timeout", failStatus: http.StatusGatewayTimeout},
+		{name: "rate limiting", failStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := failingServer(t, 1, tt.failStatus, func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(generateTokenResponse{Token: "token", KeyID: "key", Type: "web", TTL: 60})
+			})
+
+			mit := &MIT{
+				baseUrl:        server.URL,
+				cl:             &http.Client{},
+				maxRetries:     3,
+				retryBaseDelay: time.Millisecond,
+			}
+
+			token, err := mit.GenerateToken(context.Background(), "", core.TokenTypeWeb, 60)
+
+			assertProviderStatus(t, err, tt.failStatus)
+			assert.Nil(t, token)
+			assert.Equal(t, int32(1), calls.Load())
+		})
+	}
+}
+
+func TestGenerateToken_ServerErrorAfterCreating(t *testing.T) {
+	var created atomic.Int32
+
+	// The provider creates the token, but the response is lost to a failing proxy in front of it.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req generateTokenRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		if created.Add(1) > 1 {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	mit := &MIT{
+		baseUrl:        server.URL,
+		cl:             &http.Client{},
+		maxRetries:     3,
+		retryBaseDelay: time.Millisecond,
+	}
+
+	_, err := mit.GenerateToken(context.Background(), "myapp", core.TokenTypeWeb, 60)
+
+	assertProviderStatus(t, err, http.StatusBadGateway)
+	assert.NotErrorIs(t, err, core.ErrDuplicateKeyID, "a retry must not report the key ID it just took as taken")
+	assert.Equal(t, int32(1), created.Load(), "the token must not be created twice")
+}
+
+func TestGenerateToken_RetryDialError(t *testing.T) {
+	server, calls := failingServer(t, 0, 0, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(generateTokenResponse{Token: "token", KeyID: "key", Type: "web", TTL: 60})
+	})
+
+	var (
+		dialer net.Dialer
+		dials  atomic.Int32
+	)
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if dials.Add(1) <= 2 {
+				return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("connection refused")}
+			}
+
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+	t.Cleanup(transport.CloseIdleConnections)
+
+	mit := &MIT{
+		baseUrl:        server.URL,
+		cl:             &http.Client{Transport: transport},
+		maxRetries:     3,
+		retryBaseDelay: time.Millisecond,
+	}
+
+	token, err := mit.GenerateToken(context.Background(), "", core.TokenTypeWeb, 60)
+
+	require.NoError(t, err)
+	assert.Equal(t, "token", token.Token)
+	assert.Equal(t, int32(3), dials.Load())
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestGenerateToken_NoRetryOnBadRequest(t *testing.T) {
+	server, calls := failingServer(t, 1, http.StatusBadRequest, nil)
+
+	mit := &MIT{
+		baseUrl:        server.URL,
+		cl:             &http.Client{},
+		maxRetries:     3,
+		retryBaseDelay: time.Millisecond,
+	}
+
+	_, err := mit.GenerateToken(context.Background(), "bad key", core.TokenTypeWeb, 60)
+
+	assert.ErrorIs(t, err, core.ErrInvalidKeyID)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRevokeToken_Retry(t *testing.T) {
+	tests := []struct {
+		name          string
+		expectedError string
+		failStatus    int
+		failures      int32
+		maxRetries    int
+		expectedCalls int32
+	}{
+		{
+			name:          "recovers after service unavailable",
+			failStatus:    http.StatusServiceUnavailable,
+			failures:      2,
+			maxRetries:    3,
+			expectedCalls: 3,
+		},
+		{
+			name:          "recovers after gateway timeout",
+			failStatus:    http.StatusGatewayTimeout,
+			failures:      2,
+			maxRetries:    3,
+			expectedCalls: 3,
+		},
+		{
+			name:          "recovers after rate limiting",
+			failStatus:    http.StatusTooManyRequests,
+			failures:      1,
+			maxRetries:    3,
+			expectedCalls: 2,
+		},
+		{
+			name:          "gives up after max retries",
+			failStatus:    http.StatusBadGateway,
+			failures:      10,
+			maxRetries:    2,
+			expectedCalls: 3,
+			expectedError: "failed to revoke token, status code: 502",
+		},
+		{
+			name:          "retries disabled",
+			failStatus:    http.StatusServiceUnavailable,
+			failures:      1,
+			expectedCalls: 1,
+			expectedError: "failed to revoke token, status code: 503",
+		},
+		{
+			name:          "internal server error is not retried",
+			failStatus:    http.StatusInternalServerError,
+			failures:      1,
+			maxRetries:    3,
+			expectedCalls: 1,
+			expectedError: "failed to revoke token, status code: 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := failingServer(t, tt.failures, tt.failStatus, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodDelete, r.Method)
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			mit := &MIT{
+				baseUrl:        server.URL,
+				cl:             &http.Client{},
+				maxRetries:     tt.maxRetries,
+				retryBaseDelay: time.Millisecond,
+			}
+
+			err := mit.RevokeToken(context.Background(), "key")
+
+			assert.Equal(t, tt.expectedCalls, calls.Load())
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRevokeToken_RetryConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	mit := &MIT{
+		baseUrl:        server.URL,
+		cl:             &http.Client{},
+		maxRetries:     2,
+		retryBaseDelay: time.Millisecond,
+	}
+
+	err := mit.RevokeToken(context.Background(), "key")
+
+	assert.ErrorContains(t, err, "failed to send request")
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		status int
+		want   bool
+	}{
+		{name: "transport error", err: errors.New("connection refused"), want: true},
+		{name: "too many requests", status: http.StatusTooManyRequests, want: true},
+		{name: "bad gateway", status: http.StatusBadGateway, want: true},
+		{name: "service unavailable", status: http.StatusServiceUnavailable, want: true},
+		{name: "gateway timeout", status: http.StatusGatewayTimeout, want: true},
+		{name: "bad request", status: http.StatusBadRequest, want: false},
+		{name: "not found", status: http.StatusNotFound, want: false},
+		{name: "created", status: http.StatusCreated, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.status}
+			}
+
+			assert.Equal(t, tt.want, isRetryable(resp, tt.err))
+		})
+	}
+}
+
+func TestIsNotSent(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		status int
+		want   bool
+	}{
+		{name: "dial error", err: &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, want: true},
+		{name: "read error", err: &url.Error{Op: "Post", Err: &net.OpError{Op: "read", Err: errors.New("connection reset")}}, want: false},
+		{name: "other transport error", err: errors.New("EOF"), want: false},
+		{name: "service unavailable", status: http.StatusServiceUnavailable, want: false},
+		{name: "too many requests", status: http.StatusTooManyRequests, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.status}
+			}
+
+			assert.Equal(t, tt.want, isNotSent(resp, tt.err))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	mit := &MIT{retryBaseDelay: 100 * time.Millisecond}
+
+	for attempt := range 4 {
+		base := 100 * time.Millisecond << attempt
+		delay := mit.backoff(attempt)
+
+		assert.GreaterOrEqual(t, delay, base)
+		assert.LessOrEqual(t, delay, base+base/2)
+	}
+}
+
+func TestRevokeToken_RetryStopsOnContextDone(t *testing.T) {
+	server, calls := failingServer(t, 10, http.StatusServiceUnavailable, nil)
+
+	mit := &MIT{
+		baseUrl:        server.URL,
+		cl:             &http.Client{},
+		maxRetries:     5,
+		retryBaseDelay: time.Hour,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := mit.RevokeToken(ctx, "key")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestDoWithRetry_Logs(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantLog  []string
+		status   int
+		failures int32
+	}{
+		{
+			name:    "success at debug level",
+			status:  http.StatusNoContent,
+			wantLog: []string{"level=DEBUG", `msg="Provider request completed"`, "method=DELETE", "endpoint=/token/key1", "status=204", "attempt=1", "latency="},
+		},
+		{
+			name:     "failure at error level",
+			status:   http.StatusNoContent,
+			failures: 1,
+			wantLog:  []string{"level=ERROR", `msg="Provider request failed"`, "endpoint=/token/key1", "status=503", "attempt=1", "attempt=2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			prev := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+			defer slog.SetDefault(prev)
+
+			server, _ := failingServer(t, tt.failures, http.StatusServiceUnavailable, func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			})
+
+			mit := New(Config{Url: server.URL, RetryBaseDelay: time.Millisecond})
+
+			require.NoError(t, mit.RevokeToken(context.Background(), "key1"))
+
+			for _, want := range tt.wantLog {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+)
+
+// keyspaceEventsParam is the Redis setting that selects the published keyspace notifications.
+const keyspaceEventsParam = "notify-keyspace-events"
+
+// SubscribeExpiredTokens subscribes to the expired key events of the Redis database and returns a channel
+// of the API keys whose expiry markers expired. The channel is closed once ctx is done.
+// Returns core.ErrExpiryNotificationsDisabled unless expiry notifications are enabled in the config.
+//
+// Redis publishes the event when it actually deletes an expired marker, which may lag behind its TTL,
+// and the events published while the bot is not subscribed are lost.
+func (u *User) SubscribeExpiredTokens(ctx context.Context) (<-chan core.ExpiredToken, error) {
+	if !u.expiryNotifications {
+		return nil, core.ErrExpiryNotificationsDisabled
+	}
+
+	u.enableExpiredEvents(ctx)
+
+	pubsub := u.db.Subscribe(ctx, fmt.Sprintf("__keyevent@%d__:expired", u.dbIndex))
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+
+		return nil, fmt.Errorf("failed to subscribe to expired key events: %w", err)
+	}
+
+	expired := make(chan core.ExpiredToken)
+
+	go func() {
+		defer close(expired)
+		defer func() { _ = pubsub.Close() }()
+
+		events := pubsub.Channel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-events:
+				if !ok {
+					return
+				}
+
+				token, ok := u.parseExpiryKey(msg.Payload)
+				if !ok {
+					continue
+				}
+
+				select {
+				case expired <- token:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return expired, nil
+}
+
+// parseExpiryKey extracts the user and key IDs from an expiry marker key, other keys are reported as not matching.
+func (u *User) parseExpiryKey(key string) (core.ExpiredToken, bool) {
+	rest, ok := strings.CutPrefix(key, u.keyPrefix+expiryKeyPrefix)
+	if !ok {
+		return core.ExpiredToken{}, false
+	}
+
+	userID, keyID, ok := strings.Cut(rest, "::")
+	if !ok || userID == "" || keyID == "" {
+		return core.ExpiredToken{}, false
+	}
+
+	return core.ExpiredToken{UserID: userID, KeyID: keyID}, true
+}
+
+// enableExpiredEvents makes Redis publish expired key events if it doesn't yet, keeping the other configured events.
+// Managed Redis offerings often don't allow CONFIG, then the events have to be enabled on the server and
+// the failure is only logged.
+func (u *User) enableExpiredEvents(ctx context.Context) {
+	cfg, err := u.db.ConfigGet(ctx, keyspaceEventsParam).Result()
+	if err != nil {
+		slog.WarnContext(ctx, "Cannot check Redis keyspace notifications, make sure notify-keyspace-events includes \"Ex\"",
+			slog.Any("error", err),
+		)
+
+		return
+	}
+
+	events := cfg[keyspaceEventsParam]
+	if hasExpiredEvents(events) {
+		return
+	}
+
+	if err := u.db.ConfigSet(ctx, keyspaceEventsParam, events+"Ex").Err(); err != nil {
+		slog.WarnContext(ctx, "Cannot enable Redis keyspace notifications, make sure notify-keyspace-events includes \"Ex\"",
+			slog.Any("error", err),
+		)
+	}
+}
+
+// hasExpiredEvents reports whether the notify-keyspace-events flags publish expired key events: "E" selects
+// keyevent notifications and "x", or "A" for all classes, the expired events.
+func hasExpiredEvents(flags string) bool {
+	return strings.Contains(flags, "E") && strings.ContainsAny(flags, "xA")
+}
@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiryMarkers(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	marker := func(keyID string) string { return user.keyPrefix + expiryKeyPrefix + "user123::" + keyID }
+
+	require.NoError(t, user.AddAPIKey(ctx, "user123", "disabled", core.TokenTypeWeb, "", 24*time.Hour))
+	assert.False(t, mr.Exists(marker("disabled")), "markers are only stored with expiry notifications enabled")
+
+	user.expiryNotifications = true
+
+	require.NoError(t, user.AddAPIKey(ctx, "user123", "key1", core.TokenTypeWeb, "", 24*time.Hour))
+	assert.Equal(t, 24*time.Hour-ttlOffset, mr.TTL(marker("key1")))
+
+	require.NoError(t, user.AddAPIKeyWithLimit(ctx, "user123", "key2", core.TokenTypeTCP, "db", time.Hour, 1))
+	assert.Equal(t, time.Hour-ttlOffset, mr.TTL(marker("key2")))
+
+	assert.ErrorIs(t, user.AddAPIKeyWithLimit(ctx, "user123", "key3", core.TokenTypeTCP, "", time.Hour, 1), core.ErrTokenLimitReached)
+	assert.False(t, mr.Exists(marker("key3")), "rejected keys get no marker")
+
+	require.NoError(t, user.AddAPIKey(ctx, "user123", "short", core.TokenTypeWeb, "", ttlOffset))
+	assert.False(t, mr.Exists(marker("short")), "keys already treated as expired get no marker")
+
+	// Renewing a key moves its marker expiry.
+	require.NoError(t, user.AddAPIKey(ctx, "user123", "key1", core.TokenTypeWeb, "", 48*time.Hour))
+	assert.Equal(t, 48*time.Hour-ttlOffset, mr.TTL(marker("key1")))
+
+	require.NoError(t, user.RevokeToken(ctx, "user123", "key1"))
+	assert.False(t, mr.Exists(marker("key1")))
+	assert.True(t, mr.Exists(marker("key2")))
+}
+
+func TestSubscribeExpiredTokens(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := user.SubscribeExpiredTokens(ctx)
+	assert.ErrorIs(t, err, core.ErrExpiryNotificationsDisabled)
+
+	user.expiryNotifications = true
+
+	expired, err := user.SubscribeExpiredTokens(ctx)
+	require.NoError(t, err)
+
+	for _, key := range []string{
+		user.keyPrefix + convKeyPrefix + "user123",
+		"other:" + expiryKeyPrefix + "user123::key0",
+		user.keyPrefix + expiryKeyPrefix + "user123::key1",
+	} {
+		mr.Publish("__keyevent@0__:expired", key)
+	}
+
+	select {
+	case token := <-expired:
+		assert.Equal(t, core.ExpiredToken{UserID: "user123", KeyID: "key1"}, token)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expired token was not delivered")
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-expired:
+		assert.False(t, ok, "channel must be closed once the context is done")
+	case <-time.After(2 * time.Second):
+		t.Fatal("channel was not closed")
+	}
+}
+
+func TestSubscribeExpiredTokens_Unreachable(t *testing.T) {
+	mr, user := setupRedis(t)
+	user.expiryNotifications = true
+
+	mr.Close()
+
+	_, err := user.SubscribeExpiredTokens(context.Background())
+	assert.ErrorContains(t, err, "failed to subscribe to expired key events")
+}
+
+func TestHasExpiredEvents(t *testing.T) {
+	tests := []struct {
+		flags string
+		want  bool
+	}{
+		{flags: "", want: false},
+		{flags: "Ex", want: true},
+		{flags: "xE", want: true},
+		{flags: "AE", want: true},
+		{flags: "Kx", want: false},
+		{flags: "Eg", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flags, func(t *testing.T) {
+			assert.Equal(t, tt.want, hasExpiredEvents(tt.flags))
+		})
+	}
+}
@@ -2,21 +2,45 @@ package repo
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
 	"github.com/redis/go-redis/v9"
 )
 
 const (
-	ttlOffset     = 60 * time.Second
-	apiKeyPrefix  = "USER_KEYS::"
-	convKeyPrefix = "CONV::"
-	convTTL       = 24 * time.Hour // Default TTL for conversations
+	ttlOffset          = 60 * time.Second
+	pingTimeout        = 5 * time.Second
+	apiKeyPrefix       = "USER_KEYS::"
+	keyNamePrefix      = "KEY_NAMES::"
+	convKeyPrefix      = "CONV::"
+	convFlowPrefix     = "CONV_FLOW::"
+	creationsKeyPrefix = "TOKEN_CREATIONS::"
+	regenKeyPrefix     = "LAST_REGENERATION::"
+	expiryKeyPrefix    = "TOKEN_EXPIRY::"
+	userChatsKey       = "USER_CHATS"
+	inactiveChatsKey   = "INACTIVE_CHATS"
+	settingsKeyPrefix  = "SETTINGS::"
+	lockKeyPrefix      = "LOCK::"
+	idempotencyPrefix  = "IDEMPOTENCY::"
+	auditKeyPrefix     = "AUDIT::"
+	feedbackKey        = "FEEDBACK"
+	auditLogSize       = 100              // Number of audit entries kept per user
+	statsScanCount     = 100              // SCAN batch size hint when counting tokens
+	feedbackLogSize    = 1000             // Number of feedback messages kept
+	convTTL            = 15 * time.Minute // Default TTL for conversations
+	convFlowTTL        = 24 * time.Hour   // How long the flow of an expired conversation is remembered
+
+	// idempotencyDone is the marker of a completed request, a pending request has an empty marker.
+	idempotencyDone = "done"
 
 	// memberPrefixWeb is the sorted-set member prefix for web tokens.
 	memberPrefixWeb = "w:"
@@ -50,39 +74,198 @@ func decodeKeyMember(member string) (keyID string, tokenType core.TokenType) {
 }
 
 type Config struct {
-	RedisAddr string `mapstructure:"redis_addr"`
-	Password  string `mapstructure:"redis_password"`
-	KeyPrefix string `mapstructure:"key_prefix"`
+	RedisAddr       string        `mapstructure:"redis_addr"`
+	Password        string        `mapstructure:"redis_password"`
+	KeyPrefix       string        `mapstructure:"key_prefix"`
+	ConversationTTL time.Duration `mapstructure:"conversation_ttl"`
+	// UseTLS connects to Redis over TLS, as required by most managed Redis offerings.
+	UseTLS bool `mapstructure:"use_tls"`
+	// TLSSkipVerify disables server certificate verification, meant for self-signed certificates in development only.
+	TLSSkipVerify bool `mapstructure:"tls_skip_verify"`
+	// DB selects the Redis database number, 0 by default.
+	DB int `mapstructure:"db"`
+	// PoolSize, DialTimeout and ReadTimeout fall back to the go-redis defaults when zero.
+	PoolSize    int           `mapstructure:"pool_size"`
+	DialTimeout time.Duration `mapstructure:"dial_timeout"`
+	ReadTimeout time.Duration `mapstructure:"read_timeout"`
+	// ExpiryNotifications stores a marker key per API key that expires together with it, so that
+	// SubscribeExpiredTokens learns about expired keys from Redis keyspace notifications.
+	ExpiryNotifications bool `mapstructure:"expiry_notifications"`
+}
+
+// Validate checks that the Redis address is set and that the TTL and connection settings are not negative.
+// It returns every problem found joined into a single error.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.RedisAddr == "" {
+		errs = append(errs, errors.New("redis_addr is required"))
+	}
+
+	if c.ConversationTTL < 0 {
+		errs = append(errs, fmt.Errorf("conversation_ttl must not be negative, got %s", c.ConversationTTL))
+	}
+
+	if c.DB < 0 {
+		errs = append(errs, fmt.Errorf("db must not be negative, got %d", c.DB))
+	}
+
+	if c.PoolSize < 0 {
+		errs = append(errs, fmt.Errorf("pool_size must not be negative, got %d", c.PoolSize))
+	}
+
+	return errors.Join(errs...)
 }
 
 type User struct {
-	db        *redis.Client
-	keyPrefix string
+	db                  *redis.Client
+	keyPrefix           string
+	convTTL             time.Duration
+	dbIndex             int
+	expiryNotifications bool
 }
 
 // New initializes and returns a new User instance configured with the provided Config.
 func New(cfg Config) *User {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     cfg.RedisAddr,
-		Password: cfg.Password,
-	})
+	rdb := redis.NewClient(redisOptions(cfg))
+
+	ttl := cfg.ConversationTTL
+	if ttl <= 0 {
+		ttl = convTTL
+	}
 
 	return &User{
-		db:        rdb,
-		keyPrefix: cfg.KeyPrefix,
+		db:                  rdb,
+		keyPrefix:           cfg.KeyPrefix,
+		convTTL:             ttl,
+		dbIndex:             cfg.DB,
+		expiryNotifications: cfg.ExpiryNotifications,
 	}
 }
 
+// Connect creates a User like New and verifies that Redis is reachable by sending a PING,
+// bounded by pingTimeout. The client is closed if the check fails.
+func Connect(ctx context.Context, cfg Config) (*User, error) {
+	u := New(cfg)
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	if err := u.db.Ping(ctx).Err(); err != nil {
+		_ = u.db.Close()
+
+		return nil, fmt.Errorf("cannot connect to Redis at %s: %w", cfg.RedisAddr, err)
+	}
+
+	return u, nil
+}
+
+// redisOptions maps cfg to the go-redis client options. Zero connection settings are left
+// to the go-redis defaults, and a TLS config is only set when UseTLS is enabled.
+func redisOptions(cfg Config) *redis.Options {
+	opts := &redis.Options{
+		Addr:        cfg.RedisAddr,
+		Password:    cfg.Password,
+		DB:          cfg.DB,
+		PoolSize:    cfg.PoolSize,
+		DialTimeout: cfg.DialTimeout,
+		ReadTimeout: cfg.ReadTimeout,
+	}
+
+	if cfg.UseTLS {
+		opts.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			//nolint:gosec // opt-in for self-signed certificates in development
+			InsecureSkipVerify: cfg.TLSSkipVerify,
+		}
+	}
+
+	return opts
+}
+
 // Close terminates the connection to the Redis database and returns an error if the operation fails.
 func (u *User) Close() error {
 	return u.db.Close()
 }
 
-// AddAPIKey adds an API key with a token type and expiration time to the user's Redis store.
-// The key is stored as a prefixed member ("w:<keyID>" or "t:<keyID>") in a sorted set.
+// tokenKey returns the key of the sorted set holding the user's API keys.
+// Each kind of data lives in its own sub-namespace of keyPrefix, so a scan can target exactly one of them.
+func (u *User) tokenKey(userID string) string {
+	return u.keyPrefix + apiKeyPrefix + userID
+}
+
+// keyNamesKey returns the key of the hash holding the names of the user's API keys.
+func (u *User) keyNamesKey(userID string) string {
+	return u.keyPrefix + keyNamePrefix + userID
+}
+
+// convKey returns the key of the conversation with the given ID.
+func (u *User) convKey(id string) string {
+	return u.keyPrefix + convKeyPrefix + id
+}
+
+// convFlowKey returns the key remembering the flow of the conversation with the given ID while it waits for an answer.
+// It outlives the conversation by convFlowTTL, so that an answer arriving after the conversation expired can be recognized.
+func (u *User) convFlowKey(id string) string {
+	return u.keyPrefix + convFlowPrefix + id
+}
+
+// creationsKey returns the key of the sorted set tracking the user's recent token creations.
+func (u *User) creationsKey(userID string) string {
+	return u.keyPrefix + creationsKeyPrefix + userID
+}
+
+// regenKey returns the key holding the time of the user's last token regeneration.
+func (u *User) regenKey(userID string) string {
+	return u.keyPrefix + regenKeyPrefix + userID
+}
+
+// expiryKey returns the Redis key of the marker announcing the expiry of the user's API key.
+func (u *User) expiryKey(userID, apiKeyID string) string {
+	return u.keyPrefix + expiryKeyPrefix + userID + "::" + apiKeyID
+}
+
+// chatsKey returns the key of the hash mapping user IDs to their chat IDs.
+func (u *User) chatsKey() string {
+	return u.keyPrefix + userChatsKey
+}
+
+// inactiveKey returns the key of the set of users whose chat can't be reached anymore.
+func (u *User) inactiveKey() string {
+	return u.keyPrefix + inactiveChatsKey
+}
+
+// settingsKey returns the key of the hash holding the user's settings.
+func (u *User) settingsKey(userID string) string {
+	return u.keyPrefix + settingsKeyPrefix + userID
+}
+
+// lockKey returns the key of the user's advisory lock.
+func (u *User) lockKey(userID string) string {
+	return u.keyPrefix + lockKeyPrefix + userID
+}
+
+// idempotencyKey returns the key of the marker of the user's request with the given idempotency key.
+func (u *User) idempotencyKey(userID, key string) string {
+	return u.keyPrefix + idempotencyPrefix + userID + "::" + key
+}
+
+// auditKey returns the key of the list holding the user's audit entries.
+func (u *User) auditKey(userID string) string {
+	return u.keyPrefix + auditKeyPrefix + userID
+}
+
+// feedbacksKey returns the key of the list holding the latest user feedback.
+func (u *User) feedbacksKey() string {
+	return u.keyPrefix + feedbackKey
+}
+
+// AddAPIKey adds an API key with a token type, optional name and expiration time to the user's Redis store.
+// The key is stored as a prefixed member ("w:<keyID>" or "t:<keyID>") in a sorted set, and a non-empty
+// name is stored in a separate hash keyed by key ID so the sorted-set member format stays unchanged.
 // Returns an error if the operation fails.
-func (u *User) AddAPIKey(ctx context.Context, userID string, apiKeyID string, tokenType core.TokenType, expiresIn time.Duration) error {
-	redisKey := u.keyPrefix + apiKeyPrefix + userID
+func (u *User) AddAPIKey(ctx context.Context, userID string, apiKeyID string, tokenType core.TokenType, name string, expiresIn time.Duration) error {
+	redisKey := u.tokenKey(userID)
 	member := encodeKeyMember(apiKeyID, tokenType)
 
 	_, err := u.db.ZAdd(ctx, redisKey, redis.Z{
@@ -95,33 +278,168 @@ func (u *User) AddAPIKey(ctx context.Context, userID string, apiKeyID string, to
 	}
 
 	// If the result is 0, the member already exists — not an error.
+
+	if err := u.setExpiryMarker(ctx, userID, apiKeyID, expiresIn); err != nil {
+		return err
+	}
+
+	namesKey := u.keyNamesKey(userID)
+
+	if name == "" {
+		if err := u.db.HDel(ctx, namesKey, apiKeyID).Err(); err != nil {
+			return fmt.Errorf("failed to clear API key name: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := u.db.HSet(ctx, namesKey, apiKeyID, name).Err(); err != nil {
+		return fmt.Errorf("failed to save API key name: %w", err)
+	}
+
+	return nil
+}
+
+// dropExpiredKeysLua defines dropExpiredKeys(keys, names, now), which removes the keys expired by now from the
+// API keys sorted set together with their names in the key names hash, so that names don't outlive their keys.
+// Member prefixes are stripped like in decodeKeyMember to get the key IDs the names are stored under.
+const dropExpiredKeysLua = `
+local function dropExpiredKeys(keys, names, now)
+	for _, m in ipairs(redis.call('ZRANGEBYSCORE', keys, '-inf', now)) do
+		local p = string.sub(m, 1, 2)
+		if p == 'w:' or p == 't:' then
+			m = string.sub(m, 3)
+		end
+
+		redis.call('HDEL', names, m)
+	end
+
+	redis.call('ZREMRANGEBYSCORE', keys, '-inf', now)
+end
+`
+
+// dropExpiredKeysScript runs dropExpiredKeys. It's evaluated inside the pipelines that read the keys.
+//
+// KEYS[1] - API keys sorted set, KEYS[2] - key names hash.
+// ARGV: now.
+var dropExpiredKeysScript = redis.NewScript(dropExpiredKeysLua + `
+dropExpiredKeys(KEYS[1], KEYS[2], ARGV[1])
+return 0
+`)
+
+// addAPIKeyWithLimitScript atomically drops expired keys with their names, counts the active keys of the requested type and adds
+// the new one unless the count already reached the limit. Re-adding an existing member is always allowed.
+// Bare legacy members count as web keys, matching decodeKeyMember.
+//
+// KEYS[1] - API keys sorted set, KEYS[2] - key names hash.
+// ARGV: now, member, score, limit, member type prefix, count legacy members ("1"/"0"), key ID, name.
+// Returns 1 if the key was stored and 0 if the limit was reached.
+var addAPIKeyWithLimitScript = redis.NewScript(dropExpiredKeysLua + `
+dropExpiredKeys(KEYS[1], KEYS[2], ARGV[1])
+
+if not redis.call('ZSCORE', KEYS[1], ARGV[2]) then
+	local count = 0
+	for _, m in ipairs(redis.call('ZRANGE', KEYS[1], 0, -1)) do
+		local p = string.sub(m, 1, 2)
+		if p == ARGV[5] or (ARGV[6] == '1' and p ~= 'w:' and p ~= 't:') then
+			count = count + 1
+		end
+	end
+
+	if count >= tonumber(ARGV[4]) then
+		return 0
+	end
+end
+
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[2])
+
+if ARGV[8] == '' then
+	redis.call('HDEL', KEYS[2], ARGV[7])
+else
+	redis.call('HSET', KEYS[2], ARGV[7], ARGV[8])
+end
+
+return 1
+`)
+
+// AddAPIKeyWithLimit stores an API key like AddAPIKey, but only if the user has fewer than limit active keys
+// of the given type. The check and the write run as a single Lua script, so concurrent requests can't
+// both pass the check. Returns core.ErrTokenLimitReached if the key was rejected.
+func (u *User) AddAPIKeyWithLimit(ctx context.Context, userID string, apiKeyID string, tokenType core.TokenType, name string, expiresIn time.Duration, limit int) error {
+	member := encodeKeyMember(apiKeyID, tokenType)
+
+	countLegacy := "0"
+	if tokenType != core.TokenTypeTCP {
+		countLegacy = "1"
+	}
+
+	added, err := addAPIKeyWithLimitScript.Run(ctx, u.db,
+		[]string{u.tokenKey(userID), u.keyNamesKey(userID)},
+		time.Now().Unix(),
+		member,
+		time.Now().Add(expiresIn-ttlOffset).Unix(),
+		limit,
+		member[:len(memberPrefixWeb)],
+		countLegacy,
+		apiKeyID,
+		name,
+	).Int()
+	if err != nil {
+		return fmt.Errorf("failed to add API key: %w", err)
+	}
+
+	if added == 0 {
+		return core.ErrTokenLimitReached
+	}
+
+	return u.setExpiryMarker(ctx, userID, apiKeyID, expiresIn)
+}
+
+// setExpiryMarker stores a marker key that expires when the API key does, with the same ttlOffset as its score.
+// It does nothing unless expiry notifications are enabled.
+func (u *User) setExpiryMarker(ctx context.Context, userID, apiKeyID string, expiresIn time.Duration) error {
+	ttl := expiresIn - ttlOffset
+	if !u.expiryNotifications || ttl <= 0 {
+		return nil
+	}
+
+	if err := u.db.Set(ctx, u.expiryKey(userID, apiKeyID), "", ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set API key expiry marker: %w", err)
+	}
+
 	return nil
 }
 
 // GetAPIKeys retrieves all non-expired API key IDs for a user from the Redis store.
+// Expired keys are removed with their names and the rest are read in a single round trip.
 // Prefixes are stripped; bare legacy members are returned as-is (backward compat).
 // Returns a slice of bare key IDs and an error if the operation fails.
 func (u *User) GetAPIKeys(ctx context.Context, userID string) ([]string, error) {
-	redisKey := u.keyPrefix + apiKeyPrefix + userID
+	redisKey := u.tokenKey(userID)
+	now := fmt.Sprintf("%d", time.Now().Unix())
 
-	// Clean up expired keys.
-	now := time.Now().Unix()
-	_, err := u.db.ZRemRangeByScore(ctx, redisKey, "-inf", fmt.Sprintf("%d", now)).Result()
-	if err != nil {
-		return nil, fmt.Errorf("failed to remove expired API keys: %w", err)
-	}
+	var get *redis.StringSliceCmd
 
-	// Get keys with scores greater than current time (not expired).
-	members, err := u.db.ZRangeArgs(ctx, redis.ZRangeArgs{
-		Key:     redisKey,
-		ByScore: true,
-		Start:   fmt.Sprintf("%d", now),
-		Stop:    "+inf",
-	}).Result()
+	_, err := u.db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// Clean up expired keys and their names.
+		dropExpiredKeysScript.Eval(ctx, pipe, []string{redisKey, u.keyNamesKey(userID)}, now)
+
+		// Get keys with scores greater than current time (not expired).
+		get = pipe.ZRangeArgs(ctx, redis.ZRangeArgs{
+			Key:     redisKey,
+			ByScore: true,
+			Start:   now,
+			Stop:    "+inf",
+		})
+
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get API keys: %w", err)
 	}
 
+	members := get.Val()
+
 	keys := make([]string, len(members))
 	for i, m := range members {
 		keyID, _ := decodeKeyMember(m)
@@ -132,27 +450,37 @@ func (u *User) GetAPIKeys(ctx context.Context, userID string) ([]string, error)
 }
 
 // GetAPIKeysWithExpiration retrieves all active API keys for a user along with their expiration times
-// and token types. Returns a slice of KeyInfo or an error if the operation fails.
+// and token types. Expired keys are removed with their names and the rest are read with the remaining names
+// in a single round trip.
+// Returns a slice of KeyInfo or an error if the operation fails.
 func (u *User) GetAPIKeysWithExpiration(ctx context.Context, userID string) ([]core.KeyInfo, error) {
-	redisKey := u.keyPrefix + apiKeyPrefix + userID
-
-	now := time.Now().Unix()
-
-	// Clean up expired keys.
-	_, err := u.db.ZRemRangeByScore(ctx, redisKey, "-inf", fmt.Sprintf("%d", now)).Result()
-	if err != nil {
-		return nil, fmt.Errorf("failed to remove expired API keys: %w", err)
-	}
-
-	// Get keys with scores greater than current time, including scores.
-	zSlice, err := u.db.ZRangeByScoreWithScores(ctx, redisKey, &redis.ZRangeBy{
-		Min: fmt.Sprintf("%d", now),
-		Max: "+inf",
-	}).Result()
+	redisKey := u.tokenKey(userID)
+	now := fmt.Sprintf("%d", time.Now().Unix())
+
+	var (
+		get      *redis.ZSliceCmd
+		getNames *redis.MapStringStringCmd
+	)
+
+	_, err := u.db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// Clean up expired keys and their names.
+		dropExpiredKeysScript.Eval(ctx, pipe, []string{redisKey, u.keyNamesKey(userID)}, now)
+
+		// Get keys with scores greater than current time, including scores.
+		get = pipe.ZRangeByScoreWithScores(ctx, redisKey, &redis.ZRangeBy{
+			Min: now,
+			Max: "+inf",
+		})
+		getNames = pipe.HGetAll(ctx, u.keyNamesKey(userID))
+
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get API keys with scores: %w", err)
 	}
 
+	zSlice, names := get.Val(), getNames.Val()
+
 	keys := make([]core.KeyInfo, len(zSlice))
 	for i, z := range zSlice {
 		// Score is (expiresAt - ttlOffset), so restore the original expiration.
@@ -160,6 +488,7 @@ func (u *User) GetAPIKeysWithExpiration(ctx context.Context, userID string) ([]c
 		keyID, tokenType := decodeKeyMember(z.Member.(string))
 		keys[i] = core.KeyInfo{
 			KeyID:     keyID,
+			Name:      names[keyID],
 			ExpiresAt: expiresAt,
 			Type:      tokenType,
 		}
@@ -168,44 +497,78 @@ func (u *User) GetAPIKeysWithExpiration(ctx context.Context, userID string) ([]c
 	return keys, nil
 }
 
-// RevokeToken removes the specified API key for a user from the Redis store.
+// RevokeToken removes the specified API key and its name for a user from the Redis store.
 // It handles both prefixed members (new format) and bare members (legacy format).
 // Returns an error if the operation fails.
 func (u *User) RevokeToken(ctx context.Context, userID string, apiKeyID string) error {
-	redisKey := u.keyPrefix + apiKeyPrefix + userID
+	_, err := u.RevokeTokens(ctx, userID, []string{apiKeyID})
+
+	return err
+}
 
-	// Try all possible encodings: prefixed web, prefixed TCP, and bare (legacy).
-	candidates := []string{
-		encodeKeyMember(apiKeyID, core.TokenTypeWeb),
-		encodeKeyMember(apiKeyID, core.TokenTypeTCP),
-		apiKeyID, // legacy bare member
+// RevokeTokens removes the specified API keys with their names and expiry markers for a user from the Redis store in a single round trip
+// and returns the number of removed keys. Every key is removed in all possible encodings: prefixed web, prefixed TCP
+// and bare (legacy). Keys that are not found are skipped, they may have already expired or been removed.
+func (u *User) RevokeTokens(ctx context.Context, userID string, apiKeyIDs []string) (int, error) {
+	if len(apiKeyIDs) == 0 {
+		return 0, nil
 	}
 
-	for _, candidate := range candidates {
-		removed, err := u.db.ZRem(ctx, redisKey, candidate).Result()
-		if err != nil {
-			return fmt.Errorf("failed to revoke API key: %w", err)
-		}
+	names := make([]string, 0, len(apiKeyIDs))
+	markers := make([]string, 0, len(apiKeyIDs))
+	members := make([]any, 0, 3*len(apiKeyIDs))
+
+	for _, apiKeyID := range apiKeyIDs {
+		names = append(names, apiKeyID)
+		markers = append(markers, u.expiryKey(userID, apiKeyID))
+		members = append(members,
+			encodeKeyMember(apiKeyID, core.TokenTypeWeb),
+			encodeKeyMember(apiKeyID, core.TokenTypeTCP),
+			apiKeyID, // legacy bare member
+		)
+	}
 
-		if removed > 0 {
-			return nil
-		}
+	var removed *redis.IntCmd
+
+	_, err := u.db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, u.keyNamesKey(userID), names...)
+		// Markers are removed even with expiry notifications disabled, so that re-enabling them doesn't
+		// announce the expiry of revoked keys.
+		pipe.Del(ctx, markers...)
+		removed = pipe.ZRem(ctx, u.tokenKey(userID), members...)
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke API keys: %w", err)
 	}
 
-	// Member not found — not an error; it may have already expired or been removed.
-	return nil
+	return int(removed.Val()), nil
 }
 
-// SaveConversation stores a conversation object in the Redis database. Returns an error if the operation fails.
+// SaveConversation stores a conversation object in the Redis database with the configured TTL,
+// so abandoned flows expire on their own. While the conversation waits for an answer its flow is also
+// remembered for convFlowTTL longer, see GetExpiredFlow. Returns an error if the operation fails.
 func (u *User) SaveConversation(ctx context.Context, conversation *conv.Conversation) error {
-	redisKey := u.keyPrefix + convKeyPrefix + conversation.ID
+	redisKey := u.convKey(conversation.ID)
+	flowKey := u.convFlowKey(conversation.ID)
 
 	data, err := json.Marshal(conversation)
 	if err != nil {
 		return fmt.Errorf("failed to encode conversation: %w", err)
 	}
 
-	_, err = u.db.Set(ctx, redisKey, data, convTTL).Result()
+	_, err = u.db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, redisKey, data, u.convTTL)
+
+		if conversation.IsActive() {
+			pipe.Set(ctx, flowKey, string(conversation.Flow), u.convTTL+convFlowTTL)
+		} else {
+			pipe.Del(ctx, flowKey)
+		}
+
+		return nil
+	})
 
 	if err != nil {
 		return fmt.Errorf("failed to save conversation: %w", err)
@@ -214,15 +577,18 @@ func (u *User) SaveConversation(ctx context.Context, conversation *conv.Conversa
 	return nil
 }
 
-// GetConversation retrieves a conversation by its ID from the Redis store. Returns the conversation or an error if it fails.
+// GetConversation retrieves a conversation by its ID from the Redis store.
+// A missing key yields a new idle conversation; decode and Redis failures are returned as errors.
 func (u *User) GetConversation(ctx context.Context, conversationID string) (*conv.Conversation, error) {
-	redisKey := u.keyPrefix + convKeyPrefix + conversationID
+	redisKey := u.convKey(conversationID)
 
 	data, err := u.db.Get(ctx, redisKey).Result()
 	if err != nil {
-		if err == redis.Nil {
+		if errors.Is(err, redis.Nil) {
+			// No stored flow (never started or expired): hand back a fresh idle conversation.
 			return conv.New(conversationID), nil
 		}
+
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
 
@@ -234,14 +600,422 @@ func (u *User) GetConversation(ctx context.Context, conversationID string) (*con
 	return &conversation, nil
 }
 
-// DeleteConversation removes a conversation from the Redis store by its ID.
-func (u *User) DeleteConversation(ctx context.Context, conversationID string) error {
-	redisKey := u.keyPrefix + convKeyPrefix + conversationID
+// GetExpiredFlow returns the flow of a conversation that expired while waiting for an answer,
+// or an empty state if the conversation is still stored, was finished or deleted, or expired too long ago.
+func (u *User) GetExpiredFlow(ctx context.Context, conversationID string) (conv.State, error) {
+	var (
+		exists *redis.IntCmd
+		flow   *redis.StringCmd
+	)
+
+	_, err := u.db.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		exists = pipe.Exists(ctx, u.convKey(conversationID))
+		flow = pipe.Get(ctx, u.convFlowKey(conversationID))
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("failed to get expired conversation flow: %w", err)
+	}
+
+	if exists.Val() > 0 || errors.Is(flow.Err(), redis.Nil) {
+		return "", nil
+	}
 
-	res := u.db.Del(ctx, redisKey)
+	return conv.State(flow.Val()), nil
+}
+
+// DeleteConversation removes a conversation from the Redis store by its ID, together with its remembered flow.
+func (u *User) DeleteConversation(ctx context.Context, conversationID string) error {
+	res := u.db.Del(ctx, u.convKey(conversationID), u.convFlowKey(conversationID))
 	if res.Err() != nil {
 		return fmt.Errorf("failed to delete conversation: %w", res.Err())
 	}
 
 	return nil
 }
+
+// recordCreationScript drops the creations that left the window and records a new one.
+//
+// KEYS[1] - token creations sorted set.
+// ARGV: now, member, score (when the creation leaves the window), window in milliseconds, all Unix milliseconds.
+// Returns the number of creations within the window, including the new one.
+var recordCreationScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+
+return redis.call('ZCARD', KEYS[1])
+`)
+
+// IncrementTokenCreationCount records a token creation and returns the number of creations within the last window.
+// Creations are kept in a sorted set scored by the time they leave the window, so the window rolls with every
+// creation instead of restarting once it ends.
+func (u *User) IncrementTokenCreationCount(ctx context.Context, userID string, window time.Duration) (int, error) {
+	now := time.Now()
+
+	count, err := recordCreationScript.Run(ctx, u.db,
+		[]string{u.creationsKey(userID)},
+		now.UnixMilli(),
+		uuid.NewString(),
+		now.Add(window).UnixMilli(),
+		window.Milliseconds(),
+	).Int()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment token creation count: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetTokenCreationCount returns the number of tokens the user created within the window and when the oldest
+// of them leaves it, freeing up room for another creation.
+// A user without recent creations gets a zero count and a zero reset time.
+func (u *User) GetTokenCreationCount(ctx context.Context, userID string) (int, time.Time, error) {
+	redisKey := u.creationsKey(userID)
+
+	var creations *redis.ZSliceCmd
+
+	_, err := u.db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, redisKey, "-inf", strconv.FormatInt(time.Now().UnixMilli(), 10))
+		creations = pipe.ZRangeWithScores(ctx, redisKey, 0, -1)
+
+		return nil
+	})
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to get token creation count: %w", err)
+	}
+
+	items := creations.Val()
+	if len(items) == 0 {
+		return 0, time.Time{}, nil
+	}
+
+	return len(items), time.UnixMilli(int64(items[0].Score)), nil
+}
+
+// SetLastRegeneration stores the time of the user's last token regeneration as Unix milliseconds.
+// It expires after ttl, once it can no longer hold back the next regeneration.
+func (u *User) SetLastRegeneration(ctx context.Context, userID string, at time.Time, ttl time.Duration) error {
+	if err := u.db.Set(ctx, u.regenKey(userID), at.UnixMilli(), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set last regeneration: %w", err)
+	}
+
+	return nil
+}
+
+// GetLastRegeneration returns the time of the user's last token regeneration,
+// or a zero time if the user hasn't regenerated a token recently.
+func (u *User) GetLastRegeneration(ctx context.Context, userID string) (time.Time, error) {
+	ms, err := u.db.Get(ctx, u.regenKey(userID)).Int64()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return time.Time{}, nil
+	case err != nil:
+		return time.Time{}, fmt.Errorf("failed to get last regeneration: %w", err)
+	}
+
+	return time.UnixMilli(ms), nil
+}
+
+// SaveUserChat stores the chat ID used to reach the user, replacing any previously stored one.
+// All mappings live in a single hash so that they can be iterated for proactive notifications.
+// A message from the user means the chat is reachable again, so an inactive chat is reactivated.
+func (u *User) SaveUserChat(ctx context.Context, userID string, chatID int64) error {
+	_, err := u.db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, u.chatsKey(), userID, chatID)
+		pipe.SRem(ctx, u.inactiveKey(), userID)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save user chat: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserChat returns the chat ID stored for the user or core.ErrUserChatNotFound if the user has no stored chat.
+// Returns core.ErrUserChatInactive if the chat was deactivated and the user hasn't written since.
+func (u *User) GetUserChat(ctx context.Context, userID string) (int64, error) {
+	var (
+		get      *redis.StringCmd
+		inactive *redis.BoolCmd
+	)
+
+	_, err := u.db.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.HGet(ctx, u.chatsKey(), userID)
+		inactive = pipe.SIsMember(ctx, u.inactiveKey(), userID)
+
+		return nil
+	})
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return 0, core.ErrUserChatNotFound
+	case err != nil:
+		return 0, fmt.Errorf("failed to get user chat: %w", err)
+	case inactive.Val():
+		return 0, core.ErrUserChatInactive
+	}
+
+	chatID, err := get.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode user chat: %w", err)
+	}
+
+	return chatID, nil
+}
+
+// DeactivateUserChat marks the chat of the user as unreachable, e.g. after the user blocked the bot,
+// until the next SaveUserChat for the user.
+func (u *User) DeactivateUserChat(ctx context.Context, userID string) error {
+	if err := u.db.SAdd(ctx, u.inactiveKey(), userID).Err(); err != nil {
+		return fmt.Errorf("failed to deactivate user chat: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserSetting stores a setting of the user, replacing any previously stored value.
+// Settings of a user live in a single hash, separate from token and conversation keys.
+func (u *User) SetUserSetting(ctx context.Context, userID, key, value string) error {
+	if err := u.db.HSet(ctx, u.settingsKey(userID), key, value).Err(); err != nil {
+		return fmt.Errorf("failed to set user setting: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserSetting returns a setting of the user or an empty string if the user hasn't set it.
+func (u *User) GetUserSetting(ctx context.Context, userID, key string) (string, error) {
+	value, err := u.db.HGet(ctx, u.settingsKey(userID), key).Result()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return "", nil
+	case err != nil:
+		return "", fmt.Errorf("failed to get user setting: %w", err)
+	}
+
+	return value, nil
+}
+
+// DeleteUserSetting removes a setting of the user, a setting that isn't set is ignored.
+func (u *User) DeleteUserSetting(ctx context.Context, userID, key string) error {
+	if err := u.db.HDel(ctx, u.settingsKey(userID), key).Err(); err != nil {
+		return fmt.Errorf("failed to delete user setting: %w", err)
+	}
+
+	return nil
+}
+
+// releaseLockScript deletes a lock only while it is held by the given owner, so that a lock which expired
+// and was taken by another request is left alone.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireUserLock takes the user's advisory lock for ttl with SET NX PX, so that only one bot instance at a time
+// manages the user's tokens. It returns core.ErrUserBusy if the lock is already held.
+// The returned function releases the lock, it does nothing if the lock has expired in the meantime.
+func (u *User) AcquireUserLock(ctx context.Context, userID string, ttl time.Duration) (core.ReleaseFunc, error) {
+	redisKey := u.lockKey(userID)
+	owner := uuid.NewString()
+
+	ok, err := u.db.SetNX(ctx, redisKey, owner, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire user lock: %w", err)
+	}
+
+	if !ok {
+		return nil, core.ErrUserBusy
+	}
+
+	return func(ctx context.Context) error {
+		if err := releaseLockScript.Run(ctx, u.db, []string{redisKey}, owner).Err(); err != nil {
+			return fmt.Errorf("failed to release user lock: %w", err)
+		}
+
+		return nil
+	}, nil
+}
+
+// claimIdempotencyScript sets an empty pending marker unless one is stored already, in which case the stored
+// marker is returned, so that checking and claiming an idempotency key is a single atomic step.
+var claimIdempotencyScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], "", "NX", "PX", ARGV[1]) then
+	return false
+end
+return redis.call("GET", KEYS[1])
+`)
+
+// ClaimIdempotencyKey atomically marks the user's request with the given idempotency key as in progress for ttl.
+// It returns false if the key was claimed, true if the request has been completed before and core.ErrUserBusy
+// if it is still in progress.
+func (u *User) ClaimIdempotencyKey(ctx context.Context, userID, key string, ttl time.Duration) (bool, error) {
+	marker, err := claimIdempotencyScript.Run(ctx, u.db, []string{u.idempotencyKey(userID, key)}, ttl.Milliseconds()).Text()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	case marker == "":
+		return false, core.ErrUserBusy
+	}
+
+	return true, nil
+}
+
+// MarkIdempotencyKeyDone marks the request claimed with ClaimIdempotencyKey as completed, keeping the claim's TTL.
+// Only the marker is stored, not the response of the request.
+// Nothing is stored if the claim has expired or been released in the meantime.
+func (u *User) MarkIdempotencyKeyDone(ctx context.Context, userID, key string) error {
+	err := u.db.SetArgs(ctx, u.idempotencyKey(userID, key), idempotencyDone, redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to mark idempotency key as done: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey removes the claim of the user's request, so that the request can be performed again.
+func (u *User) ReleaseIdempotencyKey(ctx context.Context, userID, key string) error {
+	if err := u.db.Del(ctx, u.idempotencyKey(userID, key)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// AppendAuditLog prepends the entry to the user's audit list and trims the list to the latest auditLogSize entries.
+func (u *User) AppendAuditLog(ctx context.Context, entry core.AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	redisKey := u.auditKey(entry.UserID)
+
+	_, err = u.db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, redisKey, data)
+		pipe.LTrim(ctx, redisKey, 0, auditLogSize-1)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditLog returns up to limit latest audit entries of the user, newest first.
+func (u *User) GetAuditLog(ctx context.Context, userID string, limit int) ([]core.AuditEntry, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	items, err := u.db.LRange(ctx, u.auditKey(userID), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+
+	entries := make([]core.AuditEntry, len(items))
+
+	for i, item := range items {
+		if err := json.Unmarshal([]byte(item), &entries[i]); err != nil {
+			return nil, fmt.Errorf("failed to decode audit entry: %w", err)
+		}
+	}
+
+	return entries, nil
+}
+
+// GetTokenStats counts the active tokens of all users. It walks the API key sorted sets with SCAN, so the
+// keyspace is never loaded at once, and reads only the non-expired members of each set, which are bounded
+// by the per-user token limits. Only keys of the form USER_KEYS::<userID> are counted, so other keys that
+// happen to share the prefix, such as conversation keys, can't skew the numbers.
+func (u *User) GetTokenStats(ctx context.Context) (core.TokenStats, error) {
+	var stats core.TokenStats
+
+	prefix := u.tokenKey("")
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	iter := u.db.ScanType(ctx, 0, escapeGlob(prefix)+"*", statsScanCount, "zset").Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+
+		if userID := key[len(prefix):]; userID == "" || strings.Contains(userID, "::") {
+			continue
+		}
+
+		members, err := u.db.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: now, Max: "+inf"}).Result()
+		if err != nil {
+			return core.TokenStats{}, fmt.Errorf("failed to get API keys: %w", err)
+		}
+
+		if len(members) == 0 {
+			continue
+		}
+
+		stats.Users++
+
+		for _, m := range members {
+			if _, tokenType := decodeKeyMember(m); tokenType == core.TokenTypeTCP {
+				stats.TCP++
+			} else {
+				stats.Web++
+			}
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return core.TokenStats{}, fmt.Errorf("failed to scan API keys: %w", err)
+	}
+
+	return stats, nil
+}
+
+// escapeGlob escapes the characters that have a special meaning in Redis MATCH patterns.
+func escapeGlob(s string) string {
+	var sb strings.Builder
+
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			sb.WriteRune('\\')
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+// SaveFeedback prepends the feedback to the shared feedback list and trims the list to the latest feedbackLogSize entries.
+func (u *User) SaveFeedback(ctx context.Context, feedback core.Feedback) error {
+	data, err := json.Marshal(feedback)
+	if err != nil {
+		return fmt.Errorf("failed to encode feedback: %w", err)
+	}
+
+	redisKey := u.feedbacksKey()
+
+	_, err = u.db.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, redisKey, data)
+		pipe.LTrim(ctx, redisKey, 0, feedbackLogSize-1)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save feedback: %w", err)
+	}
+
+	return nil
+}
@@ -2,11 +2,16 @@ package repo
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/ksysoev/make-it-public-tgbot/pkg/core"
+	"github.com/ksysoev/make-it-public-tgbot/pkg/core/conv"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,6 +30,7 @@ func setupRedis(t *testing.T) (*miniredis.Miniredis, *User) {
 	user := &User{
 		db:        client,
 		keyPrefix: "prefix:",
+		convTTL:   convTTL,
 	}
 
 	return mr, user
@@ -41,9 +47,110 @@ func TestNew(t *testing.T) {
 
 	assert.NotNil(t, user)
 	assert.Equal(t, cfg.KeyPrefix, user.keyPrefix)
+	assert.Equal(t, convTTL, user.convTTL)
 	assert.NotNil(t, user.db)
 }
 
+func TestNew_ConversationTTL(t *testing.T) {
+	user := New(Config{ConversationTTL: 5 * time.Minute})
+
+	assert.Equal(t, 5*time.Minute, user.convTTL)
+}
+
+func TestNew_ExpiryNotifications(t *testing.T) {
+	user := New(Config{DB: 2, ExpiryNotifications: true})
+
+	assert.True(t, user.expiryNotifications)
+	assert.Equal(t, 2, user.dbIndex)
+}
+
+func TestConnect(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+
+	defer mr.Close()
+
+	user, err := Connect(context.Background(), Config{RedisAddr: mr.Addr(), KeyPrefix: "prefix:"})
+	require.NoError(t, err)
+
+	defer func() { _ = user.Close() }()
+
+	assert.Equal(t, "prefix:", user.keyPrefix)
+}
+
+func TestConnect_Unreachable(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+
+	addr := mr.Addr()
+	mr.Close()
+
+	user, err := Connect(context.Background(), Config{RedisAddr: addr})
+
+	assert.Nil(t, user)
+	assert.ErrorContains(t, err, "cannot connect to Redis at "+addr)
+}
+
+func TestRedisOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want redis.Options
+		tls  *tls.Config
+	}{
+		{
+			name: "defaults",
+			cfg:  Config{RedisAddr: "localhost:6379", Password: "password"},
+			want: redis.Options{Addr: "localhost:6379", Password: "password"},
+		},
+		{
+			name: "connection settings",
+			cfg: Config{
+				RedisAddr:   "redis.example.com:6380",
+				DB:          2,
+				PoolSize:    20,
+				DialTimeout: 2 * time.Second,
+				ReadTimeout: time.Second,
+			},
+			want: redis.Options{
+				Addr:        "redis.example.com:6380",
+				DB:          2,
+				PoolSize:    20,
+				DialTimeout: 2 * time.Second,
+				ReadTimeout: time.Second,
+			},
+		},
+		{
+			name: "tls",
+			cfg:  Config{RedisAddr: "redis.example.com:6380", UseTLS: true},
+			want: redis.Options{Addr: "redis.example.com:6380"},
+			tls:  &tls.Config{MinVersion: tls.VersionTLS12},
+		},
+		{
+			name: "tls without verification",
+			cfg:  Config{RedisAddr: "localhost:6380", UseTLS: true, TLSSkipVerify: true},
+			want: redis.Options{Addr: "localhost:6380"},
+			tls:  &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: true}, //nolint:gosec // test
+		},
+		{
+			name: "skip verify is ignored without tls",
+			cfg:  Config{RedisAddr: "localhost:6379", TLSSkipVerify: true},
+			want: redis.Options{Addr: "localhost:6379"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := redisOptions(tt.cfg)
+
+			assert.Equal(t, tt.tls, opts.TLSConfig)
+
+			opts.TLSConfig = nil
+			assert.Equal(t, &tt.want, opts)
+		})
+	}
+}
+
 func TestEncodeDecodeKeyMember(t *testing.T) {
 	tests := []struct {
 		tokenType      core.TokenType
@@ -97,7 +204,7 @@ func TestAddAPIKey(t *testing.T) {
 	expiresIn := 3600 * time.Second
 
 	// Test successful add (web)
-	err := user.AddAPIKey(ctx, userID, apiKeyID, core.TokenTypeWeb, expiresIn)
+	err := user.AddAPIKey(ctx, userID, apiKeyID, core.TokenTypeWeb, "", expiresIn)
 	assert.NoError(t, err)
 
 	// Verify key is retrievable
@@ -106,7 +213,7 @@ func TestAddAPIKey(t *testing.T) {
 	assert.Contains(t, keys, apiKeyID)
 
 	// Test adding the same key again (idempotent)
-	err = user.AddAPIKey(ctx, userID, apiKeyID, core.TokenTypeWeb, expiresIn)
+	err = user.AddAPIKey(ctx, userID, apiKeyID, core.TokenTypeWeb, "", expiresIn)
 	assert.NoError(t, err)
 }
 
@@ -117,10 +224,10 @@ func TestAddAPIKey_TypesAreStored(t *testing.T) {
 	ctx := context.Background()
 	userID := "userTyped"
 
-	err := user.AddAPIKey(ctx, userID, "webkey1", core.TokenTypeWeb, 3600*time.Second)
+	err := user.AddAPIKey(ctx, userID, "webkey1", core.TokenTypeWeb, "", 3600*time.Second)
 	require.NoError(t, err)
 
-	err = user.AddAPIKey(ctx, userID, "tcpkey1", core.TokenTypeTCP, 3600*time.Second)
+	err = user.AddAPIKey(ctx, userID, "tcpkey1", core.TokenTypeTCP, "", 3600*time.Second)
 	require.NoError(t, err)
 
 	keys, err := user.GetAPIKeysWithExpiration(ctx, userID)
@@ -137,6 +244,128 @@ func TestAddAPIKey_TypesAreStored(t *testing.T) {
 	assert.Equal(t, core.TokenTypeTCP, typesByID["tcpkey1"])
 }
 
+func TestAddAPIKey_Name(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	userID := "user123"
+
+	require.NoError(t, user.AddAPIKey(ctx, userID, "named", core.TokenTypeWeb, "home server", time.Hour))
+	require.NoError(t, user.AddAPIKey(ctx, userID, "unnamed", core.TokenTypeTCP, "", time.Hour))
+
+	keys, err := user.GetAPIKeysWithExpiration(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	names := map[string]string{}
+	for _, k := range keys {
+		names[k.KeyID] = k.Name
+	}
+
+	assert.Equal(t, "home server", names["named"])
+	assert.Equal(t, "", names["unnamed"])
+
+	// Revoking a key also removes its name.
+	require.NoError(t, user.RevokeToken(ctx, userID, "named"))
+	assert.False(t, mr.Exists("prefix:"+keyNamePrefix+userID))
+}
+
+func TestAddAPIKeyWithLimit(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	userID := "user123"
+	keysKey := "prefix:" + apiKeyPrefix + userID
+
+	// A legacy bare member counts as a web key.
+	_, err := mr.ZAdd(keysKey, float64(time.Now().Add(time.Hour).Unix()), "legacy")
+	require.NoError(t, err)
+
+	// An expired key doesn't count towards the limit.
+	_, err = mr.ZAdd(keysKey, float64(time.Now().Add(-time.Hour).Unix()), memberPrefixWeb+"expired")
+	require.NoError(t, err)
+
+	require.NoError(t, user.AddAPIKeyWithLimit(ctx, userID, "web1", core.TokenTypeWeb, "home", time.Hour, 2))
+
+	err = user.AddAPIKeyWithLimit(ctx, userID, "web2", core.TokenTypeWeb, "", time.Hour, 2)
+	assert.ErrorIs(t, err, core.ErrTokenLimitReached)
+
+	// Re-adding an existing key is allowed at the limit and updates its name.
+	require.NoError(t, user.AddAPIKeyWithLimit(ctx, userID, "web1", core.TokenTypeWeb, "office", time.Hour, 2))
+
+	// Limits are counted per type.
+	require.NoError(t, user.AddAPIKeyWithLimit(ctx, userID, "tcp1", core.TokenTypeTCP, "", time.Hour, 1))
+
+	err = user.AddAPIKeyWithLimit(ctx, userID, "tcp2", core.TokenTypeTCP, "", time.Hour, 1)
+	assert.ErrorIs(t, err, core.ErrTokenLimitReached)
+
+	keys, err := user.GetAPIKeysWithExpiration(ctx, userID)
+	require.NoError(t, err)
+
+	got := map[string]string{}
+	for _, k := range keys {
+		got[k.KeyID] = k.Name
+	}
+
+	assert.Equal(t, map[string]string{"legacy": "", "web1": "office", "tcp1": ""}, got)
+}
+
+func TestAddAPIKeyWithLimit_Concurrent(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	const (
+		attempts = 20
+		limit    = 3
+	)
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, attempts)
+
+	for i := range attempts {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs <- user.AddAPIKeyWithLimit(context.Background(), "user123", fmt.Sprintf("key%d", i), core.TokenTypeWeb, "", time.Hour, limit)
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	added := 0
+
+	for err := range errs {
+		if err == nil {
+			added++
+			continue
+		}
+
+		assert.ErrorIs(t, err, core.ErrTokenLimitReached)
+	}
+
+	assert.Equal(t, limit, added)
+
+	keys, err := user.GetAPIKeys(context.Background(), "user123")
+	require.NoError(t, err)
+	assert.Len(t, keys, limit)
+}
+
+func TestAddAPIKeyWithLimit_RedisError(t *testing.T) {
+	mr, user := setupRedis(t)
+	mr.Close()
+
+	err := user.AddAPIKeyWithLimit(context.Background(), "user123", "key1", core.TokenTypeWeb, "", time.Hour, 3)
+
+	assert.ErrorContains(t, err, "failed to add API key")
+	assert.NotErrorIs(t, err, core.ErrTokenLimitReached)
+}
+
 func TestGetAPIKeys(t *testing.T) {
 	mr, user := setupRedis(t)
 	defer mr.Close()
@@ -152,7 +381,7 @@ func TestGetAPIKeys(t *testing.T) {
 	// Add a web key
 	apiKeyID := "key123"
 	expiresIn := 3600 * time.Second
-	err = user.AddAPIKey(ctx, userID, apiKeyID, core.TokenTypeWeb, expiresIn)
+	err = user.AddAPIKey(ctx, userID, apiKeyID, core.TokenTypeWeb, "", expiresIn)
 	assert.NoError(t, err)
 
 	// Returns bare key ID (prefix stripped)
@@ -164,7 +393,7 @@ func TestGetAPIKeys(t *testing.T) {
 
 	// Add a TCP key
 	apiKeyID2 := "key456"
-	err = user.AddAPIKey(ctx, userID, apiKeyID2, core.TokenTypeTCP, expiresIn)
+	err = user.AddAPIKey(ctx, userID, apiKeyID2, core.TokenTypeTCP, "", expiresIn)
 	assert.NoError(t, err)
 
 	// Both keys returned as bare IDs
@@ -217,7 +446,7 @@ func TestGetAPIKeysWithExpiration(t *testing.T) {
 	assert.Empty(t, keys)
 
 	// Add a web key
-	err = user.AddAPIKey(ctx, userID, "keyB", core.TokenTypeWeb, 48*time.Hour)
+	err = user.AddAPIKey(ctx, userID, "keyB", core.TokenTypeWeb, "", 48*time.Hour)
 	require.NoError(t, err)
 
 	// Directly insert an expired entry to simulate expiry
@@ -235,6 +464,41 @@ func TestGetAPIKeysWithExpiration(t *testing.T) {
 	assert.True(t, keys[0].ExpiresAt.After(time.Now()), "expiry should be in the future")
 }
 
+func TestGetAPIKeysWithExpiration_MultipleKeys(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	userID := "multiUser"
+
+	before := time.Now()
+
+	require.NoError(t, user.AddAPIKey(ctx, userID, "web1", core.TokenTypeWeb, "", 24*time.Hour))
+	require.NoError(t, user.AddAPIKey(ctx, userID, "web2", core.TokenTypeWeb, "", 72*time.Hour))
+	require.NoError(t, user.AddAPIKey(ctx, userID, "tcp1", core.TokenTypeTCP, "", 48*time.Hour))
+
+	keys, err := user.GetAPIKeysWithExpiration(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+
+	// Keys are ordered by expiration; ExpiresAt must include the ttlOffset subtracted on insert.
+	expected := []struct {
+		keyID     string
+		tokenType core.TokenType
+		expiresIn time.Duration
+	}{
+		{"web1", core.TokenTypeWeb, 24 * time.Hour},
+		{"tcp1", core.TokenTypeTCP, 48 * time.Hour},
+		{"web2", core.TokenTypeWeb, 72 * time.Hour},
+	}
+
+	for i, exp := range expected {
+		assert.Equal(t, exp.keyID, keys[i].KeyID)
+		assert.Equal(t, exp.tokenType, keys[i].Type)
+		assert.WithinDuration(t, before.Add(exp.expiresIn), keys[i].ExpiresAt, 2*time.Second)
+	}
+}
+
 func TestGetAPIKeysWithExpiration_BackwardCompat(t *testing.T) {
 	// Bare members (no prefix) should be returned with TokenTypeWeb.
 	mr, user := setupRedis(t)
@@ -255,6 +519,127 @@ func TestGetAPIKeysWithExpiration_BackwardCompat(t *testing.T) {
 	assert.Equal(t, core.TokenTypeWeb, keys[0].Type)
 }
 
+// roundTripCounter is a go-redis hook counting the round trips made to Redis, a pipeline counts as one.
+type roundTripCounter struct {
+	n int
+}
+
+func (c *roundTripCounter) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (c *roundTripCounter) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		c.n++
+		return next(ctx, cmd)
+	}
+}
+
+func (c *roundTripCounter) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		c.n++
+		return next(ctx, cmds)
+	}
+}
+
+func TestGetAPIKeys_SingleRoundTrip(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	userID := "roundTripUser"
+	redisKey := user.tokenKey(userID)
+
+	require.NoError(t, user.AddAPIKey(ctx, userID, "fresh", core.TokenTypeWeb, "My label", time.Hour))
+	require.NoError(t, user.db.ZAdd(ctx, redisKey, redis.Z{
+		Score:  float64(time.Now().Add(-time.Hour).Unix()),
+		Member: encodeKeyMember("expired", core.TokenTypeWeb),
+	}).Err())
+
+	counter := &roundTripCounter{}
+	user.db.AddHook(counter)
+
+	keys, err := user.GetAPIKeysWithExpiration(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "fresh", keys[0].KeyID)
+	assert.Equal(t, "My label", keys[0].Name)
+	assert.Equal(t, 1, counter.n)
+
+	ids, err := user.GetAPIKeys(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fresh"}, ids)
+	assert.Equal(t, 2, counter.n)
+
+	// The expired key is removed from Redis, not only skipped.
+	members, err := mr.ZMembers(redisKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{encodeKeyMember("fresh", core.TokenTypeWeb)}, members)
+}
+
+func TestExpiredKeyNamesAreRemoved(t *testing.T) {
+	tests := []struct {
+		read func(ctx context.Context, user *User, userID string) error
+		name string
+	}{
+		{
+			name: "GetAPIKeys",
+			read: func(ctx context.Context, user *User, userID string) error {
+				_, err := user.GetAPIKeys(ctx, userID)
+				return err
+			},
+		},
+		{
+			name: "GetAPIKeysWithExpiration",
+			read: func(ctx context.Context, user *User, userID string) error {
+				_, err := user.GetAPIKeysWithExpiration(ctx, userID)
+				return err
+			},
+		},
+		{
+			name: "AddAPIKeyWithLimit",
+			read: func(ctx context.Context, user *User, userID string) error {
+				return user.AddAPIKeyWithLimit(ctx, userID, "added", core.TokenTypeWeb, "", time.Hour, 10)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, user := setupRedis(t)
+			defer mr.Close()
+
+			ctx := context.Background()
+			userID := "user123"
+
+			require.NoError(t, user.AddAPIKey(ctx, userID, "fresh", core.TokenTypeWeb, "home", time.Hour))
+
+			// Keys of every member format expire with a name.
+			expired := float64(time.Now().Add(-time.Hour).Unix())
+			for _, member := range []string{memberPrefixWeb + "web", memberPrefixTCP + "tcp", "legacy"} {
+				require.NoError(t, user.db.ZAdd(ctx, user.tokenKey(userID), redis.Z{Score: expired, Member: member}).Err())
+			}
+
+			require.NoError(t, user.db.HSet(ctx, user.keyNamesKey(userID), "web", "old web", "tcp", "old db", "legacy", "old").Err())
+
+			require.NoError(t, tt.read(ctx, user, userID))
+
+			names, err := user.db.HGetAll(ctx, user.keyNamesKey(userID)).Result()
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"fresh": "home"}, names)
+		})
+	}
+}
+
+func TestGetAPIKeys_Error(t *testing.T) {
+	mr, user := setupRedis(t)
+	mr.Close()
+
+	_, err := user.GetAPIKeys(context.Background(), "user123")
+	assert.ErrorContains(t, err, "failed to get API keys")
+
+	_, err = user.GetAPIKeysWithExpiration(context.Background(), "user123")
+	assert.ErrorContains(t, err, "failed to get API keys with scores")
+}
+
 func TestClose(t *testing.T) {
 	mr, user := setupRedis(t)
 	defer mr.Close()
@@ -272,7 +657,7 @@ func TestRevokeToken(t *testing.T) {
 	expiresIn := 3600 * time.Second
 
 	// Add a key to revoke (as web)
-	err := user.AddAPIKey(ctx, userID, "key123", core.TokenTypeWeb, expiresIn)
+	err := user.AddAPIKey(ctx, userID, "key123", core.TokenTypeWeb, "", expiresIn)
 	require.NoError(t, err)
 
 	tests := []struct {
@@ -301,8 +686,8 @@ func TestRevokeToken(t *testing.T) {
 		{
 			name: "revoke key when user has multiple keys",
 			setup: func() {
-				_ = user.AddAPIKey(ctx, userID, "key123", core.TokenTypeWeb, expiresIn)
-				_ = user.AddAPIKey(ctx, userID, "key456", core.TokenTypeWeb, expiresIn)
+				_ = user.AddAPIKey(ctx, userID, "key123", core.TokenTypeWeb, "", expiresIn)
+				_ = user.AddAPIKey(ctx, userID, "key456", core.TokenTypeWeb, "", expiresIn)
 			},
 			targetKey:     "key123",
 			expectedError: false,
@@ -334,7 +719,7 @@ func TestRevokeToken_TCPKey(t *testing.T) {
 	ctx := context.Background()
 	userID := "userTCP"
 
-	err := user.AddAPIKey(ctx, userID, "tcpkey1", core.TokenTypeTCP, 3600*time.Second)
+	err := user.AddAPIKey(ctx, userID, "tcpkey1", core.TokenTypeTCP, "", 3600*time.Second)
 	require.NoError(t, err)
 
 	err = user.RevokeToken(ctx, userID, "tcpkey1")
@@ -365,3 +750,724 @@ func TestRevokeToken_LegacyBareKey(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, keys)
 }
+
+func TestRevokeTokens(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	userID := "user123"
+
+	require.NoError(t, user.AddAPIKey(ctx, userID, "web1", core.TokenTypeWeb, "home", time.Hour))
+	require.NoError(t, user.AddAPIKey(ctx, userID, "web2", core.TokenTypeWeb, "", time.Hour))
+	require.NoError(t, user.AddAPIKey(ctx, userID, "tcp1", core.TokenTypeTCP, "db", time.Hour))
+
+	score := float64(time.Now().Add(time.Hour).Unix())
+	require.NoError(t, user.db.ZAdd(ctx, user.tokenKey(userID), redis.Z{Score: score, Member: "barekey"}).Err())
+
+	removed, err := user.RevokeTokens(ctx, userID, []string{"web1", "tcp1", "barekey", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, removed)
+
+	keys, err := user.GetAPIKeys(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web2"}, keys)
+
+	assert.False(t, mr.Exists(user.keyNamesKey(userID)), "names of removed keys must be removed")
+
+	removed, err = user.RevokeTokens(ctx, userID, nil)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+}
+
+func TestRevokeTokens_RedisError(t *testing.T) {
+	mr, user := setupRedis(t)
+	mr.Close()
+
+	_, err := user.RevokeTokens(context.Background(), "user123", []string{"key1", "key2"})
+	assert.ErrorContains(t, err, "failed to revoke API keys")
+}
+
+func TestSaveConversation_TTL(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	user.convTTL = 10 * time.Minute
+
+	err := user.SaveConversation(ctx, conv.New("user123"))
+	require.NoError(t, err)
+
+	redisKey := user.keyPrefix + convKeyPrefix + "user123"
+	assert.Equal(t, 10*time.Minute, mr.TTL(redisKey))
+
+	mr.FastForward(11 * time.Minute)
+
+	cnv, err := user.GetConversation(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, conv.StateIdle, cnv.State)
+}
+
+func TestGetConversation(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("missing key returns idle conversation", func(t *testing.T) {
+		cnv, err := user.GetConversation(ctx, "newUser")
+		require.NoError(t, err)
+		require.NotNil(t, cnv)
+		assert.Equal(t, "newUser", cnv.ID)
+		assert.Equal(t, conv.StateIdle, cnv.State)
+	})
+
+	t.Run("saved conversation is restored", func(t *testing.T) {
+		saved := conv.New("user123")
+		err := saved.Start("new_token", conv.NewQuestions([]conv.Question{{Text: "Continue?", Answers: []string{"Yes", "No"}}}))
+		require.NoError(t, err)
+
+		err = user.SaveConversation(ctx, saved)
+		require.NoError(t, err)
+
+		cnv, err := user.GetConversation(ctx, "user123")
+		require.NoError(t, err)
+		assert.Equal(t, saved, cnv)
+	})
+
+	t.Run("corrupted data returns error", func(t *testing.T) {
+		err := mr.Set(user.keyPrefix+convKeyPrefix+"broken", "not json")
+		require.NoError(t, err)
+
+		cnv, err := user.GetConversation(ctx, "broken")
+		assert.ErrorContains(t, err, "failed to decode conversation")
+		assert.Nil(t, cnv)
+	})
+
+	t.Run("redis error is returned", func(t *testing.T) {
+		mr.SetError("connection refused")
+		defer mr.SetError("")
+
+		cnv, err := user.GetConversation(ctx, "user123")
+		assert.ErrorContains(t, err, "failed to get conversation")
+		assert.Nil(t, cnv)
+	})
+}
+
+func TestGetExpiredFlow(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	user.convTTL = 10 * time.Minute
+
+	active := conv.New("user123")
+	require.NoError(t, active.Start("new_token", conv.NewQuestions([]conv.Question{{Text: "Continue?", Answers: []string{"Yes", "No"}}})))
+	require.NoError(t, user.SaveConversation(ctx, active))
+
+	flow, err := user.GetExpiredFlow(ctx, "user123")
+	require.NoError(t, err)
+	assert.Empty(t, flow, "a stored conversation hasn't expired")
+
+	mr.FastForward(11 * time.Minute)
+
+	flow, err = user.GetExpiredFlow(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, conv.State("new_token"), flow)
+
+	mr.FastForward(convFlowTTL)
+
+	flow, err = user.GetExpiredFlow(ctx, "user123")
+	require.NoError(t, err)
+	assert.Empty(t, flow, "the flow is forgotten after convFlowTTL")
+
+	t.Run("finished conversation", func(t *testing.T) {
+		require.NoError(t, user.SaveConversation(ctx, active))
+
+		_, err := active.Submit("Yes")
+		require.NoError(t, err)
+		require.NoError(t, user.SaveConversation(ctx, active))
+
+		mr.FastForward(11 * time.Minute)
+
+		flow, err := user.GetExpiredFlow(ctx, "user123")
+		require.NoError(t, err)
+		assert.Empty(t, flow)
+	})
+
+	t.Run("deleted conversation", func(t *testing.T) {
+		restarted := conv.New("user456")
+		require.NoError(t, restarted.Start("new_token", conv.NewQuestions([]conv.Question{{Text: "Continue?"}})))
+		require.NoError(t, user.SaveConversation(ctx, restarted))
+		require.NoError(t, user.DeleteConversation(ctx, "user456"))
+
+		flow, err := user.GetExpiredFlow(ctx, "user456")
+		require.NoError(t, err)
+		assert.Empty(t, flow)
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		mr.SetError("connection refused")
+		defer mr.SetError("")
+
+		_, err := user.GetExpiredFlow(ctx, "user123")
+		assert.ErrorContains(t, err, "failed to get expired conversation flow")
+	})
+}
+
+func TestTokenCreationCount(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	count, resetAt, err := user.GetTokenCreationCount(ctx, "user123")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.True(t, resetAt.IsZero())
+
+	before := time.Now()
+
+	for i := 1; i <= 3; i++ {
+		n, err := user.IncrementTokenCreationCount(ctx, "user123", 24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, i, n)
+	}
+
+	redisKey := user.keyPrefix + creationsKeyPrefix + "user123"
+	assert.Equal(t, 24*time.Hour, mr.TTL(redisKey))
+
+	count, resetAt, err = user.GetTokenCreationCount(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.WithinDuration(t, before.Add(24*time.Hour), resetAt, 5*time.Second)
+}
+
+func TestTokenCreationCount_RollingWindow(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	redisKey := user.keyPrefix + creationsKeyPrefix + "user123"
+	now := time.Now()
+
+	// Two creations 23 hours ago leave the window within the hour, one created an hour ago stays for 23 more.
+	leavesAt := func(createdAgo time.Duration) float64 {
+		return float64(now.Add(24*time.Hour - createdAgo).UnixMilli())
+	}
+
+	_, err := mr.ZAdd(redisKey, leavesAt(23*time.Hour), "old1")
+	require.NoError(t, err)
+	_, err = mr.ZAdd(redisKey, leavesAt(23*time.Hour), "old2")
+	require.NoError(t, err)
+	_, err = mr.ZAdd(redisKey, leavesAt(time.Hour), "recent")
+	require.NoError(t, err)
+
+	count, resetAt, err := user.GetTokenCreationCount(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.WithinDuration(t, now.Add(time.Hour), resetAt, time.Second)
+
+	// Once the oldest creations leave the window, only they stop counting, unlike a window that resets as a whole.
+	_, err = mr.ZAdd(redisKey, float64(now.Add(-time.Minute).UnixMilli()), "old1")
+	require.NoError(t, err)
+	_, err = mr.ZAdd(redisKey, float64(now.Add(-time.Minute).UnixMilli()), "old2")
+	require.NoError(t, err)
+
+	n, err := user.IncrementTokenCreationCount(ctx, "user123", 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	count, resetAt, err = user.GetTokenCreationCount(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.WithinDuration(t, now.Add(23*time.Hour), resetAt, time.Second)
+}
+
+func TestTokenCreationCount_Error(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	require.NoError(t, mr.Set(user.keyPrefix+creationsKeyPrefix+"user123", "3"))
+
+	_, err := user.IncrementTokenCreationCount(ctx, "user123", 24*time.Hour)
+	assert.ErrorContains(t, err, "failed to increment token creation count")
+
+	_, _, err = user.GetTokenCreationCount(ctx, "user123")
+	assert.ErrorContains(t, err, "failed to get token creation count")
+}
+
+func TestLastRegeneration(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	last, err := user.GetLastRegeneration(ctx, "user123")
+	require.NoError(t, err)
+	assert.True(t, last.IsZero())
+
+	at := time.Now()
+	require.NoError(t, user.SetLastRegeneration(ctx, "user123", at, time.Minute))
+
+	last, err = user.GetLastRegeneration(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, at.UnixMilli(), last.UnixMilli())
+	assert.Equal(t, time.Minute, mr.TTL(user.keyPrefix+regenKeyPrefix+"user123"))
+
+	mr.FastForward(time.Minute)
+
+	last, err = user.GetLastRegeneration(ctx, "user123")
+	require.NoError(t, err)
+	assert.True(t, last.IsZero())
+
+	mr.Close()
+
+	assert.ErrorContains(t, user.SetLastRegeneration(ctx, "user123", at, time.Minute), "failed to set last regeneration")
+
+	_, err = user.GetLastRegeneration(ctx, "user123")
+	assert.ErrorContains(t, err, "failed to get last regeneration")
+}
+
+func TestUserChat(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	_, err := user.GetUserChat(ctx, "user123")
+	assert.ErrorIs(t, err, core.ErrUserChatNotFound)
+
+	require.NoError(t, user.SaveUserChat(ctx, "user123", 42))
+
+	chatID, err := user.GetUserChat(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), chatID)
+
+	// A newer chat replaces the stored one.
+	require.NoError(t, user.SaveUserChat(ctx, "user123", -100500))
+
+	chatID, err = user.GetUserChat(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-100500), chatID)
+
+	_, err = user.GetUserChat(ctx, "other")
+	assert.ErrorIs(t, err, core.ErrUserChatNotFound)
+}
+
+func TestUserChat_Inactive(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	require.NoError(t, user.SaveUserChat(ctx, "user123", 42))
+	require.NoError(t, user.SaveUserChat(ctx, "other", 43))
+	require.NoError(t, user.DeactivateUserChat(ctx, "user123"))
+
+	_, err := user.GetUserChat(ctx, "user123")
+	assert.ErrorIs(t, err, core.ErrUserChatInactive)
+
+	chatID, err := user.GetUserChat(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, int64(43), chatID)
+
+	// The next message of the user saves the chat again, which reactivates it.
+	require.NoError(t, user.SaveUserChat(ctx, "user123", 42))
+
+	chatID, err = user.GetUserChat(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), chatID)
+}
+
+func TestUserChat_RedisError(t *testing.T) {
+	mr, user := setupRedis(t)
+	mr.Close()
+
+	ctx := context.Background()
+
+	assert.ErrorContains(t, user.SaveUserChat(ctx, "user123", 42), "failed to save user chat")
+
+	_, err := user.GetUserChat(ctx, "user123")
+	assert.ErrorContains(t, err, "failed to get user chat")
+
+	assert.ErrorContains(t, user.DeactivateUserChat(ctx, "user123"), "failed to deactivate user chat")
+}
+
+func TestUserSetting(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	value, err := user.GetUserSetting(ctx, "user123", core.SettingTimezone)
+	require.NoError(t, err)
+	assert.Empty(t, value)
+
+	require.NoError(t, user.SetUserSetting(ctx, "user123", core.SettingTimezone, "Europe/Berlin"))
+
+	value, err = user.GetUserSetting(ctx, "user123", core.SettingTimezone)
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", value)
+
+	// A newer value overwrites the stored one.
+	require.NoError(t, user.SetUserSetting(ctx, "user123", core.SettingTimezone, "Asia/Tokyo"))
+
+	value, err = user.GetUserSetting(ctx, "user123", core.SettingTimezone)
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", value)
+
+	value, err = user.GetUserSetting(ctx, "user123", "missing")
+	require.NoError(t, err)
+	assert.Empty(t, value)
+
+	value, err = user.GetUserSetting(ctx, "other", core.SettingTimezone)
+	require.NoError(t, err)
+	assert.Empty(t, value)
+
+	// Deleting a setting leaves the others alone, deleting a missing one is fine.
+	require.NoError(t, user.SetUserSetting(ctx, "user123", core.SettingDefaultExpiration, "30"))
+	require.NoError(t, user.DeleteUserSetting(ctx, "user123", core.SettingTimezone))
+	require.NoError(t, user.DeleteUserSetting(ctx, "user123", core.SettingTimezone))
+
+	value, err = user.GetUserSetting(ctx, "user123", core.SettingTimezone)
+	require.NoError(t, err)
+	assert.Empty(t, value)
+
+	value, err = user.GetUserSetting(ctx, "user123", core.SettingDefaultExpiration)
+	require.NoError(t, err)
+	assert.Equal(t, "30", value)
+}
+
+func TestUserSetting_DoesNotInterfereWithTokens(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	require.NoError(t, user.AddAPIKey(ctx, "user123", "key1", core.TokenTypeWeb, "", time.Hour))
+	require.NoError(t, user.SetUserSetting(ctx, "user123", core.SettingTimezone, "UTC"))
+
+	keys, err := user.GetAPIKeys(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key1"}, keys)
+
+	assert.True(t, mr.Exists(user.settingsKey("user123")))
+	assert.NotEqual(t, user.tokenKey("user123"), user.settingsKey("user123"))
+	assert.NotEqual(t, user.convKey("user123"), user.settingsKey("user123"))
+}
+
+func TestUserSetting_RedisError(t *testing.T) {
+	mr, user := setupRedis(t)
+	mr.Close()
+
+	ctx := context.Background()
+
+	assert.ErrorContains(t, user.SetUserSetting(ctx, "user123", core.SettingTimezone, "UTC"), "failed to set user setting")
+
+	_, err := user.GetUserSetting(ctx, "user123", core.SettingTimezone)
+	assert.ErrorContains(t, err, "failed to get user setting")
+}
+
+func TestUserLock(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	release, err := user.AcquireUserLock(ctx, "user123", 30*time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, release)
+
+	assert.True(t, mr.Exists(user.lockKey("user123")))
+	assert.Equal(t, 30*time.Second, mr.TTL(user.lockKey("user123")))
+
+	require.NoError(t, release(ctx))
+	assert.False(t, mr.Exists(user.lockKey("user123")))
+
+	// The lock can be taken again once released.
+	release, err = user.AcquireUserLock(ctx, "user123", 30*time.Second)
+	require.NoError(t, err)
+	require.NoError(t, release(ctx))
+}
+
+func TestUserLock_Contention(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	release, err := user.AcquireUserLock(ctx, "user123", 30*time.Second)
+	require.NoError(t, err)
+
+	_, err = user.AcquireUserLock(ctx, "user123", 30*time.Second)
+	assert.ErrorIs(t, err, core.ErrUserBusy)
+
+	// Locks of other users are independent.
+	other, err := user.AcquireUserLock(ctx, "user456", 30*time.Second)
+	require.NoError(t, err)
+	require.NoError(t, other(ctx))
+
+	require.NoError(t, release(ctx))
+
+	_, err = user.AcquireUserLock(ctx, "user123", 30*time.Second)
+	assert.NoError(t, err)
+}
+
+func TestUserLock_ExpiredLockIsNotReleasedByOldOwner(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	stale, err := user.AcquireUserLock(ctx, "user123", time.Second)
+	require.NoError(t, err)
+
+	mr.FastForward(2 * time.Second)
+
+	_, err = user.AcquireUserLock(ctx, "user123", 30*time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, stale(ctx))
+	assert.True(t, mr.Exists(user.lockKey("user123")), "lock of the new owner must be kept")
+
+	_, err = user.AcquireUserLock(ctx, "user123", 30*time.Second)
+	assert.ErrorIs(t, err, core.ErrUserBusy)
+}
+
+func TestUserLock_RedisError(t *testing.T) {
+	mr, user := setupRedis(t)
+
+	ctx := context.Background()
+
+	release, err := user.AcquireUserLock(ctx, "user123", 30*time.Second)
+	require.NoError(t, err)
+
+	mr.Close()
+
+	assert.ErrorContains(t, release(ctx), "failed to release user lock")
+
+	_, err = user.AcquireUserLock(ctx, "user123", 30*time.Second)
+	assert.ErrorContains(t, err, "failed to acquire user lock")
+	assert.NotErrorIs(t, err, core.ErrUserBusy)
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	done, err := user.ClaimIdempotencyKey(ctx, "user123", "sess:1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, done, "first claim must be granted")
+	assert.Equal(t, time.Minute, mr.TTL(user.idempotencyKey("user123", "sess:1")))
+
+	// A repeated claim while the first request is in progress is rejected.
+	_, err = user.ClaimIdempotencyKey(ctx, "user123", "sess:1", time.Minute)
+	assert.ErrorIs(t, err, core.ErrUserBusy)
+
+	require.NoError(t, user.MarkIdempotencyKeyDone(ctx, "user123", "sess:1"))
+	assert.Equal(t, time.Minute, mr.TTL(user.idempotencyKey("user123", "sess:1")), "marking the key done must keep the TTL")
+
+	// Only the marker is stored, never the response.
+	marker, err := mr.Get(user.idempotencyKey("user123", "sess:1"))
+	require.NoError(t, err)
+	assert.Equal(t, idempotencyDone, marker)
+
+	// A repeated key is reported as done.
+	done, err = user.ClaimIdempotencyKey(ctx, "user123", "sess:1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, done)
+
+	// Keys are independent per user.
+	done, err = user.ClaimIdempotencyKey(ctx, "user456", "sess:1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, done)
+}
+
+func TestIdempotencyKey_Release(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	_, err := user.ClaimIdempotencyKey(ctx, "user123", "sess:1", time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, user.ReleaseIdempotencyKey(ctx, "user123", "sess:1"))
+
+	done, err := user.ClaimIdempotencyKey(ctx, "user123", "sess:1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, done, "a released key can be claimed again")
+}
+
+func TestIdempotencyKey_Expired(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	_, err := user.ClaimIdempotencyKey(ctx, "user123", "sess:1", time.Minute)
+	require.NoError(t, err)
+
+	mr.FastForward(2 * time.Minute)
+
+	// An expired claim is not marked done.
+	require.NoError(t, user.MarkIdempotencyKeyDone(ctx, "user123", "sess:1"))
+	assert.False(t, mr.Exists(user.idempotencyKey("user123", "sess:1")))
+
+	done, err := user.ClaimIdempotencyKey(ctx, "user123", "sess:1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, done)
+}
+
+func TestIdempotencyKey_RedisError(t *testing.T) {
+	mr, user := setupRedis(t)
+	mr.Close()
+
+	ctx := context.Background()
+
+	_, err := user.ClaimIdempotencyKey(ctx, "user123", "sess:1", time.Minute)
+	assert.ErrorContains(t, err, "failed to claim idempotency key")
+
+	assert.ErrorContains(t, user.MarkIdempotencyKeyDone(ctx, "user123", "sess:1"), "failed to mark idempotency key as done")
+	assert.ErrorContains(t, user.ReleaseIdempotencyKey(ctx, "user123", "sess:1"), "failed to release idempotency key")
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&Config{RedisAddr: "localhost:6379"}).Validate())
+
+	err := (&Config{ConversationTTL: -time.Minute}).Validate()
+	assert.ErrorContains(t, err, "redis_addr is required")
+	assert.ErrorContains(t, err, "conversation_ttl must not be negative")
+}
+
+func TestAuditLog(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	start := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	for i := range auditLogSize + 5 {
+		require.NoError(t, user.AppendAuditLog(ctx, core.AuditEntry{
+			Time:   start.Add(time.Duration(i) * time.Minute),
+			UserID: "user123",
+			Action: core.AuditActionCreate,
+			KeyID:  fmt.Sprintf("key%d", i),
+		}))
+	}
+
+	// The list is capped to the latest entries.
+	items, err := mr.List("prefix:" + auditKeyPrefix + "user123")
+	require.NoError(t, err)
+	assert.Len(t, items, auditLogSize)
+
+	entries, err := user.GetAuditLog(ctx, "user123", 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, []core.AuditEntry{
+		{Time: start.Add(104 * time.Minute), UserID: "user123", Action: core.AuditActionCreate, KeyID: "key104"},
+		{Time: start.Add(103 * time.Minute), UserID: "user123", Action: core.AuditActionCreate, KeyID: "key103"},
+	}, entries)
+
+	entries, err = user.GetAuditLog(ctx, "other", 10)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestAuditLog_InvalidEntry(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	_, err := mr.Lpush("prefix:"+auditKeyPrefix+"user123", "not json")
+	require.NoError(t, err)
+
+	_, err = user.GetAuditLog(context.Background(), "user123", 10)
+	assert.ErrorContains(t, err, "failed to decode audit entry")
+}
+
+func TestGetTokenStats(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	require.NoError(t, user.AddAPIKey(ctx, "user1", "web1", core.TokenTypeWeb, "", time.Hour))
+	require.NoError(t, user.AddAPIKey(ctx, "user1", "tcp1", core.TokenTypeTCP, "", time.Hour))
+	require.NoError(t, user.AddAPIKey(ctx, "user2", "web2", core.TokenTypeWeb, "", time.Hour))
+
+	// Legacy bare members count as web tokens.
+	_, err := mr.ZAdd("prefix:"+apiKeyPrefix+"user3", float64(time.Now().Add(time.Hour).Unix()), "legacy")
+	require.NoError(t, err)
+
+	// Users whose tokens all expired are not counted.
+	_, err = mr.ZAdd("prefix:"+apiKeyPrefix+"user4", float64(time.Now().Add(-time.Hour).Unix()), "w:old")
+	require.NoError(t, err)
+
+	// Conversations and other keys sharing the prefix are ignored.
+	require.NoError(t, user.SaveConversation(ctx, conv.New("user1")))
+	_, err = mr.ZAdd("prefix:"+apiKeyPrefix+"user1::conv::", float64(time.Now().Add(time.Hour).Unix()), "w:stray")
+	require.NoError(t, err)
+
+	stats, err := user.GetTokenStats(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, core.TokenStats{Users: 3, Web: 3, TCP: 1}, stats)
+}
+
+func TestGetTokenStats_Empty(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	stats, err := user.GetTokenStats(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, core.TokenStats{}, stats)
+}
+
+func TestEscapeGlob(t *testing.T) {
+	assert.Equal(t, "prefix:USER_KEYS::", escapeGlob("prefix:USER_KEYS::"))
+	assert.Equal(t, `a\*b\?c\[d\]\\`, escapeGlob(`a*b?c[d]\`))
+}
+
+func TestKeyHelpers(t *testing.T) {
+	u := &User{keyPrefix: "prefix:"}
+
+	assert.Equal(t, "prefix:USER_KEYS::123", u.tokenKey("123"))
+	assert.Equal(t, "prefix:KEY_NAMES::123", u.keyNamesKey("123"))
+	assert.Equal(t, "prefix:CONV::123", u.convKey("123"))
+	assert.Equal(t, "prefix:TOKEN_CREATIONS::123", u.creationsKey("123"))
+	assert.Equal(t, "prefix:USER_CHATS", u.chatsKey())
+	assert.Equal(t, "prefix:AUDIT::123", u.auditKey("123"))
+	assert.Equal(t, "prefix:LOCK::123", u.lockKey("123"))
+	assert.Equal(t, "prefix:IDEMPOTENCY::123::42:7", u.idempotencyKey("123", "42:7"))
+}
+
+func TestSaveFeedback(t *testing.T) {
+	mr, user := setupRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	at := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	for i := range feedbackLogSize + 1 {
+		require.NoError(t, user.SaveFeedback(ctx, core.Feedback{
+			Time:   at,
+			UserID: "user123",
+			Text:   fmt.Sprintf("feedback %d", i),
+		}))
+	}
+
+	items, err := mr.List("prefix:" + feedbackKey)
+	require.NoError(t, err)
+	require.Len(t, items, feedbackLogSize)
+
+	var latest core.Feedback
+
+	require.NoError(t, json.Unmarshal([]byte(items[0]), &latest))
+	assert.Equal(t, core.Feedback{Time: at, UserID: "user123", Text: fmt.Sprintf("feedback %d", feedbackLogSize)}, latest)
+}